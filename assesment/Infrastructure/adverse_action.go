@@ -0,0 +1,76 @@
+package Infrastructure
+
+import (
+	"assesment/domain"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// defaultAdverseActionTemplate is used when no template file is configured. Templates are
+// executed with a domain.AdverseActionNotice.
+const defaultAdverseActionTemplate = `Notice of Action Taken on Your Loan Application
+
+Dear {{.BorrowerName}},
+
+Thank you for your application of {{.AppliedAt.Format "2 January 2006"}} for a loan of {{.Amount}} (application {{.LoanID}}). After careful review we are unable to approve it.
+
+The principal reasons for our decision are:
+{{range .Reasons}}  - {{.Description}}
+{{end}}{{with .Note}}
+{{.}}
+{{end}}
+You may ask for a copy of the information this decision was based on, and you may apply again at any time. If you have questions about this decision, please contact us and quote application {{.LoanID}}.
+
+Date of decision: {{.RejectedAt.Format "2 January 2006"}}
+`
+
+// templateAdverseActionNotifier renders adverse-action notices from a text template and emails them.
+type templateAdverseActionNotifier struct {
+	template *template.Template
+}
+
+// NewTemplateAdverseActionNotifier creates an AdverseActionNotifier from the template in
+// templateFile, or from the default template when templateFile is empty.
+func NewTemplateAdverseActionNotifier(templateFile string) (domain.AdverseActionNotifier, error) {
+	text := defaultAdverseActionTemplate
+	if templateFile != "" {
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+
+	tmpl, err := template.New("adverse_action").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid adverse-action template: %w", err)
+	}
+	return &templateAdverseActionNotifier{template: tmpl}, nil
+}
+
+// RenderNotice returns the text of the notice.
+func (n *templateAdverseActionNotifier) RenderNotice(notice domain.AdverseActionNotice) (string, error) {
+	var body strings.Builder
+	if err := n.template.Execute(&body, notice); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
+
+// SendNotice emails the notice to the borrower.
+func (n *templateAdverseActionNotifier) SendNotice(notice domain.AdverseActionNotice) error {
+	body, err := n.RenderNotice(notice)
+	if err != nil {
+		return err
+	}
+	return SendAdverseActionEmail(notice.BorrowerEmail, fmt.Sprintf("Your loan application %d", notice.LoanID), body)
+}
+
+// Send adverse-action notice email
+func SendAdverseActionEmail(email, subject, body string) error {
+	// Placeholder for sending email
+	// Use an actual email service to send the notice
+	return nil
+}
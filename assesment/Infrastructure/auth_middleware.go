@@ -65,6 +65,7 @@ func AdminMiddleware(c *gin.Context) {
 
 	c.Next()
 }
+
 func TokenGenerator(id primitive.ObjectID, email string, Role string) (string, error) {
 	err := godotenv.Load()
 	if err != nil {
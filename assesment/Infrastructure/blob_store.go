@@ -0,0 +1,131 @@
+package Infrastructure
+
+import (
+	"assesment/domain"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LocalBlobStore keeps blobs as files under a directory, one file per key.
+type LocalBlobStore struct {
+	dir string
+}
+
+// NewLocalBlobStore creates a blob store under dir, creating the directory if needed.
+func NewLocalBlobStore(dir string) (domain.BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalBlobStore{dir: dir}, nil
+}
+
+// Put writes content to the file of key. The file only appears once it is fully written.
+func (s *LocalBlobStore) Put(key string, content io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get opens the file of key.
+func (s *LocalBlobStore) Get(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.ErrBlobNotFound
+	}
+	return file, err
+}
+
+// Delete removes the file of key.
+func (s *LocalBlobStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return domain.ErrBlobNotFound
+	}
+	return err
+}
+
+// path returns the file of key, refusing keys that would leave the store directory.
+func (s *LocalBlobStore) path(key string) (string, error) {
+	name := filepath.FromSlash(key)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// GridFSBlobStore keeps blobs in a MongoDB GridFS bucket, using the key as the file ID.
+type GridFSBlobStore struct {
+	bucket *gridfs.Bucket
+}
+
+// NewGridFSBlobStore creates a blob store in the GridFS bucket named bucket of the "loan" database.
+func NewGridFSBlobStore(mongoClient *mongo.Client, bucket string) (domain.BlobStore, error) {
+	b, err := gridfs.NewBucket(mongoClient.Database("loan"), options.GridFSBucket().SetName(bucket))
+	if err != nil {
+		return nil, err
+	}
+	return &GridFSBlobStore{bucket: b}, nil
+}
+
+// Put uploads content as the file of key.
+func (s *GridFSBlobStore) Put(key string, content io.Reader) error {
+	return s.bucket.UploadFromStreamWithID(key, key, content)
+}
+
+// Get opens the file of key for download.
+func (s *GridFSBlobStore) Get(key string) (io.ReadCloser, error) {
+	stream, err := s.bucket.OpenDownloadStream(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, domain.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Delete removes the file of key and its chunks.
+func (s *GridFSBlobStore) Delete(key string) error {
+	err := s.bucket.Delete(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return domain.ErrBlobNotFound
+	}
+	return err
+}
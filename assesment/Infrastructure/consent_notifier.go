@@ -0,0 +1,28 @@
+package Infrastructure
+
+import (
+	"assesment/domain"
+	"strings"
+)
+
+// emailConsentNotifier emails co-borrowers and guarantors a link to their consent request.
+type emailConsentNotifier struct {
+	baseURL string
+}
+
+// NewEmailConsentNotifier creates a ConsentNotifier whose links point at baseURL.
+func NewEmailConsentNotifier(baseURL string) domain.ConsentNotifier {
+	return &emailConsentNotifier{baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// RequestConsent emails party the link to give or decline consent to loan.
+func (n *emailConsentNotifier) RequestConsent(party domain.LoanParty, loan domain.Loan) error {
+	return SendConsentRequestEmail(party.Email, n.baseURL+"/consents/"+party.ConsentToken)
+}
+
+// Send consent request email
+func SendConsentRequestEmail(email, link string) error {
+	// Placeholder for sending email
+	// Use an actual email service to send the consent request
+	return nil
+}
@@ -0,0 +1,30 @@
+package Infrastructure
+
+import (
+	"assesment/domain"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// creditRulesFile is the layout of a credit rules file, e.g.
+// {"rules": [{"name": "activated", "type": "active_account", "outcome": "decline"},
+// {"name": "dti", "type": "max_debt_to_income", "threshold": 40, "outcome": "refer"}]}.
+type creditRulesFile struct {
+	Rules []domain.RuleConfig `json:"rules"`
+}
+
+// LoadCreditRules reads the declarative credit rules from a JSON file.
+func LoadCreditRules(path string) ([]domain.RuleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file creditRulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid credit rules file %s: %w", path, err)
+	}
+
+	return file.Rules, nil
+}
@@ -0,0 +1,76 @@
+package Infrastructure
+
+import (
+	"assesment/domain"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// StaticExchangeRateProvider serves exchange rates from a fixed table of rates
+// quoted against a single base currency. Cross rates go through the base.
+type StaticExchangeRateProvider struct {
+	base  string
+	rates map[string]*big.Rat // units of the currency per unit of base
+}
+
+// exchangeRateFile is the layout of an exchange rate file, e.g.
+// {"base": "USD", "rates": {"ETB": "57.25", "EUR": "0.92"}}.
+type exchangeRateFile struct {
+	Base  string            `json:"base"`
+	Rates map[string]string `json:"rates"`
+}
+
+// NewStaticExchangeRateProvider creates a provider from decimal rates quoted against base.
+func NewStaticExchangeRateProvider(base string, rates map[string]string) (domain.ExchangeRateProvider, error) {
+	base = strings.ToUpper(base)
+	provider := &StaticExchangeRateProvider{
+		base:  base,
+		rates: map[string]*big.Rat{base: big.NewRat(1, 1)},
+	}
+
+	for currency, value := range rates {
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q for %s", value, currency)
+		}
+		provider.rates[strings.ToUpper(currency)] = rate
+	}
+
+	return provider, nil
+}
+
+// NewFileExchangeRateProvider creates a provider from a JSON exchange rate file.
+func NewFileExchangeRateProvider(path string) (domain.ExchangeRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file exchangeRateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid exchange rate file %s: %w", path, err)
+	}
+
+	return NewStaticExchangeRateProvider(file.Base, file.Rates)
+}
+
+// Rate returns how many units of to one unit of from buys.
+func (p *StaticExchangeRateProvider) Rate(from, to string) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return nil, domain.ErrRateUnavailable
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return nil, domain.ErrRateUnavailable
+	}
+
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
@@ -0,0 +1,71 @@
+package Infrastructure
+
+import (
+	"assesment/domain"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStaticExchangeRateProvider(t *testing.T) {
+	provider, err := NewStaticExchangeRateProvider("usd", map[string]string{"eur": "0.92", "ETB": "57.25"})
+	if err != nil {
+		t.Fatalf("NewStaticExchangeRateProvider: %v", err)
+	}
+
+	tests := []struct {
+		from, to string
+		want     *big.Rat
+		err      error
+	}{
+		{"USD", "EUR", big.NewRat(92, 100), nil},
+		{"EUR", "USD", big.NewRat(100, 92), nil},
+		{"EUR", "ETB", big.NewRat(5725, 92), nil},
+		{"USD", "USD", big.NewRat(1, 1), nil},
+		{"GBP", "GBP", big.NewRat(1, 1), nil},
+		{"USD", "GBP", nil, domain.ErrRateUnavailable},
+		{"GBP", "EUR", nil, domain.ErrRateUnavailable},
+	}
+
+	for _, tt := range tests {
+		rate, err := provider.Rate(tt.from, tt.to)
+		if err != tt.err || (tt.want != nil && (rate == nil || rate.Cmp(tt.want) != 0)) {
+			t.Errorf("Rate(%s, %s) = %v, %v, want %v, %v", tt.from, tt.to, rate, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestStaticExchangeRateProviderRefusesInvalidRates(t *testing.T) {
+	for _, value := range []string{"0", "-0.92", "abc", ""} {
+		if _, err := NewStaticExchangeRateProvider("USD", map[string]string{"EUR": value}); err == nil {
+			t.Errorf("rate %q was accepted", value)
+		}
+	}
+}
+
+func TestFileExchangeRateProvider(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "rates.json")
+	if err := os.WriteFile(valid, []byte(`{"base": "USD", "rates": {"ETB": "57.25", "EUR": "0.92"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	malformed := filepath.Join(dir, "malformed.json")
+	if err := os.WriteFile(malformed, []byte(`{"base": "USD", "rates": ["ETB"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewFileExchangeRateProvider(valid)
+	if err != nil {
+		t.Fatalf("NewFileExchangeRateProvider: %v", err)
+	}
+	if rate, err := provider.Rate("USD", "ETB"); err != nil || rate.Cmp(big.NewRat(5725, 100)) != 0 {
+		t.Errorf("Rate(USD, ETB) = %v, %v, want 57.25", rate, err)
+	}
+
+	for _, path := range []string{malformed, filepath.Join(dir, "missing.json")} {
+		if _, err := NewFileExchangeRateProvider(path); err == nil {
+			t.Errorf("loading %s succeeded", filepath.Base(path))
+		}
+	}
+}
@@ -3,7 +3,7 @@ package Infrastructure
 
 import (
 	//"errors"
-	"regexp"
+	"assesment/domain"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -18,9 +18,7 @@ import (
 
 // Function to validate email format
 func IsValidEmail(email string) bool {
-	// Simple regex for validating email format
-	re := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	return re.MatchString(email)
+	return domain.IsValidEmail(email)
 }
 
 // Function to validate password strength
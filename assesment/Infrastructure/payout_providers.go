@@ -0,0 +1,120 @@
+package Infrastructure
+
+import (
+	"assesment/domain"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// fakeFailSuffix makes the fake provider reject payouts to destinations ending in it,
+// so failures and retries can be exercised locally.
+const fakeFailSuffix = "0000"
+
+// errUnknownPayout is returned when a provider is asked about a payout it never sent.
+var errUnknownPayout = errors.New("unknown payout reference")
+
+// FakePayoutProvider pays out nothing and keeps its payouts in memory. It stands in for a
+// real bank or mobile money integration during local development.
+type FakePayoutProvider struct {
+	method  domain.PayoutMethod
+	instant bool // confirm payouts when they are sent rather than on the next status check
+
+	mu      sync.Mutex
+	payouts map[string]domain.PayoutResult
+}
+
+// NewFakePayoutProvider creates a fake provider for method. Payouts to an account or phone
+// number ending in 0000 fail. Other payouts are confirmed straight away when instant is set,
+// and otherwise stay pending until their status is checked once.
+func NewFakePayoutProvider(method domain.PayoutMethod, instant bool) domain.PayoutProvider {
+	return &FakePayoutProvider{
+		method:  method,
+		instant: instant,
+		payouts: map[string]domain.PayoutResult{},
+	}
+}
+
+// Method returns the payout method the provider serves.
+func (p *FakePayoutProvider) Method() domain.PayoutMethod {
+	return p.method
+}
+
+// Send records a payout. Sending the same reference again returns the first result, as a
+// real provider would for a duplicate request.
+func (p *FakePayoutProvider) Send(request domain.PayoutRequest) (domain.PayoutResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	providerReference := fmt.Sprintf("fake-%s-%s", p.method, request.Reference)
+	if result, ok := p.payouts[providerReference]; ok && result.Status != domain.PayoutFailed {
+		return result, nil
+	}
+
+	result := domain.PayoutResult{ProviderReference: providerReference, Status: domain.PayoutPending}
+	switch {
+	case strings.HasSuffix(request.Destination.AccountNumber, fakeFailSuffix),
+		strings.HasSuffix(request.Destination.PhoneNumber, fakeFailSuffix):
+		result.Status = domain.PayoutFailed
+		result.FailureReason = "destination rejected by the fake provider"
+	case p.instant:
+		result.Status = domain.PayoutConfirmed
+	}
+
+	p.payouts[providerReference] = result
+	return result, nil
+}
+
+// Status reports a payout, confirming it if it was still pending.
+func (p *FakePayoutProvider) Status(providerReference string) (domain.PayoutResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result, ok := p.payouts[providerReference]
+	if !ok {
+		return domain.PayoutResult{}, errUnknownPayout
+	}
+	if result.Status == domain.PayoutPending {
+		result.Status = domain.PayoutConfirmed
+		p.payouts[providerReference] = result
+	}
+	return result, nil
+}
+
+// NewConfiguredPayoutProvider returns the provider configured for method by name, or nil
+// when none is, in which case the method is not offered. The only provider available so far
+// is "fake", the local sandbox, which must be chosen explicitly because it sends no money.
+func NewConfiguredPayoutProvider(method domain.PayoutMethod, name string) (domain.PayoutProvider, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return nil, nil
+	case "fake":
+		return NewFakePayoutProvider(method, method == domain.PayoutMobileMoney), nil
+	}
+	return nil, fmt.Errorf("unknown payout provider %q for %s", name, method)
+}
+
+// ManualPayoutProvider is used for payouts made outside the system, e.g. cash or a cheque.
+// Its payouts stay pending until an admin confirms them.
+type ManualPayoutProvider struct{}
+
+// NewManualPayoutProvider creates a ManualPayoutProvider.
+func NewManualPayoutProvider() domain.PayoutProvider {
+	return ManualPayoutProvider{}
+}
+
+// Method returns the payout method the provider serves.
+func (ManualPayoutProvider) Method() domain.PayoutMethod {
+	return domain.PayoutManual
+}
+
+// Send accepts the payout and leaves it pending.
+func (ManualPayoutProvider) Send(request domain.PayoutRequest) (domain.PayoutResult, error) {
+	return domain.PayoutResult{ProviderReference: "manual-" + request.Reference, Status: domain.PayoutPending}, nil
+}
+
+// Status always reports a payout as pending; only an admin can confirm it.
+func (ManualPayoutProvider) Status(providerReference string) (domain.PayoutResult, error) {
+	return domain.PayoutResult{ProviderReference: providerReference, Status: domain.PayoutPending}, nil
+}
@@ -0,0 +1,74 @@
+package Infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work run by the Scheduler.
+type Job func() error
+
+// NamedJob is a Job with the name it is reported under.
+type NamedJob struct {
+	Name string
+	Run  Job
+}
+
+// Sequence returns a Job that runs jobs one after another, so that no two of them
+// work on the same loans at once. A failing job does not stop the ones after it;
+// their errors are joined.
+func Sequence(jobs ...NamedJob) Job {
+	return func() error {
+		var errs []error
+		for _, job := range jobs {
+			if err := job.Run(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", job.Name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// Scheduler runs background jobs at fixed intervals until it is stopped.
+type Scheduler struct {
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewScheduler creates a Scheduler with no jobs.
+func NewScheduler() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+// Every runs job once straight away and then every interval. Errors are logged and the
+// job is retried at the next tick.
+func (s *Scheduler) Every(interval time.Duration, name string, job Job) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := job(); err != nil {
+				log.Printf("scheduled job %s failed: %v", name, err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops every job and waits for runs in progress to finish.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
@@ -16,6 +16,7 @@ func InitiEnvConfigs() {
 
 type envConfigs struct {
 	LocalServerPort string `mapstructure:"LOCAL_SERVER_PORT"`
+	AppBaseURL      string `mapstructure:"APP_BASE_URL"`
 	
 
 	
@@ -23,6 +24,25 @@ type envConfigs struct {
 	JwtRefreshSecret string `mapstructure:"JWT_REFRESH_TOKEN_SECRET"`
 	AccessTokenExpiryHour  int    `mapstructure:"ACCESS_TOKEN_EXPIRY_HOUR"`
 	RefreshTokenExpiryHour int    `mapstructure:"REFRESH_TOKEN_EXPIRY_HOUR"`
+
+	ReportingCurrency string `mapstructure:"REPORTING_CURRENCY"`
+	ExchangeRatesFile string `mapstructure:"EXCHANGE_RATES_FILE"`
+	CreditRulesFile   string `mapstructure:"CREDIT_RULES_FILE"`
+
+	DualApprovalThreshold string `mapstructure:"DUAL_APPROVAL_THRESHOLD"`
+
+	DelinquentAfterDays int `mapstructure:"DELINQUENT_AFTER_DAYS"`
+	DefaultAfterDays    int `mapstructure:"DEFAULT_AFTER_DAYS"`
+	JobIntervalHours    int `mapstructure:"JOB_INTERVAL_HOURS"`
+	ReviewSLAHours      int `mapstructure:"REVIEW_SLA_HOURS"`
+
+	DocumentStore string `mapstructure:"DOCUMENT_STORE"`
+	DocumentDir   string `mapstructure:"DOCUMENT_DIR"`
+
+	AdverseActionTemplate string `mapstructure:"ADVERSE_ACTION_TEMPLATE"`
+
+	BankTransferProvider string `mapstructure:"BANK_TRANSFER_PROVIDER"`
+	MobileMoneyProvider  string `mapstructure:"MOBILE_MONEY_PROVIDER"`
 	
 
 }
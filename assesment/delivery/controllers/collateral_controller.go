@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CollateralController handles HTTP requests related to collateral and the loans it secures.
+type CollateralController struct {
+	collateralUsecase domain.CollateralUsecase
+}
+
+// NewCollateralController creates a new instance of CollateralController.
+func NewCollateralController(collateralUsecase domain.CollateralUsecase) *CollateralController {
+	return &CollateralController{
+		collateralUsecase: collateralUsecase,
+	}
+}
+
+// CreateCollateral handles the request to register a collateral of the current user.
+func (cc *CollateralController) CreateCollateral(c *gin.Context) {
+	var collateral domain.Collateral
+	if err := c.ShouldBindJSON(&collateral); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	collateral, err = cc.collateralUsecase.CreateCollateral(collateral, userID)
+	if err != nil {
+		c.JSON(collateralErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, collateral)
+}
+
+// GetMyCollateral handles the request to retrieve the collateral of the current user.
+func (cc *CollateralController) GetMyCollateral(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	collateral, err := cc.collateralUsecase.GetUserCollateral(userID)
+	if err != nil {
+		c.JSON(collateralErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collateral)
+}
+
+// GetCollateral handles the request to retrieve a collateral of the current user.
+func (cc *CollateralController) GetCollateral(c *gin.Context) {
+	id, userID, ok := collateralParams(c)
+	if !ok {
+		return
+	}
+
+	collateral, err := cc.collateralUsecase.GetCollateral(id, userID)
+	if err != nil {
+		c.JSON(collateralErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collateral)
+}
+
+// UpdateCollateral handles the request to change a collateral of the current user.
+func (cc *CollateralController) UpdateCollateral(c *gin.Context) {
+	id, userID, ok := collateralParams(c)
+	if !ok {
+		return
+	}
+
+	var update domain.Collateral
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collateral, err := cc.collateralUsecase.UpdateCollateral(id, update, userID)
+	if err != nil {
+		c.JSON(collateralErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collateral)
+}
+
+// DeleteCollateral handles the request to delete a collateral of the current user.
+func (cc *CollateralController) DeleteCollateral(c *gin.Context) {
+	id, userID, ok := collateralParams(c)
+	if !ok {
+		return
+	}
+
+	if err := cc.collateralUsecase.DeleteCollateral(id, userID); err != nil {
+		c.JSON(collateralErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collateral deleted successfully"})
+}
+
+// LinkCollateral handles the request of a borrower to pledge a collateral to their application.
+func (cc *CollateralController) LinkCollateral(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var request struct {
+		CollateralID primitive.ObjectID `json:"collateral_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	loan, err := cc.collateralUsecase.LinkCollateral(uint(id), request.CollateralID, userID)
+	if err != nil {
+		c.JSON(collateralErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// GetLoanCollateral handles the request to retrieve the collateral pledged to a loan.
+func (cc *CollateralController) GetLoanCollateral(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	collateral, err := cc.collateralUsecase.GetLoanCollateral(uint(id))
+	if err != nil {
+		c.JSON(collateralErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collateral)
+}
+
+// collateralParams reads the collateral ID from the path and the authenticated user,
+// answering the request itself when either is missing or invalid.
+func collateralParams(c *gin.Context) (primitive.ObjectID, primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collateral ID"})
+		return primitive.NilObjectID, primitive.NilObjectID, false
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return primitive.NilObjectID, primitive.NilObjectID, false
+	}
+
+	return id, userID, true
+}
+
+// collateralErrorStatus maps a collateral usecase error to the HTTP status code returned to the client.
+func collateralErrorStatus(err error) int {
+	switch err {
+	case domain.ErrInvalidCollateral, domain.ErrInvalidMoney, domain.ErrMissingCurrency,
+		domain.ErrUnknownCurrency, domain.ErrInvalidAmountScale, domain.ErrCurrencyMismatch:
+		return http.StatusBadRequest
+	case domain.ErrNotCollateralOwner, domain.ErrNotLoanOwner:
+		return http.StatusForbidden
+	case domain.ErrCollateralNotFound, mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrCollateralUnderLien, domain.ErrCollateralLocked, domain.ErrLoanModified:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
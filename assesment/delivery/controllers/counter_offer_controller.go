@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CounterOfferController handles HTTP requests related to counter-offers on loan applications.
+type CounterOfferController struct {
+	counterOfferUsecase domain.CounterOfferUsecase
+}
+
+// NewCounterOfferController creates a new instance of CounterOfferController.
+func NewCounterOfferController(counterOfferUsecase domain.CounterOfferUsecase) *CounterOfferController {
+	return &CounterOfferController{
+		counterOfferUsecase: counterOfferUsecase,
+	}
+}
+
+// MakeCounterOffer handles the request to offer a borrower other terms than those applied for.
+func (oc *CounterOfferController) MakeCounterOffer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var terms domain.CounterOfferTerms
+	if err := c.ShouldBindJSON(&terms); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	offer, err := oc.counterOfferUsecase.MakeCounterOffer(uint(id), terms, adminID)
+	if err != nil {
+		c.JSON(counterOfferErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, offer)
+}
+
+// AcceptCounterOffer handles the request of a borrower to accept a counter-offer.
+func (oc *CounterOfferController) AcceptCounterOffer(c *gin.Context) {
+	id, offerID, userID, ok := counterOfferParams(c)
+	if !ok {
+		return
+	}
+
+	loan, err := oc.counterOfferUsecase.AcceptCounterOffer(id, offerID, userID)
+	if err != nil {
+		c.JSON(counterOfferErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// DeclineCounterOffer handles the request of a borrower to decline a counter-offer.
+func (oc *CounterOfferController) DeclineCounterOffer(c *gin.Context) {
+	id, offerID, userID, ok := counterOfferParams(c)
+	if !ok {
+		return
+	}
+
+	offer, err := oc.counterOfferUsecase.DeclineCounterOffer(id, offerID, userID)
+	if err != nil {
+		c.JSON(counterOfferErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// GetLoanCounterOffers handles the request to retrieve the counter-offers made on a loan.
+func (oc *CounterOfferController) GetLoanCounterOffers(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	offers, err := oc.counterOfferUsecase.GetLoanCounterOffers(uint(id))
+	if err != nil {
+		c.JSON(counterOfferErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+// counterOfferParams reads the loan and counter-offer IDs from the path and the
+// authenticated borrower, answering the request itself when any is missing or invalid.
+func counterOfferParams(c *gin.Context) (uint, primitive.ObjectID, primitive.ObjectID, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, primitive.NilObjectID, primitive.NilObjectID, false
+	}
+
+	offerID, err := primitive.ObjectIDFromHex(c.Param("offerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid counter-offer ID"})
+		return 0, primitive.NilObjectID, primitive.NilObjectID, false
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return 0, primitive.NilObjectID, primitive.NilObjectID, false
+	}
+
+	return uint(id), offerID, userID, true
+}
+
+// counterOfferErrorStatus maps a counter-offer usecase error to the HTTP status code returned to the client.
+func counterOfferErrorStatus(err error) int {
+	var transitionErr *domain.InvalidTransitionError
+	if errors.As(err, &transitionErr) {
+		return http.StatusConflict
+	}
+	switch err {
+	case domain.ErrInvalidCounterOffer, domain.ErrInvalidMoney, domain.ErrMissingCurrency,
+		domain.ErrUnknownCurrency, domain.ErrInvalidAmountScale, domain.ErrInvalidLoanTerm,
+		domain.ErrInvalidInterestRate, domain.ErrAmountOutOfRange, domain.ErrTermNotOffered,
+		domain.ErrRateOutOfRange, domain.ErrCurrencyNotOffered:
+		return http.StatusBadRequest
+	case domain.ErrNotLoanOwner, domain.ErrNotAssignedReviewer:
+		return http.StatusForbidden
+	case domain.ErrCounterOfferNotFound, domain.ErrProductNotFound, mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrLoanNotUnderReview, domain.ErrCounterOfferNotPending, domain.ErrCounterOfferExpired,
+		domain.ErrPartyConsentRequired, domain.ErrSecondApprovalRequired, domain.ErrLoanModified:
+		return http.StatusConflict
+	case domain.ErrProductInactive, domain.ErrFrequencyNotOffered:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreditScoreController handles HTTP requests for borrower credit scores.
+type CreditScoreController struct {
+	creditScoreUsecase domain.CreditScoreUsecase
+}
+
+// NewCreditScoreController creates a new instance of CreditScoreController.
+func NewCreditScoreController(creditScoreUsecase domain.CreditScoreUsecase) *CreditScoreController {
+	return &CreditScoreController{
+		creditScoreUsecase: creditScoreUsecase,
+	}
+}
+
+// GetCreditScore handles the request to score a user from their loan history.
+func (cc *CreditScoreController) GetCreditScore(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	score, err := cc.creditScoreUsecase.GetCreditScore(id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == domain.ErrUserNotFound {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, score)
+}
@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DisbursementController handles HTTP requests related to paying out approved loans.
+type DisbursementController struct {
+	disbursementUsecase domain.DisbursementUsecase
+}
+
+// NewDisbursementController creates a new instance of DisbursementController.
+func NewDisbursementController(disbursementUsecase domain.DisbursementUsecase) *DisbursementController {
+	return &DisbursementController{
+		disbursementUsecase: disbursementUsecase,
+	}
+}
+
+// DisburseLoan handles the request to pay out an approved loan.
+func (dc *DisbursementController) DisburseLoan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var request struct {
+		Method      domain.PayoutMethod      `json:"method" binding:"required"`
+		Destination domain.PayoutDestination `json:"destination"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	disbursement, err := dc.disbursementUsecase.DisburseLoan(uint(id), request.Method, request.Destination, adminID)
+	if err != nil {
+		c.JSON(disbursementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, disbursement)
+}
+
+// RetryDisbursement handles the request to send a failed disbursement again.
+func (dc *DisbursementController) RetryDisbursement(c *gin.Context) {
+	id, disbursementID, ok := disbursementParams(c)
+	if !ok {
+		return
+	}
+
+	disbursement, err := dc.disbursementUsecase.RetryDisbursement(id, disbursementID)
+	if err != nil {
+		c.JSON(disbursementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, disbursement)
+}
+
+// ConfirmDisbursement handles the request to confirm a disbursement waiting for confirmation.
+func (dc *DisbursementController) ConfirmDisbursement(c *gin.Context) {
+	id, disbursementID, ok := disbursementParams(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Reference string `json:"reference"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	disbursement, err := dc.disbursementUsecase.ConfirmDisbursement(id, disbursementID, request.Reference)
+	if err != nil {
+		c.JSON(disbursementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, disbursement)
+}
+
+// GetLoanDisbursements handles the request to retrieve the disbursements of a loan.
+func (dc *DisbursementController) GetLoanDisbursements(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	disbursements, err := dc.disbursementUsecase.GetLoanDisbursements(uint(id))
+	if err != nil {
+		c.JSON(disbursementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, disbursements)
+}
+
+// disbursementParams reads the loan and disbursement IDs from the path, answering the
+// request itself when either is invalid.
+func disbursementParams(c *gin.Context) (uint, primitive.ObjectID, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, primitive.NilObjectID, false
+	}
+
+	disbursementID, err := primitive.ObjectIDFromHex(c.Param("disbursementId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid disbursement ID"})
+		return 0, primitive.NilObjectID, false
+	}
+
+	return uint(id), disbursementID, true
+}
+
+// disbursementErrorStatus maps a disbursement usecase error to the HTTP status code returned to the client.
+func disbursementErrorStatus(err error) int {
+	var transitionErr *domain.InvalidTransitionError
+	if errors.As(err, &transitionErr) {
+		return http.StatusConflict
+	}
+	switch err {
+	case domain.ErrUnsupportedPayoutMethod, domain.ErrInvalidDestination:
+		return http.StatusBadRequest
+	case domain.ErrDisbursementNotFound, mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrLoanNotApproved, domain.ErrDisbursementInProgress, domain.ErrDisbursementNotRetryable,
+		domain.ErrMaxAttemptsReached, domain.ErrDisbursementNotPending, domain.ErrLoanModified:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
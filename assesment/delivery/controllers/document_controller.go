@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DocumentController handles HTTP requests related to the documents attached to loan applications.
+type DocumentController struct {
+	documentUsecase domain.LoanDocumentUsecase
+}
+
+// NewDocumentController creates a new instance of DocumentController.
+func NewDocumentController(documentUsecase domain.LoanDocumentUsecase) *DocumentController {
+	return &DocumentController{
+		documentUsecase: documentUsecase,
+	}
+}
+
+// UploadDocument handles the multipart upload of a document to the current user's application.
+// The form carries the file in "file" and its document type in "type".
+func (dc *DocumentController) UploadDocument(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Leave room for the multipart headers around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, domain.MaxDocumentSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": domain.ErrDocumentTooLarge.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A document file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	document, err := dc.documentUsecase.UploadDocument(uint(id), domain.DocumentUpload{
+		Type:     domain.DocumentType(c.PostForm("type")),
+		FileName: header.Filename,
+		Size:     header.Size,
+		Content:  file,
+	}, userID)
+	if err != nil {
+		c.JSON(documentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// GetMyLoanDocuments handles the request of a borrower to list the documents of their application.
+func (dc *DocumentController) GetMyLoanDocuments(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	documents, err := dc.documentUsecase.GetMyLoanDocuments(uint(id), userID)
+	if err != nil {
+		c.JSON(documentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, documents)
+}
+
+// GetLoanDocuments handles the request of an admin to list the documents of a loan.
+func (dc *DocumentController) GetLoanDocuments(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	documents, err := dc.documentUsecase.GetLoanDocuments(uint(id))
+	if err != nil {
+		c.JSON(documentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, documents)
+}
+
+// DownloadDocument handles the request of an admin to download a document of a loan.
+func (dc *DocumentController) DownloadDocument(c *gin.Context) {
+	id, documentID, ok := documentParams(c)
+	if !ok {
+		return
+	}
+
+	document, content, err := dc.documentUsecase.DownloadDocument(id, documentID)
+	if err != nil {
+		c.JSON(documentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": document.FileName}))
+	c.Header("X-Checksum-SHA256", document.Checksum)
+	c.Data(http.StatusOK, document.ContentType, content)
+}
+
+// ReviewDocument handles the request of an admin to accept or reject a document of a loan.
+func (dc *DocumentController) ReviewDocument(c *gin.Context) {
+	id, documentID, ok := documentParams(c)
+	if !ok {
+		return
+	}
+
+	var review domain.DocumentReview
+	if err := c.ShouldBindJSON(&review); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	document, err := dc.documentUsecase.ReviewDocument(id, documentID, review, adminID)
+	if err != nil {
+		c.JSON(documentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+// documentParams reads the loan and document IDs from the path, answering the request itself
+// when either is invalid.
+func documentParams(c *gin.Context) (uint, primitive.ObjectID, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, primitive.NilObjectID, false
+	}
+
+	documentID, err := primitive.ObjectIDFromHex(c.Param("documentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return 0, primitive.NilObjectID, false
+	}
+
+	return uint(id), documentID, true
+}
+
+// documentErrorStatus maps a loan document usecase error to the HTTP status code returned to the client.
+func documentErrorStatus(err error) int {
+	switch err {
+	case domain.ErrInvalidDocumentType, domain.ErrEmptyDocument, domain.ErrInvalidDocumentReview:
+		return http.StatusBadRequest
+	case domain.ErrNotLoanOwner:
+		return http.StatusForbidden
+	case domain.ErrDocumentNotFound, domain.ErrBlobNotFound, mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrDocumentsLocked:
+		return http.StatusConflict
+	case domain.ErrDocumentTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case domain.ErrUnsupportedContentType:
+		return http.StatusUnsupportedMediaType
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// FeeController handles HTTP requests related to late fees and penalty interest.
+type FeeController struct {
+	feeUsecase domain.FeeUsecase
+}
+
+// NewFeeController creates a new instance of FeeController.
+func NewFeeController(feeUsecase domain.FeeUsecase) *FeeController {
+	return &FeeController{
+		feeUsecase: feeUsecase,
+	}
+}
+
+// GetLoanFees handles the request to retrieve the late fees and penalty interest charged on a loan.
+func (fc *FeeController) GetLoanFees(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	fees, err := fc.feeUsecase.GetLoanFees(uint(id))
+	if err != nil {
+		c.JSON(feeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fees)
+}
+
+// GetLoanBalance handles the request to break down what is still owed on a loan.
+func (fc *FeeController) GetLoanBalance(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	balance, err := fc.feeUsecase.GetLoanBalance(uint(id))
+	if err != nil {
+		c.JSON(feeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// WaiveFee handles the request to waive a late fee or penalty interest charge.
+func (fc *FeeController) WaiveFee(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	feeID, err := primitive.ObjectIDFromHex(c.Param("feeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fee ID"})
+		return
+	}
+
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	fee, err := fc.feeUsecase.WaiveFee(uint(id), feeID, request.Reason, adminID)
+	if err != nil {
+		c.JSON(feeErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fee)
+}
+
+// feeErrorStatus maps a fee usecase error to the HTTP status code returned to the client.
+func feeErrorStatus(err error) int {
+	switch err {
+	case domain.ErrWaiveReasonRequired:
+		return http.StatusBadRequest
+	case domain.ErrFeeNotFound, mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrFeeAlreadyWaived, domain.ErrFeeAlreadyPaid, domain.ErrLoanModified:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
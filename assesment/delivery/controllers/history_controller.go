@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// LoanHistoryController handles HTTP requests related to loan histories.
+type LoanHistoryController struct {
+	historyUsecase domain.LoanHistoryUsecase
+}
+
+// NewLoanHistoryController creates a new instance of LoanHistoryController.
+func NewLoanHistoryController(historyUsecase domain.LoanHistoryUsecase) *LoanHistoryController {
+	return &LoanHistoryController{
+		historyUsecase: historyUsecase,
+	}
+}
+
+// GetLoanHistory handles the request for the timeline of a loan, for its borrower or an admin.
+func (hc *LoanHistoryController) GetLoanHistory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	events, err := hc.historyUsecase.GetLoanHistory(uint(id), userID, currentUserIsAdmin(c))
+	if err != nil {
+		c.JSON(historyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// historyErrorStatus maps a loan history usecase error to the HTTP status code returned to the client.
+func historyErrorStatus(err error) int {
+	switch err {
+	case mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrNotLoanOwner:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// JobController handles HTTP requests that run background jobs on demand.
+type JobController struct {
+	delinquencyUsecase domain.DelinquencyUsecase
+	feeUsecase         domain.FeeUsecase
+	accrualUsecase     domain.AccrualUsecase
+}
+
+// NewJobController creates a new instance of JobController.
+func NewJobController(delinquencyUsecase domain.DelinquencyUsecase, feeUsecase domain.FeeUsecase, accrualUsecase domain.AccrualUsecase) *JobController {
+	return &JobController{
+		delinquencyUsecase: delinquencyUsecase,
+		feeUsecase:         feeUsecase,
+		accrualUsecase:     accrualUsecase,
+	}
+}
+
+// RunDelinquencyCheck handles the request to run the delinquency check now.
+func (jc *JobController) RunDelinquencyCheck(c *gin.Context) {
+	run, err := jc.delinquencyUsecase.RunDelinquencyCheck(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// RunLateCharges handles the request to charge late fees and penalty interest now.
+func (jc *JobController) RunLateCharges(c *gin.Context) {
+	run, err := jc.feeUsecase.AccrueLateCharges(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// RunAccrual handles the request to accrue interest now, through yesterday or the given date.
+func (jc *JobController) RunAccrual(c *gin.Context) {
+	through := time.Now().AddDate(0, 0, -1)
+	if date := c.Query("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+		through = parsed
+	}
+
+	run, err := jc.accrualUsecase.RunAccrual(through)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// GetLatestAccrualRun handles the request to retrieve the last accrual run and its progress.
+func (jc *JobController) GetLatestAccrualRun(c *gin.Context) {
+	run, err := jc.accrualUsecase.GetLatestRun()
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// GetAccrualRuns handles the request to list the recent accrual runs.
+func (jc *JobController) GetAccrualRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	runs, err := jc.accrualUsecase.GetRuns(limit)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetLoanAccruals handles the request to retrieve the daily interest accrued on a loan.
+func (jc *JobController) GetLoanAccruals(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	accruals, err := jc.accrualUsecase.GetLoanAccruals(uint(id))
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, accruals)
+}
+
+// jobErrorStatus maps a job usecase error to the HTTP status code returned to the client.
+func jobErrorStatus(err error) int {
+	switch err {
+	case domain.ErrAccrualDateInFuture:
+		return http.StatusBadRequest
+	case domain.ErrJobRunNotFound, mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrJobRunning:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LedgerController handles HTTP requests related to the loan ledger.
+type LedgerController struct {
+	ledgerUsecase domain.LedgerUsecase
+}
+
+// NewLedgerController creates a new instance of LedgerController.
+func NewLedgerController(ledgerUsecase domain.LedgerUsecase) *LedgerController {
+	return &LedgerController{
+		ledgerUsecase: ledgerUsecase,
+	}
+}
+
+// GetTrialBalance handles the request to retrieve the balance of every ledger account.
+func (lc *LedgerController) GetTrialBalance(c *gin.Context) {
+	balances, err := lc.ledgerUsecase.GetTrialBalance()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, balances)
+}
+
+// GetOutstandingPrincipal handles the request to retrieve the portfolio-wide outstanding principal.
+func (lc *LedgerController) GetOutstandingPrincipal(c *gin.Context) {
+	principal, err := lc.ledgerUsecase.GetOutstandingPrincipal()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"outstanding_principal": principal})
+}
+
+// GetEntries handles the request to retrieve journal entries posted between the from and to dates.
+func (lc *LedgerController) GetEntries(c *gin.Context) {
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+		return
+	}
+
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+		return
+	}
+
+	// The to date is inclusive.
+	entries, err := lc.ledgerUsecase.GetEntries(from, to.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetLoanEntries handles the request to retrieve the journal entries posted for a loan.
+func (lc *LedgerController) GetLoanEntries(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	entries, err := lc.ledgerUsecase.GetLoanEntries(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
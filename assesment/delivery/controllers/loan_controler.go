@@ -1,10 +1,15 @@
 package controllers
 
 import (
+    "errors"
+    "io"
     "github.com/gin-gonic/gin"
     "net/http"
     "strconv"
+    "time"
     "assesment/domain"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
     //"assesment/usecase"
 )
 
@@ -20,23 +25,35 @@ func NewLoanController(loanUsecase domain.LoanUsecase) *LoanController {
     }
 }
 
-// ApplyForLoan handles the request to apply for a loan.
+// ApplyForLoan handles the request of the authenticated user to apply for a loan in their own name.
 func (lc *LoanController) ApplyForLoan(c *gin.Context) {
-    var loan domain.Loan
-    if err := c.ShouldBindJSON(&loan); err != nil {
+    var application domain.LoanApplication
+    if err := c.ShouldBindJSON(&application); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
-    if err := lc.loanUsecase.ApplyForLoan(loan); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    userID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Loan application submitted successfully"})
+    loan, err := lc.loanUsecase.ApplyForLoan(application.Loan(userID))
+    if err == domain.ErrAdverseActionNotSent {
+        c.JSON(http.StatusAccepted, gin.H{"message": "Loan application declined, the adverse-action notice could not be sent", "status": loan.Status, "decision": loan.Decision, "rejection": loan.Rejection})
+        return
+    }
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Loan application submitted successfully", "status": loan.Status, "decision": loan.Decision})
 }
 
-// GetLoanByID handles the request to retrieve a loan by ID.
+// GetLoanByID handles the request of a borrower or an admin to retrieve a loan by ID, with
+// how it was decided and reviewed, its co-borrowers and guarantors and any rejection.
 func (lc *LoanController) GetLoanByID(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
@@ -53,12 +70,30 @@ func (lc *LoanController) GetLoanByID(c *gin.Context) {
     c.JSON(http.StatusOK, loan)
 }
 
-// GetAllLoans handles the request to retrieve all loans.
+// GetAllLoans handles the request to retrieve loans. Admins get every loan, with optional
+// filtering and sorting; borrowers get their own loans.
 func (lc *LoanController) GetAllLoans(c *gin.Context) {
+    if !currentUserIsAdmin(c) {
+        userID, err := currentUserID(c)
+        if err != nil {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+            return
+        }
+
+        loans, err := lc.loanUsecase.GetUserLoans(userID)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, loans)
+        return
+    }
+
     status := c.Query("status")
     order := c.Query("order")
+    currency := c.Query("currency")
 
-    loans, err := lc.loanUsecase.GetAllLoans(status, order)
+    loans, err := lc.loanUsecase.GetAllLoans(status, order, currency)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
@@ -67,39 +102,348 @@ func (lc *LoanController) GetAllLoans(c *gin.Context) {
     c.JSON(http.StatusOK, loans)
 }
 
-// ApproveLoan handles the request to approve a loan.
+// ApproveLoan handles the request of a reviewer to approve a loan, with an optional reason.
+// Loans that need two approvals answer 202 Accepted until the second admin approves.
 func (lc *LoanController) ApproveLoan(c *gin.Context) {
+    id, reviewerID, reason, ok := decisionParams(c)
+    if !ok {
+        return
+    }
+
+    loan, err := lc.loanUsecase.ApproveLoan(id, reviewerID, reason)
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    if loan.Status != domain.LoanStatusApproved {
+        c.JSON(http.StatusAccepted, gin.H{"message": "Approval recorded, a second admin must approve the loan", "approvals": loan.Approvals()})
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Loan approved successfully"})
+}
+
+// RejectLoan handles the request of a reviewer to reject a loan, giving one or more reason
+// codes from the catalog and an optional note.
+func (lc *LoanController) RejectLoan(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
         return
     }
 
-    if err := lc.loanUsecase.ApproveLoan(uint(id)); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    var request domain.RejectionRequest
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Loan approved successfully"})
+    reviewerID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    loan, err := lc.loanUsecase.RejectLoan(uint(id), reviewerID, request)
+    if err == domain.ErrAdverseActionNotSent {
+        c.JSON(http.StatusAccepted, gin.H{"message": "Loan rejected, the adverse-action notice could not be sent", "rejection": loan.Rejection})
+        return
+    }
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Loan rejected successfully", "rejection": loan.Rejection})
 }
 
-// RejectLoan handles the request to reject a loan.
-func (lc *LoanController) RejectLoan(c *gin.Context) {
+// WithdrawLoan handles the request of a borrower to withdraw their loan application.
+func (lc *LoanController) WithdrawLoan(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
         return
     }
 
-    if err := lc.loanUsecase.RejectLoan(uint(id)); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    var request struct {
+        Reason string `json:"reason"`
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    userID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    loan, err := lc.loanUsecase.WithdrawLoan(uint(id), userID, request.Reason)
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, loan)
+}
+
+// CancelLoan handles the request to cancel an approved loan before it is paid out.
+func (lc *LoanController) CancelLoan(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return
+    }
+
+    var request struct {
+        Reason string `json:"reason"`
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    adminID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    loan, err := lc.loanUsecase.CancelLoan(uint(id), adminID, request.Reason)
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, loan)
+}
+
+// GetLoanSchedule handles the request to retrieve the repayment schedule of a loan.
+func (lc *LoanController) GetLoanSchedule(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return
+    }
+
+    schedule, err := lc.loanUsecase.GetLoanSchedule(uint(id))
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"loan_id": id, "installments": schedule})
+}
+
+// GetPayoffQuote handles the request to compute what settles a loan in full today or on the given date.
+func (lc *LoanController) GetPayoffQuote(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return
+    }
+
+    var date time.Time
+    if value := c.Query("date"); value != "" {
+        date, err = time.Parse("2006-01-02", value)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+            return
+        }
+    }
+
+    quote, err := lc.loanUsecase.GetPayoffQuote(uint(id), date)
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, quote)
+}
+
+// RecordPayment handles the request of the borrower or an admin to record a repayment against a loan.
+func (lc *LoanController) RecordPayment(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return
+    }
+
+    var request struct {
+        Amount     domain.Money            `json:"amount"`
+        PaidAt     time.Time               `json:"paid_at"`
+        Prepayment domain.PrepaymentOption `json:"prepayment"` // reduce_term or reduce_installment
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    userID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+    if !request.PaidAt.IsZero() && !currentUserIsAdmin(c) {
+        c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrPaymentBackdating.Error()})
+        return
+    }
+
+    payment, err := lc.loanUsecase.RecordPayment(uint(id), domain.Payment{Amount: request.Amount, PaidAt: request.PaidAt, Prepayment: request.Prepayment, RecordedBy: userID})
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusCreated, payment)
+}
+
+// WriteOffLoan handles the request to write off a defaulted loan.
+func (lc *LoanController) WriteOffLoan(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return
+    }
+
+    var request struct {
+        Reason string `json:"reason"`
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    adminID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    loan, err := lc.loanUsecase.WriteOffLoan(uint(id), request.Reason, adminID)
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, loan)
+}
+
+// RecordRecovery handles the request to record money recovered on a written-off loan.
+func (lc *LoanController) RecordRecovery(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return
+    }
+
+    var request struct {
+        Amount domain.Money `json:"amount"`
+        PaidAt time.Time    `json:"paid_at"`
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    adminID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    payment, err := lc.loanUsecase.RecordRecovery(uint(id), domain.Payment{Amount: request.Amount, PaidAt: request.PaidAt, RecordedBy: adminID})
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusCreated, payment)
+}
+
+// GetLoanPayments handles the request to retrieve the payment history of a loan.
+func (lc *LoanController) GetLoanPayments(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return
+    }
+
+    payments, err := lc.loanUsecase.GetLoanPayments(uint(id))
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, payments)
+}
+
+// GetMyPayments handles the request to retrieve the payment history of the authenticated user.
+func (lc *LoanController) GetMyPayments(c *gin.Context) {
+    userID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    payments, err := lc.loanUsecase.GetUserPayments(userID)
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, payments)
+}
+
+// GetUserPayments handles the request to retrieve the payment history of a user (admin operation).
+func (lc *LoanController) GetUserPayments(c *gin.Context) {
+    userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+        return
+    }
+
+    payments, err := lc.loanUsecase.GetUserPayments(userID)
+    if err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, payments)
+}
+
+// UpdateLoanStatus handles the request of an admin to set a loan status that has no operation of its own.
+func (lc *LoanController) UpdateLoanStatus(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return
+    }
+
+    var request struct {
+        Status domain.LoanStatus `json:"status" binding:"required"`
+    }
+    if err := c.ShouldBindJSON(&request); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{"message": "Loan rejected successfully"})
+    adminID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := lc.loanUsecase.UpdateLoanStatus(uint(id), request.Status, adminID); err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Loan status updated successfully", "status": request.Status})
 }
 
-// DeleteLoan handles the request to delete a loan.
+// DeleteLoan handles the request of an admin to delete a loan.
 func (lc *LoanController) DeleteLoan(c *gin.Context) {
     id, err := strconv.Atoi(c.Param("id"))
     if err != nil {
@@ -107,10 +451,144 @@ func (lc *LoanController) DeleteLoan(c *gin.Context) {
         return
     }
 
-    if err := lc.loanUsecase.DeleteLoan(uint(id)); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    adminID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := lc.loanUsecase.DeleteLoan(uint(id), adminID); err != nil {
+        c.JSON(loanErrorStatus(err), gin.H{"error": err.Error()})
         return
     }
 
     c.JSON(http.StatusOK, gin.H{"message": "Loan deleted successfully"})
 }
+
+// loanErrorStatus maps a loan usecase error to the HTTP status code returned to the client.
+func loanErrorStatus(err error) int {
+    var transitionErr *domain.InvalidTransitionError
+    if errors.As(err, &transitionErr) {
+        return http.StatusConflict
+    }
+    switch err {
+    case domain.ErrInvalidLoanAmount, domain.ErrInvalidLoanTerm, domain.ErrInvalidInterestRate,
+        domain.ErrInvalidFrequency, domain.ErrInvalidAmortization, domain.ErrInvalidPaymentAmount,
+        domain.ErrPaymentExceedsBalance, domain.ErrInvalidMoney, domain.ErrMissingCurrency,
+        domain.ErrUnknownCurrency, domain.ErrInvalidAmountScale, domain.ErrCurrencyMismatch,
+        domain.ErrPaymentDateOutOfRange, domain.ErrLoanIDRequired:
+        return http.StatusBadRequest
+    case domain.ErrInvalidPrepaymentOption, domain.ErrQuoteDateInPast, domain.ErrPrepaymentTooLarge,
+        domain.ErrWriteOffReasonRequired, domain.ErrClosureReasonRequired, domain.ErrRejectionReasonRequired,
+        domain.ErrUnknownRejectionReason:
+        return http.StatusBadRequest
+    case domain.ErrInvalidPartyRole, domain.ErrPartyIdentityRequired, domain.ErrPartyIsBorrower, domain.ErrDuplicateParty:
+        return http.StatusBadRequest
+    case domain.ErrProductRequired, domain.ErrCurrencyNotOffered, domain.ErrAmountOutOfRange,
+        domain.ErrTermNotOffered, domain.ErrRateOutOfRange, domain.ErrFrequencyNotOffered:
+        return http.StatusBadRequest
+    case domain.ErrScheduleNotGenerated, domain.ErrProductNotFound, domain.ErrUserNotFound, domain.ErrCollateralNotFound,
+        mongo.ErrNoDocuments:
+        return http.StatusNotFound
+    case domain.ErrLoanNotRepayable, domain.ErrLoanNotWrittenOff, domain.ErrCounterOfferPending,
+        domain.ErrPartyConsentRequired, domain.ErrCollateralUnderLien, domain.ErrSecondApprovalRequired,
+        domain.ErrLoanModified, domain.ErrLoanIDTaken, domain.ErrDisbursementInProgress:
+        return http.StatusConflict
+    case domain.ErrNotLoanOwner, domain.ErrNotCollateralOwner, domain.ErrNotAssignedReviewer, domain.ErrSameApprover:
+        return http.StatusForbidden
+    case domain.ErrProductInactive, domain.ErrInactiveAccount, domain.ErrAccountTooNew,
+        domain.ErrTooManyActiveLoans, domain.ErrStatusSetByOperation:
+        return http.StatusUnprocessableEntity
+    }
+    return http.StatusInternalServerError
+}
+
+// decisionParams reads the loan ID, the reviewer and the reason of an approval,
+// answering the request itself when any is invalid. The body may be left out.
+func decisionParams(c *gin.Context) (uint, primitive.ObjectID, string, bool) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return 0, primitive.NilObjectID, "", false
+    }
+
+    var request struct {
+        Reason string `json:"reason"`
+    }
+    if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return 0, primitive.NilObjectID, "", false
+    }
+
+    reviewerID, err := currentUserID(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return 0, primitive.NilObjectID, "", false
+    }
+
+    return uint(id), reviewerID, request.Reason, true
+}
+
+// RequireLoanOwner lets a request about a loan through only for its borrower or an admin.
+// It runs after the AuthMiddleware on routes with the loan ID in the path.
+func (lc *LoanController) RequireLoanOwner(c *gin.Context) {
+    lc.requireLoanAccess(c, false)
+}
+
+// RequireLoanParticipant lets a request about a loan through only for its borrower, one of
+// its co-borrowers or guarantors with an account, or an admin.
+func (lc *LoanController) RequireLoanParticipant(c *gin.Context) {
+    lc.requireLoanAccess(c, true)
+}
+
+// requireLoanAccess lets the request through for the borrower of the loan in the path, an
+// admin and, when parties is set, the loan's co-borrowers and guarantors.
+func (lc *LoanController) requireLoanAccess(c *gin.Context, parties bool) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return
+    }
+
+    loan, err := lc.loanUsecase.GetLoanByID(uint(id))
+    if err != nil {
+        c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    userID, err := currentUserID(c)
+    if err != nil {
+        c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+    if userID == loan.UserID || currentUserIsAdmin(c) {
+        c.Next()
+        return
+    }
+    if parties {
+        for _, party := range loan.Parties {
+            if party.UserID == userID {
+                c.Next()
+                return
+            }
+        }
+    }
+
+    c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": domain.ErrNotLoanOwner.Error()})
+}
+
+// currentUserIsAdmin reports whether the authenticated user is an admin.
+func currentUserIsAdmin(c *gin.Context) bool {
+    isAdmin, _ := c.Get("isadmin")
+    return isAdmin == true
+}
+
+// currentUserID returns the ID of the user the AuthMiddleware authenticated.
+func currentUserID(c *gin.Context) (primitive.ObjectID, error) {
+    value, _ := c.Get("userid")
+    hex, ok := value.(string)
+    if !ok {
+        return primitive.NilObjectID, errors.New("missing authenticated user")
+    }
+    return primitive.ObjectIDFromHex(hex)
+}
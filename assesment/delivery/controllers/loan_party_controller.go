@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// LoanPartyController handles HTTP requests related to co-borrowers and guarantors.
+type LoanPartyController struct {
+	loanPartyUsecase domain.LoanPartyUsecase
+}
+
+// NewLoanPartyController creates a new instance of LoanPartyController.
+func NewLoanPartyController(loanPartyUsecase domain.LoanPartyUsecase) *LoanPartyController {
+	return &LoanPartyController{
+		loanPartyUsecase: loanPartyUsecase,
+	}
+}
+
+// AddLoanParty handles the request of a borrower to add a co-borrower or guarantor to their application.
+func (pc *LoanPartyController) AddLoanParty(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var party domain.LoanParty
+	if err := c.ShouldBindJSON(&party); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	party, err = pc.loanPartyUsecase.AddLoanParty(uint(id), party, userID)
+	if err != nil {
+		c.JSON(loanPartyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, party)
+}
+
+// RemoveLoanParty handles the request of a borrower to remove a co-borrower or guarantor from their application.
+func (pc *LoanPartyController) RemoveLoanParty(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	partyID, err := primitive.ObjectIDFromHex(c.Param("partyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid party ID"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := pc.loanPartyUsecase.RemoveLoanParty(uint(id), partyID, userID); err != nil {
+		c.JSON(loanPartyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Party removed successfully"})
+}
+
+// GetLoanParties handles the request to retrieve the co-borrowers and guarantors of a loan.
+func (pc *LoanPartyController) GetLoanParties(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	parties, err := pc.loanPartyUsecase.GetLoanParties(uint(id))
+	if err != nil {
+		c.JSON(loanPartyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, parties)
+}
+
+// GetConsentRequest handles the request of a party opening their consent link.
+func (pc *LoanPartyController) GetConsentRequest(c *gin.Context) {
+	request, err := pc.loanPartyUsecase.GetConsentRequest(c.Param("token"))
+	if err != nil {
+		c.JSON(loanPartyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// RespondToConsent handles the request of a party to give or decline consent through their link.
+func (pc *LoanPartyController) RespondToConsent(c *gin.Context) {
+	var request struct {
+		Consent *bool `json:"consent" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := pc.loanPartyUsecase.RespondToConsent(c.Param("token"), *request.Consent)
+	if err != nil {
+		c.JSON(loanPartyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMyGuarantees handles the request to retrieve the loans the current user is a co-borrower or guarantor on.
+func (pc *LoanPartyController) GetMyGuarantees(c *gin.Context) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	guarantees, err := pc.loanPartyUsecase.GetUserGuarantees(userID)
+	if err != nil {
+		c.JSON(loanPartyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, guarantees)
+}
+
+// loanPartyErrorStatus maps a loan party usecase error to the HTTP status code returned to the client.
+func loanPartyErrorStatus(err error) int {
+	switch err {
+	case domain.ErrInvalidPartyRole, domain.ErrPartyIdentityRequired, domain.ErrPartyIsBorrower,
+		domain.ErrDuplicateParty:
+		return http.StatusBadRequest
+	case domain.ErrNotLoanOwner:
+		return http.StatusForbidden
+	case domain.ErrPartyNotFound, domain.ErrInvalidConsentToken, domain.ErrUserNotFound, mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrPartiesLocked, domain.ErrConsentAlreadyAnswered, domain.ErrLoanModified:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductController handles HTTP requests related to loan products.
+type ProductController struct {
+	productUsecase domain.ProductUsecase
+}
+
+// NewProductController creates a new instance of ProductController.
+func NewProductController(productUsecase domain.ProductUsecase) *ProductController {
+	return &ProductController{
+		productUsecase: productUsecase,
+	}
+}
+
+// CreateProduct handles the request to define a new loan product.
+func (pc *ProductController) CreateProduct(c *gin.Context) {
+	var product domain.LoanProduct
+	if err := c.ShouldBindJSON(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := pc.productUsecase.CreateProduct(product)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetProducts handles the request to list every loan product.
+func (pc *ProductController) GetProducts(c *gin.Context) {
+	products, err := pc.productUsecase.GetProducts(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// GetActiveProducts handles the request to list the loan products open for applications.
+func (pc *ProductController) GetActiveProducts(c *gin.Context) {
+	products, err := pc.productUsecase.GetProducts(true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// GetProduct handles the request to retrieve a loan product by ID.
+func (pc *ProductController) GetProduct(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	product, err := pc.productUsecase.GetProduct(id)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// UpdateProduct handles the request to replace the definition of a loan product.
+func (pc *ProductController) UpdateProduct(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var product domain.LoanProduct
+	if err := c.ShouldBindJSON(&product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	product.ID = id
+
+	updated, err := pc.productUsecase.UpdateProduct(product)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteProduct handles the request to remove a loan product.
+func (pc *ProductController) DeleteProduct(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := pc.productUsecase.DeleteProduct(id); err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Loan product deleted successfully"})
+}
+
+// productErrorStatus maps a product usecase error to the HTTP status code returned to the client.
+func productErrorStatus(err error) int {
+	switch err {
+	case domain.ErrProductNotFound:
+		return http.StatusNotFound
+	case domain.ErrInvalidProduct, domain.ErrInvalidAmountLimit, domain.ErrInvalidRateRange,
+		domain.ErrInvalidLoanTerm, domain.ErrInvalidFrequency, domain.ErrInvalidAmortization,
+		domain.ErrInvalidMoney, domain.ErrMissingCurrency, domain.ErrUnknownCurrency,
+		domain.ErrInvalidAmountScale, domain.ErrInvalidPenaltyTerms, domain.ErrInvalidDayCount,
+		domain.ErrInvalidPrepaymentTerms:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
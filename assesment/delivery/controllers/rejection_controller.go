@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RejectionController handles HTTP requests related to rejection reasons and adverse-action notices.
+type RejectionController struct {
+	rejectionUsecase domain.RejectionUsecase
+}
+
+// NewRejectionController creates a new instance of RejectionController.
+func NewRejectionController(rejectionUsecase domain.RejectionUsecase) *RejectionController {
+	return &RejectionController{
+		rejectionUsecase: rejectionUsecase,
+	}
+}
+
+// CreateReason handles the request to add a reason to the rejection catalog.
+func (rc *RejectionController) CreateReason(c *gin.Context) {
+	var reason domain.RejectionReason
+	if err := c.ShouldBindJSON(&reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reason, err := rc.rejectionUsecase.CreateReason(reason)
+	if err != nil {
+		c.JSON(rejectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reason)
+}
+
+// GetReasons handles the request to list the rejection catalog. The active query set to
+// true leaves out retired reasons.
+func (rc *RejectionController) GetReasons(c *gin.Context) {
+	activeOnly, _ := strconv.ParseBool(c.Query("active"))
+
+	reasons, err := rc.rejectionUsecase.GetReasons(activeOnly)
+	if err != nil {
+		c.JSON(rejectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reasons)
+}
+
+// UpdateReason handles the request to reword or retire a rejection reason.
+func (rc *RejectionController) UpdateReason(c *gin.Context) {
+	var reason domain.RejectionReason
+	if err := c.ShouldBindJSON(&reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reason, err := rc.rejectionUsecase.UpdateReason(c.Param("code"), reason)
+	if err != nil {
+		c.JSON(rejectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reason)
+}
+
+// GetAdverseActionNotice handles the request of a borrower for the adverse-action notice of
+// their rejected application, as plain text.
+func (rc *RejectionController) GetAdverseActionNotice(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	notice, err := rc.rejectionUsecase.GetAdverseActionNotice(uint(id), userID)
+	if err != nil {
+		c.JSON(rejectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.String(http.StatusOK, notice)
+}
+
+// rejectionErrorStatus maps a rejection usecase error to the HTTP status code returned to the client.
+func rejectionErrorStatus(err error) int {
+	switch err {
+	case domain.ErrInvalidRejectionReason:
+		return http.StatusBadRequest
+	case domain.ErrRejectionReasonNotFound, domain.ErrUserNotFound, mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrDuplicateRejectionReason, domain.ErrLoanNotRejected:
+		return http.StatusConflict
+	case domain.ErrNotLoanOwner:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
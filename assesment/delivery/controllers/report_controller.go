@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportController handles HTTP requests for portfolio reports.
+type ReportController struct {
+	reportUsecase domain.ReportUsecase
+}
+
+// NewReportController creates a new instance of ReportController.
+func NewReportController(reportUsecase domain.ReportUsecase) *ReportController {
+	return &ReportController{
+		reportUsecase: reportUsecase,
+	}
+}
+
+// GetPortfolioReport handles the request to summarise the loan book in a reporting currency.
+func (rc *ReportController) GetPortfolioReport(c *gin.Context) {
+	report, err := rc.reportUsecase.GetPortfolioReport(c.Query("currency"))
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetAgingReport handles the request to spread the loans on book over the aging buckets.
+func (rc *ReportController) GetAgingReport(c *gin.Context) {
+	report, err := rc.reportUsecase.GetAgingReport(c.Query("currency"))
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetRecoveryReport handles the request to report what has been recovered on written-off loans.
+func (rc *ReportController) GetRecoveryReport(c *gin.Context) {
+	report, err := rc.reportUsecase.GetRecoveryReport(c.Query("currency"))
+	if err != nil {
+		c.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// reportErrorStatus maps a report usecase error to the HTTP status code returned to the client.
+func reportErrorStatus(err error) int {
+	switch err {
+	case domain.ErrUnknownCurrency:
+		return http.StatusBadRequest
+	case domain.ErrRateUnavailable:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RestructureController handles HTTP requests related to restructuring loans.
+type RestructureController struct {
+	restructureUsecase domain.RestructureUsecase
+}
+
+// NewRestructureController creates a new instance of RestructureController.
+func NewRestructureController(restructureUsecase domain.RestructureUsecase) *RestructureController {
+	return &RestructureController{
+		restructureUsecase: restructureUsecase,
+	}
+}
+
+// RestructureLoan handles the request to restructure the remaining schedule of a loan.
+func (rc *RestructureController) RestructureLoan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var terms domain.RestructureTerms
+	if err := c.ShouldBindJSON(&terms); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	modification, err := rc.restructureUsecase.RestructureLoan(uint(id), terms, adminID)
+	if err != nil {
+		c.JSON(restructureErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, modification)
+}
+
+// GetLoanModifications handles the request to retrieve the restructurings of a loan.
+func (rc *RestructureController) GetLoanModifications(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	modifications, err := rc.restructureUsecase.GetLoanModifications(uint(id))
+	if err != nil {
+		c.JSON(restructureErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, modifications)
+}
+
+// restructureErrorStatus maps a restructuring usecase error to the HTTP status code returned to the client.
+func restructureErrorStatus(err error) int {
+	var transitionErr *domain.InvalidTransitionError
+	if errors.As(err, &transitionErr) {
+		return http.StatusConflict
+	}
+	switch err {
+	case domain.ErrRestructureReasonRequired, domain.ErrInvalidRestructure, domain.ErrInvalidInterestRate,
+		domain.ErrTermExtensionRequired:
+		return http.StatusBadRequest
+	case mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrLoanNotRestructurable, domain.ErrNothingToRestructure, domain.ErrLoanModified:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"assesment/domain"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReviewController handles HTTP requests related to the underwriting queue.
+type ReviewController struct {
+	reviewUsecase domain.ReviewUsecase
+}
+
+// NewReviewController creates a new instance of ReviewController.
+func NewReviewController(reviewUsecase domain.ReviewUsecase) *ReviewController {
+	return &ReviewController{
+		reviewUsecase: reviewUsecase,
+	}
+}
+
+// GetReviewQueue handles the request to list the underwriting queue. The reviewer query
+// narrows it to a reviewer ID, "me" or "unassigned".
+func (rc *ReviewController) GetReviewQueue(c *gin.Context) {
+	var filter domain.ReviewQueueFilter
+	switch reviewer := c.Query("reviewer"); reviewer {
+	case "":
+	case "unassigned":
+		filter.Unassigned = true
+	case "me":
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		filter.ReviewerID = userID
+	default:
+		reviewerID, err := primitive.ObjectIDFromHex(reviewer)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reviewer ID"})
+			return
+		}
+		filter.ReviewerID = reviewerID
+	}
+
+	queue, err := rc.reviewUsecase.GetReviewQueue(filter, time.Now())
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// AssignUnassigned handles the request to hand the unassigned applications to the reviewers in turn now.
+func (rc *ReviewController) AssignUnassigned(c *gin.Context) {
+	assigned, err := rc.reviewUsecase.AssignUnassigned(time.Now())
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error(), "assigned": assigned})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"assigned": assigned})
+}
+
+// AssignReviewer handles the request of an admin to assign an application to a reviewer.
+func (rc *ReviewController) AssignReviewer(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var request struct {
+		ReviewerID primitive.ObjectID `json:"reviewer_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	loan, err := rc.reviewUsecase.AssignReviewer(uint(id), request.ReviewerID, adminID)
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// ClaimLoan handles the request of a reviewer to take an unassigned application.
+func (rc *ReviewController) ClaimLoan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	reviewerID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	loan, err := rc.reviewUsecase.ClaimLoan(uint(id), reviewerID)
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// AddReviewNote handles the request of a reviewer to leave an internal note on a loan.
+func (rc *ReviewController) AddReviewNote(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var request struct {
+		Text string `json:"text"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authorID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	note, err := rc.reviewUsecase.AddReviewNote(uint(id), request.Text, authorID)
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// GetReviewNotes handles the request to retrieve the internal notes of a loan.
+func (rc *ReviewController) GetReviewNotes(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	notes, err := rc.reviewUsecase.GetReviewNotes(uint(id))
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+// RequestInformation handles the request of a reviewer to ask the borrower for more information.
+func (rc *ReviewController) RequestInformation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var request struct {
+		Message string `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reviewerID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	infoRequest, err := rc.reviewUsecase.RequestInformation(uint(id), request.Message, reviewerID)
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, infoRequest)
+}
+
+// RespondToInformationRequest handles the answer of a borrower to an information request.
+func (rc *ReviewController) RespondToInformationRequest(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	requestID, err := primitive.ObjectIDFromHex(c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid information request ID"})
+		return
+	}
+
+	var request struct {
+		Response string `json:"response"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	infoRequest, err := rc.reviewUsecase.RespondToInformationRequest(uint(id), requestID, request.Response, userID)
+	if err != nil {
+		c.JSON(reviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, infoRequest)
+}
+
+// reviewErrorStatus maps a review usecase error to the HTTP status code returned to the client.
+func reviewErrorStatus(err error) int {
+	if status := loanErrorStatus(err); status != http.StatusInternalServerError {
+		return status
+	}
+	switch err {
+	case domain.ErrInvalidReviewer, domain.ErrReviewNoteRequired, domain.ErrInfoRequestMessage,
+		domain.ErrInfoResponseRequired:
+		return http.StatusBadRequest
+	case domain.ErrInfoRequestNotFound, mongo.ErrNoDocuments:
+		return http.StatusNotFound
+	case domain.ErrLoanNotInReviewQueue, domain.ErrInfoRequestAnswered, domain.ErrLoanModified:
+		return http.StatusConflict
+	case domain.ErrNoReviewers:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
@@ -1,32 +1,218 @@
 package main
 
 import (
+	"assesment/config"
 	"assesment/delivery/controllers"
 	"assesment/delivery/routes"
-	infrastructure"assesment/infrastructure"
+	"assesment/domain"
+	infrastructure"assesment/Infrastructure"
 	repositories"assesment/repo"
 	"assesment/usecase"
 	"github.com/gin-gonic/gin"
+	"log"
+	"time"
 )
 
 func main() {
+	config.InitiEnvConfigs()               // app.env loading
 	client := infrastructure.MongoDBInit() // MongoDB initialization
 
 	// Initialize the repositories
 	userRepo := repositories.NewUserRepository(client)
-	loanRepo := repositories.NewLoanRepository(client)
+	loanRepo, err := repositories.NewLoanRepository(client)
+	if err != nil {
+		log.Fatalf("Unable to set up the loans, %v", err)
+	}
+	paymentRepo := repositories.NewPaymentRepository(client)
+	ledgerRepo, err := repositories.NewLedgerRepository(client)
+	if err != nil {
+		log.Fatalf("Unable to set up the ledger, %v", err)
+	}
+	productRepo := repositories.NewProductRepository(client)
+	feeRepo := repositories.NewFeeRepository(client)
+	accrualRepo, err := repositories.NewAccrualRepository(client)
+	if err != nil {
+		log.Fatalf("Unable to set up the interest accruals, %v", err)
+	}
+	jobRunRepo := repositories.NewJobRunRepository(client)
+	disbursementRepo := repositories.NewDisbursementRepository(client)
+	modificationRepo := repositories.NewModificationRepository(client)
+	counterOfferRepo := repositories.NewCounterOfferRepository(client)
+	collateralRepo := repositories.NewCollateralRepository(client)
+	documentRepo := repositories.NewDocumentRepository(client)
+	reviewNoteRepo := repositories.NewReviewNoteRepository(client)
+	rejectionReasonRepo := repositories.NewRejectionReasonRepository(client)
+	historyRepo := repositories.NewLoanHistoryRepository(client)
 
 	// Set up the token generator, password service, and use cases
 	secretKey := "abebe"
 	tokenGen := infrastructure.NewTokenGeneratorImpl(secretKey, userRepo)
 	passwordService := infrastructure.NewPasswordService()
 
+	// Set up the exchange rates used to convert reports into the reporting currency. Without a
+	// rate file only the reporting currency has a rate, and reports list other currencies unconverted
+	reportingCurrency := config.EnvConfigs.ReportingCurrency
+	if reportingCurrency == "" {
+		reportingCurrency = "USD"
+	}
+	exchangeRates, err := infrastructure.NewStaticExchangeRateProvider(reportingCurrency, nil)
+	if config.EnvConfigs.ExchangeRatesFile != "" {
+		exchangeRates, err = infrastructure.NewFileExchangeRateProvider(config.EnvConfigs.ExchangeRatesFile)
+	}
+	if err != nil {
+		log.Fatalf("Unable to load exchange rates, %v", err)
+	}
+
+	// Set up the consent links emailed to co-borrowers and guarantors
+	appBaseURL := config.EnvConfigs.AppBaseURL
+	if appBaseURL == "" {
+		appBaseURL = "http://localhost:8080"
+	}
+	consentNotifier := infrastructure.NewEmailConsentNotifier(appBaseURL)
+
+	// Set up the adverse-action notices sent to rejected borrowers, from the built-in template
+	// unless one is configured
+	adverseActionNotifier, err := infrastructure.NewTemplateAdverseActionNotifier(config.EnvConfigs.AdverseActionTemplate)
+	if err != nil {
+		log.Fatalf("Unable to load the adverse-action template, %v", err)
+	}
+
+	// Set up where the content of uploaded loan documents is kept
+	var documentStore domain.BlobStore
+	switch config.EnvConfigs.DocumentStore {
+	case "gridfs":
+		documentStore, err = infrastructure.NewGridFSBlobStore(client, "loan_documents")
+	case "", "local":
+		documentDir := config.EnvConfigs.DocumentDir
+		if documentDir == "" {
+			documentDir = "uploads"
+		}
+		documentStore, err = infrastructure.NewLocalBlobStore(documentDir)
+	default:
+		log.Fatalf("Unknown document store %q", config.EnvConfigs.DocumentStore)
+	}
+	if err != nil {
+		log.Fatalf("Unable to set up the document store, %v", err)
+	}
+
+	// Set up the payout providers. Manual payouts, confirmed by an admin, are always offered;
+	// bank transfers and mobile money only once a provider is configured for them
+	payoutProviders := []domain.PayoutProvider{infrastructure.NewManualPayoutProvider()}
+	for method, name := range map[domain.PayoutMethod]string{
+		domain.PayoutBankTransfer: config.EnvConfigs.BankTransferProvider,
+		domain.PayoutMobileMoney:  config.EnvConfigs.MobileMoneyProvider,
+	} {
+		provider, err := infrastructure.NewConfiguredPayoutProvider(method, name)
+		if err != nil {
+			log.Fatalf("Invalid payout provider, %v", err)
+		}
+		if provider != nil {
+			payoutProviders = append(payoutProviders, provider)
+		}
+	}
+
+	// Set up the four-eyes control on large loans, a threshold in the reporting currency
+	var dualApproval domain.DualApprovalPolicy
+	if config.EnvConfigs.DualApprovalThreshold != "" {
+		threshold, err := domain.ParseMoney(config.EnvConfigs.DualApprovalThreshold, reportingCurrency)
+		if err != nil {
+			log.Fatalf("Invalid dual approval threshold, %v", err)
+		}
+		dualApproval = domain.DualApprovalPolicy{Threshold: threshold, Rates: exchangeRates}
+	}
+
+	// Set up the credit rules that decide loan applications
+	var creditRules []domain.CreditRule
+	if config.EnvConfigs.CreditRulesFile != "" {
+		ruleConfigs, err := infrastructure.LoadCreditRules(config.EnvConfigs.CreditRulesFile)
+		if err != nil {
+			log.Fatalf("Unable to load credit rules, %v", err)
+		}
+		if creditRules, err = usecase.NewCreditRules(ruleConfigs); err != nil {
+			log.Fatalf("Invalid credit rules, %v", err)
+		}
+	}
+
+	// Set up the days past due at which loans become delinquent and default
+	delinquencyPolicy := domain.DefaultDelinquencyPolicy
+	if config.EnvConfigs.DelinquentAfterDays > 0 {
+		delinquencyPolicy.DelinquentAfterDays = config.EnvConfigs.DelinquentAfterDays
+	}
+	if config.EnvConfigs.DefaultAfterDays > 0 {
+		delinquencyPolicy.DefaultAfterDays = config.EnvConfigs.DefaultAfterDays
+	}
+	reviewSLA := domain.DefaultReviewSLA
+	if config.EnvConfigs.ReviewSLAHours > 0 {
+		reviewSLA = time.Duration(config.EnvConfigs.ReviewSLAHours) * time.Hour
+	}
+	jobInterval := 24 * time.Hour
+	if config.EnvConfigs.JobIntervalHours > 0 {
+		jobInterval = time.Duration(config.EnvConfigs.JobIntervalHours) * time.Hour
+	}
+
 	// Set up the controllers
 	userCtrl := controllers.NewUserController(usecase.NewUserUsecase(userRepo, tokenGen, passwordService))
-	loanCtrl := controllers.NewLoanController(usecase.NewLoanUsecase(loanRepo))
+	ledgerUsecase := usecase.NewLedgerUsecase(ledgerRepo)
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, paymentRepo, disbursementRepo, productRepo, userRepo, ledgerUsecase, usecase.NewDecisionEngine(creditRules...), consentNotifier, collateralRepo, dualApproval, rejectionReasonRepo, adverseActionNotifier, historyRepo)
+	loanCtrl := controllers.NewLoanController(loanUsecase)
+	ledgerCtrl := controllers.NewLedgerController(ledgerUsecase)
+	productCtrl := controllers.NewProductController(usecase.NewProductUsecase(productRepo))
+	creditScoreCtrl := controllers.NewCreditScoreController(usecase.NewCreditScoreUsecase(loanRepo, userRepo, usecase.NewRepaymentHistoryScorer()))
+	reportCtrl := controllers.NewReportController(usecase.NewReportUsecase(loanRepo, exchangeRates, reportingCurrency))
+	delinquencyUsecase, err := usecase.NewDelinquencyUsecase(loanRepo, loanUsecase, delinquencyPolicy, historyRepo)
+	if err != nil {
+		log.Fatalf("Invalid delinquency thresholds, %v", err)
+	}
+	feeUsecase := usecase.NewFeeUsecase(loanRepo, feeRepo, loanUsecase, ledgerUsecase, historyRepo)
+	feeCtrl := controllers.NewFeeController(feeUsecase)
+	accrualUsecase := usecase.NewAccrualUsecase(loanRepo, accrualRepo, jobRunRepo, ledgerUsecase, historyRepo)
+	jobCtrl := controllers.NewJobController(delinquencyUsecase, feeUsecase, accrualUsecase)
+	restructureCtrl := controllers.NewRestructureController(usecase.NewRestructureUsecase(loanRepo, modificationRepo, loanUsecase, ledgerUsecase, historyRepo))
+	counterOfferUsecase := usecase.NewCounterOfferUsecase(loanRepo, counterOfferRepo, productRepo, loanUsecase, dualApproval, historyRepo)
+	counterOfferCtrl := controllers.NewCounterOfferController(counterOfferUsecase)
+	loanPartyCtrl := controllers.NewLoanPartyController(usecase.NewLoanPartyUsecase(loanRepo, userRepo, consentNotifier, historyRepo))
+	collateralCtrl := controllers.NewCollateralController(usecase.NewCollateralUsecase(collateralRepo, loanRepo, historyRepo))
+	documentCtrl := controllers.NewDocumentController(usecase.NewDocumentUsecase(loanRepo, documentRepo, documentStore, historyRepo))
+	reviewUsecase := usecase.NewReviewUsecase(loanRepo, userRepo, reviewNoteRepo, loanUsecase, historyRepo, reviewSLA)
+	reviewCtrl := controllers.NewReviewController(reviewUsecase)
+	rejectionUsecase := usecase.NewRejectionUsecase(rejectionReasonRepo, loanRepo, userRepo, adverseActionNotifier)
+	if err := rejectionUsecase.SeedDefaultReasons(); err != nil {
+		log.Fatalf("Unable to seed the rejection reasons, %v", err)
+	}
+	rejectionCtrl := controllers.NewRejectionController(rejectionUsecase)
+	historyCtrl := controllers.NewLoanHistoryController(usecase.NewLoanHistoryUsecase(loanRepo, historyRepo))
+	disbursementCtrl := controllers.NewDisbursementController(usecase.NewDisbursementUsecase(loanRepo, disbursementRepo, loanUsecase, payoutProviders...))
+
+	// Start the background jobs
+	scheduler := infrastructure.NewScheduler()
+	// The jobs run one after another: each of them updates loans, and running them
+	// side by side would have them overwrite each other's changes.
+	scheduler.Every(jobInterval, "loan_servicing", infrastructure.Sequence(
+		infrastructure.NamedJob{Name: "interest_accrual", Run: func() error {
+			_, err := accrualUsecase.RunAccrual(time.Now().AddDate(0, 0, -1))
+			return err
+		}},
+		infrastructure.NamedJob{Name: "late_charges", Run: func() error {
+			_, err := feeUsecase.AccrueLateCharges(time.Now())
+			return err
+		}},
+		infrastructure.NamedJob{Name: "delinquency", Run: func() error {
+			_, err := delinquencyUsecase.RunDelinquencyCheck(time.Now())
+			return err
+		}},
+		infrastructure.NamedJob{Name: "counter_offer_expiry", Run: func() error {
+			_, err := counterOfferUsecase.ExpireCounterOffers(time.Now())
+			return err
+		}},
+		infrastructure.NamedJob{Name: "review_assignment", Run: func() error {
+			_, err := reviewUsecase.AssignUnassigned(time.Now())
+			return err
+		}},
+	))
+	defer scheduler.Stop()
 
 	// Set up the router
 	router := gin.Default()
-	routes.SetupRoutes(router, userCtrl, loanCtrl)
+	routes.SetupRoutes(router, userCtrl, loanCtrl, ledgerCtrl, reportCtrl, productCtrl, creditScoreCtrl, jobCtrl, feeCtrl, disbursementCtrl, restructureCtrl, counterOfferCtrl, loanPartyCtrl, collateralCtrl, documentCtrl, reviewCtrl, rejectionCtrl, historyCtrl)
 	router.Run(":8080")
 }
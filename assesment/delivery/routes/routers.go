@@ -2,12 +2,12 @@ package routes
 
 import (
 	controllers "assesment/delivery/controllers"
-	infrastructure "assesment/infrastructure"
+	infrastructure "assesment/Infrastructure"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes initializes and configures the routes for the application.
-func SetupRoutes(gino *gin.Engine, userCtrl *controllers.UserController, loanCtrl *controllers.LoanController) {
+func SetupRoutes(gino *gin.Engine, userCtrl *controllers.UserController, loanCtrl *controllers.LoanController, ledgerCtrl *controllers.LedgerController, reportCtrl *controllers.ReportController, productCtrl *controllers.ProductController, creditScoreCtrl *controllers.CreditScoreController, jobCtrl *controllers.JobController, feeCtrl *controllers.FeeController, disbursementCtrl *controllers.DisbursementController, restructureCtrl *controllers.RestructureController, counterOfferCtrl *controllers.CounterOfferController, loanPartyCtrl *controllers.LoanPartyController, collateralCtrl *controllers.CollateralController, documentCtrl *controllers.DocumentController, reviewCtrl *controllers.ReviewController, rejectionCtrl *controllers.RejectionController, historyCtrl *controllers.LoanHistoryController) {
 
 	// Public routes
 	// Route for user registration
@@ -23,13 +23,15 @@ func SetupRoutes(gino *gin.Engine, userCtrl *controllers.UserController, loanCtr
 	// Route to refresh a user's JWT token
 	gino.POST("/auth/refresh-token", userCtrl.RefreshToken)
 
+	// Public routes for loan products
+	// Route to list the loan products open for applications
+	gino.GET("/products", productCtrl.GetActiveProducts)
+
 	// Public routes for loans
-	// Route to apply for a loan
-	gino.POST("/loans", loanCtrl.ApplyForLoan)
-	// Route to get a loan by ID
-	gino.GET("/loans/:id", loanCtrl.GetLoanByID)
-	// Route to get all loans with optional filtering and sorting
-	gino.GET("/loans", loanCtrl.GetAllLoans)
+	// Route for a co-borrower or guarantor to open the consent link emailed to them
+	gino.GET("/consents/:token", loanPartyCtrl.GetConsentRequest)
+	// Route for a co-borrower or guarantor to give or decline consent through their link
+	gino.POST("/consents/:token", loanPartyCtrl.RespondToConsent)
 
 	// Protected routes group
 	auth := gino.Group("/")
@@ -42,6 +44,64 @@ func SetupRoutes(gino *gin.Engine, userCtrl *controllers.UserController, loanCtr
 		auth.PUT("/user/update", userCtrl.UpdateUser)
 		// Route to update the current user's password
 		auth.POST("/user/update-password", userCtrl.UpdateUserPassword)
+		// Route for the current user to apply for a loan
+		auth.POST("/loans", loanCtrl.ApplyForLoan)
+		// Route for an admin to get all loans with optional filtering and sorting, or a borrower their own loans
+		auth.GET("/loans", loanCtrl.GetAllLoans)
+		// Route for the borrower, or an admin, to get a loan with its review, parties and any rejection
+		auth.GET("/loans/:id", loanCtrl.RequireLoanOwner, loanCtrl.GetLoanByID)
+		// Route to get the current user's payment history
+		auth.GET("/user/payments", loanCtrl.GetMyPayments)
+		// Route for the borrower, or an admin, to record a repayment against a loan
+		auth.POST("/loans/:id/payments", loanCtrl.RequireLoanOwner, loanCtrl.RecordPayment)
+		// Route for the borrower, or an admin, to get the payment history of a loan
+		auth.GET("/loans/:id/payments", loanCtrl.RequireLoanOwner, loanCtrl.GetLoanPayments)
+		// Route for the borrower, or an admin, to get the repayment schedule of a loan
+		auth.GET("/loans/:id/schedule", loanCtrl.RequireLoanOwner, loanCtrl.GetLoanSchedule)
+		// Route for the borrower, or an admin, to get what settles a loan in full today or on a given date
+		auth.GET("/loans/:id/payoff-quote", loanCtrl.RequireLoanOwner, loanCtrl.GetPayoffQuote)
+		// Route for the borrower, or an admin, to get what is still owed on a loan, by component
+		auth.GET("/loans/:id/balance", loanCtrl.RequireLoanOwner, feeCtrl.GetLoanBalance)
+		// Route for the borrower, or an admin, to get the late fees and penalty interest charged on a loan
+		auth.GET("/loans/:id/fees", loanCtrl.RequireLoanOwner, feeCtrl.GetLoanFees)
+		// Route for the borrower, or an admin, to get the restructurings of a loan with the schedules before and after
+		auth.GET("/loans/:id/modifications", loanCtrl.RequireLoanOwner, restructureCtrl.GetLoanModifications)
+		// Route for the borrower, or an admin, to get the counter-offers made on a loan application
+		auth.GET("/loans/:id/counter-offers", loanCtrl.RequireLoanOwner, counterOfferCtrl.GetLoanCounterOffers)
+		// Route for the borrower, or an admin, to get the collateral pledged to a loan
+		auth.GET("/loans/:id/collateral", loanCtrl.RequireLoanOwner, collateralCtrl.GetLoanCollateral)
+		// Route to get the loans the current user is a co-borrower or guarantor on
+		auth.GET("/user/guarantees", loanPartyCtrl.GetMyGuarantees)
+		// Route for the borrower, a co-borrower or guarantor, or an admin to get the parties of a loan
+		auth.GET("/loans/:id/parties", loanCtrl.RequireLoanParticipant, loanPartyCtrl.GetLoanParties)
+		// Route for the current user to add a co-borrower or guarantor to their application
+		auth.POST("/loans/:id/parties", loanPartyCtrl.AddLoanParty)
+		// Route for the current user to remove a co-borrower or guarantor from their application
+		auth.DELETE("/loans/:id/parties/:partyId", loanPartyCtrl.RemoveLoanParty)
+		// Route for the current user to pledge a collateral to their application
+		auth.POST("/loans/:id/collateral", collateralCtrl.LinkCollateral)
+		// Routes for the current user to manage their collateral
+		auth.POST("/collateral", collateralCtrl.CreateCollateral)
+		auth.GET("/collateral", collateralCtrl.GetMyCollateral)
+		auth.GET("/collateral/:id", collateralCtrl.GetCollateral)
+		auth.PUT("/collateral/:id", collateralCtrl.UpdateCollateral)
+		auth.DELETE("/collateral/:id", collateralCtrl.DeleteCollateral)
+		// Route for the current user to upload a document to their application
+		auth.POST("/loans/:id/documents", documentCtrl.UploadDocument)
+		// Route for the current user to list the documents of their application
+		auth.GET("/loans/:id/documents", documentCtrl.GetMyLoanDocuments)
+		// Route for the current user to answer an information request on their application
+		auth.POST("/loans/:id/info-requests/:requestId/respond", reviewCtrl.RespondToInformationRequest)
+		// Route for the borrower, or an admin, to get the timeline of every change to a loan
+		auth.GET("/loans/:id/history", historyCtrl.GetLoanHistory)
+		// Route for the current user to get the adverse-action notice of their rejected application
+		auth.GET("/loans/:id/adverse-action-notice", rejectionCtrl.GetAdverseActionNotice)
+		// Route for the current user to withdraw a loan application still under consideration
+		auth.POST("/loans/:id/withdraw", loanCtrl.WithdrawLoan)
+		// Route for the current user to accept a counter-offer on their application
+		auth.POST("/loans/:id/counter-offers/:offerId/accept", counterOfferCtrl.AcceptCounterOffer)
+		// Route for the current user to decline a counter-offer on their application
+		auth.POST("/loans/:id/counter-offers/:offerId/decline", counterOfferCtrl.DeclineCounterOffer)
 
 		// Admin-specific endpoint group
 		admin := auth.Group("/")
@@ -54,14 +114,107 @@ func SetupRoutes(gino *gin.Engine, userCtrl *controllers.UserController, loanCtr
 			admin.GET("/admin/users/:id", userCtrl.GetUserByID)
 			// Route to delete a user by ID (admin operation)
 			admin.DELETE("/admin/users/:id", userCtrl.DeleteUser)
+			// Route to get a user's payment history
+			admin.GET("/admin/users/:id/payments", loanCtrl.GetUserPayments)
+			// Route to get a user's credit score and the factors behind it
+			admin.GET("/admin/users/:id/credit-score", creditScoreCtrl.GetCreditScore)
 			
 			// Admin-specific routes for loans
+			// Route to list the underwriting queue, optionally for one reviewer (admin operation)
+			admin.GET("/admin/review-queue", reviewCtrl.GetReviewQueue)
+			// Route to hand the unassigned applications to the reviewers in turn (admin operation)
+			admin.POST("/admin/review-queue/assign", reviewCtrl.AssignUnassigned)
+			// Route to assign an application to a reviewer (admin operation)
+			admin.POST("/admin/loans/:id/assign", reviewCtrl.AssignReviewer)
+			// Route for a reviewer to claim an unassigned application (admin operation)
+			admin.POST("/admin/loans/:id/claim", reviewCtrl.ClaimLoan)
+			// Routes for reviewers to leave and read internal notes on a loan (admin operation)
+			admin.POST("/admin/loans/:id/notes", reviewCtrl.AddReviewNote)
+			admin.GET("/admin/loans/:id/notes", reviewCtrl.GetReviewNotes)
+			// Route for a reviewer to ask the borrower for more information (admin operation)
+			admin.POST("/admin/loans/:id/info-requests", reviewCtrl.RequestInformation)
 			// Route to approve a loan
 			admin.POST("/admin/loans/:id/approve", loanCtrl.ApproveLoan)
-			// Route to reject a loan
+			// Route to reject a loan, giving reason codes from the catalog
 			admin.POST("/admin/loans/:id/reject", loanCtrl.RejectLoan)
+			// Routes to manage the catalog of rejection reasons (admin operation)
+			admin.POST("/admin/rejection-reasons", rejectionCtrl.CreateReason)
+			admin.GET("/admin/rejection-reasons", rejectionCtrl.GetReasons)
+			admin.PUT("/admin/rejection-reasons/:code", rejectionCtrl.UpdateReason)
+			// Route to offer the borrower a lower amount, another term or another rate
+			admin.POST("/admin/loans/:id/counter-offers", counterOfferCtrl.MakeCounterOffer)
+			// Route to list the documents of a loan (admin operation)
+			admin.GET("/admin/loans/:id/documents", documentCtrl.GetLoanDocuments)
+			// Route to download a document of a loan (admin operation)
+			admin.GET("/admin/loans/:id/documents/:documentId", documentCtrl.DownloadDocument)
+			// Route to accept or reject a document of a loan (admin operation)
+			admin.POST("/admin/loans/:id/documents/:documentId/review", documentCtrl.ReviewDocument)
+			// Route to pay out an approved loan
+			admin.POST("/admin/loans/:id/disbursements", disbursementCtrl.DisburseLoan)
+			// Route to get the disbursements of a loan and their attempts
+			admin.GET("/admin/loans/:id/disbursements", disbursementCtrl.GetLoanDisbursements)
+			// Route to send a failed disbursement again
+			admin.POST("/admin/loans/:id/disbursements/:disbursementId/retry", disbursementCtrl.RetryDisbursement)
+			// Route to confirm a disbursement waiting for confirmation
+			admin.POST("/admin/loans/:id/disbursements/:disbursementId/confirm", disbursementCtrl.ConfirmDisbursement)
+			// Route to restructure the remaining schedule of a loan
+			admin.POST("/admin/loans/:id/restructure", restructureCtrl.RestructureLoan)
+			// Route to waive a late fee or penalty interest charge
+			admin.POST("/admin/loans/:id/fees/:feeId/waive", feeCtrl.WaiveFee)
+			// Route to write off a defaulted loan
+			admin.POST("/admin/loans/:id/write-off", loanCtrl.WriteOffLoan)
+			// Route to record money recovered on a written-off loan
+			admin.POST("/admin/loans/:id/recoveries", loanCtrl.RecordRecovery)
+			// Route to cancel an approved loan before it is paid out
+			admin.POST("/admin/loans/:id/cancel", loanCtrl.CancelLoan)
+			// Route to move a loan to another lifecycle state
+			admin.PATCH("/admin/loans/:id/status", loanCtrl.UpdateLoanStatus)
 			// Route to delete a loan
 			admin.DELETE("/admin/loans/:id", loanCtrl.DeleteLoan)
+			// Route to get the journal entries posted for a loan
+			admin.GET("/admin/loans/:id/ledger", ledgerCtrl.GetLoanEntries)
+			// Route to get the daily interest accrued on a loan
+			admin.GET("/admin/loans/:id/accruals", jobCtrl.GetLoanAccruals)
+
+			// Admin-specific routes for loan products
+			// Route to create a loan product
+			admin.POST("/admin/products", productCtrl.CreateProduct)
+			// Route to get all loan products, including inactive ones
+			admin.GET("/admin/products", productCtrl.GetProducts)
+			// Route to get a loan product by ID
+			admin.GET("/admin/products/:id", productCtrl.GetProduct)
+			// Route to update a loan product
+			admin.PUT("/admin/products/:id", productCtrl.UpdateProduct)
+			// Route to delete a loan product
+			admin.DELETE("/admin/products/:id", productCtrl.DeleteProduct)
+
+			// Admin-specific routes for the ledger
+			// Route to get the balance of every ledger account
+			admin.GET("/admin/ledger/accounts", ledgerCtrl.GetTrialBalance)
+			// Route to get the outstanding principal across the portfolio
+			admin.GET("/admin/ledger/outstanding-principal", ledgerCtrl.GetOutstandingPrincipal)
+			// Route to get the journal entries posted within a date range
+			admin.GET("/admin/ledger/entries", ledgerCtrl.GetEntries)
+
+			// Admin-specific routes for reports
+			// Route to get the portfolio summary in a reporting currency
+			admin.GET("/admin/reports/portfolio", reportCtrl.GetPortfolioReport)
+			// Route to get the loans on book grouped by days past due
+			admin.GET("/admin/reports/aging", reportCtrl.GetAgingReport)
+			// Route to get what has been written off and recovered since
+			admin.GET("/admin/reports/recoveries", reportCtrl.GetRecoveryReport)
+
+			// Admin-specific routes for background jobs
+			// Route to run the delinquency check now
+			admin.POST("/admin/jobs/delinquency", jobCtrl.RunDelinquencyCheck)
+			// Route to charge late fees and penalty interest now
+			admin.POST("/admin/jobs/late-charges", jobCtrl.RunLateCharges)
+			// Route to accrue interest now
+			admin.POST("/admin/jobs/accrual", jobCtrl.RunAccrual)
+			// Route to get the last accrual run and its progress
+			admin.GET("/admin/jobs/accrual/runs/latest", jobCtrl.GetLatestAccrualRun)
+			// Route to get the recent accrual runs
+			admin.GET("/admin/jobs/accrual/runs", jobCtrl.GetAccrualRuns)
 		}
 	}
 }
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DayCountConvention sets how many days of interest a period earns and how long a year is.
+type DayCountConvention string
+
+const (
+	DayCountActual365 DayCountConvention = "actual/365" // calendar days over a 365-day year
+	DayCount30360     DayCountConvention = "30/360"     // 30-day months over a 360-day year (US bond basis)
+)
+
+// IsValid reports whether c is a supported day-count convention.
+func (c DayCountConvention) IsValid() bool {
+	return c == DayCountActual365 || c == DayCount30360
+}
+
+// YearFraction returns the share of a year between the days from and to fall on.
+func (c DayCountConvention) YearFraction(from, to time.Time) *big.Rat {
+	if c == DayCount30360 {
+		return big.NewRat(int64(days30360(from, to)), 360)
+	}
+
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	days := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC).Sub(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)).Hours() / 24
+	return big.NewRat(int64(days), 365)
+}
+
+// days30360 counts the days between from and to with every month taken as 30 days long.
+func days30360(from, to time.Time) int {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+	return 360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1)
+}
+
+// InterestAccrual is the interest a loan earned on one day. There is at most one per loan and day.
+type InterestAccrual struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LoanID    uint               `bson:"loan_id" json:"loan_id"`
+	Date      time.Time          `bson:"date" json:"date"`           // the day the interest was earned, at midnight UTC
+	Principal Money              `bson:"principal" json:"principal"` // outstanding principal interest was charged on
+	Rate      float64            `bson:"rate" json:"rate"`           // nominal annual rate, in percent
+	DayCount  DayCountConvention `bson:"day_count" json:"day_count"`
+	Amount    Money              `bson:"amount" json:"amount"`
+	Posted    bool               `bson:"posted" json:"posted"` // recorded in the ledger
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// AccrualRepository defines the methods for storing and retrieving interest accruals.
+type AccrualRepository interface {
+	// UpsertAccrual stores accrual unless the loan already has one for that day, and returns
+	// the stored accrual and whether it was created by this call.
+	UpsertAccrual(accrual InterestAccrual) (InterestAccrual, bool, error)
+	MarkAccrualPosted(id primitive.ObjectID) error
+	GetUnpostedAccruals() ([]InterestAccrual, error)
+	GetAccrualsByLoan(loanID uint) ([]InterestAccrual, error)
+}
+
+// JobRunStatus is the state of a run of a background job.
+type JobRunStatus string
+
+const (
+	JobRunRunning   JobRunStatus = "running"
+	JobRunSucceeded JobRunStatus = "succeeded"
+	JobRunPartial   JobRunStatus = "completed_with_errors" // some loans failed and will be retried by the next run
+	JobRunFailed    JobRunStatus = "failed"
+)
+
+// JobRun records the progress and outcome of a run of a background job.
+type JobRun struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Job            string             `bson:"job" json:"job"`
+	BusinessDate   time.Time          `bson:"business_date" json:"business_date"` // the last day the run covers
+	Status         JobRunStatus       `bson:"status" json:"status"`
+	LoansTotal     int                `bson:"loans_total" json:"loans_total"`
+	LoansProcessed int                `bson:"loans_processed" json:"loans_processed"`
+	Created        int                `bson:"created" json:"created"` // records created, e.g. accruals
+	Errors         []string           `bson:"errors,omitempty" json:"errors,omitempty"`
+	StartedAt      time.Time          `bson:"started_at" json:"started_at"`
+	FinishedAt     *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
+
+// JobRunRepository defines the methods for storing and retrieving job runs.
+type JobRunRepository interface {
+	CreateRun(run JobRun) error
+	UpdateRun(run JobRun) error
+	GetLatestRun(job string) (JobRun, error)
+	GetRuns(job string, limit int) ([]JobRun, error)
+}
+
+// AccrualUsecase defines the daily interest accrual job and its history.
+type AccrualUsecase interface {
+	RunAccrual(through time.Time) (JobRun, error)
+	GetLatestRun() (JobRun, error)
+	GetRuns(limit int) ([]JobRun, error)
+	GetLoanAccruals(loanID uint) ([]InterestAccrual, error)
+}
+
+// Accrual errors
+var (
+	ErrInvalidDayCount     = errors.New("unsupported day-count convention")
+	ErrJobRunning          = errors.New("the job is already running")
+	ErrJobRunNotFound      = errors.New("the job has not run yet")
+	ErrAccrualDateInFuture = errors.New("interest cannot be accrued for days that have not ended")
+)
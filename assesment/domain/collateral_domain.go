@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CollateralType is the kind of asset pledged as collateral.
+type CollateralType string
+
+const (
+	CollateralRealEstate  CollateralType = "real_estate"
+	CollateralVehicle     CollateralType = "vehicle"
+	CollateralEquipment   CollateralType = "equipment"
+	CollateralCashDeposit CollateralType = "cash_deposit"
+	CollateralOther       CollateralType = "other"
+)
+
+// IsValid reports whether t is a supported collateral type.
+func (t CollateralType) IsValid() bool {
+	switch t {
+	case CollateralRealEstate, CollateralVehicle, CollateralEquipment, CollateralCashDeposit, CollateralOther:
+		return true
+	}
+	return false
+}
+
+// LienStatus is the state of the lender's claim on a collateral.
+type LienStatus string
+
+const (
+	LienNone     LienStatus = "none"     // not pledged to any loan
+	LienActive   LienStatus = "active"   // pledged to the loan it is linked to
+	LienReleased LienStatus = "released" // the loan it secured was repaid or never paid out
+)
+
+// CollateralDocument references a document supporting a collateral, such as a title deed
+// or a valuation report.
+type CollateralDocument struct {
+	Name      string    `bson:"name" json:"name"`
+	Reference string    `bson:"reference" json:"reference"` // where the document is kept
+	AddedAt   time.Time `bson:"added_at" json:"added_at"`
+}
+
+// Collateral is an asset a borrower pledges to secure a loan. It secures at most one loan at
+// a time, and can be pledged again once its lien is released.
+type Collateral struct {
+	ID               primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	OwnerID          primitive.ObjectID   `bson:"owner_id" json:"owner_id"`
+	Type             CollateralType       `bson:"type" json:"type"`
+	Description      string               `bson:"description" json:"description"`
+	EstimatedValue   Money                `bson:"estimated_value" json:"estimated_value"`
+	ValuationDate    time.Time            `bson:"valuation_date" json:"valuation_date"`
+	Documents        []CollateralDocument `bson:"documents" json:"documents"`
+	LoanID           uint                 `bson:"loan_id,omitempty" json:"loan_id,omitempty"` // loan the collateral secures or last secured
+	LienStatus       LienStatus           `bson:"lien_status" json:"lien_status"`
+	LienRegisteredAt *time.Time           `bson:"lien_registered_at,omitempty" json:"lien_registered_at,omitempty"`
+	LienReleasedAt   *time.Time           `bson:"lien_released_at,omitempty" json:"lien_released_at,omitempty"`
+	CreatedAt        time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// Validate checks the type, description and valuation of a collateral.
+func (c Collateral) Validate() error {
+	if !c.Type.IsValid() || strings.TrimSpace(c.Description) == "" || c.ValuationDate.IsZero() {
+		return ErrInvalidCollateral
+	}
+	if err := c.EstimatedValue.Validate(); err != nil {
+		return err
+	}
+	if !c.EstimatedValue.IsPositive() {
+		return ErrInvalidCollateral
+	}
+	return nil
+}
+
+// CollateralRepository defines the methods for storing and retrieving collateral.
+type CollateralRepository interface {
+	CreateCollateral(collateral Collateral) error
+	GetCollateralByID(id primitive.ObjectID) (Collateral, error)
+	GetCollateralByOwner(ownerID primitive.ObjectID) ([]Collateral, error)
+	GetCollateralByLoan(loanID uint) ([]Collateral, error)
+	UpdateCollateral(collateral Collateral) error
+	DeleteCollateral(id primitive.ObjectID) error
+}
+
+// CollateralUsecase defines the business logic for managing collateral and pledging it to loans.
+type CollateralUsecase interface {
+	CreateCollateral(collateral Collateral, ownerID primitive.ObjectID) (Collateral, error)
+	GetCollateral(id primitive.ObjectID, ownerID primitive.ObjectID) (Collateral, error)
+	GetUserCollateral(ownerID primitive.ObjectID) ([]Collateral, error)
+	UpdateCollateral(id primitive.ObjectID, collateral Collateral, ownerID primitive.ObjectID) (Collateral, error)
+	DeleteCollateral(id primitive.ObjectID, ownerID primitive.ObjectID) error
+	LinkCollateral(loanID uint, collateralID primitive.ObjectID, userID primitive.ObjectID) (Loan, error)
+	GetLoanCollateral(loanID uint) ([]Collateral, error)
+}
+
+// Collateral errors
+var (
+	ErrInvalidCollateral   = errors.New("collateral needs a supported type, a description, a positive estimated value and a valuation date")
+	ErrCollateralNotFound  = errors.New("collateral not found")
+	ErrNotCollateralOwner  = errors.New("the collateral belongs to another user")
+	ErrCollateralUnderLien = errors.New("the collateral is pledged to a loan")
+	ErrCollateralLocked    = errors.New("collateral can only be linked while the application is pending or under review")
+)
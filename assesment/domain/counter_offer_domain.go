@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultCounterOfferValidity is how long a borrower has to answer a counter-offer when the
+// underwriter does not say.
+const DefaultCounterOfferValidity = 7 * 24 * time.Hour
+
+// CounterOfferStatus is the state of a counter-offer.
+type CounterOfferStatus string
+
+const (
+	CounterOfferPending    CounterOfferStatus = "pending" // waiting for the borrower
+	CounterOfferAccepted   CounterOfferStatus = "accepted"
+	CounterOfferDeclined   CounterOfferStatus = "declined"
+	CounterOfferExpired    CounterOfferStatus = "expired"
+	CounterOfferSuperseded CounterOfferStatus = "superseded" // replaced by a later offer
+)
+
+// CounterOffer is an approval on different terms from those applied for. The loan waits in
+// counter_offered until the borrower accepts, which approves it on the offered terms, or
+// declines or lets the offer expire, which returns it to review.
+type CounterOffer struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LoanID          uint               `bson:"loan_id" json:"loan_id"`
+	Amount          Money              `bson:"amount" json:"amount"`
+	Term            int                `bson:"term" json:"term"`                   // number of installments
+	InterestRate    float64            `bson:"interest_rate" json:"interest_rate"` // nominal annual rate, in percent
+	Note            string             `bson:"note,omitempty" json:"note,omitempty"`
+	RequestedAmount Money              `bson:"requested_amount" json:"requested_amount"` // terms applied for, kept once the loan takes the offered ones
+	RequestedTerm   int                `bson:"requested_term" json:"requested_term"`
+	RequestedRate   float64            `bson:"requested_rate" json:"requested_rate"`
+	Status          CounterOfferStatus `bson:"status" json:"status"`
+	OfferedBy       primitive.ObjectID `bson:"offered_by" json:"offered_by"`
+	ExpiresAt       time.Time          `bson:"expires_at" json:"expires_at"`
+	RespondedAt     *time.Time         `bson:"responded_at,omitempty" json:"responded_at,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CounterOfferTerms are what an underwriter offers instead of the terms applied for. A zero
+// term or rate keeps the one applied for.
+type CounterOfferTerms struct {
+	Amount       Money   `json:"amount"`
+	Term         int     `json:"term"`
+	InterestRate float64 `json:"interest_rate"`
+	Note         string  `json:"note"`
+	ValidForDays int     `json:"valid_for_days"` // defaults to DefaultCounterOfferValidity
+}
+
+// CounterOfferRepository defines the methods for storing and retrieving counter-offers.
+type CounterOfferRepository interface {
+	CreateCounterOffer(offer CounterOffer) error
+	GetCounterOfferByID(id primitive.ObjectID) (CounterOffer, error)
+	GetCounterOffersByLoan(loanID uint) ([]CounterOffer, error)
+	GetExpiredCounterOffers(now time.Time) ([]CounterOffer, error) // pending offers whose expiry has passed
+	UpdateCounterOffer(offer CounterOffer) error
+}
+
+// CounterOfferUsecase defines the business logic for counter-offers on loan applications.
+type CounterOfferUsecase interface {
+	MakeCounterOffer(loanID uint, terms CounterOfferTerms, offeredBy primitive.ObjectID) (CounterOffer, error)
+	AcceptCounterOffer(loanID uint, id primitive.ObjectID, userID primitive.ObjectID) (Loan, error)
+	DeclineCounterOffer(loanID uint, id primitive.ObjectID, userID primitive.ObjectID) (CounterOffer, error)
+	GetLoanCounterOffers(loanID uint) ([]CounterOffer, error)
+	ExpireCounterOffers(now time.Time) (int, error)
+}
+
+// Counter-offer errors
+var (
+	ErrInvalidCounterOffer    = errors.New("a counter-offer needs a positive amount in the loan's currency, no larger than applied for, and a non-negative term, rate and validity")
+	ErrLoanNotUnderReview     = errors.New("counter-offers can only be made on applications pending or under review")
+	ErrCounterOfferNotFound   = errors.New("counter-offer not found")
+	ErrCounterOfferNotPending = errors.New("the counter-offer has already been answered or replaced")
+	ErrCounterOfferExpired    = errors.New("the counter-offer has expired")
+	ErrCounterOfferPending    = errors.New("the loan is waiting for the borrower to answer a counter-offer")
+)
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bounds of the internal credit score.
+const (
+	MinCreditScore = 300
+	MaxCreditScore = 850
+)
+
+// CreditHistory is what a scoring model sees of a borrower.
+type CreditHistory struct {
+	User  User
+	Loans []Loan
+	Now   time.Time
+}
+
+// CreditScoreFactor is the contribution of one aspect of a borrower's history to their score.
+type CreditScoreFactor struct {
+	Name        string `json:"name"`
+	Points      int    `json:"points"` // added to (or, when negative, taken from) the base score
+	Description string `json:"description"`
+}
+
+// CreditScore is a borrower's internal credit score with the factors it was derived from.
+type CreditScore struct {
+	UserID     primitive.ObjectID  `json:"user_id"`
+	Model      string              `json:"model"`
+	Score      int                 `json:"score"` // between MinCreditScore and MaxCreditScore
+	Band       string              `json:"band"`
+	BaseScore  int                 `json:"base_score"`
+	Factors    []CreditScoreFactor `json:"factors"`
+	ComputedAt time.Time           `json:"computed_at"`
+}
+
+// CreditScorer is a credit scoring model.
+type CreditScorer interface {
+	Score(history CreditHistory) CreditScore
+}
+
+// CreditScoreUsecase defines the business logic for scoring borrowers.
+type CreditScoreUsecase interface {
+	GetCreditScore(userID primitive.ObjectID) (CreditScore, error)
+}
+
+// CreditScoreBand returns the risk band of a score.
+func CreditScoreBand(score int) string {
+	switch {
+	case score < 580:
+		return "poor"
+	case score < 670:
+		return "fair"
+	case score < 740:
+		return "good"
+	case score < 800:
+		return "very_good"
+	default:
+		return "excellent"
+	}
+}
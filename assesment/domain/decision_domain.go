@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// DecisionOutcome is the verdict of the credit rules on a loan application.
+type DecisionOutcome string
+
+const (
+	DecisionApprove DecisionOutcome = "approve" // the loan is approved without manual review
+	DecisionRefer   DecisionOutcome = "refer"   // the loan is queued for manual review
+	DecisionDecline DecisionOutcome = "decline" // the loan is rejected
+)
+
+// decisionSeverity orders the outcomes so the most severe one of a set of rules wins.
+var decisionSeverity = map[DecisionOutcome]int{
+	DecisionApprove: 0,
+	DecisionRefer:   1,
+	DecisionDecline: 2,
+}
+
+// IsValid reports whether o is a known outcome.
+func (o DecisionOutcome) IsValid() bool {
+	_, ok := decisionSeverity[o]
+	return ok
+}
+
+// Worse returns the more severe of o and other.
+func (o DecisionOutcome) Worse(other DecisionOutcome) DecisionOutcome {
+	if decisionSeverity[other] > decisionSeverity[o] {
+		return other
+	}
+	return o
+}
+
+// LoanStatus returns the state an application with this outcome is moved to.
+func (o DecisionOutcome) LoanStatus() LoanStatus {
+	switch o {
+	case DecisionApprove:
+		return LoanStatusApproved
+	case DecisionDecline:
+		return LoanStatusRejected
+	default:
+		return LoanStatusUnderReview
+	}
+}
+
+// RuleType identifies the check a credit rule performs.
+type RuleType string
+
+const (
+	RuleActiveAccount   RuleType = "active_account"       // the borrower has activated their account
+	RuleMinAccountAge   RuleType = "min_account_age_days" // the account is at least Threshold days old
+	RuleMaxActiveLoans  RuleType = "max_active_loans"     // the borrower has fewer than Threshold loans that are not closed
+	RuleMaxDebtToIncome RuleType = "max_debt_to_income"   // monthly repayments stay within Threshold percent of monthly income
+	RuleAmountLimit     RuleType = "amount_limit"         // the amount lies within Limits for its currency
+)
+
+// DefaultRuleReasonCodes are the rejection reasons stated to the borrower when a rule of each
+// type declines their application and its config names no other.
+var DefaultRuleReasonCodes = map[RuleType]string{
+	RuleActiveAccount:   "unable_to_verify_identity",
+	RuleMinAccountAge:   "insufficient_credit_history",
+	RuleMaxActiveLoans:  "excessive_obligations",
+	RuleMaxDebtToIncome: "excessive_obligations",
+	RuleAmountLimit:     "amount_outside_limits",
+}
+
+// RuleConfig is the declarative definition of a credit rule, as read from the rules file, e.g.
+// {"name": "dti", "type": "max_debt_to_income", "threshold": 40, "outcome": "refer"}.
+type RuleConfig struct {
+	Name       string          `json:"name" bson:"name"`
+	Type       RuleType        `json:"type" bson:"type"`
+	Threshold  float64         `json:"threshold,omitempty" bson:"threshold,omitempty"`
+	Limits     []AmountLimit   `json:"limits,omitempty" bson:"limits,omitempty"`
+	Outcome    DecisionOutcome `json:"outcome" bson:"outcome"`                             // outcome when the rule fails: refer or decline
+	ReasonCode string          `json:"reason_code,omitempty" bson:"reason_code,omitempty"` // rejection reason stated when the rule declines, defaults by type
+}
+
+// CreditApplication is what the credit rules see of a loan application.
+type CreditApplication struct {
+	Loan          Loan
+	User          User
+	ExistingLoans []Loan
+	Now           time.Time
+}
+
+// CreditRule is a single eligibility or credit check on an application.
+type CreditRule interface {
+	Name() string
+	Evaluate(app CreditApplication) RuleResult
+}
+
+// RuleResult records how one rule judged an application.
+type RuleResult struct {
+	Rule       string          `json:"rule" bson:"rule"`
+	Outcome    DecisionOutcome `json:"outcome" bson:"outcome"` // approve when the rule passed
+	Reason     string          `json:"reason,omitempty" bson:"reason,omitempty"`
+	ReasonCode string          `json:"reason_code,omitempty" bson:"reason_code,omitempty"` // rejection reason stated to the borrower when the rule failed
+}
+
+// CreditDecision is the audited verdict of the rules engine, stored on the loan.
+type CreditDecision struct {
+	Outcome   DecisionOutcome `json:"outcome" bson:"outcome"`
+	Reasons   []string        `json:"reasons,omitempty" bson:"reasons,omitempty"`
+	Results   []RuleResult    `json:"results" bson:"results"`
+	DecidedAt time.Time       `json:"decided_at" bson:"decided_at"`
+}
+
+// DecisionEngine evaluates loan applications against the configured credit rules.
+type DecisionEngine interface {
+	Evaluate(app CreditApplication) CreditDecision
+}
+
+// Credit decision errors
+var (
+	ErrUnknownRuleType = errors.New("unknown credit rule type")
+	ErrInvalidRule     = errors.New("credit rule needs a name, a refer or decline outcome, a valid threshold and a valid reason code")
+)
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// AgingBucket groups loans by how many days their oldest unpaid installment is past due.
+type AgingBucket string
+
+const (
+	AgingCurrent AgingBucket = "current"
+	Aging1To30   AgingBucket = "1-30"
+	Aging31To60  AgingBucket = "31-60"
+	Aging61To90  AgingBucket = "61-90"
+	AgingOver90  AgingBucket = "90+"
+)
+
+// AgingBuckets lists the aging buckets from the least to the most overdue.
+var AgingBuckets = []AgingBucket{AgingCurrent, Aging1To30, Aging31To60, Aging61To90, AgingOver90}
+
+// AgingBucketFor returns the aging bucket of a loan that is daysPastDue days past due.
+func AgingBucketFor(daysPastDue int) AgingBucket {
+	switch {
+	case daysPastDue <= 0:
+		return AgingCurrent
+	case daysPastDue <= 30:
+		return Aging1To30
+	case daysPastDue <= 60:
+		return Aging31To60
+	case daysPastDue <= 90:
+		return Aging61To90
+	default:
+		return AgingOver90
+	}
+}
+
+// DelinquencyPolicy sets the days past due at which loans change state.
+type DelinquencyPolicy struct {
+	DelinquentAfterDays int // an active loan this many days past due becomes delinquent
+	DefaultAfterDays    int // a loan this many days past due is defaulted
+}
+
+// DefaultDelinquencyPolicy marks loans delinquent from the first day past due and
+// defaults them once they fall into the 90+ bucket.
+var DefaultDelinquencyPolicy = DelinquencyPolicy{DelinquentAfterDays: 1, DefaultAfterDays: 91}
+
+// Validate checks that loans become delinquent before they default.
+func (p DelinquencyPolicy) Validate() error {
+	if p.DelinquentAfterDays < 1 || p.DefaultAfterDays <= p.DelinquentAfterDays {
+		return ErrInvalidDelinquencyPolicy
+	}
+	return nil
+}
+
+// DelinquencyRun summarises one run of the delinquency check.
+type DelinquencyRun struct {
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	LoansChecked     int       `json:"loans_checked"`
+	MarkedDelinquent int       `json:"marked_delinquent"`
+	MarkedDefaulted  int       `json:"marked_defaulted"`
+	Cured            int       `json:"cured"` // delinquent loans brought back to active
+	Errors           []string  `json:"errors,omitempty"`
+}
+
+// DelinquencyUsecase defines the delinquency check run against the loans on book.
+type DelinquencyUsecase interface {
+	RunDelinquencyCheck(now time.Time) (DelinquencyRun, error)
+}
+
+// AgingBucketSummary totals the loans in one aging bucket.
+type AgingBucketSummary struct {
+	Bucket               AgingBucket `json:"bucket"`
+	LoanCount            int         `json:"loan_count"`
+	OutstandingPrincipal Money       `json:"outstanding_principal"` // converted into the reporting currency
+	Unconverted          []Money     `json:"unconverted,omitempty"` // per currency with no exchange rate
+}
+
+// AgingReport spreads the outstanding principal of the loans on book over the aging buckets.
+// Principal in currencies with no exchange rate is kept per currency and left out of the totals.
+type AgingReport struct {
+	ReportingCurrency     string               `json:"reporting_currency"`
+	GeneratedAt           time.Time            `json:"generated_at"`
+	Buckets               []AgingBucketSummary `json:"buckets"`
+	TotalOutstanding      Money                `json:"total_outstanding"`
+	UnconvertedCurrencies []string             `json:"unconverted_currencies,omitempty"`
+}
+
+// Delinquency errors
+var (
+	ErrInvalidDelinquencyPolicy = errors.New("loans must become delinquent after at least one day and before they default")
+)
@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+func TestAgingBucketFor(t *testing.T) {
+	tests := []struct {
+		dpd  int
+		want AgingBucket
+	}{
+		{-1, AgingCurrent},
+		{0, AgingCurrent},
+		{1, Aging1To30},
+		{30, Aging1To30},
+		{31, Aging31To60},
+		{60, Aging31To60},
+		{61, Aging61To90},
+		{90, Aging61To90},
+		{91, AgingOver90},
+	}
+
+	for _, tt := range tests {
+		if got := AgingBucketFor(tt.dpd); got != tt.want {
+			t.Errorf("AgingBucketFor(%d) = %s, want %s", tt.dpd, got, tt.want)
+		}
+	}
+}
+
+func TestDelinquencyPolicyValidate(t *testing.T) {
+	tests := []struct {
+		policy DelinquencyPolicy
+		valid  bool
+	}{
+		{DefaultDelinquencyPolicy, true},
+		{DelinquencyPolicy{DelinquentAfterDays: 30, DefaultAfterDays: 31}, true},
+		{DelinquencyPolicy{DelinquentAfterDays: 0, DefaultAfterDays: 90}, false},
+		{DelinquencyPolicy{DelinquentAfterDays: 30, DefaultAfterDays: 30}, false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.policy.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%+v.Validate() = %v, want valid %v", tt.policy, err, tt.valid)
+		}
+	}
+}
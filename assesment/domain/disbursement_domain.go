@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PayoutMethod is the channel a loan is paid out through.
+type PayoutMethod string
+
+const (
+	PayoutBankTransfer PayoutMethod = "bank_transfer"
+	PayoutMobileMoney  PayoutMethod = "mobile_money"
+	PayoutManual       PayoutMethod = "manual" // paid outside the system and confirmed by an admin
+)
+
+// PayoutDestination is where a payout is sent. Bank transfers need an account number,
+// mobile money a phone number.
+type PayoutDestination struct {
+	AccountName   string `bson:"account_name,omitempty" json:"account_name,omitempty"`
+	AccountNumber string `bson:"account_number,omitempty" json:"account_number,omitempty"`
+	BankCode      string `bson:"bank_code,omitempty" json:"bank_code,omitempty"`
+	PhoneNumber   string `bson:"phone_number,omitempty" json:"phone_number,omitempty"`
+}
+
+// PayoutStatus is the state of a payout at the provider.
+type PayoutStatus string
+
+const (
+	PayoutPending   PayoutStatus = "pending" // accepted, waiting for the funds to arrive
+	PayoutConfirmed PayoutStatus = "confirmed"
+	PayoutFailed    PayoutStatus = "failed"
+)
+
+// PayoutRequest asks a provider to pay out a disbursement.
+type PayoutRequest struct {
+	Reference   string // the same for every attempt to pay out a loan, so providers can deduplicate retries
+	LoanID      uint
+	Amount      Money
+	Destination PayoutDestination
+}
+
+// PayoutResult is what a provider reports about a payout.
+type PayoutResult struct {
+	ProviderReference string
+	Status            PayoutStatus
+	FailureReason     string
+}
+
+// PayoutProvider sends loan payouts through one payout method.
+type PayoutProvider interface {
+	Method() PayoutMethod
+	Send(request PayoutRequest) (PayoutResult, error)
+	Status(providerReference string) (PayoutResult, error)
+}
+
+// DisbursementStatus is the state of a disbursement.
+type DisbursementStatus string
+
+const (
+	DisbursementProcessing DisbursementStatus = "processing" // sent, waiting for confirmation
+	DisbursementConfirmed  DisbursementStatus = "confirmed"
+	DisbursementFailed     DisbursementStatus = "failed"
+)
+
+// MaxDisbursementAttempts is how many times a disbursement is sent before it must be
+// replaced by a new one.
+const MaxDisbursementAttempts = 3
+
+// DisbursementAttempt records one attempt to send a disbursement.
+type DisbursementAttempt struct {
+	Number            int          `bson:"number" json:"number"`
+	At                time.Time    `bson:"at" json:"at"`
+	Status            PayoutStatus `bson:"status" json:"status"`
+	ProviderReference string       `bson:"provider_reference,omitempty" json:"provider_reference,omitempty"`
+	Error             string       `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// Disbursement is the payout of an approved loan to its borrower.
+type Disbursement struct {
+	ID                primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	LoanID            uint                  `bson:"loan_id" json:"loan_id"`
+	Amount            Money                 `bson:"amount" json:"amount"`
+	Method            PayoutMethod          `bson:"method" json:"method"`
+	Destination       PayoutDestination     `bson:"destination" json:"destination"`
+	Status            DisbursementStatus    `bson:"status" json:"status"`
+	Reference         string                `bson:"reference" json:"reference"` // sent to the provider with every attempt, shared by the replacements of a failed disbursement
+	ProviderReference string                `bson:"provider_reference,omitempty" json:"provider_reference,omitempty"`
+	Attempts          []DisbursementAttempt `bson:"attempts" json:"attempts"`
+	RequestedBy       primitive.ObjectID    `bson:"requested_by" json:"requested_by"`
+	ConfirmedAt       *time.Time            `bson:"confirmed_at,omitempty" json:"confirmed_at,omitempty"`
+	CreatedAt         time.Time             `bson:"created_at" json:"created_at"`
+	UpdatedAt         time.Time             `bson:"updated_at" json:"updated_at"`
+}
+
+// PayoutReference returns the reference the disbursement is sent under. Disbursements
+// stored before references were kept are sent under their ID.
+func (d Disbursement) PayoutReference() string {
+	if d.Reference != "" {
+		return d.Reference
+	}
+	return d.ID.Hex()
+}
+
+// DisbursementRepository defines the methods for storing and retrieving disbursements.
+type DisbursementRepository interface {
+	CreateDisbursement(disbursement Disbursement) error
+	GetDisbursementByID(id primitive.ObjectID) (Disbursement, error)
+	GetDisbursementsByLoan(loanID uint) ([]Disbursement, error)
+	UpdateDisbursement(disbursement Disbursement) error
+}
+
+// DisbursementUsecase defines the business logic for paying out approved loans.
+type DisbursementUsecase interface {
+	DisburseLoan(loanID uint, method PayoutMethod, destination PayoutDestination, requestedBy primitive.ObjectID) (Disbursement, error)
+	RetryDisbursement(loanID uint, id primitive.ObjectID) (Disbursement, error)
+	ConfirmDisbursement(loanID uint, id primitive.ObjectID, reference string) (Disbursement, error)
+	GetLoanDisbursements(loanID uint) ([]Disbursement, error)
+}
+
+// Disbursement errors
+var (
+	ErrUnsupportedPayoutMethod  = errors.New("unsupported payout method")
+	ErrInvalidDestination       = errors.New("bank transfers need an account number and mobile money a phone number")
+	ErrLoanNotApproved          = errors.New("only approved loans can be disbursed")
+	ErrDisbursementInProgress   = errors.New("the loan already has a disbursement in progress or confirmed")
+	ErrDisbursementNotFound     = errors.New("disbursement not found")
+	ErrDisbursementNotRetryable = errors.New("only failed disbursements can be retried")
+	ErrMaxAttemptsReached       = errors.New("disbursement has reached its maximum number of attempts")
+	ErrDisbursementNotPending   = errors.New("only disbursements waiting for confirmation can be confirmed")
+)
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxDocumentSize is the largest document a borrower can upload, in bytes.
+const MaxDocumentSize = 10 << 20
+
+// AllowedDocumentContentTypes are the file formats accepted for loan documents.
+var AllowedDocumentContentTypes = []string{"application/pdf", "image/jpeg", "image/png"}
+
+// DocumentType is what a document attached to a loan application proves.
+type DocumentType string
+
+const (
+	DocumentProofOfIncome  DocumentType = "proof_of_income"
+	DocumentIdentity       DocumentType = "identity"
+	DocumentBankStatement  DocumentType = "bank_statement"
+	DocumentProofOfAddress DocumentType = "proof_of_address"
+	DocumentOther          DocumentType = "other"
+)
+
+// IsValid reports whether t is a supported document type.
+func (t DocumentType) IsValid() bool {
+	switch t {
+	case DocumentProofOfIncome, DocumentIdentity, DocumentBankStatement, DocumentProofOfAddress, DocumentOther:
+		return true
+	}
+	return false
+}
+
+// DocumentStatus is the outcome of an admin's review of a document.
+type DocumentStatus string
+
+const (
+	DocumentPending  DocumentStatus = "pending"
+	DocumentAccepted DocumentStatus = "accepted"
+	DocumentRejected DocumentStatus = "rejected"
+)
+
+// LoanDocument is a file a borrower uploaded to support their loan application. The file
+// itself is kept in a BlobStore under StorageKey.
+type LoanDocument struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	LoanID      uint                `bson:"loan_id" json:"loan_id"`
+	Type        DocumentType        `bson:"type" json:"type"`
+	FileName    string              `bson:"file_name" json:"file_name"`
+	ContentType string              `bson:"content_type" json:"content_type"` // detected from the content, not the upload headers
+	Size        int64               `bson:"size" json:"size"`
+	Checksum    string              `bson:"checksum" json:"checksum"` // hex SHA-256 of the content
+	StorageKey  string              `bson:"storage_key" json:"-"`
+	Status      DocumentStatus      `bson:"status" json:"status"`
+	ReviewNote  string              `bson:"review_note,omitempty" json:"review_note,omitempty"`
+	ReviewedBy  *primitive.ObjectID `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time          `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	UploadedBy  primitive.ObjectID  `bson:"uploaded_by" json:"uploaded_by"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// DocumentUpload is a file received for a loan, before it is checked and stored.
+type DocumentUpload struct {
+	Type     DocumentType
+	FileName string
+	Size     int64 // as announced by the client; the content is measured again
+	Content  io.Reader
+}
+
+// DocumentReview is an admin's decision on a document. A rejection needs a note telling the
+// borrower what to upload instead.
+type DocumentReview struct {
+	Status DocumentStatus `json:"status" binding:"required"`
+	Note   string         `json:"note"`
+}
+
+// BlobStore keeps the content of uploaded files under a key.
+type BlobStore interface {
+	Put(key string, content io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LoanDocumentRepository defines the methods for storing and retrieving loan documents.
+type LoanDocumentRepository interface {
+	CreateDocument(document LoanDocument) error
+	GetDocumentByID(id primitive.ObjectID) (LoanDocument, error)
+	GetDocumentsByLoan(loanID uint) ([]LoanDocument, error)
+	UpdateDocument(document LoanDocument) error
+}
+
+// LoanDocumentUsecase defines the business logic for uploading and reviewing loan documents.
+type LoanDocumentUsecase interface {
+	UploadDocument(loanID uint, upload DocumentUpload, userID primitive.ObjectID) (LoanDocument, error)
+	GetMyLoanDocuments(loanID uint, userID primitive.ObjectID) ([]LoanDocument, error)
+	GetLoanDocuments(loanID uint) ([]LoanDocument, error)
+	DownloadDocument(loanID uint, id primitive.ObjectID) (LoanDocument, []byte, error)
+	ReviewDocument(loanID uint, id primitive.ObjectID, review DocumentReview, adminID primitive.ObjectID) (LoanDocument, error)
+}
+
+// Loan document errors
+var (
+	ErrInvalidDocumentType    = errors.New("invalid document type")
+	ErrEmptyDocument          = errors.New("the document is empty")
+	ErrDocumentTooLarge       = errors.New("the document exceeds the 10 MB limit")
+	ErrUnsupportedContentType = errors.New("documents must be PDF, JPEG or PNG files")
+	ErrDocumentsLocked        = errors.New("documents can only be uploaded while the application is under consideration")
+	ErrDocumentNotFound       = errors.New("document not found")
+	ErrInvalidDocumentReview  = errors.New("a review accepts or rejects the document, and a rejection needs a note")
+	ErrBlobNotFound           = errors.New("document content not found")
+	ErrChecksumMismatch       = errors.New("the stored document does not match its checksum")
+)
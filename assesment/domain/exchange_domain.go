@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+// ExchangeRateProvider supplies the rates used to convert amounts between currencies.
+type ExchangeRateProvider interface {
+	// Rate returns how many units of to one unit of from buys.
+	Rate(from, to string) (*big.Rat, error)
+}
+
+// ConvertMoney converts m into the to currency using rates, rounding half to even
+// to the minor unit of the target currency.
+func ConvertMoney(m Money, to string, rates ExchangeRateProvider) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+
+	fromExponent, ok := CurrencyExponent(m.Currency)
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+	toExponent, ok := CurrencyExponent(to)
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+
+	rate, err := rates.Rate(m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// Rescale from the source currency's minor units to the target's.
+	scale := new(big.Rat).SetFrac(
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toExponent)), nil),
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromExponent)), nil),
+	)
+	converted := NewMoney(m.Amount, to).Mul(new(big.Rat).Mul(rate, scale))
+	return converted, nil
+}
+
+// CurrencyExposure summarises the loans held in one currency.
+type CurrencyExposure struct {
+	Currency             string `json:"currency"`
+	LoanCount            int    `json:"loan_count"`
+	Principal            Money  `json:"principal"`             // principal lent in this currency
+	OutstandingPrincipal Money  `json:"outstanding_principal"` // principal not yet repaid
+	Rate                 string `json:"rate,omitempty"`        // units of the reporting currency per unit of this currency
+	ConvertedPrincipal   *Money `json:"converted_principal,omitempty"`
+	ConvertedOutstanding *Money `json:"converted_outstanding,omitempty"`
+}
+
+// PortfolioReport summarises the loan book in a single reporting currency. Currencies with
+// no exchange rate are listed unconverted and left out of the totals.
+type PortfolioReport struct {
+	ReportingCurrency     string             `json:"reporting_currency"`
+	GeneratedAt           time.Time          `json:"generated_at"`
+	Currencies            []CurrencyExposure `json:"currencies"`
+	TotalPrincipal        Money              `json:"total_principal"`
+	TotalOutstanding      Money              `json:"total_outstanding"`
+	UnconvertedCurrencies []string           `json:"unconverted_currencies,omitempty"`
+}
+
+// ReportUsecase defines the portfolio reports available to admins.
+type ReportUsecase interface {
+	GetPortfolioReport(reportingCurrency string) (PortfolioReport, error)
+	GetAgingReport(reportingCurrency string) (AgingReport, error)
+	GetRecoveryReport(reportingCurrency string) (RecoveryReport, error)
+}
+
+// Exchange rate errors
+var (
+	ErrRateUnavailable = errors.New("no exchange rate available for the currency pair")
+)
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// fixedRates quotes currencies in units per US dollar.
+type fixedRates map[string]*big.Rat
+
+func (r fixedRates) Rate(from, to string) (*big.Rat, error) {
+	fromRate, ok := r[from]
+	if !ok {
+		return nil, ErrRateUnavailable
+	}
+	toRate, ok := r[to]
+	if !ok {
+		return nil, ErrRateUnavailable
+	}
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
+
+func TestConvertMoney(t *testing.T) {
+	rates := fixedRates{
+		"USD": big.NewRat(1, 1),
+		"EUR": big.NewRat(92, 100),
+		"JPY": big.NewRat(150, 1),
+		"KWD": big.NewRat(307, 1000),
+	}
+
+	tests := []struct {
+		name  string
+		m     Money
+		to    string
+		rates ExchangeRateProvider
+		want  Money
+		err   error
+	}{
+		{"same currency needs no rate", usd(12345), "USD", nil, usd(12345), nil},
+		{"dollars to euros", usd(10000), "EUR", rates, NewMoney(9200, "EUR"), nil},
+		{"into a currency without minor units", usd(1234), "JPY", rates, NewMoney(1851, "JPY"), nil},
+		{"out of a currency without minor units", NewMoney(1000, "JPY"), "USD", rates, usd(667), nil},
+		{"into a currency with three decimals", usd(1000), "KWD", rates, NewMoney(3070, "KWD"), nil},
+		{"cross rate through the base", NewMoney(9200, "EUR"), "JPY", rates, NewMoney(15000, "JPY"), nil},
+		{"tie rounds half to even", NewMoney(1, "JPY"), "USD", fixedRates{"USD": big.NewRat(1, 1), "JPY": big.NewRat(200, 1)}, usd(0), nil},
+		{"no rate for the currency", usd(100), "ETB", rates, Money{}, ErrRateUnavailable},
+		{"unknown currency", usd(100), "XXX", rates, Money{}, ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConvertMoney(tt.m, tt.to, tt.rates)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Errorf("ConvertMoney(%s, %s) = %s, %v, want %s, %v", tt.m, tt.to, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
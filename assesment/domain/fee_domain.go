@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LateFeePolicy sets the one-off fee charged on an installment once it is overdue: a flat
+// amount, a percentage of the overdue principal and interest, or both, optionally capped.
+// Flat and Cap only apply to loans in their currency.
+type LateFeePolicy struct {
+	Flat       *Money  `bson:"flat,omitempty" json:"flat,omitempty"`
+	Percentage float64 `bson:"percentage" json:"percentage"`
+	Cap        *Money  `bson:"cap,omitempty" json:"cap,omitempty"`
+}
+
+// PenaltyTerms are the charges a product levies on overdue installments. They are copied
+// onto each loan when it is applied for.
+type PenaltyTerms struct {
+	GraceDays   int           `bson:"grace_days" json:"grace_days"` // days past due before anything is charged
+	LateFee     LateFeePolicy `bson:"late_fee" json:"late_fee"`
+	PenaltyRate float64       `bson:"penalty_rate" json:"penalty_rate"` // annual rate, in percent, on overdue principal and interest
+}
+
+// Validate checks the penalty terms of a product.
+func (t PenaltyTerms) Validate() error {
+	if t.GraceDays < 0 || t.LateFee.Percentage < 0 || t.PenaltyRate < 0 {
+		return ErrInvalidPenaltyTerms
+	}
+	for _, amount := range []*Money{t.LateFee.Flat, t.LateFee.Cap} {
+		if amount == nil {
+			continue
+		}
+		if err := amount.Validate(); err != nil {
+			return err
+		}
+		if amount.IsNegative() {
+			return ErrInvalidPenaltyTerms
+		}
+	}
+	return nil
+}
+
+// ChargeType identifies a charge levied on an overdue installment.
+type ChargeType string
+
+const (
+	ChargeLateFee         ChargeType = "late_fee"
+	ChargePenaltyInterest ChargeType = "penalty_interest"
+)
+
+// LoanFee records a late fee or a period of penalty interest charged on an installment.
+type LoanFee struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LoanID            uint               `bson:"loan_id" json:"loan_id"`
+	InstallmentNumber int                `bson:"installment_number" json:"installment_number"`
+	Type              ChargeType         `bson:"type" json:"type"`
+	Amount            Money              `bson:"amount" json:"amount"`
+	PeriodStart       *time.Time         `bson:"period_start,omitempty" json:"period_start,omitempty"` // days penalty interest was charged for
+	PeriodEnd         *time.Time         `bson:"period_end,omitempty" json:"period_end,omitempty"`
+	ChargedAt         time.Time          `bson:"charged_at" json:"charged_at"`
+	WaivedAmount      Money              `bson:"waived_amount" json:"waived_amount"` // the part of Amount that was still unpaid when waived
+	WaiveReason       string             `bson:"waive_reason,omitempty" json:"waive_reason,omitempty"`
+	WaivedBy          primitive.ObjectID `bson:"waived_by,omitempty" json:"waived_by,omitempty"`
+	WaivedAt          *time.Time         `bson:"waived_at,omitempty" json:"waived_at,omitempty"`
+}
+
+// IsWaived reports whether an admin has waived the fee.
+func (f LoanFee) IsWaived() bool {
+	return f.WaivedAt != nil
+}
+
+// BalanceBreakdown splits what is still owed on a loan by component.
+type BalanceBreakdown struct {
+	LoanID    uint  `json:"loan_id"`
+	Principal Money `json:"principal"`
+	Interest  Money `json:"interest"`
+	Fees      Money `json:"fees"`
+	Penalties Money `json:"penalties"` // unpaid late fees and penalty interest
+	Total     Money `json:"total"`
+}
+
+// LateChargeRun summarises one run of the late charge job.
+type LateChargeRun struct {
+	StartedAt        time.Time `json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	LoansChecked     int       `json:"loans_checked"`
+	LateFeesCharged  int       `json:"late_fees_charged"`
+	PenaltiesCharged int       `json:"penalties_charged"`
+	Errors           []string  `json:"errors,omitempty"`
+}
+
+// FeeRepository defines the methods for storing and retrieving loan fees.
+type FeeRepository interface {
+	CreateFee(fee LoanFee) error
+	GetFeeByID(id primitive.ObjectID) (LoanFee, error)
+	GetFeesByLoan(loanID uint) ([]LoanFee, error)
+	UpdateFee(fee LoanFee) error
+	DeleteFee(id primitive.ObjectID) error
+}
+
+// FeeUsecase defines the business logic for late fees and penalty interest.
+type FeeUsecase interface {
+	AccrueLateCharges(now time.Time) (LateChargeRun, error)
+	GetLoanFees(loanID uint) ([]LoanFee, error)
+	GetLoanBalance(loanID uint) (BalanceBreakdown, error)
+	WaiveFee(loanID uint, feeID primitive.ObjectID, reason string, waivedBy primitive.ObjectID) (LoanFee, error)
+}
+
+// Fee errors
+var (
+	ErrInvalidPenaltyTerms = errors.New("penalty terms cannot be negative")
+	ErrFeeNotFound         = errors.New("fee not found")
+	ErrFeeAlreadyWaived    = errors.New("fee has already been waived")
+	ErrFeeAlreadyPaid      = errors.New("fee has already been paid")
+	ErrWaiveReasonRequired = errors.New("a reason is required to waive a fee")
+)
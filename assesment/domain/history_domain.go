@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoanEventType is the kind of change a loan history event records.
+type LoanEventType string
+
+// Loan history event types
+const (
+	LoanEventApplied         LoanEventType = "applied"
+	LoanEventStatusChanged   LoanEventType = "status_changed"
+	LoanEventTermsChanged    LoanEventType = "terms_changed" // amount, term or rate changed by an accepted counter-offer or a restructuring
+	LoanEventNoteAdded       LoanEventType = "note_added"    // internal, not shown to the borrower
+	LoanEventPaymentRecorded LoanEventType = "payment_recorded"
+	LoanEventDeleted         LoanEventType = "deleted"
+
+	LoanEventApprovalRecorded     LoanEventType = "approval_recorded" // internal, a sign-off that waits for another
+	LoanEventReviewerAssigned     LoanEventType = "reviewer_assigned" // internal
+	LoanEventInfoRequested        LoanEventType = "info_requested"
+	LoanEventInfoProvided         LoanEventType = "info_provided"
+	LoanEventPartyAdded           LoanEventType = "party_added"
+	LoanEventPartyRemoved         LoanEventType = "party_removed"
+	LoanEventConsentRecorded      LoanEventType = "consent_recorded"
+	LoanEventCollateralLinked     LoanEventType = "collateral_linked"
+	LoanEventLienReleased         LoanEventType = "lien_released"
+	LoanEventDocumentReviewed     LoanEventType = "document_reviewed"
+	LoanEventCounterOffered       LoanEventType = "counter_offered"
+	LoanEventCounterOfferDeclined LoanEventType = "counter_offer_declined"
+	LoanEventCounterOfferExpired  LoanEventType = "counter_offer_expired"
+	LoanEventFeeCharged           LoanEventType = "fee_charged" // late fees and penalty interest
+	LoanEventFeeWaived            LoanEventType = "fee_waived"
+	LoanEventDelinquencyUpdated   LoanEventType = "delinquency_updated" // days past due or aging bucket
+	LoanEventInterestAccrued      LoanEventType = "interest_accrued"    // by the daily accrual job
+)
+
+// Internal reports whether events of type t are for staff only and left out of the history
+// a borrower sees.
+func (t LoanEventType) Internal() bool {
+	switch t {
+	case LoanEventNoteAdded, LoanEventApprovalRecorded, LoanEventReviewerAssigned:
+		return true
+	}
+	return false
+}
+
+// LoanState holds the fields of a loan an event changed, as they were before or after it.
+// Fields the event did not touch are left out.
+type LoanState struct {
+	Status             LoanStatus         `bson:"status,omitempty" json:"status,omitempty"`
+	Amount             *Money             `bson:"amount,omitempty" json:"amount,omitempty"`
+	Term               int                `bson:"term,omitempty" json:"term,omitempty"`
+	InterestRate       float64            `bson:"interest_rate,omitempty" json:"interest_rate,omitempty"`
+	OutstandingBalance *Money             `bson:"outstanding_balance,omitempty" json:"outstanding_balance,omitempty"`
+	DaysPastDue        *int               `bson:"days_past_due,omitempty" json:"days_past_due,omitempty"`
+	AgingBucket        AgingBucket        `bson:"aging_bucket,omitempty" json:"aging_bucket,omitempty"`
+	CollateralValue    *Money             `bson:"collateral_value,omitempty" json:"collateral_value,omitempty"`
+	LoanToValue        float64            `bson:"loan_to_value,omitempty" json:"loan_to_value,omitempty"`
+	ReviewerID         primitive.ObjectID `bson:"reviewer_id,omitempty" json:"reviewer_id,omitempty"`
+	AccruedInterest    *Money             `bson:"accrued_interest,omitempty" json:"accrued_interest,omitempty"`
+	InterestAccruedTo  *time.Time         `bson:"interest_accrued_to,omitempty" json:"interest_accrued_to,omitempty"`
+}
+
+// LoanEvent is an entry in the history of a loan. Events are only ever appended, so the
+// history keeps every state a loan went through even after the loan itself is deleted.
+type LoanEvent struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LoanID     uint               `bson:"loan_id" json:"loan_id"`
+	Type       LoanEventType      `bson:"type" json:"type"`
+	ActorID    primitive.ObjectID `bson:"actor_id,omitempty" json:"actor_id,omitempty"` // unset for the credit rules and background jobs
+	Before     *LoanState         `bson:"before,omitempty" json:"before,omitempty"`
+	After      *LoanState         `bson:"after,omitempty" json:"after,omitempty"`
+	Detail     string             `bson:"detail,omitempty" json:"detail,omitempty"` // the note, or what the change was made for
+	RecordedAt time.Time          `bson:"recorded_at" json:"recorded_at"`
+}
+
+// LoanHistoryRepository defines the methods for appending to and reading loan histories.
+// There is deliberately no way to change or remove an event.
+type LoanHistoryRepository interface {
+	AppendEvent(event LoanEvent) error
+	GetEventsByLoan(loanID uint) ([]LoanEvent, error)
+}
+
+// LoanHistoryUsecase defines the business logic of reading loan histories.
+type LoanHistoryUsecase interface {
+	GetLoanHistory(loanID uint, userID primitive.ObjectID, isAdmin bool) ([]LoanEvent, error)
+}
@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccountType classifies a ledger account and decides which side increases its balance.
+type AccountType string
+
+// Ledger account types.
+const (
+	AccountTypeAsset     AccountType = "asset"
+	AccountTypeLiability AccountType = "liability"
+	AccountTypeEquity    AccountType = "equity"
+	AccountTypeIncome    AccountType = "income"
+	AccountTypeExpense   AccountType = "expense"
+)
+
+// DebitNormal reports whether debits increase the balance of accounts of type t.
+func (t AccountType) DebitNormal() bool {
+	return t == AccountTypeAsset || t == AccountTypeExpense
+}
+
+// LedgerAccount is an account in the chart of accounts.
+type LedgerAccount struct {
+	Code string      `bson:"code" json:"code"`
+	Name string      `bson:"name" json:"name"`
+	Type AccountType `bson:"type" json:"type"`
+}
+
+// Ledger account codes.
+const (
+	AccountCash               = "cash"
+	AccountLoansReceivable    = "loans_receivable"
+	AccountInterestReceivable = "interest_receivable"
+	AccountFeesReceivable     = "fees_receivable"
+	AccountInterestIncome     = "interest_income"
+	AccountFeeIncome          = "fee_income"
+	AccountLoanLossExpense    = "loan_loss_expense"
+	AccountRecoveryIncome     = "recovery_income"
+)
+
+// ChartOfAccounts lists every account loan activity posts to.
+var ChartOfAccounts = []LedgerAccount{
+	{Code: AccountCash, Name: "Cash at bank", Type: AccountTypeAsset},
+	{Code: AccountLoansReceivable, Name: "Loans receivable (principal)", Type: AccountTypeAsset},
+	{Code: AccountInterestReceivable, Name: "Interest receivable", Type: AccountTypeAsset},
+	{Code: AccountFeesReceivable, Name: "Fees receivable", Type: AccountTypeAsset},
+	{Code: AccountInterestIncome, Name: "Interest income", Type: AccountTypeIncome},
+	{Code: AccountFeeIncome, Name: "Fee income", Type: AccountTypeIncome},
+	{Code: AccountLoanLossExpense, Name: "Loan loss expense", Type: AccountTypeExpense},
+	{Code: AccountRecoveryIncome, Name: "Recoveries on written-off loans", Type: AccountTypeIncome},
+}
+
+// LookupAccount returns the account with the given code from the chart of accounts.
+func LookupAccount(code string) (LedgerAccount, bool) {
+	for _, account := range ChartOfAccounts {
+		if account.Code == code {
+			return account, true
+		}
+	}
+	return LedgerAccount{}, false
+}
+
+// EntryType is the business event a journal entry records.
+type EntryType string
+
+// Journal entry types.
+const (
+	EntryDisbursement    EntryType = "disbursement"
+	EntryRepayment       EntryType = "repayment"
+	EntryInterestAccrual EntryType = "interest_accrual"
+	EntryFee             EntryType = "fee"
+	EntryFeeWaiver       EntryType = "fee_waiver"
+	EntryCapitalization  EntryType = "capitalization"
+	EntryWriteOff        EntryType = "write_off"
+	EntryRecovery        EntryType = "recovery"
+)
+
+// Posting is one side of a journal entry against a single account.
+// Exactly one of Debit and Credit is set.
+type Posting struct {
+	Account string `bson:"account" json:"account"`
+	Debit   Money  `bson:"debit" json:"debit"`
+	Credit  Money  `bson:"credit" json:"credit"`
+}
+
+// JournalEntry is a balanced set of postings recording a single money movement.
+type JournalEntry struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Type        EntryType          `bson:"type" json:"type"`
+	LoanID      uint               `bson:"loan_id" json:"loan_id"`
+	Reference   string             `bson:"reference,omitempty" json:"reference,omitempty"` // e.g. the payment the entry records
+	Description string             `bson:"description" json:"description"`
+	Postings    []Posting          `bson:"postings" json:"postings"`
+	PostedAt    time.Time          `bson:"posted_at" json:"posted_at"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// Validate checks that the entry posts to known accounts in a single currency and
+// that its debits equal its credits.
+func (e JournalEntry) Validate() error {
+	if len(e.Postings) < 2 {
+		return ErrUnbalancedEntry
+	}
+
+	currency := e.Currency()
+	var debits, credits int64
+	for _, posting := range e.Postings {
+		if _, ok := LookupAccount(posting.Account); !ok {
+			return ErrUnknownAccount
+		}
+		if posting.Debit.IsNegative() || posting.Credit.IsNegative() || posting.Debit.IsPositive() == posting.Credit.IsPositive() {
+			return ErrInvalidPosting
+		}
+		for _, side := range []Money{posting.Debit, posting.Credit} {
+			if side.IsPositive() && side.Currency != currency {
+				return ErrCurrencyMismatch
+			}
+		}
+		debits += posting.Debit.Amount
+		credits += posting.Credit.Amount
+	}
+
+	if debits != credits {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// Currency returns the currency the entry is posted in.
+func (e JournalEntry) Currency() string {
+	for _, posting := range e.Postings {
+		if posting.Debit.IsPositive() {
+			return posting.Debit.Currency
+		}
+	}
+	return ""
+}
+
+// AccountTotal is the sum of all debits and credits posted to an account in one currency.
+type AccountTotal struct {
+	Account  string `bson:"account" json:"account"`
+	Currency string `bson:"currency" json:"currency"`
+	Debit    int64  `bson:"debit" json:"debit"`   // minor units
+	Credit   int64  `bson:"credit" json:"credit"` // minor units
+}
+
+// AccountBalance is an account together with its balance, in one currency, on its normal side.
+type AccountBalance struct {
+	LedgerAccount
+	Debit   Money `json:"debit"`
+	Credit  Money `json:"credit"`
+	Balance Money `json:"balance"`
+}
+
+// LedgerRepository defines the methods for storing and querying journal entries.
+type LedgerRepository interface {
+	PostEntry(entry JournalEntry) error // ErrEntryAlreadyPosted if the loan has an entry of the type with the same reference
+	GetEntriesByLoan(loanID uint) ([]JournalEntry, error)
+	GetEntries(from, to time.Time) ([]JournalEntry, error)
+	GetAccountTotals() ([]AccountTotal, error)
+}
+
+// LedgerUsecase defines the postings loan activity makes and the ledger reports.
+type LedgerUsecase interface {
+	PostDisbursement(loan Loan) error
+	PostRepayment(payment Payment) error
+	PostInterestAccrual(loanID uint, amount Money, reference string) error
+	PostFee(loanID uint, amount Money, reference string) error
+	PostFeeWaiver(loanID uint, amount Money, reference string) error
+	PostCapitalization(loanID uint, interestReceivable, interestIncome, fees Money, reference string) error
+	PostWriteOff(loanID uint, principal, interest, fees Money) error
+	PostRecovery(payment Payment) error
+	GetLoanEntries(loanID uint) ([]JournalEntry, error)
+	GetEntries(from, to time.Time) ([]JournalEntry, error)
+	GetTrialBalance() ([]AccountBalance, error)
+	GetOutstandingPrincipal() ([]Money, error)
+}
+
+// Ledger errors
+var (
+	ErrUnbalancedEntry = errors.New("journal entry debits and credits do not balance")
+	ErrUnknownAccount  = errors.New("journal entry posts to an unknown account")
+	ErrInvalidPosting  = errors.New("each posting must carry either a positive debit or a positive credit")
+
+	ErrEntryAlreadyPosted = errors.New("a journal entry with this reference is already posted for the loan")
+)
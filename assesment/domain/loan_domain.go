@@ -1,32 +1,141 @@
 package domain
 
-import "time"
+import (
+    "errors"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // Loan represents the loan entity in the domain layer.
 type Loan struct {
-    ID        uint      `json:"id"`
-    UserID    uint      `json:"user_id"`
-    Amount    float64   `json:"amount"`
-    Status    string    `json:"status"`   // possible values: "pending", "approved", "rejected"
-    CreatedAt time.Time `json:"created_at"`
-    UpdatedAt time.Time `json:"updated_at"`
+    ID                 uint               `json:"id" bson:"id"`
+    UserID             primitive.ObjectID `json:"user_id" bson:"user_id"`
+    ProductID          primitive.ObjectID `json:"product_id" bson:"product_id"`
+    Amount             Money              `json:"amount" bson:"amount"`
+    Term               int                `json:"term" bson:"term"`                               // number of installments
+    InterestRate       float64            `json:"interest_rate" bson:"interest_rate"`             // nominal annual rate, in percent
+    RepaymentFrequency RepaymentFrequency `json:"repayment_frequency" bson:"repayment_frequency"` // defaults to monthly
+    AmortizationMethod AmortizationMethod `json:"amortization_method" bson:"amortization_method"` // defaults to the product's method
+    DayCount           DayCountConvention `json:"day_count" bson:"day_count"`                     // copied from the product, used to accrue interest daily
+    OriginationFee     Money              `json:"origination_fee" bson:"origination_fee"`         // set from the product's fees, due with the first installment
+    Penalties          PenaltyTerms       `json:"penalties" bson:"penalties"`                     // copied from the product, charged on overdue installments
+    Prepayment         PrepaymentTerms    `json:"prepayment" bson:"prepayment"`                   // copied from the product, charged on principal repaid early
+    CollateralIDs      []primitive.ObjectID `json:"collateral_ids,omitempty" bson:"collateral_ids"` // collateral pledged to the loan
+    CollateralValue    Money              `json:"collateral_value" bson:"collateral_value"`       // estimated value of the collateral when pledged
+    LoanToValue        float64            `json:"loan_to_value" bson:"loan_to_value"`             // amount as a percentage of the collateral value, 0 when unsecured
+    Parties            []LoanParty        `json:"parties,omitempty" bson:"parties"`               // co-borrowers and guarantors, who must consent before underwriting
+    MonthlyIncome      Money              `json:"monthly_income" bson:"monthly_income"`           // declared by the applicant, used for the debt-to-income rule
+    Decision           *CreditDecision    `json:"decision,omitempty" bson:"decision,omitempty"`   // verdict of the credit rules on the application
+    Review             *LoanReview        `json:"review,omitempty" bson:"review,omitempty"`       // underwriting by a reviewer, for applications the rules referred
+    Schedule           []Installment      `json:"schedule,omitempty" bson:"schedule,omitempty"`   // generated on approval
+    OutstandingBalance Money              `json:"outstanding_balance" bson:"outstanding_balance"` // fees, interest and principal still owed on the schedule
+    AccruedInterest    Money              `json:"accrued_interest" bson:"accrued_interest"`       // interest accrued daily and not yet received
+    InterestAccruedTo  *time.Time         `json:"interest_accrued_to,omitempty" bson:"interest_accrued_to,omitempty"` // last day interest was accrued for
+    Status             LoanStatus         `json:"status" bson:"status"`                           // see LoanStatus for the lifecycle states
+    DaysPastDue        int                `json:"days_past_due" bson:"days_past_due"`             // age of the oldest unpaid installment past its due date
+    AgingBucket        AgingBucket        `json:"aging_bucket,omitempty" bson:"aging_bucket,omitempty"`
+    Closure            *LoanClosure       `json:"closure,omitempty" bson:"closure,omitempty"`     // set when the application is withdrawn or cancelled
+    Rejection          *LoanRejection     `json:"rejection,omitempty" bson:"rejection,omitempty"` // set when a reviewer rejects the application, shown to its borrower
+    WriteOff           *WriteOff          `json:"write_off,omitempty" bson:"write_off,omitempty"` // set when the loan is written off
+    Recovered          Money              `json:"recovered" bson:"recovered"`                     // received since the loan was written off
+    DisbursedAt        *time.Time         `json:"disbursed_at,omitempty" bson:"disbursed_at,omitempty"` // when the payout to the borrower was confirmed
+    Version            int                `json:"-" bson:"version"`                               // bumped on every update; a write based on an older version is refused
+    CreatedAt          time.Time          `json:"created_at" bson:"created_at"`
+    UpdatedAt          time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// LoanClosure records why and by whom a loan was withdrawn or cancelled before it was paid out.
+type LoanClosure struct {
+    Reason   string             `json:"reason" bson:"reason"`
+    ClosedBy primitive.ObjectID `json:"closed_by" bson:"closed_by"`
+    ClosedAt time.Time          `json:"closed_at" bson:"closed_at"`
+}
+
+// LoanApplication is what an applicant submits to apply for a loan. Everything else on the
+// loan is set by the lender, so it cannot be smuggled in with the application.
+type LoanApplication struct {
+    ID                 uint                 `json:"id"`
+    ProductID          primitive.ObjectID   `json:"product_id"`
+    Amount             Money                `json:"amount"`
+    Term               int                  `json:"term"`
+    InterestRate       float64              `json:"interest_rate"`
+    RepaymentFrequency RepaymentFrequency   `json:"repayment_frequency"`
+    AmortizationMethod AmortizationMethod   `json:"amortization_method"`
+    CollateralIDs      []primitive.ObjectID `json:"collateral_ids"`
+    Parties            []LoanParty          `json:"parties"`
+    MonthlyIncome      Money                `json:"monthly_income"`
+}
+
+// Loan returns the loan an application of borrower asks for.
+func (a LoanApplication) Loan(borrower primitive.ObjectID) Loan {
+    return Loan{
+        ID:                 a.ID,
+        UserID:             borrower,
+        ProductID:          a.ProductID,
+        Amount:             a.Amount,
+        Term:               a.Term,
+        InterestRate:       a.InterestRate,
+        RepaymentFrequency: a.RepaymentFrequency,
+        AmortizationMethod: a.AmortizationMethod,
+        CollateralIDs:      a.CollateralIDs,
+        Parties:            a.Parties,
+        MonthlyIncome:      a.MonthlyIncome,
+    }
 }
 
 // LoanRepository provides an interface for loan-related operations in the repository layer.
 type LoanRepository interface {
     ApplyForLoan(loan Loan) error               // Method to apply for a loan
     GetLoanByID(id uint) (Loan, error)          // Method to retrieve a loan by its ID
-    GetAllLoans(status, order string) ([]Loan, error) // Method to retrieve all loans with optional filtering by status and sorting
-    UpdateLoanStatus(id uint, status string) error // Method to update the status of a loan (approve/reject)
+    GetAllLoans(status, order, currency string) ([]Loan, error) // Method to retrieve all loans with optional filtering by status and currency, and sorting
+    GetLoansByUser(userID primitive.ObjectID) ([]Loan, error) // Method to retrieve every loan of a borrower
+    GetLoansByStatus(statuses ...LoanStatus) ([]Loan, error) // Method to retrieve the loans in any of the given states
+    GetLoansByParty(userID primitive.ObjectID, email string) ([]Loan, error) // Method to retrieve the loans a user is a co-borrower or guarantor on
+    GetLoanByConsentToken(token string) (Loan, error) // Method to retrieve the loan a consent link was sent for
+    GetLastAssignedLoan() (Loan, error)         // Method to retrieve the loan most recently assigned to a reviewer
+    UpdateLoanStatus(loan *Loan, status LoanStatus) error // Method to update the status of a loan unless it changed since it was read
+    UpdateLoan(loan *Loan) error                // Method to store every field of an existing loan unless it changed since it was read
     DeleteLoan(id uint) error                   // Method to delete a loan by its ID
 }
 
 // LoanUsecase provides an interface for loan-related business logic in the use case layer.
 type LoanUsecase interface {
-    ApplyForLoan(loan Loan) error               // Method to apply for a loan
+    ApplyForLoan(loan Loan) (Loan, error)       // Method to apply for a loan and run the credit rules on it
     GetLoanByID(id uint) (Loan, error)          // Method to retrieve a loan by its ID
-    GetAllLoans(status, order string) ([]Loan, error) // Method to retrieve all loans with optional filtering by status and sorting
-    ApproveLoan(id uint) error                  // Method to approve a loan
-    RejectLoan(id uint) error                   // Method to reject a loan
-    DeleteLoan(id uint) error                   // Method to delete a loan by its ID
+    GetAllLoans(status, order, currency string) ([]Loan, error) // Method to retrieve all loans with optional filtering by status and currency, and sorting
+    GetUserLoans(userID primitive.ObjectID) ([]Loan, error) // Method to retrieve every loan of a borrower
+    TransitionLoan(loan *Loan, to LoanStatus, actor primitive.ObjectID) error // Method to move a loaded loan to another lifecycle state on behalf of actor, zero for background jobs
+    UpdateLoanStatus(id uint, to LoanStatus, adminID primitive.ObjectID) error // Method for an admin to set a status that has no operation of its own
+    ApproveLoan(id uint, reviewerID primitive.ObjectID, reason string) (Loan, error) // Method for a reviewer to approve a loan, or sign it off when it needs two approvals
+    RejectLoan(id uint, reviewerID primitive.ObjectID, rejection RejectionRequest) (Loan, error) // Method for a reviewer to reject a loan with reasons from the catalog and notify the borrower
+    GetLoanSchedule(id uint) ([]Installment, error) // Method to retrieve the repayment schedule of a loan
+    GetPayoffQuote(id uint, date time.Time) (PayoffQuote, error) // Method to compute what settles a loan in full on a given day
+    RecordPayment(loanID uint, payment Payment) (Payment, error) // Method to record a repayment against a loan
+    WriteOffLoan(id uint, reason string, writtenOffBy primitive.ObjectID) (Loan, error) // Method to take a defaulted loan off the books
+    RecordRecovery(loanID uint, payment Payment) (Payment, error) // Method to record money recovered on a written-off loan
+    WithdrawLoan(id uint, userID primitive.ObjectID, reason string) (Loan, error) // Method for a borrower to withdraw an application still under consideration
+    CancelLoan(id uint, adminID primitive.ObjectID, reason string) (Loan, error) // Method to cancel an approved loan before it is paid out
+    GetLoanPayments(loanID uint) ([]Payment, error) // Method to retrieve the payment history of a loan
+    GetUserPayments(userID primitive.ObjectID) ([]Payment, error) // Method to retrieve the payment history of a user
+    DeleteLoan(id uint, deletedBy primitive.ObjectID) error // Method to delete a loan by its ID, keeping its history
 }
+
+// MaxLoanTerm is the most installments a loan can be repaid in, whatever its product allows.
+const MaxLoanTerm = 360
+
+// Loan errors
+var (
+    ErrInvalidLoanAmount     = errors.New("invalid loan amount")
+    ErrInvalidLoanTerm       = errors.New("loan term must be between 1 and 360 installments")
+    ErrInvalidInterestRate   = errors.New("interest rate cannot be negative")
+    ErrInvalidFrequency      = errors.New("unsupported repayment frequency")
+    ErrInvalidAmortization   = errors.New("unsupported amortization method")
+    ErrScheduleNotGenerated  = errors.New("repayment schedule is generated when the loan is approved")
+    ErrClosureReasonRequired = errors.New("a reason is required to withdraw or cancel a loan")
+    ErrNotLoanOwner          = errors.New("the loan belongs to another user")
+    ErrLoanModified          = errors.New("the loan was changed in the meantime, try again")
+    ErrLoanIDTaken           = errors.New("a loan with this ID exists or was deleted, choose another ID")
+    ErrLoanIDRequired        = errors.New("a loan needs an ID greater than zero")
+    ErrStatusSetByOperation  = errors.New("only a pending application can be moved to under_review directly; other statuses are set by approving, rejecting, disbursing, withdrawing, cancelling or writing off the loan")
+)
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PartyRole is the part a person other than the borrower plays on a loan.
+type PartyRole string
+
+const (
+	PartyCoBorrower PartyRole = "co_borrower" // jointly liable for the loan
+	PartyGuarantor  PartyRole = "guarantor"   // liable if the borrower does not pay
+)
+
+// IsValid reports whether r is a supported party role.
+func (r PartyRole) IsValid() bool {
+	return r == PartyCoBorrower || r == PartyGuarantor
+}
+
+// ConsentStatus is the answer of a party to the request to join a loan.
+type ConsentStatus string
+
+const (
+	ConsentPending  ConsentStatus = "pending"
+	ConsentGiven    ConsentStatus = "given"
+	ConsentDeclined ConsentStatus = "declined"
+)
+
+// LoanParty is a co-borrower or guarantor on a loan: an existing user or an external person
+// identified by name and email. Parties confirm through the link emailed to them, and a loan
+// is not underwritten until all of them have consented.
+type LoanParty struct {
+	ID                 primitive.ObjectID `bson:"id" json:"id"`
+	Role               PartyRole          `bson:"role" json:"role"`
+	UserID             primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"` // unset for external persons
+	Name               string             `bson:"name" json:"name"`
+	Email              string             `bson:"email" json:"email"`
+	ConsentStatus      ConsentStatus      `bson:"consent_status" json:"consent_status"`
+	ConsentToken       string             `bson:"consent_token" json:"-"` // sent in the confirmation link only
+	ConsentRequestedAt time.Time          `bson:"consent_requested_at" json:"consent_requested_at"`
+	RespondedAt        *time.Time         `bson:"responded_at,omitempty" json:"responded_at,omitempty"`
+}
+
+// AllPartiesConsented reports whether every co-borrower and guarantor of a loan has consented.
+func (l Loan) AllPartiesConsented() bool {
+	for _, party := range l.Parties {
+		if party.ConsentStatus != ConsentGiven {
+			return false
+		}
+	}
+	return true
+}
+
+// ConsentRequest is what a party sees when opening their confirmation link.
+type ConsentRequest struct {
+	LoanID        uint          `json:"loan_id"`
+	Role          PartyRole     `json:"role"`
+	Name          string        `json:"name"`
+	Amount        Money         `json:"amount"`
+	Term          int           `json:"term"`
+	InterestRate  float64       `json:"interest_rate"`
+	ConsentStatus ConsentStatus `json:"consent_status"`
+}
+
+// GuaranteedLoan is what a co-borrower or guarantor sees of a loan they are a party to. It
+// leaves out the borrower's income, the credit decision and the other parties.
+type GuaranteedLoan struct {
+	LoanID             uint          `json:"loan_id"`
+	Role               PartyRole     `json:"role"`
+	ConsentStatus      ConsentStatus `json:"consent_status"`
+	Amount             Money         `json:"amount"`
+	Term               int           `json:"term"`
+	InterestRate       float64       `json:"interest_rate"`
+	Status             LoanStatus    `json:"status"`
+	OutstandingBalance Money         `json:"outstanding_balance"`
+}
+
+// ConsentNotifier sends a party the link to give or decline consent to a loan.
+type ConsentNotifier interface {
+	RequestConsent(party LoanParty, loan Loan) error
+}
+
+// LoanPartyUsecase defines the business logic for co-borrowers and guarantors.
+type LoanPartyUsecase interface {
+	AddLoanParty(loanID uint, party LoanParty, userID primitive.ObjectID) (LoanParty, error)
+	RemoveLoanParty(loanID uint, partyID primitive.ObjectID, userID primitive.ObjectID) error
+	GetLoanParties(loanID uint) ([]LoanParty, error)
+	GetConsentRequest(token string) (ConsentRequest, error)
+	RespondToConsent(token string, consent bool) (ConsentRequest, error)
+	GetUserGuarantees(userID primitive.ObjectID) ([]GuaranteedLoan, error)
+}
+
+// Loan party errors
+var (
+	ErrInvalidPartyRole       = errors.New("party role must be co_borrower or guarantor")
+	ErrPartyIdentityRequired  = errors.New("a party needs a user ID or a name and a valid email")
+	ErrPartyIsBorrower        = errors.New("the borrower cannot be a party to their own loan")
+	ErrDuplicateParty         = errors.New("the person is already a party to the loan")
+	ErrPartiesLocked          = errors.New("parties can only be changed while the application is pending or under review")
+	ErrPartyNotFound          = errors.New("loan party not found")
+	ErrInvalidConsentToken    = errors.New("invalid or unknown consent link")
+	ErrConsentAlreadyAnswered = errors.New("the party has already answered")
+	ErrPartyConsentRequired   = errors.New("every co-borrower and guarantor must consent before the loan is underwritten")
+	ErrConsentRequestNotSent  = errors.New("failed to send the consent request")
+)
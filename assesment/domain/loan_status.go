@@ -0,0 +1,96 @@
+package domain
+
+import "fmt"
+
+// LoanStatus represents a state in the loan lifecycle.
+type LoanStatus string
+
+// Loan lifecycle states.
+const (
+	LoanStatusPending        LoanStatus = "pending"
+	LoanStatusUnderReview    LoanStatus = "under_review"
+	LoanStatusCounterOffered LoanStatus = "counter_offered" // approved on other terms, waiting for the borrower
+	LoanStatusApproved       LoanStatus = "approved"
+	LoanStatusDisbursed      LoanStatus = "disbursed"
+	LoanStatusActive         LoanStatus = "active"
+	LoanStatusDelinquent     LoanStatus = "delinquent"
+	LoanStatusPaidOff        LoanStatus = "paid_off"
+	LoanStatusDefaulted      LoanStatus = "defaulted"
+	LoanStatusWrittenOff     LoanStatus = "written_off"
+	LoanStatusRejected       LoanStatus = "rejected"
+	LoanStatusWithdrawn      LoanStatus = "withdrawn"
+	LoanStatusCancelled      LoanStatus = "cancelled"
+)
+
+// loanTransitions lists, for every state, the states a loan may move to next.
+// A state without an entry is terminal.
+var loanTransitions = map[LoanStatus][]LoanStatus{
+	LoanStatusPending:        {LoanStatusUnderReview, LoanStatusApproved, LoanStatusCounterOffered, LoanStatusRejected, LoanStatusWithdrawn},
+	LoanStatusUnderReview:    {LoanStatusApproved, LoanStatusCounterOffered, LoanStatusRejected, LoanStatusWithdrawn},
+	LoanStatusCounterOffered: {LoanStatusApproved, LoanStatusUnderReview, LoanStatusRejected, LoanStatusWithdrawn},
+	LoanStatusApproved:       {LoanStatusDisbursed, LoanStatusCancelled},
+	LoanStatusDisbursed:      {LoanStatusActive},
+	LoanStatusActive:         {LoanStatusPaidOff, LoanStatusDelinquent, LoanStatusDefaulted},
+	LoanStatusDelinquent:     {LoanStatusActive, LoanStatusPaidOff, LoanStatusDefaulted},
+	LoanStatusDefaulted:      {LoanStatusPaidOff, LoanStatusWrittenOff, LoanStatusActive, LoanStatusDelinquent}, // back on track only through a restructuring
+}
+
+// IsValid reports whether s is a known loan status.
+func (s LoanStatus) IsValid() bool {
+	switch s {
+	case LoanStatusPending, LoanStatusUnderReview, LoanStatusCounterOffered, LoanStatusApproved, LoanStatusDisbursed,
+		LoanStatusActive, LoanStatusDelinquent, LoanStatusPaidOff, LoanStatusDefaulted, LoanStatusWrittenOff,
+		LoanStatusRejected, LoanStatusWithdrawn, LoanStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are allowed from s.
+func (s LoanStatus) IsTerminal() bool {
+	return len(loanTransitions[s]) == 0
+}
+
+// AcceptsPayments reports whether repayments can be recorded against a loan in state s.
+func (s LoanStatus) AcceptsPayments() bool {
+	return s == LoanStatusActive || s == LoanStatusDelinquent || s == LoanStatusDefaulted
+}
+
+// IsOnBook reports whether a loan in state s has been paid out and is still owed to us.
+func (s LoanStatus) IsOnBook() bool {
+	return s == LoanStatusDisbursed || s == LoanStatusActive || s == LoanStatusDelinquent || s == LoanStatusDefaulted
+}
+
+// CanTransitionTo reports whether a loan in state s may move to state to.
+func (s LoanStatus) CanTransitionTo(to LoanStatus) bool {
+	for _, next := range loanTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition validates the move from s to to and returns the new state,
+// or an *InvalidTransitionError if the move is not allowed.
+func (s LoanStatus) Transition(to LoanStatus) (LoanStatus, error) {
+	if !to.IsValid() || !s.CanTransitionTo(to) {
+		return s, &InvalidTransitionError{From: s, To: to}
+	}
+	return to, nil
+}
+
+// InvalidTransitionError is returned when a loan is asked to move to a state
+// that is not reachable from its current state.
+type InvalidTransitionError struct {
+	From LoanStatus `json:"from"`
+	To   LoanStatus `json:"to"`
+}
+
+// Error implements the error interface for InvalidTransitionError.
+func (e *InvalidTransitionError) Error() string {
+	if !e.To.IsValid() {
+		return fmt.Sprintf("unknown loan status %q", e.To)
+	}
+	return fmt.Sprintf("cannot move loan from %q to %q", e.From, e.To)
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoanStatusTransition(t *testing.T) {
+	tests := []struct {
+		from, to LoanStatus
+		ok       bool
+	}{
+		{LoanStatusPending, LoanStatusUnderReview, true},
+		{LoanStatusPending, LoanStatusApproved, true},
+		{LoanStatusPending, LoanStatusRejected, true},
+		{LoanStatusPending, LoanStatusWithdrawn, true},
+		{LoanStatusPending, LoanStatusDisbursed, false},
+		{LoanStatusUnderReview, LoanStatusCounterOffered, true},
+		{LoanStatusUnderReview, LoanStatusPending, false},
+		{LoanStatusCounterOffered, LoanStatusApproved, true},
+		{LoanStatusApproved, LoanStatusDisbursed, true},
+		{LoanStatusApproved, LoanStatusCancelled, true},
+		{LoanStatusApproved, LoanStatusWithdrawn, false},
+		{LoanStatusApproved, LoanStatusActive, false},
+		{LoanStatusDisbursed, LoanStatusActive, true},
+		{LoanStatusDisbursed, LoanStatusCancelled, false},
+		{LoanStatusActive, LoanStatusDelinquent, true},
+		{LoanStatusActive, LoanStatusPaidOff, true},
+		{LoanStatusActive, LoanStatusWrittenOff, false},
+		{LoanStatusDelinquent, LoanStatusActive, true},
+		{LoanStatusDelinquent, LoanStatusDefaulted, true},
+		{LoanStatusDefaulted, LoanStatusWrittenOff, true},
+		{LoanStatusDefaulted, LoanStatusPaidOff, true},
+		{LoanStatusDefaulted, LoanStatusActive, true},
+		{LoanStatusDefaulted, LoanStatusDelinquent, true},
+		{LoanStatusDefaulted, LoanStatusUnderReview, false},
+		{LoanStatusPaidOff, LoanStatusActive, false},
+		{LoanStatusRejected, LoanStatusApproved, false},
+		{LoanStatusWrittenOff, LoanStatusActive, false},
+		{LoanStatusActive, LoanStatusActive, false},
+		{LoanStatusPending, "funded", false},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.from.Transition(tt.to)
+		if tt.ok {
+			if err != nil || got != tt.to {
+				t.Errorf("%s -> %s: got %s, %v, want %s", tt.from, tt.to, got, err, tt.to)
+			}
+			continue
+		}
+
+		var transitionErr *InvalidTransitionError
+		if !errors.As(err, &transitionErr) {
+			t.Errorf("%s -> %s: got error %v, want an InvalidTransitionError", tt.from, tt.to, err)
+			continue
+		}
+		if got != tt.from {
+			t.Errorf("%s -> %s: status changed to %s on a refused move", tt.from, tt.to, got)
+		}
+		if transitionErr.From != tt.from || transitionErr.To != tt.to {
+			t.Errorf("%s -> %s: error reports %s -> %s", tt.from, tt.to, transitionErr.From, transitionErr.To)
+		}
+	}
+}
+
+func TestLoanStatusIsTerminal(t *testing.T) {
+	terminal := map[LoanStatus]bool{
+		LoanStatusPaidOff:    true,
+		LoanStatusWrittenOff: true,
+		LoanStatusRejected:   true,
+		LoanStatusWithdrawn:  true,
+		LoanStatusCancelled:  true,
+	}
+
+	for _, status := range []LoanStatus{
+		LoanStatusPending, LoanStatusUnderReview, LoanStatusCounterOffered, LoanStatusApproved, LoanStatusDisbursed,
+		LoanStatusActive, LoanStatusDelinquent, LoanStatusPaidOff, LoanStatusDefaulted, LoanStatusWrittenOff,
+		LoanStatusRejected, LoanStatusWithdrawn, LoanStatusCancelled,
+	} {
+		if !status.IsValid() {
+			t.Errorf("%s is not valid", status)
+		}
+		if got := status.IsTerminal(); got != terminal[status] {
+			t.Errorf("%s.IsTerminal() = %v, want %v", status, got, terminal[status])
+		}
+	}
+	if LoanStatus("funded").IsValid() {
+		t.Error("unknown status is valid")
+	}
+}
+
+func TestInvalidTransitionErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  InvalidTransitionError
+		want string
+	}{
+		{InvalidTransitionError{From: LoanStatusPaidOff, To: LoanStatusActive}, `cannot move loan from "paid_off" to "active"`},
+		{InvalidTransitionError{From: LoanStatusPending, To: "funded"}, `unknown loan status "funded"`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
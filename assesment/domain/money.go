@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// currencyExponents maps the ISO-4217 currencies we lend in to their number of minor-unit digits.
+var currencyExponents = map[string]int{
+	"ETB": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"KES": 2,
+	"NGN": 2,
+	"ZAR": 2,
+	"UGX": 0,
+	"JPY": 0,
+	"KWD": 3,
+}
+
+// CurrencyExponent returns the number of minor-unit digits of an ISO-4217 currency.
+func CurrencyExponent(currency string) (int, bool) {
+	exponent, ok := currencyExponents[currency]
+	return exponent, ok
+}
+
+// IsValidCurrency reports whether currency is a supported ISO-4217 code.
+func IsValidCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+	return ok
+}
+
+// Money is an amount in the minor units (e.g. cents) of an ISO-4217 currency.
+// Arithmetic between two values requires them to share a currency.
+type Money struct {
+	Amount   int64  `bson:"amount"`   // minor units
+	Currency string `bson:"currency"` // ISO-4217 code
+}
+
+// NewMoney creates a Money value from an amount in minor units.
+func NewMoney(minorUnits int64, currency string) Money {
+	return Money{Amount: minorUnits, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount such as "1250.50" in the given currency.
+// The amount may not have more decimal places than the currency allows.
+func ParseMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	exponent, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, ErrUnknownCurrency
+	}
+
+	amount = strings.TrimSpace(amount)
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(amount, "-")
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, ErrInvalidMoney
+	}
+	if len(fraction) > exponent {
+		return Money{}, ErrInvalidAmountScale
+	}
+	for _, part := range []string{whole, fraction} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return Money{}, ErrInvalidMoney
+			}
+		}
+	}
+
+	digits := whole + fraction + strings.Repeat("0", exponent-len(fraction))
+	minorUnits, ok := new(big.Int).SetString(digits, 10)
+	if !ok || !minorUnits.IsInt64() {
+		return Money{}, ErrInvalidMoney
+	}
+	if negative {
+		minorUnits.Neg(minorUnits)
+	}
+
+	return Money{Amount: minorUnits.Int64(), Currency: currency}, nil
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive reports whether the amount is greater than zero.
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative reports whether the amount is less than zero.
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Zero returns a zero amount in the same currency.
+func (m Money) Zero() Money {
+	return Money{Currency: m.Currency}
+}
+
+// Add returns m + o. It panics if the currencies differ, which is a programming error:
+// amounts are validated at the API boundary and every amount on a loan shares its currency.
+func (m Money) Add(o Money) Money {
+	m.mustMatch(o)
+	return Money{Amount: m.Amount + o.Amount, Currency: m.currency(o)}
+}
+
+// Sub returns m - o. It panics if the currencies differ.
+func (m Money) Sub(o Money) Money {
+	m.mustMatch(o)
+	return Money{Amount: m.Amount - o.Amount, Currency: m.currency(o)}
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Cmp compares m and o and returns -1, 0 or +1. It panics if the currencies differ.
+func (m Money) Cmp(o Money) int {
+	m.mustMatch(o)
+	switch {
+	case m.Amount < o.Amount:
+		return -1
+	case m.Amount > o.Amount:
+		return 1
+	}
+	return 0
+}
+
+// Min returns the smaller of m and o.
+func (m Money) Min(o Money) Money {
+	if m.Cmp(o) <= 0 {
+		return m
+	}
+	return o
+}
+
+// Mul returns m multiplied by factor, rounded half to even to the nearest minor unit.
+func (m Money) Mul(factor *big.Rat) Money {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), factor)
+	return Money{Amount: RoundHalfEven(product), Currency: m.Currency}
+}
+
+// Div returns m divided by n, rounded half to even to the nearest minor unit.
+func (m Money) Div(n int64) Money {
+	return m.Mul(big.NewRat(1, n))
+}
+
+// Rat returns the amount in major units as an exact rational number.
+func (m Money) Rat() *big.Rat {
+	exponent, _ := CurrencyExponent(m.Currency)
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+}
+
+// Decimal formats the amount in major units with the currency's number of decimal places.
+func (m Money) Decimal() string {
+	exponent, _ := CurrencyExponent(m.Currency)
+	return m.Rat().FloatString(exponent)
+}
+
+// String formats the amount with its currency, e.g. "1250.50 USD".
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Validate checks that the currency is supported.
+func (m Money) Validate() error {
+	if !IsValidCurrency(m.Currency) {
+		return ErrUnknownCurrency
+	}
+	return nil
+}
+
+// moneyJSON is the wire format of Money: the amount is a decimal string in major units.
+type moneyJSON struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON encodes Money as {"amount": "1250.50", "currency": "USD"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes Money from {"amount": "1250.50", "currency": "USD"}. The amount may
+// also be a JSON number; either way it is validated against the currency's scale.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var wire moneyJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return ErrInvalidMoney
+	}
+	if wire.Currency == "" {
+		return ErrMissingCurrency
+	}
+
+	parsed, err := ParseMoney(wire.Amount.String(), wire.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// RoundHalfEven rounds r to the nearest integer, resolving ties to the even neighbour.
+func RoundHalfEven(r *big.Rat) int64 {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+	twiceRemainder := new(big.Int).Abs(remainder)
+	twiceRemainder.Lsh(twiceRemainder, 1)
+	switch twiceRemainder.Cmp(r.Denom()) {
+	case 1:
+		quotient.Add(quotient, big.NewInt(int64(r.Sign())))
+	case 0:
+		if quotient.Bit(0) == 1 {
+			quotient.Add(quotient, big.NewInt(int64(r.Sign())))
+		}
+	}
+	return quotient.Int64()
+}
+
+// PercentRate converts a percentage such as 12.5 to the exact fraction 0.125.
+// The percentage is read through its shortest decimal representation so that
+// binary floating point noise does not leak into interest calculations.
+func PercentRate(percent float64) *big.Rat {
+	rate, ok := new(big.Rat).SetString(strconv.FormatFloat(percent, 'f', -1, 64))
+	if !ok {
+		rate = new(big.Rat).SetFloat64(percent)
+	}
+	return rate.Quo(rate, big.NewRat(100, 1))
+}
+
+// mustMatch panics if m and o carry different currencies. The zero value, which has
+// no currency yet, is compatible with any currency.
+func (m Money) mustMatch(o Money) {
+	if m.Currency != o.Currency && m.Currency != "" && o.Currency != "" {
+		panic(fmt.Sprintf("money: currency mismatch %s vs %s", m.Currency, o.Currency))
+	}
+}
+
+// currency returns the currency shared by m and o.
+func (m Money) currency(o Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return o.Currency
+}
+
+// Money errors
+var (
+	ErrInvalidMoney       = errors.New("invalid money amount")
+	ErrMissingCurrency    = errors.New("money amount requires a currency")
+	ErrUnknownCurrency    = errors.New("unsupported currency")
+	ErrInvalidAmountScale = errors.New("amount has more decimal places than its currency allows")
+	ErrCurrencyMismatch   = errors.New("amounts are in different currencies")
+)
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func usd(cents int64) Money {
+	return NewMoney(cents, "USD")
+}
+
+func TestRoundHalfEven(t *testing.T) {
+	tests := []struct {
+		num, denom int64
+		want       int64
+	}{
+		{5, 2, 2},
+		{7, 2, 4},
+		{-5, 2, -2},
+		{-7, 2, -4},
+		{24, 10, 2},
+		{26, 10, 3},
+		{-26, 10, -3},
+		{1, 3, 0},
+		{2, 3, 1},
+		{10, 1, 10},
+	}
+
+	for _, tt := range tests {
+		if got := RoundHalfEven(big.NewRat(tt.num, tt.denom)); got != tt.want {
+			t.Errorf("RoundHalfEven(%d/%d) = %d, want %d", tt.num, tt.denom, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyMulAndDiv(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Money
+		want Money
+	}{
+		{"tie rounds down to even", usd(1001).Mul(big.NewRat(1, 2)), usd(500)},
+		{"tie rounds up to even", usd(1003).Mul(big.NewRat(1, 2)), usd(502)},
+		{"interest at a percentage", usd(123456).Mul(PercentRate(12.5)), usd(15432)},
+		{"float noise in the rate does not leak", usd(100000).Mul(PercentRate(0.1 + 0.2)), usd(300)},
+		{"division rounds down below half", usd(100).Div(3), usd(33)},
+		{"division rounds up above half", usd(200).Div(3), usd(67)},
+		{"negative amounts round symmetrically", usd(-1003).Mul(big.NewRat(1, 2)), usd(-502)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount, currency string
+		want             Money
+		err              error
+	}{
+		{"1250.50", "USD", usd(125050), nil},
+		{"1250.5", "usd", usd(125050), nil},
+		{" -3.10 ", "USD", usd(-310), nil},
+		{".5", "USD", usd(50), nil},
+		{"100", "JPY", NewMoney(100, "JPY"), nil},
+		{"1.234", "KWD", NewMoney(1234, "KWD"), nil},
+		{"12.345", "USD", Money{}, ErrInvalidAmountScale},
+		{"1.5", "JPY", Money{}, ErrInvalidAmountScale},
+		{"12a", "USD", Money{}, ErrInvalidMoney},
+		{"-", "USD", Money{}, ErrInvalidMoney},
+		{"99999999999999999999", "USD", Money{}, ErrInvalidMoney},
+		{"10", "XXX", Money{}, ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.amount, tt.currency)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseMoney(%q, %q) = %v, %v; want %v, %v", tt.amount, tt.currency, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestMoneyCurrencyMismatchPanics(t *testing.T) {
+	eur := NewMoney(100, "EUR")
+
+	tests := []struct {
+		name string
+		op   func()
+	}{
+		{"Add", func() { usd(100).Add(eur) }},
+		{"Sub", func() { usd(100).Sub(eur) }},
+		{"Cmp", func() { usd(100).Cmp(eur) }},
+		{"Min", func() { usd(100).Min(eur) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s of USD and EUR did not panic", tt.name)
+				}
+			}()
+			tt.op()
+		})
+	}
+}
+
+func TestMoneyZeroValueTakesCurrency(t *testing.T) {
+	var zero Money
+	if got := zero.Add(usd(100)); got != usd(100) {
+		t.Errorf("zero + 1.00 USD = %v, want 1.00 USD", got)
+	}
+	if got := usd(100).Sub(zero); got != usd(100) {
+		t.Errorf("1.00 USD - zero = %v, want 1.00 USD", got)
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Payment represents a repayment recorded against a loan, together with how it
+// was allocated across penalties, fees, interest and principal.
+type Payment struct {
+	ID                 primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LoanID             uint               `bson:"loan_id" json:"loan_id"`
+	UserID             primitive.ObjectID `bson:"user_id" json:"user_id"`
+	RecordedBy         primitive.ObjectID `bson:"recorded_by,omitempty" json:"recorded_by,omitempty"` // the borrower or admin who posted the payment
+	Amount             Money              `bson:"amount" json:"amount"`
+	Penalties          Money              `bson:"penalties" json:"penalties"`
+	Fees               Money              `bson:"fees" json:"fees"`
+	Interest           Money              `bson:"interest" json:"interest"`
+	InterestReceivable Money              `bson:"interest_receivable" json:"interest_receivable"`   // part of Interest that settles interest already accrued
+	Principal          Money              `bson:"principal" json:"principal"`                       // includes principal repaid ahead of schedule
+	PrepaymentPenalty  Money              `bson:"prepayment_penalty" json:"prepayment_penalty"`     // part of Fees charged on principal repaid early
+	Prepayment         PrepaymentOption   `bson:"prepayment,omitempty" json:"prepayment,omitempty"` // how an amount beyond what is due was applied
+	Settlement         bool               `bson:"settlement" json:"settlement"`                     // the payment settled the loan under its payoff quote
+	Recovery           bool               `bson:"recovery" json:"recovery"`                         // received after the loan was written off
+	BalanceAfter       Money              `bson:"balance_after" json:"balance_after"`
+	PaidAt             time.Time          `bson:"paid_at" json:"paid_at"`
+	CreatedAt          time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// MaxPaymentBackdate is how far in the past an admin may date a payment, for money received
+// before it could be posted. Borrowers' payments are always dated when they are made.
+const MaxPaymentBackdate = 30 * 24 * time.Hour
+
+// PaymentRepository defines the methods for storing and retrieving payments.
+type PaymentRepository interface {
+	CreatePayment(payment Payment) error
+	GetPaymentsByLoan(loanID uint) ([]Payment, error)
+	GetPaymentsByUser(userID primitive.ObjectID) ([]Payment, error)
+}
+
+// Payment errors
+var (
+	ErrInvalidPaymentAmount  = errors.New("payment amount must be positive")
+	ErrLoanNotRepayable      = errors.New("payments can only be recorded against active, delinquent or defaulted loans")
+	ErrPaymentExceedsBalance = errors.New("payment exceeds the outstanding balance")
+	ErrPaymentBackdating     = errors.New("only admins may date a payment in the past")
+	ErrPaymentDateOutOfRange = errors.New("a payment cannot be dated in the future, before the loan was paid out or more than 30 days back")
+)
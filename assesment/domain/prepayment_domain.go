@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// PrepaymentTerms are the rules a product sets for repaying principal ahead of schedule.
+// They are copied onto each loan when it is applied for.
+type PrepaymentTerms struct {
+	PenaltyPercentage float64 `bson:"penalty_percentage" json:"penalty_percentage"` // charged on the principal repaid before it falls due
+}
+
+// Validate checks the prepayment terms of a product.
+func (t PrepaymentTerms) Validate() error {
+	if t.PenaltyPercentage < 0 {
+		return ErrInvalidPrepaymentTerms
+	}
+	return nil
+}
+
+// PrepaymentOption is how the schedule is recalculated after a partial prepayment.
+type PrepaymentOption string
+
+const (
+	PrepayReduceTerm        PrepaymentOption = "reduce_term"        // keep the installment amount and finish earlier
+	PrepayReduceInstallment PrepaymentOption = "reduce_installment" // keep the term and lower the installments
+)
+
+// IsValid reports whether o is a supported prepayment option.
+func (o PrepaymentOption) IsValid() bool {
+	return o == PrepayReduceTerm || o == PrepayReduceInstallment
+}
+
+// PayoffQuote is what it takes to settle a loan in full on a given day: the principal still
+// owed, interest due or earned up to that day, fees and penalties, and the prepayment
+// penalty on the principal not yet due. Interest the loan would have earned afterwards is
+// not charged.
+type PayoffQuote struct {
+	LoanID            uint      `json:"loan_id"`
+	Principal         Money     `json:"principal"`
+	Interest          Money     `json:"interest"`
+	Fees              Money     `json:"fees"`
+	Penalties         Money     `json:"penalties"`
+	PrepaymentPenalty Money     `json:"prepayment_penalty"`
+	Total             Money     `json:"total"`
+	QuotedAt          time.Time `json:"quoted_at"`
+	ValidUntil        time.Time `json:"valid_until"` // end of the day the quote is for
+}
+
+// Prepayment errors
+var (
+	ErrInvalidPrepaymentTerms  = errors.New("prepayment penalty cannot be negative")
+	ErrInvalidPrepaymentOption = errors.New("prepayment must be reduce_term or reduce_installment")
+	ErrQuoteDateInPast         = errors.New("payoff quotes cannot be given for past days")
+	ErrPrepaymentTooLarge      = errors.New("prepayment would repay all the principal; pay the payoff quote to settle the loan")
+)
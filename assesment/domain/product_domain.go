@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoanProduct is an admin-defined loan offering. Every application references a
+// product and is validated against its rules.
+type LoanProduct struct {
+	ID                   primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name                 string               `bson:"name" json:"name"`
+	Description          string               `bson:"description" json:"description"`
+	AmountLimits         []AmountLimit        `bson:"amount_limits" json:"amount_limits"`                 // one per currency the product is offered in
+	AllowedTerms         []int                `bson:"allowed_terms" json:"allowed_terms"`                 // numbers of installments; empty allows any term up to MaxLoanTerm
+	MinInterestRate      float64              `bson:"min_interest_rate" json:"min_interest_rate"`         // nominal annual rate, in percent
+	MaxInterestRate      float64              `bson:"max_interest_rate" json:"max_interest_rate"`         // equal to MinInterestRate for a fixed rate
+	InterestRate         float64              `bson:"interest_rate" json:"interest_rate"`                 // charged on applications, MinInterestRate if not set
+	RepaymentFrequencies []RepaymentFrequency `bson:"repayment_frequencies" json:"repayment_frequencies"` // empty allows any frequency
+	AmortizationMethod   AmortizationMethod   `bson:"amortization_method" json:"amortization_method"`     // default for applications that do not choose one
+	DayCount             DayCountConvention   `bson:"day_count" json:"day_count"`                         // used to accrue interest daily, actual/365 by default
+	Fees                 []ProductFee         `bson:"fees" json:"fees"`
+	Penalties            PenaltyTerms         `bson:"penalties" json:"penalties"`   // charged on overdue installments
+	Prepayment           PrepaymentTerms      `bson:"prepayment" json:"prepayment"` // charged on principal repaid early
+	Eligibility          ProductEligibility   `bson:"eligibility" json:"eligibility"`
+	Active               bool                 `bson:"active" json:"active"`
+	CreatedAt            time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt            time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// AmountLimit bounds the principal a product lends in one currency.
+type AmountLimit struct {
+	Min Money `bson:"min" json:"min"`
+	Max Money `bson:"max" json:"max"`
+}
+
+// ProductFee is an origination fee charged when a loan is approved, as a percentage
+// of the principal, a flat amount, or both. A flat amount only applies to loans in its currency.
+type ProductFee struct {
+	Name       string  `bson:"name" json:"name"`
+	Percentage float64 `bson:"percentage" json:"percentage"`
+	Flat       *Money  `bson:"flat,omitempty" json:"flat,omitempty"`
+}
+
+// ProductEligibility lists the borrower requirements of a product. Zero values disable a requirement.
+type ProductEligibility struct {
+	RequireActiveAccount bool `bson:"require_active_account" json:"require_active_account"`
+	MinAccountAgeDays    int  `bson:"min_account_age_days" json:"min_account_age_days"`
+	MaxActiveLoans       int  `bson:"max_active_loans" json:"max_active_loans"`
+}
+
+// LimitFor returns the amount limit of the product in currency, if it is offered in it.
+func (p LoanProduct) LimitFor(currency string) (AmountLimit, bool) {
+	for _, limit := range p.AmountLimits {
+		if limit.Min.Currency == currency {
+			return limit, true
+		}
+	}
+	return AmountLimit{}, false
+}
+
+// ApplicationRate returns the interest rate the product charges the loans applied for under it.
+// Counter-offers can set another rate within the product's range.
+func (p LoanProduct) ApplicationRate() float64 {
+	if p.InterestRate == 0 {
+		return p.MinInterestRate
+	}
+	return p.InterestRate
+}
+
+// AllowsTerm reports whether the product offers a term of n installments.
+func (p LoanProduct) AllowsTerm(n int) bool {
+	if len(p.AllowedTerms) == 0 {
+		return true
+	}
+	for _, term := range p.AllowedTerms {
+		if term == n {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsFrequency reports whether the product offers the repayment frequency f.
+func (p LoanProduct) AllowsFrequency(f RepaymentFrequency) bool {
+	if len(p.RepaymentFrequencies) == 0 {
+		return true
+	}
+	for _, frequency := range p.RepaymentFrequencies {
+		if frequency == f {
+			return true
+		}
+	}
+	return false
+}
+
+// ProductRepository defines the methods for storing and retrieving loan products.
+type ProductRepository interface {
+	CreateProduct(product LoanProduct) error
+	GetProductByID(id primitive.ObjectID) (LoanProduct, error)
+	GetProducts(activeOnly bool) ([]LoanProduct, error)
+	UpdateProduct(product LoanProduct) error
+	DeleteProduct(id primitive.ObjectID) error
+}
+
+// ProductUsecase defines the business logic for managing loan products.
+type ProductUsecase interface {
+	CreateProduct(product LoanProduct) (LoanProduct, error)
+	GetProduct(id primitive.ObjectID) (LoanProduct, error)
+	GetProducts(activeOnly bool) ([]LoanProduct, error)
+	UpdateProduct(product LoanProduct) (LoanProduct, error)
+	DeleteProduct(id primitive.ObjectID) error
+}
+
+// Product errors
+var (
+	ErrProductNotFound     = errors.New("loan product not found")
+	ErrProductRequired     = errors.New("a loan application must reference a product")
+	ErrProductInactive     = errors.New("loan product is not open for applications")
+	ErrInvalidProduct      = errors.New("loan product needs a name and at least one amount limit")
+	ErrInvalidAmountLimit  = errors.New("amount limits need a positive minimum not above the maximum, in one currency")
+	ErrInvalidRateRange    = errors.New("interest rates must satisfy 0 <= min <= rate <= max")
+	ErrCurrencyNotOffered  = errors.New("loan product is not offered in this currency")
+	ErrAmountOutOfRange    = errors.New("loan amount is outside the product's limits")
+	ErrTermNotOffered      = errors.New("loan product does not offer this term")
+	ErrRateOutOfRange      = errors.New("interest rate is outside the product's range")
+	ErrFrequencyNotOffered = errors.New("loan product does not offer this repayment frequency")
+	ErrInactiveAccount     = errors.New("loan product requires an activated account")
+	ErrAccountTooNew       = errors.New("account is too new for this loan product")
+	ErrTooManyActiveLoans  = errors.New("borrower already has the maximum number of active loans")
+)
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RejectionReason is an entry of the catalog of reasons a loan application can be rejected
+// for. The description is the statement the borrower reads in their adverse-action notice.
+type RejectionReason struct {
+	Code        string    `bson:"_id" json:"code"`
+	Description string    `bson:"description" json:"description"`
+	Active      bool      `bson:"active" json:"active"` // retired reasons stay on past rejections but cannot be given again
+	CreatedAt   time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// DefaultRejectionReasons seed the catalog on first start.
+var DefaultRejectionReasons = []RejectionReason{
+	{Code: "insufficient_income", Description: "Income insufficient for the amount of credit requested"},
+	{Code: "excessive_obligations", Description: "Excessive obligations in relation to income"},
+	{Code: "insufficient_credit_history", Description: "Insufficient credit history"},
+	{Code: "delinquent_credit_history", Description: "Delinquent past or present credit obligations"},
+	{Code: "unable_to_verify_income", Description: "Unable to verify income"},
+	{Code: "unable_to_verify_identity", Description: "Unable to verify identity"},
+	{Code: "insufficient_collateral", Description: "Value or type of collateral not sufficient"},
+	{Code: "incomplete_application", Description: "Incomplete application"},
+	{Code: "amount_outside_limits", Description: "Amount of credit requested is outside the limits we lend"},
+}
+
+// StatedReason is a rejection reason as it was given on a loan, kept as worded at the time.
+type StatedReason struct {
+	Code        string `bson:"code" json:"code"`
+	Description string `bson:"description" json:"description"`
+}
+
+// RejectionRequest is what a reviewer gives when rejecting an application: one or more
+// reason codes from the catalog and an optional note for the borrower.
+type RejectionRequest struct {
+	ReasonCodes []string `json:"reason_codes"`
+	Note        string   `json:"note"`
+}
+
+// LoanRejection records why an application was rejected and when the borrower was told.
+type LoanRejection struct {
+	Reasons      []StatedReason     `bson:"reasons" json:"reasons"`
+	Note         string             `bson:"note,omitempty" json:"note,omitempty"`
+	RejectedBy   primitive.ObjectID `bson:"rejected_by" json:"rejected_by"`
+	RejectedAt   time.Time          `bson:"rejected_at" json:"rejected_at"`
+	NoticeSentAt *time.Time         `bson:"notice_sent_at,omitempty" json:"notice_sent_at,omitempty"`
+}
+
+// AdverseActionNotice is the notice telling a borrower their application was rejected and why.
+type AdverseActionNotice struct {
+	LoanID        uint
+	BorrowerName  string
+	BorrowerEmail string
+	Amount        Money
+	AppliedAt     time.Time
+	RejectedAt    time.Time
+	Reasons       []StatedReason
+	Note          string
+}
+
+// AdverseActionNotifier renders adverse-action notices from a template and sends them to borrowers.
+type AdverseActionNotifier interface {
+	RenderNotice(notice AdverseActionNotice) (string, error)
+	SendNotice(notice AdverseActionNotice) error
+}
+
+// RejectionReasonRepository defines the methods for storing and retrieving the rejection reason catalog.
+type RejectionReasonRepository interface {
+	CreateReason(reason RejectionReason) error
+	GetReasonByCode(code string) (RejectionReason, error)
+	GetReasons(activeOnly bool) ([]RejectionReason, error)
+	UpdateReason(reason RejectionReason) error
+}
+
+// RejectionUsecase defines the business logic for the rejection reason catalog and adverse-action notices.
+type RejectionUsecase interface {
+	CreateReason(reason RejectionReason) (RejectionReason, error)
+	GetReasons(activeOnly bool) ([]RejectionReason, error)
+	UpdateReason(code string, reason RejectionReason) (RejectionReason, error)
+	SeedDefaultReasons() error
+	GetAdverseActionNotice(loanID uint, userID primitive.ObjectID) (string, error)
+}
+
+// Rejection errors
+var (
+	ErrRejectionReasonRequired  = errors.New("at least one rejection reason code is required")
+	ErrUnknownRejectionReason   = errors.New("unknown or retired rejection reason code")
+	ErrInvalidRejectionReason   = errors.New("a rejection reason needs a code of lowercase letters, digits and underscores and a description")
+	ErrDuplicateRejectionReason = errors.New("a rejection reason with this code already exists")
+	ErrRejectionReasonNotFound  = errors.New("rejection reason not found")
+	ErrLoanNotRejected          = errors.New("the loan was not rejected with stated reasons")
+	ErrAdverseActionNotSent     = errors.New("the loan was rejected but the adverse-action notice could not be sent")
+)
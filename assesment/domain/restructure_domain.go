@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RestructureTerms are the changes an admin makes to the remaining schedule of a loan.
+type RestructureTerms struct {
+	Reason            string   `bson:"reason" json:"reason"`
+	ExtendTerm        int      `bson:"extend_term" json:"extend_term"`                         // installments added to the remaining term
+	InterestRate      *float64 `bson:"interest_rate,omitempty" json:"interest_rate,omitempty"` // new nominal annual rate, in percent; unchanged when omitted
+	CapitalizeArrears bool     `bson:"capitalize_arrears" json:"capitalize_arrears"`           // add overdue amounts to the principal
+	PaymentHoliday    int      `bson:"payment_holiday" json:"payment_holiday"`                 // periods without an installment before the new schedule starts
+}
+
+// Validate checks that the terms give a reason and change something.
+func (t RestructureTerms) Validate() error {
+	if t.Reason == "" {
+		return ErrRestructureReasonRequired
+	}
+	if t.ExtendTerm < 0 || t.PaymentHoliday < 0 {
+		return ErrInvalidRestructure
+	}
+	if t.InterestRate != nil && *t.InterestRate < 0 {
+		return ErrInvalidInterestRate
+	}
+	if t.ExtendTerm == 0 && t.InterestRate == nil && !t.CapitalizeArrears && t.PaymentHoliday == 0 {
+		return ErrInvalidRestructure
+	}
+	return nil
+}
+
+// LoanModification records a restructuring of a loan with the schedule before and after it.
+type LoanModification struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LoanID            uint               `bson:"loan_id" json:"loan_id"`
+	Terms             RestructureTerms   `bson:"terms" json:"terms"`
+	PreviousTerm      int                `bson:"previous_term" json:"previous_term"`
+	NewTerm           int                `bson:"new_term" json:"new_term"`
+	PreviousRate      float64            `bson:"previous_rate" json:"previous_rate"`
+	NewRate           float64            `bson:"new_rate" json:"new_rate"`
+	CapitalizedAmount Money              `bson:"capitalized_amount" json:"capitalized_amount"` // overdue interest, fees and penalties added to the principal
+	PreviousSchedule  []Installment      `bson:"previous_schedule" json:"previous_schedule"`
+	NewSchedule       []Installment      `bson:"new_schedule" json:"new_schedule"`
+	ModifiedBy        primitive.ObjectID `bson:"modified_by" json:"modified_by"`
+	CreatedAt         time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ModificationRepository defines the methods for storing and retrieving loan modifications.
+type ModificationRepository interface {
+	CreateModification(modification LoanModification) error
+	GetModificationsByLoan(loanID uint) ([]LoanModification, error)
+}
+
+// RestructureUsecase defines the business logic for restructuring loans.
+type RestructureUsecase interface {
+	RestructureLoan(loanID uint, terms RestructureTerms, modifiedBy primitive.ObjectID) (LoanModification, error)
+	GetLoanModifications(loanID uint) ([]LoanModification, error)
+}
+
+// Restructuring errors
+var (
+	ErrRestructureReasonRequired = errors.New("a reason is required to restructure a loan")
+	ErrInvalidRestructure        = errors.New("restructuring must extend the term, change the rate, capitalize arrears or grant a payment holiday, with no negative values")
+	ErrLoanNotRestructurable     = errors.New("only active, delinquent and defaulted loans can be restructured")
+	ErrNothingToRestructure      = errors.New("the loan has nothing left to reschedule")
+	ErrTermExtensionRequired     = errors.New("every installment of the loan is due; extend the term to reschedule it")
+)
@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultReviewSLA is how long an application may wait in the underwriting queue before it
+// is flagged as overdue.
+const DefaultReviewSLA = 48 * time.Hour
+
+// ReviewerRole is the user role whose members review loan applications.
+const ReviewerRole = "admin"
+
+// LoanReview is the underwriting record of a loan: who reviews it, what the borrower was
+// asked for, and who decided it and why. Internal notes are kept apart in ReviewNote.
+type LoanReview struct {
+	ReviewerID     primitive.ObjectID `bson:"reviewer_id,omitempty" json:"reviewer_id,omitempty"`
+	AssignedBy     primitive.ObjectID `bson:"assigned_by,omitempty" json:"assigned_by,omitempty"` // unset for round-robin assignments
+	AssignedAt     *time.Time         `bson:"assigned_at,omitempty" json:"assigned_at,omitempty"`
+	InfoRequests   []InfoRequest      `bson:"info_requests" json:"info_requests,omitempty"`
+	Approvals      []Approval         `bson:"approvals" json:"approvals,omitempty"`         // sign-offs on the current terms, two needed above the dual approval threshold
+	Decision       LoanStatus         `bson:"decision,omitempty" json:"decision,omitempty"` // approved or rejected
+	DecisionReason string             `bson:"decision_reason,omitempty" json:"decision_reason,omitempty"`
+	DecidedBy      primitive.ObjectID `bson:"decided_by,omitempty" json:"decided_by,omitempty"`
+	DecidedAt      *time.Time         `bson:"decided_at,omitempty" json:"decided_at,omitempty"`
+}
+
+// Approval is an admin's sign-off on the terms of a loan.
+type Approval struct {
+	AdminID    primitive.ObjectID `bson:"admin_id" json:"admin_id"`
+	Reason     string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	ApprovedAt time.Time          `bson:"approved_at" json:"approved_at"`
+}
+
+// Approvals returns the sign-offs recorded on the current terms of a loan.
+func (l Loan) Approvals() []Approval {
+	if l.Review == nil {
+		return nil
+	}
+	return l.Review.Approvals
+}
+
+// ApprovedBy reports whether adminID has signed off the current terms of a loan.
+func (l Loan) ApprovedBy(adminID primitive.ObjectID) bool {
+	for _, approval := range l.Approvals() {
+		if approval.AdminID == adminID {
+			return true
+		}
+	}
+	return false
+}
+
+// DualApprovalPolicy is the four-eyes control on large loans: a loan above Threshold is only
+// approved once two different admins have signed it off.
+type DualApprovalPolicy struct {
+	Threshold Money                // a zero threshold disables the control
+	Rates     ExchangeRateProvider // converts loan amounts into the threshold currency
+}
+
+// Requires reports whether a loan of amount needs two approvals. An amount that cannot be
+// converted into the threshold currency needs them too.
+func (p DualApprovalPolicy) Requires(amount Money) bool {
+	if !p.Threshold.IsPositive() {
+		return false
+	}
+	if amount.Currency != p.Threshold.Currency && p.Rates == nil {
+		return true
+	}
+	converted, err := ConvertMoney(amount, p.Threshold.Currency, p.Rates)
+	if err != nil {
+		return true
+	}
+	return converted.Cmp(p.Threshold) > 0
+}
+
+// InfoRequest is a reviewer's request for more information from the borrower, and their answer.
+type InfoRequest struct {
+	ID          primitive.ObjectID `bson:"id" json:"id"`
+	Message     string             `bson:"message" json:"message"`
+	RequestedBy primitive.ObjectID `bson:"requested_by" json:"requested_by"`
+	RequestedAt time.Time          `bson:"requested_at" json:"requested_at"`
+	Response    string             `bson:"response,omitempty" json:"response,omitempty"`
+	RespondedAt *time.Time         `bson:"responded_at,omitempty" json:"responded_at,omitempty"`
+}
+
+// OpenInfoRequests returns how many information requests of a loan the borrower has not answered.
+func (l Loan) OpenInfoRequests() int {
+	if l.Review == nil {
+		return 0
+	}
+	open := 0
+	for _, request := range l.Review.InfoRequests {
+		if request.RespondedAt == nil {
+			open++
+		}
+	}
+	return open
+}
+
+// ReviewNote is an internal note a reviewer left on a loan. Borrowers never see notes.
+type ReviewNote struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LoanID    uint               `bson:"loan_id" json:"loan_id"`
+	AuthorID  primitive.ObjectID `bson:"author_id" json:"author_id"`
+	Text      string             `bson:"text" json:"text"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ReviewQueueItem is an application waiting in the underwriting queue, with how long it has waited.
+type ReviewQueueItem struct {
+	Loan             Loan    `json:"loan"`
+	AgeHours         float64 `json:"age_hours"` // since the application was submitted
+	OverSLA          bool    `json:"over_sla"`
+	AwaitingBorrower bool    `json:"awaiting_borrower"`        // an information request is unanswered
+	AwaitingApproval bool    `json:"awaiting_second_approval"` // signed off by one admin, waiting for a second
+}
+
+// ReviewQueueFilter narrows the underwriting queue to one reviewer or to unassigned applications.
+type ReviewQueueFilter struct {
+	ReviewerID primitive.ObjectID
+	Unassigned bool
+}
+
+// ReviewNoteRepository defines the methods for storing and retrieving review notes.
+type ReviewNoteRepository interface {
+	CreateReviewNote(note ReviewNote) error
+	GetReviewNotesByLoan(loanID uint) ([]ReviewNote, error)
+}
+
+// ReviewUsecase defines the business logic of the underwriting queue.
+type ReviewUsecase interface {
+	GetReviewQueue(filter ReviewQueueFilter, now time.Time) ([]ReviewQueueItem, error)
+	AssignReviewer(loanID uint, reviewerID primitive.ObjectID, assignedBy primitive.ObjectID) (Loan, error)
+	ClaimLoan(loanID uint, reviewerID primitive.ObjectID) (Loan, error)
+	AssignUnassigned(now time.Time) (int, error)
+	AddReviewNote(loanID uint, text string, authorID primitive.ObjectID) (ReviewNote, error)
+	GetReviewNotes(loanID uint) ([]ReviewNote, error)
+	RequestInformation(loanID uint, message string, reviewerID primitive.ObjectID) (InfoRequest, error)
+	RespondToInformationRequest(loanID uint, requestID primitive.ObjectID, response string, userID primitive.ObjectID) (InfoRequest, error)
+}
+
+// Review errors
+var (
+	ErrLoanNotInReviewQueue   = errors.New("only applications pending or under review are in the underwriting queue")
+	ErrInvalidReviewer        = errors.New("reviewers must be admin users")
+	ErrNoReviewers            = errors.New("there are no reviewers to assign applications to")
+	ErrNotAssignedReviewer    = errors.New("the application is assigned to another reviewer")
+	ErrReviewNoteRequired     = errors.New("a note needs text")
+	ErrInfoRequestMessage     = errors.New("an information request needs a message")
+	ErrInfoRequestNotFound    = errors.New("information request not found")
+	ErrInfoRequestAnswered    = errors.New("the information request was already answered")
+	ErrInfoResponseRequired   = errors.New("a response is required")
+	ErrSecondApprovalRequired = errors.New("loans above the dual approval threshold need the approval of a second admin")
+	ErrSameApprover           = errors.New("the second approval must come from a different admin")
+)
@@ -0,0 +1,110 @@
+package domain
+
+import "time"
+
+// RepaymentFrequency is how often a loan installment falls due.
+type RepaymentFrequency string
+
+// Supported repayment frequencies.
+const (
+	FrequencyWeekly   RepaymentFrequency = "weekly"
+	FrequencyBiweekly RepaymentFrequency = "biweekly"
+	FrequencyMonthly  RepaymentFrequency = "monthly"
+)
+
+// PeriodsPerYear returns the number of installments a frequency produces in a year,
+// or 0 if the frequency is unknown.
+func (f RepaymentFrequency) PeriodsPerYear() int {
+	switch f {
+	case FrequencyWeekly:
+		return 52
+	case FrequencyBiweekly:
+		return 26
+	case FrequencyMonthly:
+		return 12
+	}
+	return 0
+}
+
+// DueDate returns the due date of the n-th installment of a schedule starting at start.
+func (f RepaymentFrequency) DueDate(start time.Time, n int) time.Time {
+	switch f {
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case FrequencyBiweekly:
+		return start.AddDate(0, 0, 14*n)
+	}
+	// Monthly installments keep the start day, clamped to the end of shorter months.
+	year, month, day := start.Date()
+	lastDay := time.Date(year, month+time.Month(n)+1, 0, 0, 0, 0, 0, start.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	hour, min, sec := start.Clock()
+	return time.Date(year, month+time.Month(n), day, hour, min, sec, start.Nanosecond(), start.Location())
+}
+
+// AmortizationMethod is how principal and interest are spread across installments.
+type AmortizationMethod string
+
+// Supported amortization methods.
+const (
+	// AmortizationAnnuity produces equal installments, with interest charged on the declining balance.
+	AmortizationAnnuity AmortizationMethod = "annuity"
+	// AmortizationFlat charges interest on the original principal for the whole term.
+	AmortizationFlat AmortizationMethod = "flat"
+)
+
+// IsValid reports whether m is a supported amortization method.
+func (m AmortizationMethod) IsValid() bool {
+	return m == AmortizationAnnuity || m == AmortizationFlat
+}
+
+// Installment is a single scheduled repayment of a loan.
+type Installment struct {
+	Number           int        `json:"number" bson:"number"`
+	DueDate          time.Time  `json:"due_date" bson:"due_date"`
+	Principal        Money      `json:"principal" bson:"principal"`
+	Interest         Money      `json:"interest" bson:"interest"`
+	Fees             Money      `json:"fees" bson:"fees"`
+	Total            Money      `json:"total" bson:"total"`
+	RemainingBalance Money      `json:"remaining_balance" bson:"remaining_balance"`
+	PrincipalPaid    Money      `json:"principal_paid" bson:"principal_paid"`
+	InterestPaid     Money      `json:"interest_paid" bson:"interest_paid"`
+	FeesPaid         Money      `json:"fees_paid" bson:"fees_paid"`
+	Penalties        Money      `json:"penalties" bson:"penalties"` // late fees and penalty interest charged once overdue, net of waivers
+	PenaltiesPaid    Money      `json:"penalties_paid" bson:"penalties_paid"`
+	LateFeeCharged   bool       `json:"late_fee_charged" bson:"late_fee_charged"`
+	PenaltyAccruedTo *time.Time `json:"penalty_accrued_to,omitempty" bson:"penalty_accrued_to,omitempty"` // penalty interest has been charged up to this day
+	PaidAt           *time.Time `json:"paid_at,omitempty" bson:"paid_at,omitempty"`                       // set once the installment is settled in full
+}
+
+// PrincipalDue returns the principal of the installment that is still unpaid.
+func (i Installment) PrincipalDue() Money {
+	return i.Principal.Sub(i.PrincipalPaid)
+}
+
+// InterestDue returns the interest of the installment that is still unpaid.
+func (i Installment) InterestDue() Money {
+	return i.Interest.Sub(i.InterestPaid)
+}
+
+// FeesDue returns the fees charged on the installment that are still unpaid.
+func (i Installment) FeesDue() Money {
+	return i.Fees.Sub(i.FeesPaid)
+}
+
+// PenaltiesDue returns the late fees and penalty interest of the installment that are still unpaid.
+func (i Installment) PenaltiesDue() Money {
+	return i.Penalties.Sub(i.PenaltiesPaid)
+}
+
+// AmountDue returns everything still owed on the installment.
+func (i Installment) AmountDue() Money {
+	return i.PenaltiesDue().Add(i.FeesDue()).Add(i.InterestDue()).Add(i.PrincipalDue())
+}
+
+// IsPaid reports whether the installment has been settled in full.
+func (i Installment) IsPaid() bool {
+	return i.PaidAt != nil
+}
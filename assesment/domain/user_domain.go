@@ -5,6 +5,7 @@ import (
 	//"net/http"
 	"time"
 	"errors"
+	"regexp"
 	"github.com/golang-jwt/jwt"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -25,6 +26,7 @@ type User struct {
 type UserRepository interface {
 	GetUserByID(id primitive.ObjectID) (User, error)
 	GetUserByEmail(email string) (User, error)
+	GetUsersByRole(role string) ([]User, error)
 	Register(user User) error
 	LoginUser(user User) (int, string, error)
 	UpdateUser(user User) error
@@ -66,6 +68,13 @@ func (e *CustomError) Error() string {
 	return e.Message
 }
 
+var emailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// IsValidEmail reports whether email has the shape of an email address.
+func IsValidEmail(email string) bool {
+	return emailPattern.MatchString(email)
+}
+
 // Common errors
 var (
 	ErrUserAlreadyExists = New("user already exists", 409)
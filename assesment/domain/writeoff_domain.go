@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WriteOff records what was taken off the books when a loan was written off.
+type WriteOff struct {
+	Principal    Money              `bson:"principal" json:"principal"`
+	Interest     Money              `bson:"interest" json:"interest"` // interest accrued and not yet received
+	Fees         Money              `bson:"fees" json:"fees"`         // fees and penalties still due
+	Reason       string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	WrittenOffBy primitive.ObjectID `bson:"written_off_by,omitempty" json:"written_off_by,omitempty"`
+	WrittenOffAt time.Time          `bson:"written_off_at" json:"written_off_at"`
+}
+
+// Total returns everything that was written off.
+func (w WriteOff) Total() Money {
+	return w.Principal.Add(w.Interest).Add(w.Fees)
+}
+
+// LoanRecovery is what has been recovered so far on a written-off loan.
+type LoanRecovery struct {
+	LoanID              uint      `json:"loan_id"`
+	WrittenOff          Money     `json:"written_off"`
+	Recovered           Money     `json:"recovered"`
+	ConvertedWrittenOff *Money    `json:"converted_written_off,omitempty"`
+	ConvertedRecovered  *Money    `json:"converted_recovered,omitempty"`
+	WrittenOffAt        time.Time `json:"written_off_at"`
+}
+
+// RecoveryReport summarises recoveries on written-off loans in a single reporting currency.
+// Loans in currencies with no exchange rate are listed unconverted and left out of the totals.
+type RecoveryReport struct {
+	ReportingCurrency     string         `json:"reporting_currency"`
+	GeneratedAt           time.Time      `json:"generated_at"`
+	Loans                 []LoanRecovery `json:"loans"`
+	TotalWrittenOff       Money          `json:"total_written_off"`
+	TotalRecovered        Money          `json:"total_recovered"`
+	RecoveryRate          float64        `json:"recovery_rate"` // recovered as a percentage of what was written off
+	UnconvertedCurrencies []string       `json:"unconverted_currencies,omitempty"`
+}
+
+// Write-off errors
+var (
+	ErrWriteOffReasonRequired = errors.New("a reason is required to write off a loan")
+	ErrLoanNotWrittenOff      = errors.New("recoveries can only be recorded against written-off loans")
+)
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AccrualRepository implements the AccrualRepository interface for MongoDB.
+type AccrualRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewAccrualRepository creates a new instance of AccrualRepository and makes sure a loan
+// can have only one accrual per day, even when accrual runs overlap.
+func NewAccrualRepository(mongoClient *mongo.Client) (domain.AccrualRepository, error) {
+	collection := mongoClient.Database("loan").Collection("interest_accruals")
+	_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "loan_id", Value: 1}, {Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccrualRepository{
+		database:   mongoClient.Database("loan"),
+		collection: collection,
+	}, nil
+}
+
+// UpsertAccrual inserts an accrual unless one exists for the same loan and day, keyed on
+// (loan_id, date), and returns the stored accrual. Losing a race to insert the same day is
+// treated as that day being accrued already.
+func (r *AccrualRepository) UpsertAccrual(accrual domain.InterestAccrual) (domain.InterestAccrual, bool, error) {
+	filter := bson.M{"loan_id": accrual.LoanID, "date": accrual.Date}
+	result, err := r.collection.UpdateOne(
+		context.Background(),
+		filter,
+		bson.M{"$setOnInsert": accrual},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return domain.InterestAccrual{}, false, err
+	}
+	inserted := err == nil && result.UpsertedCount == 1
+
+	var stored domain.InterestAccrual
+	if err := r.collection.FindOne(context.Background(), filter).Decode(&stored); err != nil {
+		return domain.InterestAccrual{}, false, err
+	}
+	return stored, inserted, nil
+}
+
+// MarkAccrualPosted records that an accrual has been posted to the ledger.
+func (r *AccrualRepository) MarkAccrualPosted(id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": id}, bson.M{"$set": bson.M{"posted": true}})
+	return err
+}
+
+// GetUnpostedAccruals retrieves the accruals not yet posted to the ledger, oldest first.
+func (r *AccrualRepository) GetUnpostedAccruals() ([]domain.InterestAccrual, error) {
+	return r.find(bson.M{"posted": false})
+}
+
+// GetAccrualsByLoan retrieves the accruals of a loan, oldest first.
+func (r *AccrualRepository) GetAccrualsByLoan(loanID uint) ([]domain.InterestAccrual, error) {
+	return r.find(bson.M{"loan_id": loanID})
+}
+
+// find runs an accrual query sorted by day.
+func (r *AccrualRepository) find(filter bson.M) ([]domain.InterestAccrual, error) {
+	accruals := []domain.InterestAccrual{}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "loan_id", Value: 1}})
+	cursor, err := r.collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &accruals); err != nil {
+		return nil, err
+	}
+
+	return accruals, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollateralRepository implements the CollateralRepository interface for MongoDB.
+type CollateralRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewCollateralRepository creates a new instance of CollateralRepository.
+func NewCollateralRepository(mongoClient *mongo.Client) domain.CollateralRepository {
+	return &CollateralRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("collateral"),
+	}
+}
+
+// CreateCollateral inserts a new collateral into the MongoDB collection.
+func (r *CollateralRepository) CreateCollateral(collateral domain.Collateral) error {
+	_, err := r.collection.InsertOne(context.Background(), collateral)
+	return err
+}
+
+// GetCollateralByID retrieves a collateral by its ID.
+func (r *CollateralRepository) GetCollateralByID(id primitive.ObjectID) (domain.Collateral, error) {
+	var collateral domain.Collateral
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&collateral)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Collateral{}, domain.ErrCollateralNotFound
+	}
+	return collateral, err
+}
+
+// GetCollateralByOwner retrieves the collateral of a user, oldest first.
+func (r *CollateralRepository) GetCollateralByOwner(ownerID primitive.ObjectID) ([]domain.Collateral, error) {
+	return r.find(bson.M{"owner_id": ownerID})
+}
+
+// GetCollateralByLoan retrieves the collateral pledged to a loan, oldest first.
+func (r *CollateralRepository) GetCollateralByLoan(loanID uint) ([]domain.Collateral, error) {
+	return r.find(bson.M{"loan_id": loanID})
+}
+
+// UpdateCollateral replaces the stored fields of an existing collateral.
+func (r *CollateralRepository) UpdateCollateral(collateral domain.Collateral) error {
+	result, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": collateral.ID}, bson.M{"$set": collateral})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCollateralNotFound
+	}
+	return nil
+}
+
+// DeleteCollateral deletes a collateral by its ID.
+func (r *CollateralRepository) DeleteCollateral(id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrCollateralNotFound
+	}
+	return nil
+}
+
+// find retrieves the collateral matching filter, oldest first.
+func (r *CollateralRepository) find(filter bson.M) ([]domain.Collateral, error) {
+	collateral := []domain.Collateral{}
+
+	findOptions := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := r.collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &collateral); err != nil {
+		return nil, err
+	}
+
+	return collateral, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CounterOfferRepository implements the CounterOfferRepository interface for MongoDB.
+type CounterOfferRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewCounterOfferRepository creates a new instance of CounterOfferRepository.
+func NewCounterOfferRepository(mongoClient *mongo.Client) domain.CounterOfferRepository {
+	return &CounterOfferRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("counter_offers"),
+	}
+}
+
+// CreateCounterOffer inserts a new counter-offer into the MongoDB collection.
+func (r *CounterOfferRepository) CreateCounterOffer(offer domain.CounterOffer) error {
+	_, err := r.collection.InsertOne(context.Background(), offer)
+	return err
+}
+
+// GetCounterOfferByID retrieves a counter-offer by its ID.
+func (r *CounterOfferRepository) GetCounterOfferByID(id primitive.ObjectID) (domain.CounterOffer, error) {
+	var offer domain.CounterOffer
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&offer)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.CounterOffer{}, domain.ErrCounterOfferNotFound
+	}
+	return offer, err
+}
+
+// GetCounterOffersByLoan retrieves the counter-offers made on a loan, oldest first.
+func (r *CounterOfferRepository) GetCounterOffersByLoan(loanID uint) ([]domain.CounterOffer, error) {
+	return r.find(bson.M{"loan_id": loanID})
+}
+
+// GetExpiredCounterOffers retrieves the pending counter-offers whose expiry has passed by now.
+func (r *CounterOfferRepository) GetExpiredCounterOffers(now time.Time) ([]domain.CounterOffer, error) {
+	return r.find(bson.M{"status": domain.CounterOfferPending, "expires_at": bson.M{"$lte": now}})
+}
+
+// UpdateCounterOffer replaces the stored fields of an existing counter-offer.
+func (r *CounterOfferRepository) UpdateCounterOffer(offer domain.CounterOffer) error {
+	result, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": offer.ID}, bson.M{"$set": offer})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCounterOfferNotFound
+	}
+	return nil
+}
+
+// find retrieves the counter-offers matching filter, oldest first.
+func (r *CounterOfferRepository) find(filter bson.M) ([]domain.CounterOffer, error) {
+	offers := []domain.CounterOffer{}
+
+	findOptions := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := r.collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &offers); err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
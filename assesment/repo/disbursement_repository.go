@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DisbursementRepository implements the DisbursementRepository interface for MongoDB.
+type DisbursementRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewDisbursementRepository creates a new instance of DisbursementRepository.
+func NewDisbursementRepository(mongoClient *mongo.Client) domain.DisbursementRepository {
+	return &DisbursementRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("disbursements"),
+	}
+}
+
+// CreateDisbursement inserts a new disbursement into the MongoDB collection.
+func (r *DisbursementRepository) CreateDisbursement(disbursement domain.Disbursement) error {
+	_, err := r.collection.InsertOne(context.Background(), disbursement)
+	return err
+}
+
+// GetDisbursementByID retrieves a disbursement by its ID.
+func (r *DisbursementRepository) GetDisbursementByID(id primitive.ObjectID) (domain.Disbursement, error) {
+	var disbursement domain.Disbursement
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&disbursement)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Disbursement{}, domain.ErrDisbursementNotFound
+	}
+	return disbursement, err
+}
+
+// GetDisbursementsByLoan retrieves the disbursements of a loan, oldest first.
+func (r *DisbursementRepository) GetDisbursementsByLoan(loanID uint) ([]domain.Disbursement, error) {
+	disbursements := []domain.Disbursement{}
+
+	findOptions := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := r.collection.Find(context.Background(), bson.M{"loan_id": loanID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &disbursements); err != nil {
+		return nil, err
+	}
+
+	return disbursements, nil
+}
+
+// UpdateDisbursement replaces the stored fields of an existing disbursement.
+func (r *DisbursementRepository) UpdateDisbursement(disbursement domain.Disbursement) error {
+	result, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": disbursement.ID}, bson.M{"$set": disbursement})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrDisbursementNotFound
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DocumentRepository implements the LoanDocumentRepository interface for MongoDB.
+type DocumentRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewDocumentRepository creates a new instance of DocumentRepository.
+func NewDocumentRepository(mongoClient *mongo.Client) domain.LoanDocumentRepository {
+	return &DocumentRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("loan_documents"),
+	}
+}
+
+// CreateDocument inserts a new loan document into the MongoDB collection.
+func (r *DocumentRepository) CreateDocument(document domain.LoanDocument) error {
+	_, err := r.collection.InsertOne(context.Background(), document)
+	return err
+}
+
+// GetDocumentByID retrieves a loan document by its ID.
+func (r *DocumentRepository) GetDocumentByID(id primitive.ObjectID) (domain.LoanDocument, error) {
+	var document domain.LoanDocument
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&document)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.LoanDocument{}, domain.ErrDocumentNotFound
+	}
+	return document, err
+}
+
+// GetDocumentsByLoan retrieves the documents of a loan, oldest first.
+func (r *DocumentRepository) GetDocumentsByLoan(loanID uint) ([]domain.LoanDocument, error) {
+	documents := []domain.LoanDocument{}
+
+	findOptions := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := r.collection.Find(context.Background(), bson.M{"loan_id": loanID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &documents); err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+// UpdateDocument replaces the stored fields of an existing loan document.
+func (r *DocumentRepository) UpdateDocument(document domain.LoanDocument) error {
+	result, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": document.ID}, bson.M{"$set": document})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrDocumentNotFound
+	}
+	return nil
+}
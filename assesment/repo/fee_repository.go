@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FeeRepository implements the FeeRepository interface for MongoDB.
+type FeeRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewFeeRepository creates a new instance of FeeRepository.
+func NewFeeRepository(mongoClient *mongo.Client) domain.FeeRepository {
+	return &FeeRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("loan_fees"),
+	}
+}
+
+// CreateFee inserts a new fee into the MongoDB collection.
+func (r *FeeRepository) CreateFee(fee domain.LoanFee) error {
+	_, err := r.collection.InsertOne(context.Background(), fee)
+	return err
+}
+
+// GetFeeByID retrieves a fee by its ID.
+func (r *FeeRepository) GetFeeByID(id primitive.ObjectID) (domain.LoanFee, error) {
+	var fee domain.LoanFee
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&fee)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.LoanFee{}, domain.ErrFeeNotFound
+	}
+	return fee, err
+}
+
+// GetFeesByLoan retrieves the fees charged on a loan, oldest first.
+func (r *FeeRepository) GetFeesByLoan(loanID uint) ([]domain.LoanFee, error) {
+	fees := []domain.LoanFee{}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "charged_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(context.Background(), bson.M{"loan_id": loanID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &fees); err != nil {
+		return nil, err
+	}
+
+	return fees, nil
+}
+
+// UpdateFee replaces the stored fields of an existing fee.
+func (r *FeeRepository) UpdateFee(fee domain.LoanFee) error {
+	result, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": fee.ID}, bson.M{"$set": fee})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrFeeNotFound
+	}
+	return nil
+}
+
+// DeleteFee removes a fee, for a charge that could not be posted to the ledger.
+func (r *FeeRepository) DeleteFee(id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrFeeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoanHistoryRepository implements the LoanHistoryRepository interface for MongoDB.
+type LoanHistoryRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewLoanHistoryRepository creates a new instance of LoanHistoryRepository.
+func NewLoanHistoryRepository(mongoClient *mongo.Client) domain.LoanHistoryRepository {
+	return &LoanHistoryRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("loan_history"),
+	}
+}
+
+// AppendEvent inserts a new event into the MongoDB collection.
+func (r *LoanHistoryRepository) AppendEvent(event domain.LoanEvent) error {
+	_, err := r.collection.InsertOne(context.Background(), event)
+	return err
+}
+
+// GetEventsByLoan retrieves the history of a loan, oldest first. Events recorded in the same
+// instant keep the order they were appended in.
+func (r *LoanHistoryRepository) GetEventsByLoan(loanID uint) ([]domain.LoanEvent, error) {
+	events := []domain.LoanEvent{}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "recorded_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(context.Background(), bson.M{"loan_id": loanID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobRunRepository implements the JobRunRepository interface for MongoDB.
+type JobRunRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewJobRunRepository creates a new instance of JobRunRepository.
+func NewJobRunRepository(mongoClient *mongo.Client) domain.JobRunRepository {
+	return &JobRunRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("job_runs"),
+	}
+}
+
+// CreateRun inserts a new job run into the MongoDB collection.
+func (r *JobRunRepository) CreateRun(run domain.JobRun) error {
+	_, err := r.collection.InsertOne(context.Background(), run)
+	return err
+}
+
+// UpdateRun replaces the stored progress of a job run.
+func (r *JobRunRepository) UpdateRun(run domain.JobRun) error {
+	_, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": run.ID}, bson.M{"$set": run})
+	return err
+}
+
+// GetLatestRun retrieves the most recently started run of a job.
+func (r *JobRunRepository) GetLatestRun(job string) (domain.JobRun, error) {
+	var run domain.JobRun
+	findOptions := options.FindOne().SetSort(bson.M{"started_at": -1})
+	err := r.collection.FindOne(context.Background(), bson.M{"job": job}, findOptions).Decode(&run)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.JobRun{}, domain.ErrJobRunNotFound
+	}
+	return run, err
+}
+
+// GetRuns retrieves the most recent runs of a job, newest first.
+func (r *JobRunRepository) GetRuns(job string, limit int) ([]domain.JobRun, error) {
+	runs := []domain.JobRun{}
+
+	findOptions := options.Find().SetSort(bson.M{"started_at": -1}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(context.Background(), bson.M{"job": job}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &runs); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
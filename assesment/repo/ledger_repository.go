@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LedgerRepository implements the LedgerRepository interface for MongoDB.
+type LedgerRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewLedgerRepository creates a new instance of LedgerRepository and makes sure a loan can
+// have only one entry of each type per reference, so posting the same payment, fee or
+// accrual again cannot book it twice.
+func NewLedgerRepository(mongoClient *mongo.Client) (domain.LedgerRepository, error) {
+	collection := mongoClient.Database("loan").Collection("journal_entries")
+	_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "loan_id", Value: 1}, {Key: "type", Value: 1}, {Key: "reference", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"reference": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &LedgerRepository{
+		database:   mongoClient.Database("loan"),
+		collection: collection,
+	}, nil
+}
+
+// PostEntry appends a journal entry to the MongoDB collection. Entries are never updated.
+func (r *LedgerRepository) PostEntry(entry domain.JournalEntry) error {
+	_, err := r.collection.InsertOne(context.Background(), entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrEntryAlreadyPosted
+	}
+	return err
+}
+
+// GetEntriesByLoan retrieves the journal entries posted for a loan, oldest first.
+func (r *LedgerRepository) GetEntriesByLoan(loanID uint) ([]domain.JournalEntry, error) {
+	return r.find(bson.M{"loan_id": loanID})
+}
+
+// GetEntries retrieves the journal entries posted within [from, to), oldest first.
+func (r *LedgerRepository) GetEntries(from, to time.Time) ([]domain.JournalEntry, error) {
+	return r.find(bson.M{"posted_at": bson.M{"$gte": from, "$lt": to}})
+}
+
+// GetAccountTotals sums every posting per account and currency.
+func (r *LedgerRepository) GetAccountTotals() ([]domain.AccountTotal, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$unwind", Value: "$postings"}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"account":  "$postings.account",
+				"currency": bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$postings.debit.amount", 0}}, "$postings.debit.currency", "$postings.credit.currency"}},
+			},
+			"debit":  bson.M{"$sum": "$postings.debit.amount"},
+			"credit": bson.M{"$sum": "$postings.credit.amount"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"account":  "$_id.account",
+			"currency": "$_id.currency",
+			"debit":    1,
+			"credit":   1,
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := []domain.AccountTotal{}
+	if err := cursor.All(context.Background(), &totals); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// find runs a journal entry query sorted by posting date.
+func (r *LedgerRepository) find(filter bson.M) ([]domain.JournalEntry, error) {
+	entries := []domain.JournalEntry{}
+
+	findOptions := options.Find().SetSort(bson.M{"posted_at": 1})
+	cursor, err := r.collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
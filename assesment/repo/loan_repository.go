@@ -6,6 +6,7 @@ import (
     "go.mongodb.org/mongo-driver/mongo"
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/bson/primitive"
+    "strings"
     "time"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -15,12 +16,22 @@ type LoanRepository struct {
     collection *mongo.Collection
 }
 
-// NewLoanRepository creates a new instance of LoanRepository.
-func NewLoanRepository(mongoClient *mongo.Client) domain.LoanRepository {
+// NewLoanRepository creates a new instance of LoanRepository and makes sure no two loans
+// share an ID, even when two applications under the same ID arrive at once.
+func NewLoanRepository(mongoClient *mongo.Client) (domain.LoanRepository, error) {
+    collection := mongoClient.Database("loan").Collection("loans")
+    _, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+        Keys:    bson.D{{Key: "id", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    if err != nil {
+        return nil, err
+    }
+
     return &LoanRepository{
         database:   mongoClient.Database("loan"),
-        collection: mongoClient.Database("loan").Collection("loans"),
-    }
+        collection: collection,
+    }, nil
 }
 
 // ApplyForLoan inserts a new loan application into the MongoDB collection.
@@ -28,21 +39,21 @@ func (r *LoanRepository) ApplyForLoan(loan domain.Loan) error {
     loan.CreatedAt = time.Now()
     loan.UpdatedAt = time.Now()
     _, err := r.collection.InsertOne(context.Background(), loan)
+    if mongo.IsDuplicateKeyError(err) {
+        return domain.ErrLoanIDTaken
+    }
     return err
 }
 
 // GetLoanByID retrieves a loan by its ID from the MongoDB collection.
 func (r *LoanRepository) GetLoanByID(id uint) (domain.Loan, error) {
     var loan domain.Loan
-    objectID := primitive.NewObjectIDFromTimestamp(time.Unix(int64(id), 0)) // assuming the id is a Unix timestamp
-
-    err := r.collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&loan)
+    err := r.collection.FindOne(context.Background(), bson.M{"id": id}).Decode(&loan)
     return loan, err
 }
 
-// GetAllLoans retrieves all loans from the MongoDB collection, optionally filtering by status and sorting.
-// GetAllLoans retrieves all loans from the MongoDB collection, optionally filtering by status and sorting.
-func (r *LoanRepository) GetAllLoans(status, order string) ([]domain.Loan, error) {
+// GetAllLoans retrieves all loans from the MongoDB collection, optionally filtering by status and currency, and sorting.
+func (r *LoanRepository) GetAllLoans(status, order, currency string) ([]domain.Loan, error) {
     var loans []domain.Loan
     filter := bson.M{}
 
@@ -51,6 +62,11 @@ func (r *LoanRepository) GetAllLoans(status, order string) ([]domain.Loan, error
         filter["status"] = status
     }
 
+    // Apply currency filter if provided
+    if currency != "" {
+        filter["amount.currency"] = strings.ToUpper(currency)
+    }
+
     // Set the sorting order
     sortOrder := 1
     if order == "desc" {
@@ -73,22 +89,149 @@ func (r *LoanRepository) GetAllLoans(status, order string) ([]domain.Loan, error
 
     return loans, nil
 }
-// UpdateLoanStatus updates the status of a loan in the MongoDB collection.
-func (r *LoanRepository) UpdateLoanStatus(id uint, status string) error {
-    objectID := primitive.NewObjectIDFromTimestamp(time.Unix(int64(id), 0)) // assuming the id is a Unix timestamp
+// GetLoansByUser retrieves every loan of a borrower from the MongoDB collection, oldest first.
+func (r *LoanRepository) GetLoansByUser(userID primitive.ObjectID) ([]domain.Loan, error) {
+    loans := []domain.Loan{}
+
+    findOptions := options.Find().SetSort(bson.M{"created_at": 1})
+    cursor, err := r.collection.Find(context.Background(), bson.M{"user_id": userID}, findOptions)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := cursor.All(context.Background(), &loans); err != nil {
+        return nil, err
+    }
+
+    return loans, nil
+}
 
-    _, err := r.collection.UpdateOne(
+// GetLoansByStatus retrieves the loans in any of the given states from the MongoDB collection, oldest first.
+func (r *LoanRepository) GetLoansByStatus(statuses ...domain.LoanStatus) ([]domain.Loan, error) {
+    loans := []domain.Loan{}
+
+    findOptions := options.Find().SetSort(bson.M{"created_at": 1})
+    cursor, err := r.collection.Find(context.Background(), bson.M{"status": bson.M{"$in": statuses}}, findOptions)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := cursor.All(context.Background(), &loans); err != nil {
+        return nil, err
+    }
+
+    return loans, nil
+}
+
+// GetLoansByParty retrieves the loans on which a user, by ID or email, is a co-borrower or guarantor, oldest first.
+func (r *LoanRepository) GetLoansByParty(userID primitive.ObjectID, email string) ([]domain.Loan, error) {
+    loans := []domain.Loan{}
+
+    filter := bson.M{"parties": bson.M{"$elemMatch": bson.M{"$or": bson.A{
+        bson.M{"user_id": userID},
+        bson.M{"email": strings.ToLower(email)},
+    }}}}
+    findOptions := options.Find().SetSort(bson.M{"created_at": 1})
+    cursor, err := r.collection.Find(context.Background(), filter, findOptions)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := cursor.All(context.Background(), &loans); err != nil {
+        return nil, err
+    }
+
+    return loans, nil
+}
+
+// GetLoanByConsentToken retrieves the loan with a party holding the given consent token.
+func (r *LoanRepository) GetLoanByConsentToken(token string) (domain.Loan, error) {
+    var loan domain.Loan
+    err := r.collection.FindOne(context.Background(), bson.M{"parties.consent_token": token}).Decode(&loan)
+    return loan, err
+}
+
+// GetLastAssignedLoan retrieves the loan most recently assigned to a reviewer.
+func (r *LoanRepository) GetLastAssignedLoan() (domain.Loan, error) {
+    var loan domain.Loan
+    findOptions := options.FindOne().SetSort(bson.M{"review.assigned_at": -1})
+    err := r.collection.FindOne(context.Background(), bson.M{"review.assigned_at": bson.M{"$exists": true}}, findOptions).Decode(&loan)
+    return loan, err
+}
+
+// UpdateLoanStatus updates the status of a loan in the MongoDB collection, unless the loan
+// was changed since it was read, and bumps its version.
+func (r *LoanRepository) UpdateLoanStatus(loan *domain.Loan, status domain.LoanStatus) error {
+    now := time.Now()
+    result, err := r.collection.UpdateOne(
         context.Background(),
-        bson.M{"_id": objectID},
-        bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}},
+        versionFilter(*loan),
+        bson.M{"$set": bson.M{"status": status, "updated_at": now}, "$inc": bson.M{"version": 1}},
     )
-    return err
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return r.missingOrModified(loan.ID)
+    }
+
+    loan.Status = status
+    loan.UpdatedAt = now
+    loan.Version++
+    return nil
+}
+
+// UpdateLoan replaces the stored fields of an existing loan in the MongoDB collection, unless
+// the loan was changed since it was read, and bumps its version.
+func (r *LoanRepository) UpdateLoan(loan *domain.Loan) error {
+    stored := *loan
+    stored.UpdatedAt = time.Now()
+    stored.Version++
+    result, err := r.collection.UpdateOne(
+        context.Background(),
+        versionFilter(*loan),
+        bson.M{"$set": stored},
+    )
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return r.missingOrModified(loan.ID)
+    }
+
+    *loan = stored
+    return nil
+}
+
+// versionFilter matches a loan only while it still has the version it was read with. Loans
+// stored before versions were kept have none and match version 0.
+func versionFilter(loan domain.Loan) bson.M {
+    if loan.Version == 0 {
+        return bson.M{"id": loan.ID, "version": bson.M{"$in": bson.A{0, nil}}}
+    }
+    return bson.M{"id": loan.ID, "version": loan.Version}
+}
+
+// missingOrModified explains why an update of the loan with the given ID matched nothing.
+func (r *LoanRepository) missingOrModified(id uint) error {
+    count, err := r.collection.CountDocuments(context.Background(), bson.M{"id": id})
+    if err != nil {
+        return err
+    }
+    if count == 0 {
+        return mongo.ErrNoDocuments
+    }
+    return domain.ErrLoanModified
 }
 
 // DeleteLoan deletes a loan by its ID from the MongoDB collection.
 func (r *LoanRepository) DeleteLoan(id uint) error {
-    objectID := primitive.NewObjectIDFromTimestamp(time.Unix(int64(id), 0)) // assuming the id is a Unix timestamp
-
-    _, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": objectID})
-    return err
+    result, err := r.collection.DeleteOne(context.Background(), bson.M{"id": id})
+    if err != nil {
+        return err
+    }
+    if result.DeletedCount == 0 {
+        return mongo.ErrNoDocuments
+    }
+    return nil
 }
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ModificationRepository implements the ModificationRepository interface for MongoDB.
+type ModificationRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewModificationRepository creates a new instance of ModificationRepository.
+func NewModificationRepository(mongoClient *mongo.Client) domain.ModificationRepository {
+	return &ModificationRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("loan_modifications"),
+	}
+}
+
+// CreateModification inserts a new loan modification into the MongoDB collection.
+func (r *ModificationRepository) CreateModification(modification domain.LoanModification) error {
+	_, err := r.collection.InsertOne(context.Background(), modification)
+	return err
+}
+
+// GetModificationsByLoan retrieves the modifications of a loan, oldest first.
+func (r *ModificationRepository) GetModificationsByLoan(loanID uint) ([]domain.LoanModification, error) {
+	modifications := []domain.LoanModification{}
+
+	findOptions := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := r.collection.Find(context.Background(), bson.M{"loan_id": loanID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &modifications); err != nil {
+		return nil, err
+	}
+
+	return modifications, nil
+}
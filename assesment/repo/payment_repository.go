@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PaymentRepository implements the PaymentRepository interface for MongoDB.
+type PaymentRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewPaymentRepository creates a new instance of PaymentRepository.
+func NewPaymentRepository(mongoClient *mongo.Client) domain.PaymentRepository {
+	return &PaymentRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("payments"),
+	}
+}
+
+// CreatePayment inserts a new payment into the MongoDB collection.
+func (r *PaymentRepository) CreatePayment(payment domain.Payment) error {
+	_, err := r.collection.InsertOne(context.Background(), payment)
+	return err
+}
+
+// GetPaymentsByLoan retrieves the payments recorded against a loan, oldest first.
+func (r *PaymentRepository) GetPaymentsByLoan(loanID uint) ([]domain.Payment, error) {
+	return r.find(bson.M{"loan_id": loanID})
+}
+
+// GetPaymentsByUser retrieves the payments made by a user, oldest first.
+func (r *PaymentRepository) GetPaymentsByUser(userID primitive.ObjectID) ([]domain.Payment, error) {
+	return r.find(bson.M{"user_id": userID})
+}
+
+// find runs a payment query sorted by payment date.
+func (r *PaymentRepository) find(filter bson.M) ([]domain.Payment, error) {
+	payments := []domain.Payment{}
+
+	findOptions := options.Find().SetSort(bson.M{"paid_at": 1})
+	cursor, err := r.collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &payments); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProductRepository implements the ProductRepository interface for MongoDB.
+type ProductRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewProductRepository creates a new instance of ProductRepository.
+func NewProductRepository(mongoClient *mongo.Client) domain.ProductRepository {
+	return &ProductRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("products"),
+	}
+}
+
+// CreateProduct inserts a new loan product into the MongoDB collection.
+func (r *ProductRepository) CreateProduct(product domain.LoanProduct) error {
+	_, err := r.collection.InsertOne(context.Background(), product)
+	return err
+}
+
+// GetProductByID retrieves a loan product by its ID.
+func (r *ProductRepository) GetProductByID(id primitive.ObjectID) (domain.LoanProduct, error) {
+	var product domain.LoanProduct
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&product)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.LoanProduct{}, domain.ErrProductNotFound
+	}
+	return product, err
+}
+
+// GetProducts retrieves all loan products sorted by name, optionally only the active ones.
+func (r *ProductRepository) GetProducts(activeOnly bool) ([]domain.LoanProduct, error) {
+	filter := bson.M{}
+	if activeOnly {
+		filter["active"] = true
+	}
+
+	findOptions := options.Find().SetSort(bson.M{"name": 1})
+	cursor, err := r.collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	products := []domain.LoanProduct{}
+	if err := cursor.All(context.Background(), &products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// UpdateProduct replaces the stored fields of an existing loan product.
+func (r *ProductRepository) UpdateProduct(product domain.LoanProduct) error {
+	result, err := r.collection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": product.ID},
+		bson.M{"$set": product},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrProductNotFound
+	}
+	return nil
+}
+
+// DeleteProduct removes a loan product by its ID.
+func (r *ProductRepository) DeleteProduct(id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrProductNotFound
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RejectionReasonRepository implements the RejectionReasonRepository interface for MongoDB.
+type RejectionReasonRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewRejectionReasonRepository creates a new instance of RejectionReasonRepository.
+func NewRejectionReasonRepository(mongoClient *mongo.Client) domain.RejectionReasonRepository {
+	return &RejectionReasonRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("rejection_reasons"),
+	}
+}
+
+// CreateReason inserts a new rejection reason, keyed by its code.
+func (r *RejectionReasonRepository) CreateReason(reason domain.RejectionReason) error {
+	_, err := r.collection.InsertOne(context.Background(), reason)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrDuplicateRejectionReason
+	}
+	return err
+}
+
+// GetReasonByCode retrieves a rejection reason by its code.
+func (r *RejectionReasonRepository) GetReasonByCode(code string) (domain.RejectionReason, error) {
+	var reason domain.RejectionReason
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": code}).Decode(&reason)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.RejectionReason{}, domain.ErrRejectionReasonNotFound
+	}
+	return reason, err
+}
+
+// GetReasons retrieves the rejection reasons, or only the active ones, ordered by code.
+func (r *RejectionReasonRepository) GetReasons(activeOnly bool) ([]domain.RejectionReason, error) {
+	reasons := []domain.RejectionReason{}
+
+	filter := bson.M{}
+	if activeOnly {
+		filter["active"] = true
+	}
+	findOptions := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &reasons); err != nil {
+		return nil, err
+	}
+
+	return reasons, nil
+}
+
+// UpdateReason replaces the description and status of an existing rejection reason.
+func (r *RejectionReasonRepository) UpdateReason(reason domain.RejectionReason) error {
+	result, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": reason.Code}, bson.M{"$set": bson.M{
+		"description": reason.Description,
+		"active":      reason.Active,
+		"updated_at":  reason.UpdatedAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrRejectionReasonNotFound
+	}
+	return nil
+}
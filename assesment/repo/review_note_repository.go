@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"assesment/domain"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReviewNoteRepository implements the ReviewNoteRepository interface for MongoDB.
+type ReviewNoteRepository struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewReviewNoteRepository creates a new instance of ReviewNoteRepository.
+func NewReviewNoteRepository(mongoClient *mongo.Client) domain.ReviewNoteRepository {
+	return &ReviewNoteRepository{
+		database:   mongoClient.Database("loan"),
+		collection: mongoClient.Database("loan").Collection("review_notes"),
+	}
+}
+
+// CreateReviewNote inserts a new review note into the MongoDB collection.
+func (r *ReviewNoteRepository) CreateReviewNote(note domain.ReviewNote) error {
+	_, err := r.collection.InsertOne(context.Background(), note)
+	return err
+}
+
+// GetReviewNotesByLoan retrieves the review notes of a loan, oldest first.
+func (r *ReviewNoteRepository) GetReviewNotesByLoan(loanID uint) ([]domain.ReviewNote, error) {
+	notes := []domain.ReviewNote{}
+
+	findOptions := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := r.collection.Find(context.Background(), bson.M{"loan_id": loanID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &notes); err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
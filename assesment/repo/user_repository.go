@@ -122,6 +122,19 @@ func (ur *UserRepository) GetUserByEmail(email string) (domain.User, error) {
 	return user, nil
 }
 
+// GetUsersByRole retrieves the users with the given role.
+func (ur *UserRepository) GetUsersByRole(role string) ([]domain.User, error) {
+	users := []domain.User{}
+	cursor, err := ur.collection.Find(context.Background(), bson.M{"role": role})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.Background(), &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // UpdateUserPassword updates the user's password in the database.
 func (ur *UserRepository) UpdateUserPassword(user domain.User) error {
 	_, err := ur.collection.UpdateOne(
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// accrualJob is the name accrual runs are recorded under.
+const accrualJob = "interest_accrual"
+
+// accrualProgressEvery is how many loans are processed between progress updates of a run.
+const accrualProgressEvery = 25
+
+type accrualUsecase struct {
+	loanRepo    domain.LoanRepository
+	accrualRepo domain.AccrualRepository
+	jobRunRepo  domain.JobRunRepository
+	ledger      domain.LedgerUsecase
+	history     domain.LoanHistoryRepository
+	running     sync.Mutex
+}
+
+// NewAccrualUsecase creates a new instance of AccrualUsecase.
+func NewAccrualUsecase(loanRepo domain.LoanRepository, accrualRepo domain.AccrualRepository, jobRunRepo domain.JobRunRepository, ledger domain.LedgerUsecase, history domain.LoanHistoryRepository) domain.AccrualUsecase {
+	return &accrualUsecase{
+		loanRepo:    loanRepo,
+		accrualRepo: accrualRepo,
+		jobRunRepo:  jobRunRepo,
+		ledger:      ledger,
+		history:     history,
+	}
+}
+
+// RunAccrual accrues daily interest on every loan being repaid for each day after the last
+// one it was accrued for, up to and including through. Accruals are keyed by loan and day,
+// so re-running, even after a crash, never accrues a day twice. The run and its progress
+// are recorded as a JobRun.
+func (uc *accrualUsecase) RunAccrual(through time.Time) (domain.JobRun, error) {
+	if !uc.running.TryLock() {
+		return domain.JobRun{}, domain.ErrJobRunning
+	}
+	defer uc.running.Unlock()
+
+	through = accrualDay(through)
+	if !through.Before(accrualDay(time.Now())) {
+		return domain.JobRun{}, domain.ErrAccrualDateInFuture
+	}
+
+	run := domain.JobRun{
+		ID:           primitive.NewObjectID(),
+		Job:          accrualJob,
+		BusinessDate: through,
+		Status:       domain.JobRunRunning,
+		StartedAt:    time.Now(),
+	}
+	if err := uc.jobRunRepo.CreateRun(run); err != nil {
+		return domain.JobRun{}, err
+	}
+
+	if err := uc.accrueAll(&run, through); err != nil {
+		run.Status = domain.JobRunFailed
+		run.Errors = append(run.Errors, err.Error())
+	} else if len(run.Errors) > 0 {
+		run.Status = domain.JobRunPartial
+	} else {
+		run.Status = domain.JobRunSucceeded
+	}
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	if err := uc.jobRunRepo.UpdateRun(run); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// accrueAll posts the accruals an interrupted run left out of the ledger, then accrues
+// every loan being repaid. Failures of single loans are recorded on the run.
+func (uc *accrualUsecase) accrueAll(run *domain.JobRun, through time.Time) error {
+	unposted, err := uc.accrualRepo.GetUnpostedAccruals()
+	if err != nil {
+		return err
+	}
+	for _, accrual := range unposted {
+		if err := uc.post(accrual); err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("accrual %s: %v", accrual.ID.Hex(), err))
+		}
+	}
+
+	loans, err := uc.loanRepo.GetLoansByStatus(domain.LoanStatusActive, domain.LoanStatusDelinquent, domain.LoanStatusDefaulted)
+	if err != nil {
+		return err
+	}
+	run.LoansTotal = len(loans)
+
+	for i, loan := range loans {
+		created, err := uc.accrueLoan(loan, through)
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("loan %d: %v", loan.ID, err))
+		}
+		run.Created += created
+		run.LoansProcessed++
+
+		if (i+1)%accrualProgressEvery == 0 {
+			if err := uc.jobRunRepo.UpdateRun(*run); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// accrueLoan accrues interest on a loan's outstanding principal for each day after
+// InterestAccruedTo up to through, and returns how many accruals it created. A loan that
+// was never accrued starts on through. The loan is only updated once every day has been
+// recorded, so an interrupted loan is picked up again from the same day; the update is
+// then added to the loan's history.
+func (uc *accrualUsecase) accrueLoan(loan domain.Loan, through time.Time) (int, error) {
+	from := accrualStart(loan)
+	before := accrualState(loan)
+
+	dayCount := loan.DayCount
+	if dayCount == "" {
+		dayCount = domain.DayCountActual365
+	}
+	rate := domain.PercentRate(loan.InterestRate)
+
+	created, days := 0, 0
+	for day := from; !day.After(through); day = day.AddDate(0, 0, 1) {
+		principal := outstandingPrincipal(loan)
+		if !principal.IsPositive() {
+			break
+		}
+
+		accrual, isNew, err := uc.accrualRepo.UpsertAccrual(domain.InterestAccrual{
+			ID:        primitive.NewObjectID(),
+			LoanID:    loan.ID,
+			Date:      day,
+			Principal: principal,
+			Rate:      loan.InterestRate,
+			DayCount:  dayCount,
+			Amount:    principal.Mul(new(big.Rat).Mul(rate, dayCount.YearFraction(day.AddDate(0, 0, -1), day))),
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return created, err
+		}
+		if isNew {
+			created++
+		}
+		if !accrual.Posted {
+			if err := uc.post(accrual); err != nil {
+				return created, err
+			}
+		}
+
+		accruedTo := day
+		loan.AccruedInterest = loan.AccruedInterest.Add(accrual.Amount)
+		loan.InterestAccruedTo = &accruedTo
+		days++
+	}
+
+	if days == 0 {
+		return created, nil
+	}
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return created, err
+	}
+	return created, recordEvent(uc.history, domain.LoanEvent{
+		LoanID: loan.ID,
+		Type:   domain.LoanEventInterestAccrued,
+		Before: before,
+		After:  accrualState(loan),
+		Detail: "through " + loan.InterestAccruedTo.Format(time.DateOnly),
+	})
+}
+
+// post records an accrual in the ledger and marks it posted. The entry is referenced by the
+// accrual, so an accrual posted by a run that stopped before marking it is not posted again.
+func (uc *accrualUsecase) post(accrual domain.InterestAccrual) error {
+	if accrual.Amount.IsPositive() {
+		err := uc.ledger.PostInterestAccrual(accrual.LoanID, accrual.Amount, accrual.ID.Hex())
+		if err != nil && !errors.Is(err, domain.ErrEntryAlreadyPosted) {
+			return err
+		}
+	}
+	return uc.accrualRepo.MarkAccrualPosted(accrual.ID)
+}
+
+// GetLatestRun retrieves the most recent accrual run, which may still be in progress.
+func (uc *accrualUsecase) GetLatestRun() (domain.JobRun, error) {
+	return uc.jobRunRepo.GetLatestRun(accrualJob)
+}
+
+// GetRuns retrieves the most recent accrual runs, newest first.
+func (uc *accrualUsecase) GetRuns(limit int) ([]domain.JobRun, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	return uc.jobRunRepo.GetRuns(accrualJob, limit)
+}
+
+// GetLoanAccruals retrieves the daily interest accrued on a loan.
+func (uc *accrualUsecase) GetLoanAccruals(loanID uint) ([]domain.InterestAccrual, error) {
+	if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+		return nil, err
+	}
+
+	return uc.accrualRepo.GetAccrualsByLoan(loanID)
+}
+
+// accrualStart returns the first day interest has not been accrued for yet. A loan that has
+// never accrued starts on the day after it was paid out, or after it was created when the
+// payout date was not recorded.
+func accrualStart(loan domain.Loan) time.Time {
+	switch {
+	case loan.InterestAccruedTo != nil:
+		return accrualDay(*loan.InterestAccruedTo).AddDate(0, 0, 1)
+	case loan.DisbursedAt != nil:
+		return accrualDay(*loan.DisbursedAt).AddDate(0, 0, 1)
+	}
+	return accrualDay(loan.CreatedAt).AddDate(0, 0, 1)
+}
+
+// accrualDay returns midnight UTC of the calendar day t falls on, the key accruals are stored under.
+func accrualDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
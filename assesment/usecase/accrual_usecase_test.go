@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"math/big"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryAccruals keeps accruals in memory, one per loan and day like the Mongo repository.
+type memoryAccruals struct {
+	domain.AccrualRepository
+	byDay map[time.Time]domain.InterestAccrual
+}
+
+func (r *memoryAccruals) UpsertAccrual(accrual domain.InterestAccrual) (domain.InterestAccrual, bool, error) {
+	if stored, ok := r.byDay[accrual.Date]; ok {
+		return stored, false, nil
+	}
+	r.byDay[accrual.Date] = accrual
+	return accrual, true, nil
+}
+
+func (r *memoryAccruals) MarkAccrualPosted(id primitive.ObjectID) error {
+	for day, accrual := range r.byDay {
+		if accrual.ID == id {
+			accrual.Posted = true
+			r.byDay[day] = accrual
+		}
+	}
+	return nil
+}
+
+type recordingLedger struct {
+	domain.LedgerUsecase
+	accrued []domain.Money
+}
+
+func (l *recordingLedger) PostInterestAccrual(loanID uint, amount domain.Money, reference string) error {
+	l.accrued = append(l.accrued, amount)
+	return nil
+}
+
+type recordingLoans struct {
+	domain.LoanRepository
+	updated []domain.Loan
+}
+
+func (r *recordingLoans) UpdateLoan(loan *domain.Loan) error {
+	r.updated = append(r.updated, *loan)
+	return nil
+}
+
+func utcDay(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestYearFraction(t *testing.T) {
+	tests := []struct {
+		name       string
+		convention domain.DayCountConvention
+		from, to   time.Time
+		want       *big.Rat
+	}{
+		{"actual/365 one day", domain.DayCountActual365, utcDay(2024, time.March, 1), utcDay(2024, time.March, 2), big.NewRat(1, 365)},
+		{"actual/365 leap day", domain.DayCountActual365, utcDay(2024, time.February, 28), utcDay(2024, time.March, 1), big.NewRat(2, 365)},
+		{"actual/365 ignores the time of day", domain.DayCountActual365, time.Date(2024, time.March, 1, 23, 0, 0, 0, time.UTC), time.Date(2024, time.March, 2, 1, 0, 0, 0, time.UTC), big.NewRat(1, 365)},
+		{"actual/365 leap year", domain.DayCountActual365, utcDay(2024, time.January, 1), utcDay(2025, time.January, 1), big.NewRat(366, 365)},
+		{"30/360 one month", domain.DayCount30360, utcDay(2024, time.January, 15), utcDay(2024, time.February, 15), big.NewRat(30, 360)},
+		{"30/360 end of February to March", domain.DayCount30360, utcDay(2024, time.February, 29), utcDay(2024, time.March, 1), big.NewRat(2, 360)},
+		{"30/360 the 31st earns nothing", domain.DayCount30360, utcDay(2024, time.January, 30), utcDay(2024, time.January, 31), big.NewRat(0, 360)},
+		{"30/360 from the 31st", domain.DayCount30360, utcDay(2024, time.January, 31), utcDay(2024, time.February, 1), big.NewRat(1, 360)},
+		{"30/360 one year", domain.DayCount30360, utcDay(2024, time.January, 1), utcDay(2025, time.January, 1), big.NewRat(1, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.convention.YearFraction(tt.from, tt.to); got.Cmp(tt.want) != 0 {
+				t.Errorf("YearFraction = %s, want %s", got.RatString(), tt.want.RatString())
+			}
+		})
+	}
+}
+
+func TestAccrueLoan(t *testing.T) {
+	tests := []struct {
+		name      string
+		dayCount  domain.DayCountConvention
+		principal int64
+		accrued   time.Time // the day accruals last ran for
+		through   time.Time
+		want      []int64 // accrued per day, in cents
+	}{
+		{"actual/365 across a leap day", domain.DayCountActual365, 365000, utcDay(2024, time.February, 27), utcDay(2024, time.March, 1), []int64{100, 100, 100}},
+		{"30/360 across the end of February", domain.DayCount30360, 360000, utcDay(2024, time.February, 27), utcDay(2024, time.March, 1), []int64{100, 100, 200}},
+		{"30/360 across a 31st", domain.DayCount30360, 360000, utcDay(2024, time.January, 29), utcDay(2024, time.February, 1), []int64{100, 0, 100}},
+		{"defaults to actual/365", "", 365000, utcDay(2024, time.January, 30), utcDay(2024, time.February, 1), []int64{100, 100}},
+		{"already accrued through the day", domain.DayCountActual365, 365000, utcDay(2024, time.March, 1), utcDay(2024, time.March, 1), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accruedTo := tt.accrued
+			loan := domain.Loan{
+				ID:                7,
+				Amount:            usd(tt.principal),
+				InterestRate:      10,
+				DayCount:          tt.dayCount,
+				AccruedInterest:   usd(0),
+				InterestAccruedTo: &accruedTo,
+				Schedule:          []domain.Installment{dueInstallment(1, tt.principal, 0, 0, 0)},
+			}
+			accruals := &memoryAccruals{byDay: map[time.Time]domain.InterestAccrual{}}
+			ledger := &recordingLedger{}
+			loans := &recordingLoans{}
+			history := &memoryHistory{}
+			uc := &accrualUsecase{loanRepo: loans, accrualRepo: accruals, ledger: ledger, history: history}
+
+			created, err := uc.accrueLoan(loan, tt.through)
+			if err != nil {
+				t.Fatalf("accrueLoan: %v", err)
+			}
+			if created != len(tt.want) {
+				t.Errorf("created %d accruals, want %d", created, len(tt.want))
+			}
+
+			total := usd(0)
+			for i, cents := range tt.want {
+				accrual := accruals.byDay[accruedTo.AddDate(0, 0, i+1)]
+				if accrual.Amount != usd(cents) || !accrual.Posted {
+					t.Errorf("day %d: accrued %s (posted %v), want %s posted", i+1, accrual.Amount, accrual.Posted, usd(cents))
+				}
+				total = total.Add(usd(cents))
+			}
+
+			if len(tt.want) == 0 {
+				if len(loans.updated) != 0 || len(history.events) != 0 {
+					t.Errorf("loan updated or its history extended with nothing accrued")
+				}
+				return
+			}
+			if len(loans.updated) != 1 {
+				t.Fatalf("loan updated %d times, want once", len(loans.updated))
+			}
+			updated := loans.updated[0]
+			if updated.AccruedInterest != total || !updated.InterestAccruedTo.Equal(tt.through) {
+				t.Errorf("loan accrued %s through %s, want %s through %s", updated.AccruedInterest, updated.InterestAccruedTo, total, tt.through)
+			}
+
+			if len(history.events) != 1 || history.events[0].Type != domain.LoanEventInterestAccrued {
+				t.Fatalf("recorded %+v, want one interest accrual", history.events)
+			}
+			before, after := history.events[0].Before, history.events[0].After
+			if *before.AccruedInterest != usd(0) || !before.InterestAccruedTo.Equal(tt.accrued) {
+				t.Errorf("history before: %s through %s, want nothing through %s", before.AccruedInterest, before.InterestAccruedTo, tt.accrued)
+			}
+			if *after.AccruedInterest != total || !after.InterestAccruedTo.Equal(tt.through) {
+				t.Errorf("history after: %s through %s, want %s through %s", after.AccruedInterest, after.InterestAccruedTo, total, tt.through)
+			}
+		})
+	}
+}
+
+func TestAccrueLoanAgainAfterAnInterruptedRun(t *testing.T) {
+	accruedTo := utcDay(2024, time.March, 1)
+	loan := domain.Loan{
+		ID:                7,
+		Amount:            usd(365000),
+		InterestRate:      10,
+		AccruedInterest:   usd(0),
+		InterestAccruedTo: &accruedTo,
+		Schedule:          []domain.Installment{dueInstallment(1, 365000, 0, 0, 0)},
+	}
+	accruals := &memoryAccruals{byDay: map[time.Time]domain.InterestAccrual{}}
+	ledger := &recordingLedger{}
+	loans := &recordingLoans{}
+	uc := &accrualUsecase{loanRepo: loans, accrualRepo: accruals, ledger: ledger, history: &memoryHistory{}}
+
+	through := utcDay(2024, time.March, 3)
+	if _, err := uc.accrueLoan(loan, through); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	// The loan update of the first run is lost, so the second run starts from the same day.
+	created, err := uc.accrueLoan(loan, through)
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+
+	if created != 0 {
+		t.Errorf("second run created %d accruals, want none", created)
+	}
+	if len(ledger.accrued) != 2 {
+		t.Errorf("posted %d accruals to the ledger, want 2", len(ledger.accrued))
+	}
+	if got := loans.updated[1].AccruedInterest; got != usd(200) {
+		t.Errorf("second run left %s accrued, want 2.00 USD", got)
+	}
+}
+
+func TestAccrualStart(t *testing.T) {
+	accruedTo := utcDay(2024, time.March, 1)
+	disbursedAt := time.Date(2024, time.February, 20, 15, 30, 0, 0, time.UTC)
+	createdAt := time.Date(2024, time.February, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		loan domain.Loan
+		want time.Time
+	}{
+		{"after the last accrual", domain.Loan{InterestAccruedTo: &accruedTo, DisbursedAt: &disbursedAt, CreatedAt: createdAt}, utcDay(2024, time.March, 2)},
+		{"after the payout", domain.Loan{DisbursedAt: &disbursedAt, CreatedAt: createdAt}, utcDay(2024, time.February, 21)},
+		{"after the application without a payout date", domain.Loan{CreatedAt: createdAt}, utcDay(2024, time.February, 11)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accrualStart(tt.loan); !got.Equal(tt.want) {
+				t.Errorf("accrualStart = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccrueLoanThatNeverAccrued(t *testing.T) {
+	disbursedAt := time.Date(2024, time.February, 27, 15, 30, 0, 0, time.UTC)
+	loan := domain.Loan{
+		ID:              7,
+		Amount:          usd(365000),
+		InterestRate:    10,
+		AccruedInterest: usd(0),
+		DisbursedAt:     &disbursedAt,
+		Schedule:        []domain.Installment{dueInstallment(1, 365000, 0, 0, 0)},
+	}
+	accruals := &memoryAccruals{byDay: map[time.Time]domain.InterestAccrual{}}
+	loans := &recordingLoans{}
+	uc := &accrualUsecase{loanRepo: loans, accrualRepo: accruals, ledger: &recordingLedger{}, history: &memoryHistory{}}
+
+	created, err := uc.accrueLoan(loan, utcDay(2024, time.March, 1))
+	if err != nil {
+		t.Fatalf("accrueLoan: %v", err)
+	}
+	if created != 3 {
+		t.Errorf("created %d accruals, want one for each day since the payout", created)
+	}
+	if got := loans.updated[0].AccruedInterest; got != usd(300) {
+		t.Errorf("accrued %s, want 3.00 USD", got)
+	}
+}
+
+func TestAccrualPostedBeforeACrashIsNotPostedAgain(t *testing.T) {
+	accruedTo := utcDay(2024, time.March, 1)
+	day := utcDay(2024, time.March, 2)
+	loan := domain.Loan{
+		ID:                7,
+		Amount:            usd(365000),
+		InterestRate:      10,
+		AccruedInterest:   usd(0),
+		InterestAccruedTo: &accruedTo,
+		Schedule:          []domain.Installment{dueInstallment(1, 365000, 0, 0, 0)},
+	}
+	// The run posted the accrual to the ledger and stopped before marking it posted.
+	accrual := domain.InterestAccrual{ID: primitive.NewObjectID(), LoanID: 7, Date: day, Amount: usd(100)}
+	accruals := &memoryAccruals{byDay: map[time.Time]domain.InterestAccrual{day: accrual}}
+	entries := &memoryLedger{}
+	ledger := NewLedgerUsecase(entries)
+	if err := ledger.PostInterestAccrual(7, accrual.Amount, accrual.ID.Hex()); err != nil {
+		t.Fatalf("PostInterestAccrual: %v", err)
+	}
+	uc := &accrualUsecase{loanRepo: &recordingLoans{}, accrualRepo: accruals, ledger: ledger, history: &memoryHistory{}}
+
+	if _, err := uc.accrueLoan(loan, day); err != nil {
+		t.Fatalf("accrueLoan: %v", err)
+	}
+	if len(entries.entries) != 1 {
+		t.Errorf("ledger has %d entries for the accrual, want 1", len(entries.entries))
+	}
+	if !accruals.byDay[day].Posted {
+		t.Errorf("accrual not marked posted")
+	}
+}
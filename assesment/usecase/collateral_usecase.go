@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type collateralUsecase struct {
+	collateralRepo domain.CollateralRepository
+	loanRepo       domain.LoanRepository
+	history        domain.LoanHistoryRepository
+}
+
+// NewCollateralUsecase creates a new instance of CollateralUsecase.
+func NewCollateralUsecase(collateralRepo domain.CollateralRepository, loanRepo domain.LoanRepository, history domain.LoanHistoryRepository) domain.CollateralUsecase {
+	return &collateralUsecase{
+		collateralRepo: collateralRepo,
+		loanRepo:       loanRepo,
+		history:        history,
+	}
+}
+
+// CreateCollateral registers an asset a user can pledge to their loans.
+func (uc *collateralUsecase) CreateCollateral(collateral domain.Collateral, ownerID primitive.ObjectID) (domain.Collateral, error) {
+	collateral.Description = strings.TrimSpace(collateral.Description)
+	if err := collateral.Validate(); err != nil {
+		return domain.Collateral{}, err
+	}
+
+	now := time.Now()
+	collateral.ID = primitive.NewObjectID()
+	collateral.OwnerID = ownerID
+	collateral.Documents = stampDocuments(collateral.Documents, nil, now)
+	collateral.LoanID = 0
+	collateral.LienStatus = domain.LienNone
+	collateral.LienRegisteredAt = nil
+	collateral.LienReleasedAt = nil
+	collateral.CreatedAt = now
+	collateral.UpdatedAt = now
+
+	if err := uc.collateralRepo.CreateCollateral(collateral); err != nil {
+		return domain.Collateral{}, err
+	}
+	return collateral, nil
+}
+
+// GetCollateral retrieves a collateral of ownerID.
+func (uc *collateralUsecase) GetCollateral(id primitive.ObjectID, ownerID primitive.ObjectID) (domain.Collateral, error) {
+	collateral, err := uc.collateralRepo.GetCollateralByID(id)
+	if err != nil {
+		return domain.Collateral{}, err
+	}
+	if collateral.OwnerID != ownerID {
+		return domain.Collateral{}, domain.ErrNotCollateralOwner
+	}
+	return collateral, nil
+}
+
+// GetUserCollateral retrieves the collateral of a user, oldest first.
+func (uc *collateralUsecase) GetUserCollateral(ownerID primitive.ObjectID) ([]domain.Collateral, error) {
+	return uc.collateralRepo.GetCollateralByOwner(ownerID)
+}
+
+// UpdateCollateral changes the type, description, valuation and documents of a collateral
+// that is not pledged to a loan.
+func (uc *collateralUsecase) UpdateCollateral(id primitive.ObjectID, update domain.Collateral, ownerID primitive.ObjectID) (domain.Collateral, error) {
+	collateral, err := uc.GetCollateral(id, ownerID)
+	if err != nil {
+		return domain.Collateral{}, err
+	}
+	if collateral.LienStatus == domain.LienActive {
+		return domain.Collateral{}, domain.ErrCollateralUnderLien
+	}
+
+	now := time.Now()
+	collateral.Type = update.Type
+	collateral.Description = strings.TrimSpace(update.Description)
+	collateral.EstimatedValue = update.EstimatedValue
+	collateral.ValuationDate = update.ValuationDate
+	collateral.Documents = stampDocuments(update.Documents, collateral.Documents, now)
+	if err := collateral.Validate(); err != nil {
+		return domain.Collateral{}, err
+	}
+	collateral.UpdatedAt = now
+
+	if err := uc.collateralRepo.UpdateCollateral(collateral); err != nil {
+		return domain.Collateral{}, err
+	}
+	return collateral, nil
+}
+
+// DeleteCollateral deletes a collateral that is not pledged to a loan.
+func (uc *collateralUsecase) DeleteCollateral(id primitive.ObjectID, ownerID primitive.ObjectID) error {
+	collateral, err := uc.GetCollateral(id, ownerID)
+	if err != nil {
+		return err
+	}
+	if collateral.LienStatus == domain.LienActive {
+		return domain.ErrCollateralUnderLien
+	}
+
+	return uc.collateralRepo.DeleteCollateral(id)
+}
+
+// LinkCollateral pledges a collateral of the borrower to their application while it is
+// pending or under review, and works out the loan-to-value ratio again.
+func (uc *collateralUsecase) LinkCollateral(loanID uint, collateralID primitive.ObjectID, userID primitive.ObjectID) (domain.Loan, error) {
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	if loan.UserID != userID {
+		return domain.Loan{}, domain.ErrNotLoanOwner
+	}
+	if loan.Status != domain.LoanStatusPending && loan.Status != domain.LoanStatusUnderReview {
+		return domain.Loan{}, domain.ErrCollateralLocked
+	}
+
+	collateral, err := uc.collateralRepo.GetCollateralByID(collateralID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	if err := checkPledge(collateral, loan); err != nil {
+		return domain.Loan{}, err
+	}
+	pledged, err := uc.collateralRepo.GetCollateralByLoan(loan.ID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	now := time.Now()
+	before := collateralState(loan)
+	if err := pledgeCollateral(uc.collateralRepo, collateral, loan.ID, now); err != nil {
+		return domain.Loan{}, err
+	}
+	collateral.LienStatus = domain.LienActive
+	loan.CollateralIDs = append(loan.CollateralIDs, collateral.ID)
+	loan.CollateralValue = collateralValue(loan.Amount.Zero(), append(activeLiens(pledged), collateral))
+	loan.LoanToValue = loanToValue(loan.Amount, loan.CollateralValue)
+	loan.UpdatedAt = now
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return domain.Loan{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{
+		LoanID:  loan.ID,
+		Type:    domain.LoanEventCollateralLinked,
+		ActorID: userID,
+		Before:  before,
+		After:   collateralState(loan),
+		Detail:  "collateral " + collateral.ID.Hex(),
+	}); err != nil {
+		return domain.Loan{}, err
+	}
+
+	return loan, nil
+}
+
+// GetLoanCollateral retrieves the collateral pledged to a loan.
+func (uc *collateralUsecase) GetLoanCollateral(loanID uint) ([]domain.Collateral, error) {
+	if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+		return nil, err
+	}
+
+	return uc.collateralRepo.GetCollateralByLoan(loanID)
+}
+
+// stampDocuments dates the documents added to a collateral, keeping the date of those it
+// already had.
+func stampDocuments(documents, previous []domain.CollateralDocument, now time.Time) []domain.CollateralDocument {
+	added := make(map[string]time.Time, len(previous))
+	for _, document := range previous {
+		added[document.Name+"\x00"+document.Reference] = document.AddedAt
+	}
+
+	stamped := []domain.CollateralDocument{}
+	for _, document := range documents {
+		document.Name = strings.TrimSpace(document.Name)
+		document.Reference = strings.TrimSpace(document.Reference)
+		if document.Name == "" && document.Reference == "" {
+			continue
+		}
+		document.AddedAt = now
+		if at, ok := added[document.Name+"\x00"+document.Reference]; ok {
+			document.AddedAt = at
+		}
+		stamped = append(stamped, document)
+	}
+	return stamped
+}
+
+// checkPledge checks that a collateral can secure loan: it belongs to the borrower, is not
+// pledged to another loan and is valued in the loan's currency.
+func checkPledge(collateral domain.Collateral, loan domain.Loan) error {
+	if collateral.OwnerID != loan.UserID {
+		return domain.ErrNotCollateralOwner
+	}
+	if collateral.LienStatus == domain.LienActive {
+		return domain.ErrCollateralUnderLien
+	}
+	if collateral.EstimatedValue.Currency != loan.Amount.Currency {
+		return domain.ErrCurrencyMismatch
+	}
+	return nil
+}
+
+// pledgeCollateral registers a lien on a collateral for loanID.
+func pledgeCollateral(repo domain.CollateralRepository, collateral domain.Collateral, loanID uint, now time.Time) error {
+	collateral.LoanID = loanID
+	collateral.LienStatus = domain.LienActive
+	collateral.LienRegisteredAt = &now
+	collateral.LienReleasedAt = nil
+	collateral.UpdatedAt = now
+	return repo.UpdateCollateral(collateral)
+}
+
+// releaseLiens releases the liens a loan holds on its collateral and records each release in
+// the history of the loan.
+func releaseLiens(repo domain.CollateralRepository, history domain.LoanHistoryRepository, loanID uint, now time.Time) error {
+	collateral, err := repo.GetCollateralByLoan(loanID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range activeLiens(collateral) {
+		c.LienStatus = domain.LienReleased
+		c.LienReleasedAt = &now
+		c.UpdatedAt = now
+		if err := repo.UpdateCollateral(c); err != nil {
+			return err
+		}
+		if err := recordEvent(history, domain.LoanEvent{LoanID: loanID, Type: domain.LoanEventLienReleased, Detail: "collateral " + c.ID.Hex()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activeLiens returns the collateral still under lien.
+func activeLiens(collateral []domain.Collateral) []domain.Collateral {
+	var active []domain.Collateral
+	for _, c := range collateral {
+		if c.LienStatus == domain.LienActive {
+			active = append(active, c)
+		}
+	}
+	return active
+}
+
+// collateralValue returns the total estimated value of collateral, starting from zero.
+func collateralValue(zero domain.Money, collateral []domain.Collateral) domain.Money {
+	value := zero
+	for _, c := range collateral {
+		value = value.Add(c.EstimatedValue)
+	}
+	return value
+}
+
+// loanToValue returns amount as a percentage of the collateral value, rounded to two
+// decimals, or 0 for an unsecured loan.
+func loanToValue(amount, value domain.Money) float64 {
+	if !value.IsPositive() {
+		return 0
+	}
+
+	ratio, _ := new(big.Rat).Quo(amount.Rat(), value.Rat()).Float64()
+	return math.Round(ratio*10000) / 100
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryCollateral keeps collateral in memory by its ID.
+type memoryCollateral struct {
+	domain.CollateralRepository
+	collateral map[primitive.ObjectID]domain.Collateral
+}
+
+func newMemoryCollateral(collateral ...domain.Collateral) *memoryCollateral {
+	r := &memoryCollateral{collateral: map[primitive.ObjectID]domain.Collateral{}}
+	for _, c := range collateral {
+		r.collateral[c.ID] = c
+	}
+	return r
+}
+
+func (r *memoryCollateral) GetCollateralByID(id primitive.ObjectID) (domain.Collateral, error) {
+	c, ok := r.collateral[id]
+	if !ok {
+		return domain.Collateral{}, mongo.ErrNoDocuments
+	}
+	return c, nil
+}
+
+func (r *memoryCollateral) GetCollateralByLoan(loanID uint) ([]domain.Collateral, error) {
+	collateral := []domain.Collateral{}
+	for _, c := range r.collateral {
+		if c.LoanID == loanID {
+			collateral = append(collateral, c)
+		}
+	}
+	return collateral, nil
+}
+
+func (r *memoryCollateral) UpdateCollateral(collateral domain.Collateral) error {
+	r.collateral[collateral.ID] = collateral
+	return nil
+}
+
+func TestCheckPledge(t *testing.T) {
+	owner := primitive.NewObjectID()
+	loan := domain.Loan{UserID: owner, Amount: usd(100000)}
+	car := domain.Collateral{OwnerID: owner, EstimatedValue: usd(250000), LienStatus: domain.LienNone}
+
+	tests := []struct {
+		name   string
+		change func(*domain.Collateral)
+		want   error
+	}{
+		{"own collateral", nil, nil},
+		{"released lien", func(c *domain.Collateral) { c.LienStatus = domain.LienReleased }, nil},
+		{"someone else's", func(c *domain.Collateral) { c.OwnerID = primitive.NewObjectID() }, domain.ErrNotCollateralOwner},
+		{"under another lien", func(c *domain.Collateral) { c.LienStatus = domain.LienActive }, domain.ErrCollateralUnderLien},
+		{"valued in another currency", func(c *domain.Collateral) { c.EstimatedValue = domain.NewMoney(250000, "EUR") }, domain.ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collateral := car
+			if tt.change != nil {
+				tt.change(&collateral)
+			}
+			if err := checkPledge(collateral, loan); err != tt.want {
+				t.Errorf("checkPledge = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoanToValue(t *testing.T) {
+	tests := []struct {
+		amount, value domain.Money
+		want          float64
+	}{
+		{usd(100000), usd(250000), 40},
+		{usd(100000), usd(300000), 33.33},
+		{usd(300000), usd(200000), 150},
+		{usd(100000), usd(0), 0},
+	}
+
+	for _, tt := range tests {
+		if got := loanToValue(tt.amount, tt.value); got != tt.want {
+			t.Errorf("loanToValue(%s, %s) = %g, want %g", tt.amount, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestApplyForLoanPledgesCollateralOnlyWhenNotDeclined(t *testing.T) {
+	tests := []struct {
+		name   string
+		active bool // the account is activated, which the only credit rule requires
+		want   domain.LoanStatus
+		lien   domain.LienStatus
+	}{
+		{"application waiting for its guarantor", true, domain.LoanStatusUnderReview, domain.LienActive},
+		{"declined application", false, domain.LoanStatusRejected, domain.LienNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newApplicationFixture(tt.active)
+			car := domain.Collateral{ID: primitive.NewObjectID(), OwnerID: f.borrower.ID, EstimatedValue: usd(250000), LienStatus: domain.LienNone}
+			collateral := newMemoryCollateral(car)
+			f.collateral = collateral
+
+			application := f.application()
+			application.CollateralIDs = []primitive.ObjectID{car.ID}
+			application.Parties = []domain.LoanParty{{Role: domain.PartyGuarantor, Name: "Sara", Email: "sara@example.com"}}
+			loan, err := f.usecase().ApplyForLoan(application)
+			if err != nil {
+				t.Fatalf("ApplyForLoan: %v", err)
+			}
+
+			if loan.Status != tt.want {
+				t.Errorf("application is %s, want %s", loan.Status, tt.want)
+			}
+			if loan.CollateralValue != usd(250000) || loan.LoanToValue != 40 {
+				t.Errorf("collateral value %s at %g%% LTV, want 2,500.00 USD at 40%%", loan.CollateralValue, loan.LoanToValue)
+			}
+			if stored := collateral.collateral[car.ID]; stored.LienStatus != tt.lien {
+				t.Errorf("collateral lien is %s, want %s", stored.LienStatus, tt.lien)
+			}
+			if sent := len(f.consents.requested); (sent > 0) != (tt.lien == domain.LienActive) {
+				t.Errorf("sent %d consent requests for a %s application", sent, loan.Status)
+			}
+		})
+	}
+}
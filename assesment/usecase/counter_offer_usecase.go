@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type counterOfferUsecase struct {
+	loanRepo    domain.LoanRepository
+	offerRepo   domain.CounterOfferRepository
+	productRepo domain.ProductRepository
+	loanUsecase domain.LoanUsecase
+	approvals   domain.DualApprovalPolicy
+	history     domain.LoanHistoryRepository
+}
+
+// NewCounterOfferUsecase creates a new instance of CounterOfferUsecase. Status changes go
+// through loanUsecase so they follow the loan state machine.
+func NewCounterOfferUsecase(loanRepo domain.LoanRepository, offerRepo domain.CounterOfferRepository, productRepo domain.ProductRepository, loanUsecase domain.LoanUsecase, approvals domain.DualApprovalPolicy, history domain.LoanHistoryRepository) domain.CounterOfferUsecase {
+	return &counterOfferUsecase{
+		loanRepo:    loanRepo,
+		offerRepo:   offerRepo,
+		productRepo: productRepo,
+		loanUsecase: loanUsecase,
+		approvals:   approvals,
+		history:     history,
+	}
+}
+
+// MakeCounterOffer offers the borrower of an application pending or under review a lower
+// amount, another term or another rate, within the limits of the loan product. An offer
+// still waiting for an answer is superseded by the new one. Only the assigned reviewer can
+// make an offer; one above the dual approval threshold is its maker's approval and waits
+// for a second admin before the borrower can accept it.
+func (uc *counterOfferUsecase) MakeCounterOffer(loanID uint, terms domain.CounterOfferTerms, offeredBy primitive.ObjectID) (domain.CounterOffer, error) {
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.CounterOffer{}, err
+	}
+	switch loan.Status {
+	case domain.LoanStatusPending, domain.LoanStatusUnderReview, domain.LoanStatusCounterOffered:
+	default:
+		return domain.CounterOffer{}, domain.ErrLoanNotUnderReview
+	}
+	if !loan.AllPartiesConsented() {
+		return domain.CounterOffer{}, domain.ErrPartyConsentRequired
+	}
+	if err := checkReviewer(loan, offeredBy); err != nil {
+		return domain.CounterOffer{}, err
+	}
+
+	if terms.Term == 0 {
+		terms.Term = loan.Term
+	}
+	if terms.InterestRate == 0 {
+		terms.InterestRate = loan.InterestRate
+	}
+	if err := terms.Amount.Validate(); err != nil {
+		return domain.CounterOffer{}, err
+	}
+	if !terms.Amount.IsPositive() || terms.Amount.Currency != loan.Amount.Currency || terms.Amount.Cmp(loan.Amount) > 0 ||
+		terms.Term < 0 || terms.InterestRate < 0 || terms.ValidForDays < 0 {
+		return domain.CounterOffer{}, domain.ErrInvalidCounterOffer
+	}
+
+	product, err := uc.productRepo.GetProductByID(loan.ProductID)
+	if err != nil {
+		return domain.CounterOffer{}, err
+	}
+	offered := withOfferedTerms(loan, terms.Amount, terms.Term, terms.InterestRate, product)
+	if err := applyProductRules(&offered, product); err != nil {
+		return domain.CounterOffer{}, err
+	}
+	if err := validateLoanTerms(offered); err != nil {
+		return domain.CounterOffer{}, err
+	}
+
+	now := time.Now()
+	validity := domain.DefaultCounterOfferValidity
+	if terms.ValidForDays > 0 {
+		validity = time.Duration(terms.ValidForDays) * 24 * time.Hour
+	}
+	offer := domain.CounterOffer{
+		ID:              primitive.NewObjectID(),
+		LoanID:          loan.ID,
+		Amount:          terms.Amount,
+		Term:            terms.Term,
+		InterestRate:    terms.InterestRate,
+		Note:            strings.TrimSpace(terms.Note),
+		RequestedAmount: loan.Amount,
+		RequestedTerm:   loan.Term,
+		RequestedRate:   loan.InterestRate,
+		Status:          domain.CounterOfferPending,
+		OfferedBy:       offeredBy,
+		ExpiresAt:       now.Add(validity),
+		CreatedAt:       now,
+	}
+
+	existing, err := uc.offerRepo.GetCounterOffersByLoan(loan.ID)
+	if err != nil {
+		return domain.CounterOffer{}, err
+	}
+	for _, previous := range existing {
+		if previous.Status != domain.CounterOfferPending {
+			continue
+		}
+		previous.Status = domain.CounterOfferSuperseded
+		if err := uc.offerRepo.UpdateCounterOffer(previous); err != nil {
+			return domain.CounterOffer{}, err
+		}
+	}
+
+	// Earlier approvals were for other terms. Above the threshold the offer is its maker's
+	// sign-off on the new ones, the first of the two they need.
+	if loan.Review == nil {
+		loan.Review = &domain.LoanReview{}
+	}
+	loan.Review.Approvals = nil
+	if uc.approvals.Requires(terms.Amount) {
+		recordApproval(&loan, offeredBy, offer.Note, now)
+	}
+	loan.UpdatedAt = now
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return domain.CounterOffer{}, err
+	}
+
+	if loan.Status != domain.LoanStatusCounterOffered {
+		if err := uc.loanUsecase.TransitionLoan(&loan, domain.LoanStatusCounterOffered, offeredBy); err != nil {
+			return domain.CounterOffer{}, err
+		}
+	}
+	if err := uc.offerRepo.CreateCounterOffer(offer); err != nil {
+		return domain.CounterOffer{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{
+		LoanID:  loan.ID,
+		Type:    domain.LoanEventCounterOffered,
+		ActorID: offeredBy,
+		Detail:  strings.TrimSpace(fmt.Sprintf("counter-offer %s: %s over %d installments at %g%% %s", offer.ID.Hex(), offer.Amount, offer.Term, offer.InterestRate, offer.Note)),
+	}); err != nil {
+		return domain.CounterOffer{}, err
+	}
+
+	return offer, nil
+}
+
+// AcceptCounterOffer lets the borrower take a counter-offer. The loan is approved on the
+// offered terms, with its origination fee and repayment schedule worked out again.
+func (uc *counterOfferUsecase) AcceptCounterOffer(loanID uint, id primitive.ObjectID, userID primitive.ObjectID) (domain.Loan, error) {
+	offer, loan, err := uc.openOffer(loanID, id, userID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	product, err := uc.productRepo.GetProductByID(loan.ProductID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	now := time.Now()
+	requested := termsState(loan)
+	loan = withOfferedTerms(loan, offer.Amount, offer.Term, offer.InterestRate, product)
+	schedule, err := generateSchedule(loan, now)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	if err := uc.loanUsecase.TransitionLoan(&loan, domain.LoanStatusApproved, userID); err != nil {
+		return domain.Loan{}, err
+	}
+	loan.Schedule = schedule
+	loan.OutstandingBalance = outstandingBalance(loan)
+	loan.UpdatedAt = now
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return domain.Loan{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{
+		LoanID:  loan.ID,
+		Type:    domain.LoanEventTermsChanged,
+		ActorID: userID,
+		Before:  requested,
+		After:   termsState(loan),
+		Detail:  "counter-offer " + offer.ID.Hex() + " accepted",
+	}); err != nil {
+		return domain.Loan{}, err
+	}
+
+	offer.Status = domain.CounterOfferAccepted
+	offer.RespondedAt = &now
+	if err := uc.offerRepo.UpdateCounterOffer(offer); err != nil {
+		return domain.Loan{}, err
+	}
+
+	return loan, nil
+}
+
+// DeclineCounterOffer lets the borrower turn down a counter-offer. The application goes back
+// to review, where it can be approved, rejected or offered other terms.
+func (uc *counterOfferUsecase) DeclineCounterOffer(loanID uint, id primitive.ObjectID, userID primitive.ObjectID) (domain.CounterOffer, error) {
+	offer, loan, err := uc.openOffer(loanID, id, userID)
+	if err != nil {
+		return domain.CounterOffer{}, err
+	}
+
+	now := time.Now()
+	offer.Status = domain.CounterOfferDeclined
+	offer.RespondedAt = &now
+	if err := uc.offerRepo.UpdateCounterOffer(offer); err != nil {
+		return domain.CounterOffer{}, err
+	}
+	if err := uc.clearApprovals(&loan); err != nil {
+		return domain.CounterOffer{}, err
+	}
+	if err := uc.loanUsecase.TransitionLoan(&loan, domain.LoanStatusUnderReview, userID); err != nil {
+		return domain.CounterOffer{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{LoanID: loan.ID, Type: domain.LoanEventCounterOfferDeclined, ActorID: userID, Detail: "counter-offer " + offer.ID.Hex()}); err != nil {
+		return domain.CounterOffer{}, err
+	}
+
+	return offer, nil
+}
+
+// GetLoanCounterOffers retrieves the counter-offers made on a loan, oldest first.
+func (uc *counterOfferUsecase) GetLoanCounterOffers(loanID uint) ([]domain.CounterOffer, error) {
+	if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+		return nil, err
+	}
+
+	return uc.offerRepo.GetCounterOffersByLoan(loanID)
+}
+
+// ExpireCounterOffers expires the counter-offers left unanswered past their expiry and
+// returns their applications to review. It returns how many offers expired; an offer that
+// fails to expire is reported in the error and does not stop the others.
+func (uc *counterOfferUsecase) ExpireCounterOffers(now time.Time) (int, error) {
+	offers, err := uc.offerRepo.GetExpiredCounterOffers(now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	var errs []error
+	for _, offer := range offers {
+		loan, err := uc.loanRepo.GetLoanByID(offer.LoanID)
+		if err == nil {
+			err = uc.expire(offer, loan)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("counter-offer %s: %w", offer.ID.Hex(), err))
+			continue
+		}
+		expired++
+	}
+
+	return expired, errors.Join(errs...)
+}
+
+// openOffer loads a counter-offer of loanID that its borrower userID can still answer. An
+// offer found past its expiry is expired on the spot.
+func (uc *counterOfferUsecase) openOffer(loanID uint, id primitive.ObjectID, userID primitive.ObjectID) (domain.CounterOffer, domain.Loan, error) {
+	offer, err := uc.offerRepo.GetCounterOfferByID(id)
+	if err != nil {
+		return domain.CounterOffer{}, domain.Loan{}, err
+	}
+	if offer.LoanID != loanID {
+		return domain.CounterOffer{}, domain.Loan{}, domain.ErrCounterOfferNotFound
+	}
+
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.CounterOffer{}, domain.Loan{}, err
+	}
+	if loan.UserID != userID {
+		return domain.CounterOffer{}, domain.Loan{}, domain.ErrNotLoanOwner
+	}
+	if offer.Status != domain.CounterOfferPending || loan.Status != domain.LoanStatusCounterOffered {
+		return domain.CounterOffer{}, domain.Loan{}, domain.ErrCounterOfferNotPending
+	}
+	if !time.Now().Before(offer.ExpiresAt) {
+		if err := uc.expire(offer, loan); err != nil {
+			return domain.CounterOffer{}, domain.Loan{}, err
+		}
+		return domain.CounterOffer{}, domain.Loan{}, domain.ErrCounterOfferExpired
+	}
+
+	return offer, loan, nil
+}
+
+// expire marks a pending counter-offer expired and returns its application to review if it
+// is still waiting on the offer.
+func (uc *counterOfferUsecase) expire(offer domain.CounterOffer, loan domain.Loan) error {
+	offer.Status = domain.CounterOfferExpired
+	if err := uc.offerRepo.UpdateCounterOffer(offer); err != nil {
+		return err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{LoanID: loan.ID, Type: domain.LoanEventCounterOfferExpired, Detail: "counter-offer " + offer.ID.Hex()}); err != nil {
+		return err
+	}
+	if loan.Status != domain.LoanStatusCounterOffered {
+		return nil
+	}
+	if err := uc.clearApprovals(&loan); err != nil {
+		return err
+	}
+	return uc.loanUsecase.TransitionLoan(&loan, domain.LoanStatusUnderReview, primitive.NilObjectID)
+}
+
+// clearApprovals drops the sign-offs on the terms of a counter-offer that was not taken, so
+// the application goes back to review without them.
+func (uc *counterOfferUsecase) clearApprovals(loan *domain.Loan) error {
+	if len(loan.Approvals()) == 0 {
+		return nil
+	}
+	loan.Review.Approvals = nil
+	return uc.loanRepo.UpdateLoan(loan)
+}
+
+// withOfferedTerms returns loan with the amount, term and rate of a counter-offer, the
+// origination fee the product charges on that amount and its loan-to-value ratio.
+func withOfferedTerms(loan domain.Loan, amount domain.Money, term int, rate float64, product domain.LoanProduct) domain.Loan {
+	loan.Amount = amount
+	loan.Term = term
+	loan.InterestRate = rate
+	loan.OriginationFee = originationFee(product, amount)
+	loan.LoanToValue = loanToValue(amount, loan.CollateralValue)
+	return loan
+}
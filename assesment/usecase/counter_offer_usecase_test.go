@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryCounterOffers keeps counter-offers in memory in the order they were made.
+type memoryCounterOffers struct {
+	domain.CounterOfferRepository
+	offers []domain.CounterOffer
+}
+
+func (r *memoryCounterOffers) CreateCounterOffer(offer domain.CounterOffer) error {
+	r.offers = append(r.offers, offer)
+	return nil
+}
+
+func (r *memoryCounterOffers) GetCounterOfferByID(id primitive.ObjectID) (domain.CounterOffer, error) {
+	for _, offer := range r.offers {
+		if offer.ID == id {
+			return offer, nil
+		}
+	}
+	return domain.CounterOffer{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryCounterOffers) GetCounterOffersByLoan(loanID uint) ([]domain.CounterOffer, error) {
+	offers := []domain.CounterOffer{}
+	for _, offer := range r.offers {
+		if offer.LoanID == loanID {
+			offers = append(offers, offer)
+		}
+	}
+	return offers, nil
+}
+
+func (r *memoryCounterOffers) UpdateCounterOffer(offer domain.CounterOffer) error {
+	for i := range r.offers {
+		if r.offers[i].ID == offer.ID {
+			r.offers[i] = offer
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+// memoryProducts serves a single loan product.
+type memoryProducts struct {
+	domain.ProductRepository
+	product domain.LoanProduct
+}
+
+func (r *memoryProducts) GetProductByID(id primitive.ObjectID) (domain.LoanProduct, error) {
+	if id != r.product.ID {
+		return domain.LoanProduct{}, domain.ErrProductNotFound
+	}
+	return r.product, nil
+}
+
+// counterOfferFixture is an application for 5,000.00 USD under review by reviewer, on a
+// product lending 100.00 to 10,000.00 USD at 5% to 20%, with two approvals needed from
+// 3,000.00 USD.
+type counterOfferFixture struct {
+	borrower, reviewer primitive.ObjectID
+	loans              *memoryLoans
+	offers             *memoryCounterOffers
+	loanUsecase        domain.LoanUsecase
+	uc                 domain.CounterOfferUsecase
+}
+
+func newCounterOfferFixture() counterOfferFixture {
+	product := domain.LoanProduct{
+		ID:              primitive.NewObjectID(),
+		AmountLimits:    []domain.AmountLimit{{Min: usd(10000), Max: usd(1000000)}},
+		MinInterestRate: 5,
+		MaxInterestRate: 20,
+		Active:          true,
+	}
+	f := counterOfferFixture{borrower: primitive.NewObjectID(), reviewer: primitive.NewObjectID()}
+	f.loans = newMemoryLoans(domain.Loan{
+		ID:                 9,
+		UserID:             f.borrower,
+		ProductID:          product.ID,
+		Amount:             usd(500000),
+		Term:               12,
+		InterestRate:       10,
+		RepaymentFrequency: domain.FrequencyMonthly,
+		AmortizationMethod: domain.AmortizationAnnuity,
+		Status:             domain.LoanStatusUnderReview,
+		Review:             &domain.LoanReview{ReviewerID: f.reviewer},
+	})
+	f.offers = &memoryCounterOffers{}
+	approvals := domain.DualApprovalPolicy{Threshold: usd(300000)}
+	history := &memoryHistory{}
+	products := &memoryProducts{product: product}
+	f.loanUsecase = NewLoanUsecase(f.loans, nil, nil, products, nil, nil, nil, nil, nil, approvals, nil, nil, history)
+	f.uc = NewCounterOfferUsecase(f.loans, f.offers, products, f.loanUsecase, approvals, history)
+	return f
+}
+
+func TestMakeCounterOfferNeedsTheAssignedReviewer(t *testing.T) {
+	f := newCounterOfferFixture()
+
+	_, err := f.uc.MakeCounterOffer(9, domain.CounterOfferTerms{Amount: usd(200000)}, primitive.NewObjectID())
+	if err != domain.ErrNotAssignedReviewer {
+		t.Fatalf("MakeCounterOffer by another admin = %v, want %v", err, domain.ErrNotAssignedReviewer)
+	}
+	if loan, _ := f.loans.GetLoanByID(9); loan.Status != domain.LoanStatusUnderReview || len(f.offers.offers) != 0 {
+		t.Errorf("loan is %s with %d offers, want under review with none", loan.Status, len(f.offers.offers))
+	}
+}
+
+func TestMakeCounterOfferRefusesWorseTerms(t *testing.T) {
+	tests := []struct {
+		name  string
+		terms domain.CounterOfferTerms
+		want  error
+	}{
+		{"more than applied for", domain.CounterOfferTerms{Amount: usd(600000)}, domain.ErrInvalidCounterOffer},
+		{"another currency", domain.CounterOfferTerms{Amount: domain.NewMoney(200000, "EUR")}, domain.ErrInvalidCounterOffer},
+		{"nothing", domain.CounterOfferTerms{Amount: usd(0)}, domain.ErrInvalidCounterOffer},
+		{"below the product minimum", domain.CounterOfferTerms{Amount: usd(5000)}, domain.ErrAmountOutOfRange},
+		{"rate above the product maximum", domain.CounterOfferTerms{Amount: usd(200000), InterestRate: 25}, domain.ErrRateOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCounterOfferFixture()
+			if _, err := f.uc.MakeCounterOffer(9, tt.terms, f.reviewer); err != tt.want {
+				t.Errorf("MakeCounterOffer = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCounterOfferDualApproval(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    domain.Money
+		approvals int // recorded with the offer
+	}{
+		{"offer below the threshold", usd(200000), 0},
+		{"offer above the threshold", usd(400000), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCounterOfferFixture()
+			offer, err := f.uc.MakeCounterOffer(9, domain.CounterOfferTerms{Amount: tt.amount, Term: 6}, f.reviewer)
+			if err != nil {
+				t.Fatalf("MakeCounterOffer: %v", err)
+			}
+			loan, _ := f.loans.GetLoanByID(9)
+			if loan.Status != domain.LoanStatusCounterOffered || len(loan.Approvals()) != tt.approvals {
+				t.Fatalf("loan is %s with %d approvals, want counter_offered with %d", loan.Status, len(loan.Approvals()), tt.approvals)
+			}
+			if offer.RequestedAmount != usd(500000) || !offer.ExpiresAt.After(time.Now()) {
+				t.Errorf("offer for %s expiring %s, want it to keep the 5,000.00 USD applied for and expire later", offer.RequestedAmount, offer.ExpiresAt)
+			}
+
+			if tt.approvals == 0 {
+				if _, err := f.loanUsecase.ApproveLoan(9, f.reviewer, "confirmed"); err != domain.ErrCounterOfferPending {
+					t.Errorf("ApproveLoan of an offer below the threshold = %v, want %v", err, domain.ErrCounterOfferPending)
+				}
+			} else {
+				if _, err := f.uc.AcceptCounterOffer(9, offer.ID, f.borrower); err != domain.ErrSecondApprovalRequired {
+					t.Fatalf("AcceptCounterOffer before the second approval = %v, want %v", err, domain.ErrSecondApprovalRequired)
+				}
+				if _, err := f.loanUsecase.ApproveLoan(9, f.reviewer, "confirmed"); err != domain.ErrSameApprover {
+					t.Errorf("ApproveLoan by the offer's maker = %v, want %v", err, domain.ErrSameApprover)
+				}
+				if _, err := f.loanUsecase.ApproveLoan(9, primitive.NewObjectID(), "confirmed"); err != nil {
+					t.Fatalf("second approval: %v", err)
+				}
+			}
+
+			approved, err := f.uc.AcceptCounterOffer(9, offer.ID, f.borrower)
+			if err != nil {
+				t.Fatalf("AcceptCounterOffer: %v", err)
+			}
+			if approved.Status != domain.LoanStatusApproved || approved.Amount != tt.amount || approved.Term != 6 || len(approved.Schedule) != 6 {
+				t.Errorf("loan is %s for %s over %d installments (%d scheduled), want approved on the offered terms",
+					approved.Status, approved.Amount, approved.Term, len(approved.Schedule))
+			}
+			if stored, _ := f.offers.GetCounterOfferByID(offer.ID); stored.Status != domain.CounterOfferAccepted {
+				t.Errorf("offer is %s, want accepted", stored.Status)
+			}
+		})
+	}
+}
+
+func TestNewCounterOfferSupersedesThePendingOne(t *testing.T) {
+	f := newCounterOfferFixture()
+
+	first, err := f.uc.MakeCounterOffer(9, domain.CounterOfferTerms{Amount: usd(400000)}, f.reviewer)
+	if err != nil {
+		t.Fatalf("first offer: %v", err)
+	}
+	second, err := f.uc.MakeCounterOffer(9, domain.CounterOfferTerms{Amount: usd(200000)}, f.reviewer)
+	if err != nil {
+		t.Fatalf("second offer: %v", err)
+	}
+
+	if stored, _ := f.offers.GetCounterOfferByID(first.ID); stored.Status != domain.CounterOfferSuperseded {
+		t.Errorf("first offer is %s, want superseded", stored.Status)
+	}
+	if loan, _ := f.loans.GetLoanByID(9); len(loan.Approvals()) != 0 {
+		t.Errorf("loan keeps %d approvals of the first offer, want none", len(loan.Approvals()))
+	}
+	if _, err := f.uc.AcceptCounterOffer(9, first.ID, f.borrower); err != domain.ErrCounterOfferNotPending {
+		t.Errorf("accepting the superseded offer = %v, want %v", err, domain.ErrCounterOfferNotPending)
+	}
+
+	declined, err := f.uc.DeclineCounterOffer(9, second.ID, f.borrower)
+	if err != nil {
+		t.Fatalf("DeclineCounterOffer: %v", err)
+	}
+	if loan, _ := f.loans.GetLoanByID(9); declined.Status != domain.CounterOfferDeclined || loan.Status != domain.LoanStatusUnderReview {
+		t.Errorf("offer is %s and loan %s, want declined and back under review", declined.Status, loan.Status)
+	}
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type creditScoreUsecase struct {
+	loanRepo domain.LoanRepository
+	userRepo domain.UserRepository
+	scorer   domain.CreditScorer
+}
+
+// NewCreditScoreUsecase creates a new instance of CreditScoreUsecase that scores borrowers with scorer.
+func NewCreditScoreUsecase(loanRepo domain.LoanRepository, userRepo domain.UserRepository, scorer domain.CreditScorer) domain.CreditScoreUsecase {
+	return &creditScoreUsecase{
+		loanRepo: loanRepo,
+		userRepo: userRepo,
+		scorer:   scorer,
+	}
+}
+
+// GetCreditScore scores a borrower from their loan history.
+func (uc *creditScoreUsecase) GetCreditScore(userID primitive.ObjectID) (domain.CreditScore, error) {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return domain.CreditScore{}, domain.ErrUserNotFound
+	}
+
+	loans, err := uc.loanRepo.GetLoansByUser(userID)
+	if err != nil {
+		return domain.CreditScore{}, err
+	}
+
+	score := uc.scorer.Score(domain.CreditHistory{User: user, Loans: loans, Now: time.Now()})
+	score.UserID = userID
+	return score, nil
+}
+
+// Weights of the repayment history model, in score points.
+const (
+	repaymentBaseScore   = 600
+	onTimeMaxPoints      = 120 // every due installment paid on time
+	onTimeMinPoints      = -150
+	defaultedPoints      = -80 // per loan that is or was defaulted but has not been written off
+	writtenOffPoints     = -150
+	delinquencyMinPoints = -250
+	utilizationMaxPoints = 40 // nothing outstanding on the loans on book
+	utilizationMinPoints = -40
+	paidOffPoints        = 15 // per loan repaid in full
+	paidOffMaxPoints     = 60
+	accountAgeMaxPoints  = 60
+	accountAgeFullDays   = 3 * 365 // account age earning the full points
+)
+
+type repaymentHistoryScorer struct{}
+
+// NewRepaymentHistoryScorer creates a CreditScorer that scores borrowers on how they repaid
+// their loans: on-time installments, defaults, utilization, completed loans and account age.
+func NewRepaymentHistoryScorer() domain.CreditScorer {
+	return &repaymentHistoryScorer{}
+}
+
+// Score derives a score from the base score and the points of every factor, clamped to
+// the bounds of the score.
+func (s *repaymentHistoryScorer) Score(history domain.CreditHistory) domain.CreditScore {
+	factors := []domain.CreditScoreFactor{
+		onTimeFactor(history),
+		delinquencyFactor(history),
+		utilizationFactor(history),
+		paidOffFactor(history),
+		accountAgeFactor(history),
+	}
+
+	score := repaymentBaseScore
+	for _, factor := range factors {
+		score += factor.Points
+	}
+	score = min(max(score, domain.MinCreditScore), domain.MaxCreditScore)
+
+	return domain.CreditScore{
+		Model:      "repayment_history",
+		Score:      score,
+		Band:       domain.CreditScoreBand(score),
+		BaseScore:  repaymentBaseScore,
+		Factors:    factors,
+		ComputedAt: history.Now,
+	}
+}
+
+// onTimeFactor rewards installments paid by their due date and penalises those paid late
+// or still unpaid after it.
+func onTimeFactor(history domain.CreditHistory) domain.CreditScoreFactor {
+	due, onTime := 0, 0
+	for _, loan := range history.Loans {
+		for _, inst := range loan.Schedule {
+			if inst.DueDate.After(history.Now) {
+				continue
+			}
+			due++
+			if inst.IsPaid() && !inst.PaidAt.After(endOfDay(inst.DueDate)) {
+				onTime++
+			}
+		}
+	}
+
+	factor := domain.CreditScoreFactor{Name: "on_time_payments"}
+	if due == 0 {
+		factor.Description = "no installments have fallen due yet"
+		return factor
+	}
+
+	share := float64(onTime) / float64(due)
+	factor.Points = int(math.Round(onTimeMinPoints + share*(onTimeMaxPoints-onTimeMinPoints)))
+	factor.Description = fmt.Sprintf("%d of %d due installments paid on time", onTime, due)
+	return factor
+}
+
+// delinquencyFactor penalises loans that were defaulted or written off.
+func delinquencyFactor(history domain.CreditHistory) domain.CreditScoreFactor {
+	defaulted, writtenOff := 0, 0
+	for _, loan := range history.Loans {
+		switch loan.Status {
+		case domain.LoanStatusDefaulted:
+			defaulted++
+		case domain.LoanStatusWrittenOff:
+			writtenOff++
+		}
+	}
+
+	points := max(defaulted*defaultedPoints+writtenOff*writtenOffPoints, delinquencyMinPoints)
+	return domain.CreditScoreFactor{
+		Name:        "defaults",
+		Points:      points,
+		Description: fmt.Sprintf("%d defaulted and %d written-off loans", defaulted, writtenOff),
+	}
+}
+
+// utilizationFactor compares the principal still outstanding on the loans on book with the
+// principal lent, averaged across loans so currencies need not be converted.
+func utilizationFactor(history domain.CreditHistory) domain.CreditScoreFactor {
+	factor := domain.CreditScoreFactor{Name: "utilization"}
+
+	total := new(big.Rat)
+	count := 0
+	for _, loan := range history.Loans {
+		if !loan.Status.IsOnBook() || !loan.Amount.IsPositive() {
+			continue
+		}
+		total.Add(total, new(big.Rat).Quo(outstandingPrincipal(loan).Rat(), loan.Amount.Rat()))
+		count++
+	}
+
+	if count == 0 {
+		factor.Description = "no loans on book"
+		return factor
+	}
+
+	utilization, _ := total.Quo(total, big.NewRat(int64(count), 1)).Float64()
+	factor.Points = int(math.Round(utilizationMaxPoints + utilization*(utilizationMinPoints-utilizationMaxPoints)))
+	factor.Description = fmt.Sprintf("%.0f%% of the principal on %d loans on book is outstanding", utilization*100, count)
+	return factor
+}
+
+// paidOffFactor rewards loans repaid in full.
+func paidOffFactor(history domain.CreditHistory) domain.CreditScoreFactor {
+	paidOff := 0
+	for _, loan := range history.Loans {
+		if loan.Status == domain.LoanStatusPaidOff {
+			paidOff++
+		}
+	}
+
+	return domain.CreditScoreFactor{
+		Name:        "paid_off_loans",
+		Points:      min(paidOff*paidOffPoints, paidOffMaxPoints),
+		Description: fmt.Sprintf("%d loans repaid in full", paidOff),
+	}
+}
+
+// accountAgeFactor rewards long-standing accounts, up to accountAgeFullDays.
+func accountAgeFactor(history domain.CreditHistory) domain.CreditScoreFactor {
+	days := max(accountAgeDays(history.User, history.Now), 0)
+	return domain.CreditScoreFactor{
+		Name:        "account_age",
+		Points:      min(days, accountAgeFullDays) * accountAgeMaxPoints / accountAgeFullDays,
+		Description: fmt.Sprintf("account opened %d days ago", days),
+	}
+}
+
+// endOfDay returns the last instant of the day t falls on.
+func endOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location()).Add(-time.Nanosecond)
+}
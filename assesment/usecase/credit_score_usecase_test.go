@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// paidInstallment returns installment number of 100.00 USD principal, paid daysLate days after it fell due.
+func paidInstallment(number int, daysLate int) domain.Installment {
+	inst := dueInstallment(number, 10000, 0, 0, 0)
+	paidAt := inst.DueDate.AddDate(0, 0, daysLate).Add(12 * time.Hour)
+	inst.PrincipalPaid, inst.PaidAt = usd(10000), &paidAt
+	return inst
+}
+
+func TestRepaymentHistoryScorer(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	user := func(ageDays int) domain.User {
+		return domain.User{ID: primitive.NewObjectIDFromTimestamp(now.AddDate(0, 0, -ageDays))}
+	}
+
+	tests := []struct {
+		name  string
+		user  domain.User
+		loans []domain.Loan
+		score int
+		band  string
+	}{
+		{"new borrower gets the base score", user(0), nil, 600, "fair"},
+		{"long-standing borrower who repaid on time", user(4 * 365), []domain.Loan{
+			{Status: domain.LoanStatusPaidOff, Amount: usd(20000), Schedule: []domain.Installment{paidInstallment(1, 0), paidInstallment(2, 0)}},
+			{Status: domain.LoanStatusPaidOff, Amount: usd(10000), Schedule: []domain.Installment{paidInstallment(3, 0)}},
+		}, 810, "excellent"},
+		{"defaults are floored at the minimum score", user(0), []domain.Loan{
+			{Status: domain.LoanStatusDefaulted, Amount: usd(10000), Schedule: []domain.Installment{dueInstallment(1, 10000, 0, 0, 0)}},
+			{Status: domain.LoanStatusWrittenOff, Amount: usd(10000), Schedule: []domain.Installment{dueInstallment(2, 10000, 0, 0, 0)}},
+		}, domain.MinCreditScore, "poor"},
+		{"half the installments late and half the principal outstanding", user(0), []domain.Loan{
+			{Status: domain.LoanStatusActive, Amount: usd(20000), Schedule: []domain.Installment{
+				paidInstallment(1, 0), paidInstallment(2, 10), dueInstallment(7, 10000, 0, 0, 0),
+			}},
+		}, 585, "fair"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := NewRepaymentHistoryScorer().Score(domain.CreditHistory{User: tt.user, Loans: tt.loans, Now: now})
+
+			if score.Score != tt.score {
+				t.Errorf("score %d, want %d (factors %+v)", score.Score, tt.score, score.Factors)
+			}
+			if score.Band != tt.band {
+				t.Errorf("band %s, want %s", score.Band, tt.band)
+			}
+			if score.BaseScore != repaymentBaseScore || len(score.Factors) != 5 {
+				t.Errorf("got base %d and %d factors, want %d and 5", score.BaseScore, len(score.Factors), repaymentBaseScore)
+			}
+		})
+	}
+}
+
+func TestOnTimeFactor(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule []domain.Installment
+		points   int
+	}{
+		{"nothing due yet", []domain.Installment{dueInstallment(7, 10000, 0, 0, 0)}, 0},
+		{"paid on the due date", []domain.Installment{paidInstallment(1, 0)}, onTimeMaxPoints},
+		{"paid a day late", []domain.Installment{paidInstallment(1, 1)}, onTimeMinPoints},
+		{"unpaid past due", []domain.Installment{dueInstallment(1, 10000, 0, 0, 0)}, onTimeMinPoints},
+		{"one of two on time", []domain.Installment{paidInstallment(1, 0), paidInstallment(2, 3)}, -15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := domain.CreditHistory{Loans: []domain.Loan{{Schedule: tt.schedule}}, Now: now}
+			if got := onTimeFactor(history).Points; got != tt.points {
+				t.Errorf("points %d, want %d", got, tt.points)
+			}
+		})
+	}
+}
+
+func TestCreditScoreBand(t *testing.T) {
+	tests := []struct {
+		score int
+		want  string
+	}{
+		{300, "poor"}, {579, "poor"}, {580, "fair"}, {669, "fair"}, {670, "good"},
+		{739, "good"}, {740, "very_good"}, {799, "very_good"}, {800, "excellent"}, {850, "excellent"},
+	}
+
+	for _, tt := range tests {
+		if got := domain.CreditScoreBand(tt.score); got != tt.want {
+			t.Errorf("CreditScoreBand(%d) = %s, want %s", tt.score, got, tt.want)
+		}
+	}
+}
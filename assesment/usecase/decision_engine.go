@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+type decisionEngine struct {
+	rules []domain.CreditRule
+}
+
+// NewDecisionEngine creates a DecisionEngine that evaluates applications against rules.
+func NewDecisionEngine(rules ...domain.CreditRule) domain.DecisionEngine {
+	return &decisionEngine{
+		rules: rules,
+	}
+}
+
+// Evaluate runs every rule against the application. The most severe outcome wins and the
+// reasons of every failed rule are kept. Without any rules, applications are referred.
+func (e *decisionEngine) Evaluate(app domain.CreditApplication) domain.CreditDecision {
+	decision := domain.CreditDecision{
+		Outcome:   domain.DecisionApprove,
+		Results:   make([]domain.RuleResult, 0, len(e.rules)),
+		DecidedAt: app.Now,
+	}
+
+	if len(e.rules) == 0 {
+		decision.Outcome = domain.DecisionRefer
+		decision.Reasons = []string{"no credit rules are configured"}
+		return decision
+	}
+
+	for _, rule := range e.rules {
+		result := rule.Evaluate(app)
+		decision.Results = append(decision.Results, result)
+		if result.Outcome != domain.DecisionApprove {
+			decision.Outcome = decision.Outcome.Worse(result.Outcome)
+			decision.Reasons = append(decision.Reasons, result.Reason)
+		}
+	}
+
+	return decision
+}
+
+// ruleCheck reports whether an application passes a configured rule, and why not if it does not.
+type ruleCheck func(config domain.RuleConfig, app domain.CreditApplication) (bool, string)
+
+// ruleChecks holds the check behind each declarative rule type.
+var ruleChecks = map[domain.RuleType]ruleCheck{
+	domain.RuleActiveAccount:   checkActiveAccount,
+	domain.RuleMinAccountAge:   checkAccountAge,
+	domain.RuleMaxActiveLoans:  checkActiveLoans,
+	domain.RuleMaxDebtToIncome: checkDebtToIncome,
+	domain.RuleAmountLimit:     checkAmountLimit,
+}
+
+// configuredRule is a CreditRule built from a declarative RuleConfig.
+type configuredRule struct {
+	config domain.RuleConfig
+	check  ruleCheck
+}
+
+// NewCreditRules builds the credit rules described by configs.
+func NewCreditRules(configs []domain.RuleConfig) ([]domain.CreditRule, error) {
+	rules := make([]domain.CreditRule, 0, len(configs))
+	for _, config := range configs {
+		check, ok := ruleChecks[config.Type]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", domain.ErrUnknownRuleType, config.Type)
+		}
+		if err := validateRuleConfig(config); err != nil {
+			return nil, err
+		}
+		if config.ReasonCode == "" {
+			config.ReasonCode = domain.DefaultRuleReasonCodes[config.Type]
+		}
+		rules = append(rules, &configuredRule{config: config, check: check})
+	}
+	return rules, nil
+}
+
+// Name returns the configured name of the rule.
+func (r *configuredRule) Name() string {
+	return r.config.Name
+}
+
+// Evaluate applies the rule, returning its configured outcome when the application fails it.
+func (r *configuredRule) Evaluate(app domain.CreditApplication) domain.RuleResult {
+	if ok, reason := r.check(r.config, app); !ok {
+		return domain.RuleResult{Rule: r.config.Name, Outcome: r.config.Outcome, Reason: reason, ReasonCode: r.config.ReasonCode}
+	}
+	return domain.RuleResult{Rule: r.config.Name, Outcome: domain.DecisionApprove}
+}
+
+// validateRuleConfig checks the fields every rule needs and the threshold its type expects.
+func validateRuleConfig(config domain.RuleConfig) error {
+	if strings.TrimSpace(config.Name) == "" ||
+		(config.Outcome != domain.DecisionRefer && config.Outcome != domain.DecisionDecline) ||
+		(config.ReasonCode != "" && !reasonCodePattern.MatchString(config.ReasonCode)) {
+		return domain.ErrInvalidRule
+	}
+
+	switch config.Type {
+	case domain.RuleMinAccountAge:
+		if config.Threshold < 0 {
+			return domain.ErrInvalidRule
+		}
+	case domain.RuleMaxActiveLoans, domain.RuleMaxDebtToIncome:
+		if config.Threshold <= 0 {
+			return domain.ErrInvalidRule
+		}
+	case domain.RuleAmountLimit:
+		if len(config.Limits) == 0 {
+			return domain.ErrInvalidRule
+		}
+		for _, limit := range config.Limits {
+			if err := limit.Min.Validate(); err != nil {
+				return err
+			}
+			if limit.Min.Currency != limit.Max.Currency || limit.Min.Cmp(limit.Max) > 0 {
+				return domain.ErrInvalidAmountLimit
+			}
+		}
+	}
+	return nil
+}
+
+// checkActiveAccount requires the borrower to have activated their account.
+func checkActiveAccount(_ domain.RuleConfig, app domain.CreditApplication) (bool, string) {
+	if !app.User.IsActive {
+		return false, "account is not activated"
+	}
+	return true, ""
+}
+
+// checkAccountAge requires the account to be at least Threshold days old.
+func checkAccountAge(config domain.RuleConfig, app domain.CreditApplication) (bool, string) {
+	if age := accountAgeDays(app.User, app.Now); float64(age) < config.Threshold {
+		return false, fmt.Sprintf("account is %d days old, %g required", age, config.Threshold)
+	}
+	return true, ""
+}
+
+// checkActiveLoans limits the number of loans the borrower holds that are not closed.
+func checkActiveLoans(config domain.RuleConfig, app domain.CreditApplication) (bool, string) {
+	if active := countActiveLoans(app.ExistingLoans); float64(active) >= config.Threshold {
+		return false, fmt.Sprintf("borrower already has %d active loans, at most %g allowed", active, config.Threshold)
+	}
+	return true, ""
+}
+
+// checkDebtToIncome limits the monthly repayments of the new loan and the borrower's loans
+// on book to Threshold percent of the declared monthly income. Only loans in the currency of
+// the income are counted.
+func checkDebtToIncome(config domain.RuleConfig, app domain.CreditApplication) (bool, string) {
+	income := app.Loan.MonthlyIncome
+	if !income.IsPositive() {
+		return false, "no monthly income declared"
+	}
+	if income.Currency != app.Loan.Amount.Currency {
+		return false, "monthly income must be declared in the loan currency"
+	}
+
+	schedule, err := generateSchedule(app.Loan, app.Now)
+	if err != nil {
+		return false, err.Error()
+	}
+	debt := monthlyRepayment(app.Loan, schedule)
+	for _, loan := range app.ExistingLoans {
+		if loan.Status.IsOnBook() && loan.Amount.Currency == income.Currency {
+			debt = debt.Add(monthlyRepayment(loan, loan.Schedule))
+		}
+	}
+
+	ratio := new(big.Rat).Quo(debt.Rat(), income.Rat())
+	if ratio.Cmp(domain.PercentRate(config.Threshold)) > 0 {
+		percent, _ := new(big.Rat).Mul(ratio, big.NewRat(100, 1)).Float64()
+		return false, fmt.Sprintf("debt-to-income ratio of %.1f%% exceeds %g%%", percent, config.Threshold)
+	}
+	return true, ""
+}
+
+// checkAmountLimit requires the amount to lie within the limit configured for its currency.
+func checkAmountLimit(config domain.RuleConfig, app domain.CreditApplication) (bool, string) {
+	amount := app.Loan.Amount
+	for _, limit := range config.Limits {
+		if limit.Min.Currency != amount.Currency {
+			continue
+		}
+		if amount.Cmp(limit.Min) < 0 || amount.Cmp(limit.Max) > 0 {
+			return false, fmt.Sprintf("amount %s is outside %s to %s", amount, limit.Min, limit.Max)
+		}
+		return true, ""
+	}
+	return false, fmt.Sprintf("no amount limit is configured for %s", amount.Currency)
+}
+
+// monthlyRepayment returns the principal and interest of the next unpaid installment of a
+// schedule, scaled to a monthly amount. Fees are one-off and left out.
+func monthlyRepayment(loan domain.Loan, schedule []domain.Installment) domain.Money {
+	for _, inst := range schedule {
+		if inst.IsPaid() {
+			continue
+		}
+		perPeriod := inst.Principal.Add(inst.Interest)
+		return perPeriod.Mul(big.NewRat(int64(loan.RepaymentFrequency.PeriodsPerYear()), 12))
+	}
+	return loan.Amount.Zero()
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// creditApplication returns an application for a 12 month loan of 1,200.00 USD at no interest
+// by an active user whose account was opened 100 days before now, declaring 1,000.00 USD a month.
+func creditApplication(now time.Time) domain.CreditApplication {
+	return domain.CreditApplication{
+		Loan: domain.Loan{
+			Amount:             usd(120000),
+			Term:               12,
+			RepaymentFrequency: domain.FrequencyMonthly,
+			AmortizationMethod: domain.AmortizationAnnuity,
+			MonthlyIncome:      usd(100000),
+		},
+		User: domain.User{ID: primitive.NewObjectIDFromTimestamp(now.AddDate(0, 0, -100)), IsActive: true},
+		Now:  now,
+	}
+}
+
+func TestDecisionEngine(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	rule := func(ruleType domain.RuleType, threshold float64, outcome domain.DecisionOutcome) domain.RuleConfig {
+		return domain.RuleConfig{Name: string(ruleType), Type: ruleType, Threshold: threshold, Outcome: outcome}
+	}
+
+	tests := []struct {
+		name    string
+		configs []domain.RuleConfig
+		change  func(*domain.CreditApplication)
+		want    domain.DecisionOutcome
+		reasons int
+	}{
+		{"no rules refer", nil, nil, domain.DecisionRefer, 1},
+		{"all rules pass", []domain.RuleConfig{
+			rule(domain.RuleActiveAccount, 0, domain.DecisionDecline),
+			rule(domain.RuleMinAccountAge, 30, domain.DecisionRefer),
+			rule(domain.RuleMaxActiveLoans, 2, domain.DecisionRefer),
+			rule(domain.RuleMaxDebtToIncome, 40, domain.DecisionRefer),
+		}, nil, domain.DecisionApprove, 0},
+		{"inactive account declines", []domain.RuleConfig{rule(domain.RuleActiveAccount, 0, domain.DecisionDecline)},
+			func(app *domain.CreditApplication) { app.User.IsActive = false }, domain.DecisionDecline, 1},
+		{"young account refers", []domain.RuleConfig{rule(domain.RuleMinAccountAge, 180, domain.DecisionRefer)},
+			nil, domain.DecisionRefer, 1},
+		{"too many active loans", []domain.RuleConfig{rule(domain.RuleMaxActiveLoans, 1, domain.DecisionRefer)},
+			func(app *domain.CreditApplication) {
+				app.ExistingLoans = []domain.Loan{{Status: domain.LoanStatusActive}, {Status: domain.LoanStatusPaidOff}}
+			}, domain.DecisionRefer, 1},
+		{"closed loans do not count", []domain.RuleConfig{rule(domain.RuleMaxActiveLoans, 1, domain.DecisionRefer)},
+			func(app *domain.CreditApplication) {
+				app.ExistingLoans = []domain.Loan{{Status: domain.LoanStatusPaidOff}, {Status: domain.LoanStatusRejected}}
+			}, domain.DecisionApprove, 0},
+		{"debt-to-income over the limit", []domain.RuleConfig{rule(domain.RuleMaxDebtToIncome, 5, domain.DecisionDecline)},
+			nil, domain.DecisionDecline, 1},
+		{"no income declared", []domain.RuleConfig{rule(domain.RuleMaxDebtToIncome, 40, domain.DecisionRefer)},
+			func(app *domain.CreditApplication) { app.Loan.MonthlyIncome = domain.Money{} }, domain.DecisionRefer, 1},
+		{"income in another currency", []domain.RuleConfig{rule(domain.RuleMaxDebtToIncome, 40, domain.DecisionRefer)},
+			func(app *domain.CreditApplication) { app.Loan.MonthlyIncome = domain.NewMoney(100000, "EUR") }, domain.DecisionRefer, 1},
+		{"most severe outcome wins", []domain.RuleConfig{
+			rule(domain.RuleMinAccountAge, 180, domain.DecisionRefer),
+			rule(domain.RuleActiveAccount, 0, domain.DecisionDecline),
+		}, func(app *domain.CreditApplication) { app.User.IsActive = false }, domain.DecisionDecline, 2},
+		{"amount within the limit", []domain.RuleConfig{{Name: "limit", Type: domain.RuleAmountLimit, Outcome: domain.DecisionDecline,
+			Limits: []domain.AmountLimit{{Min: usd(10000), Max: usd(500000)}}}}, nil, domain.DecisionApprove, 0},
+		{"amount above the limit", []domain.RuleConfig{{Name: "limit", Type: domain.RuleAmountLimit, Outcome: domain.DecisionDecline,
+			Limits: []domain.AmountLimit{{Min: usd(10000), Max: usd(100000)}}}}, nil, domain.DecisionDecline, 1},
+		{"no limit for the currency", []domain.RuleConfig{{Name: "limit", Type: domain.RuleAmountLimit, Outcome: domain.DecisionRefer,
+			Limits: []domain.AmountLimit{{Min: domain.NewMoney(100, "EUR"), Max: domain.NewMoney(1000000, "EUR")}}}}, nil, domain.DecisionRefer, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewCreditRules(tt.configs)
+			if err != nil {
+				t.Fatalf("NewCreditRules: %v", err)
+			}
+			app := creditApplication(now)
+			if tt.change != nil {
+				tt.change(&app)
+			}
+
+			decision := NewDecisionEngine(rules...).Evaluate(app)
+			if decision.Outcome != tt.want {
+				t.Errorf("outcome %s, want %s (reasons %v)", decision.Outcome, tt.want, decision.Reasons)
+			}
+			if len(decision.Reasons) != tt.reasons {
+				t.Errorf("got %d reasons %v, want %d", len(decision.Reasons), decision.Reasons, tt.reasons)
+			}
+			if len(decision.Results) != len(tt.configs) {
+				t.Errorf("got %d rule results, want %d", len(decision.Results), len(tt.configs))
+			}
+			if !decision.DecidedAt.Equal(now) {
+				t.Errorf("decided at %s, want %s", decision.DecidedAt, now)
+			}
+		})
+	}
+}
+
+func TestNewCreditRulesRejectsInvalidConfigs(t *testing.T) {
+	tests := []struct {
+		name   string
+		config domain.RuleConfig
+		want   error
+	}{
+		{"unknown type", domain.RuleConfig{Name: "x", Type: "credit_bureau", Outcome: domain.DecisionRefer}, domain.ErrUnknownRuleType},
+		{"no name", domain.RuleConfig{Type: domain.RuleActiveAccount, Outcome: domain.DecisionRefer}, domain.ErrInvalidRule},
+		{"approve outcome", domain.RuleConfig{Name: "x", Type: domain.RuleActiveAccount, Outcome: domain.DecisionApprove}, domain.ErrInvalidRule},
+		{"no loan count", domain.RuleConfig{Name: "x", Type: domain.RuleMaxActiveLoans, Outcome: domain.DecisionRefer}, domain.ErrInvalidRule},
+		{"negative account age", domain.RuleConfig{Name: "x", Type: domain.RuleMinAccountAge, Threshold: -1, Outcome: domain.DecisionRefer}, domain.ErrInvalidRule},
+		{"no limits", domain.RuleConfig{Name: "x", Type: domain.RuleAmountLimit, Outcome: domain.DecisionRefer}, domain.ErrInvalidRule},
+		{"inverted limit", domain.RuleConfig{Name: "x", Type: domain.RuleAmountLimit, Outcome: domain.DecisionRefer,
+			Limits: []domain.AmountLimit{{Min: usd(500), Max: usd(100)}}}, domain.ErrInvalidAmountLimit},
+		{"malformed reason code", domain.RuleConfig{Name: "x", Type: domain.RuleActiveAccount, Outcome: domain.DecisionDecline, ReasonCode: "Not Verified"}, domain.ErrInvalidRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCreditRules([]domain.RuleConfig{tt.config}); !errors.Is(err, tt.want) {
+				t.Errorf("NewCreditRules = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleReasonCodes(t *testing.T) {
+	rules, err := NewCreditRules([]domain.RuleConfig{
+		{Name: "activated", Type: domain.RuleActiveAccount, Outcome: domain.DecisionDecline},
+		{Name: "loans", Type: domain.RuleMaxActiveLoans, Threshold: 1, Outcome: domain.DecisionDecline, ReasonCode: "too_many_loans"},
+	})
+	if err != nil {
+		t.Fatalf("NewCreditRules: %v", err)
+	}
+	app := domain.CreditApplication{ExistingLoans: []domain.Loan{{Status: domain.LoanStatusActive}}, Now: time.Now()}
+
+	want := []string{"unable_to_verify_identity", "too_many_loans"}
+	for i, rule := range rules {
+		if result := rule.Evaluate(app); result.ReasonCode != want[i] {
+			t.Errorf("rule %s failed with reason code %q, want %q", rule.Name(), result.ReasonCode, want[i])
+		}
+	}
+}
+
+func TestDecisionOutcomeLoanStatus(t *testing.T) {
+	tests := map[domain.DecisionOutcome]domain.LoanStatus{
+		domain.DecisionApprove: domain.LoanStatusApproved,
+		domain.DecisionRefer:   domain.LoanStatusUnderReview,
+		domain.DecisionDecline: domain.LoanStatusRejected,
+	}
+
+	for outcome, want := range tests {
+		if got := outcome.LoanStatus(); got != want {
+			t.Errorf("%s.LoanStatus() = %s, want %s", outcome, got, want)
+		}
+	}
+}
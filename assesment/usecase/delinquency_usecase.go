@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type delinquencyUsecase struct {
+	loanRepo    domain.LoanRepository
+	loanUsecase domain.LoanUsecase
+	policy      domain.DelinquencyPolicy
+	history     domain.LoanHistoryRepository
+}
+
+// NewDelinquencyUsecase creates a new instance of DelinquencyUsecase. Status changes go
+// through loanUsecase so they follow the loan state machine.
+func NewDelinquencyUsecase(loanRepo domain.LoanRepository, loanUsecase domain.LoanUsecase, policy domain.DelinquencyPolicy, history domain.LoanHistoryRepository) (domain.DelinquencyUsecase, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &delinquencyUsecase{
+		loanRepo:    loanRepo,
+		loanUsecase: loanUsecase,
+		policy:      policy,
+		history:     history,
+	}, nil
+}
+
+// RunDelinquencyCheck refreshes the days past due and aging bucket of every loan being
+// repaid, and moves loans to delinquent, defaulted or back to active as the policy says.
+// A loan that fails to update is reported in the run and does not stop the others.
+func (uc *delinquencyUsecase) RunDelinquencyCheck(now time.Time) (domain.DelinquencyRun, error) {
+	run := domain.DelinquencyRun{StartedAt: time.Now()}
+
+	loans, err := uc.loanRepo.GetLoansByStatus(domain.LoanStatusActive, domain.LoanStatusDelinquent, domain.LoanStatusDefaulted)
+	if err != nil {
+		return run, err
+	}
+
+	for _, loan := range loans {
+		run.LoansChecked++
+
+		dpd := daysPastDue(loan.Schedule, now)
+		if dpd != loan.DaysPastDue || loan.AgingBucket != domain.AgingBucketFor(dpd) {
+			before := delinquencyState(loan)
+			loan.DaysPastDue = dpd
+			loan.AgingBucket = domain.AgingBucketFor(dpd)
+			if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+				run.Errors = append(run.Errors, fmt.Sprintf("loan %d: %v", loan.ID, err))
+				continue
+			}
+			if err := recordEvent(uc.history, domain.LoanEvent{
+				LoanID: loan.ID,
+				Type:   domain.LoanEventDelinquencyUpdated,
+				Before: before,
+				After:  delinquencyState(loan),
+			}); err != nil {
+				run.Errors = append(run.Errors, fmt.Sprintf("loan %d: %v", loan.ID, err))
+			}
+		}
+
+		next := delinquencyStatus(loan.Status, dpd, uc.policy)
+		if next == loan.Status {
+			continue
+		}
+		if err := uc.loanUsecase.TransitionLoan(&loan, next, primitive.NilObjectID); err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("loan %d: %v", loan.ID, err))
+			continue
+		}
+
+		switch next {
+		case domain.LoanStatusDelinquent:
+			run.MarkedDelinquent++
+		case domain.LoanStatusDefaulted:
+			run.MarkedDefaulted++
+		case domain.LoanStatusActive:
+			run.Cured++
+		}
+	}
+
+	run.FinishedAt = time.Now()
+	return run, nil
+}
+
+// delinquencyStatus returns the state a loan in status should be in at dpd days past due.
+// Defaulted loans stay defaulted until they are repaid or written off.
+func delinquencyStatus(status domain.LoanStatus, dpd int, policy domain.DelinquencyPolicy) domain.LoanStatus {
+	switch status {
+	case domain.LoanStatusActive, domain.LoanStatusDelinquent:
+		if dpd >= policy.DefaultAfterDays {
+			return domain.LoanStatusDefaulted
+		}
+		if dpd >= policy.DelinquentAfterDays {
+			return domain.LoanStatusDelinquent
+		}
+		return domain.LoanStatusActive
+	}
+	return status
+}
+
+// daysPastDue returns how many whole days the oldest unpaid installment of a schedule is
+// past its due date at now, or 0 when nothing is overdue.
+func daysPastDue(schedule []domain.Installment, now time.Time) int {
+	for _, inst := range schedule {
+		if inst.IsPaid() {
+			continue
+		}
+		return overdueDays(inst.DueDate, now)
+	}
+	return 0
+}
+
+// startOfDay returns midnight of the day t falls on.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDelinquencyStatus(t *testing.T) {
+	policy := domain.DefaultDelinquencyPolicy
+
+	tests := []struct {
+		status domain.LoanStatus
+		dpd    int
+		want   domain.LoanStatus
+	}{
+		{domain.LoanStatusActive, 0, domain.LoanStatusActive},
+		{domain.LoanStatusActive, 1, domain.LoanStatusDelinquent},
+		{domain.LoanStatusActive, 90, domain.LoanStatusDelinquent},
+		{domain.LoanStatusActive, 91, domain.LoanStatusDefaulted},
+		{domain.LoanStatusDelinquent, 0, domain.LoanStatusActive},
+		{domain.LoanStatusDelinquent, 45, domain.LoanStatusDelinquent},
+		{domain.LoanStatusDelinquent, 120, domain.LoanStatusDefaulted},
+		{domain.LoanStatusDefaulted, 0, domain.LoanStatusDefaulted},
+		{domain.LoanStatusPaidOff, 30, domain.LoanStatusPaidOff},
+	}
+
+	for _, tt := range tests {
+		if got := delinquencyStatus(tt.status, tt.dpd, policy); got != tt.want {
+			t.Errorf("delinquencyStatus(%s, %d) = %s, want %s", tt.status, tt.dpd, got, tt.want)
+		}
+	}
+}
+
+func TestDaysPastDue(t *testing.T) {
+	now := time.Date(2024, time.March, 20, 9, 0, 0, 0, time.UTC)
+	paid := paidInstallment(1, 0)
+
+	tests := []struct {
+		name     string
+		schedule []domain.Installment
+		want     int
+	}{
+		{"nothing overdue", []domain.Installment{paid, dueInstallment(4, 10000, 0, 0, 0)}, 0},
+		{"oldest unpaid installment counts", []domain.Installment{paid, dueInstallment(2, 10000, 0, 0, 0), dueInstallment(3, 10000, 0, 0, 0)}, 34},
+		{"fully paid", []domain.Installment{paid}, 0},
+		{"empty schedule", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := daysPastDue(tt.schedule, now); got != tt.want {
+				t.Errorf("daysPastDue = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunDelinquencyCheck(t *testing.T) {
+	now := time.Date(2024, time.June, 20, 9, 0, 0, 0, time.UTC)
+	overdue := func(id uint, status domain.LoanStatus, days int) domain.Loan {
+		inst := dueInstallment(1, 10000, 0, 0, 0)
+		inst.DueDate = time.Date(2024, time.June, 20-days, 0, 0, 0, 0, time.UTC)
+		return domain.Loan{ID: id, Amount: usd(10000), Status: status, Schedule: []domain.Installment{inst}}
+	}
+	current := overdue(1, domain.LoanStatusActive, -5)
+	current.AgingBucket = domain.AgingCurrent
+	caughtUp := domain.Loan{ID: 4, Amount: usd(10000), Status: domain.LoanStatusDelinquent, DaysPastDue: 12, AgingBucket: domain.Aging1To30,
+		Schedule: []domain.Installment{paidInstallment(1, 12)}}
+
+	loans := newMemoryLoans(
+		current,
+		overdue(2, domain.LoanStatusActive, 10),
+		overdue(3, domain.LoanStatusActive, 95),
+		caughtUp,
+		overdue(5, domain.LoanStatusDefaulted, 0),
+		overdue(6, domain.LoanStatusPaidOff, 40),
+	)
+	history := &memoryHistory{}
+	loanUsecase := NewLoanUsecase(loans, nil, nil, nil, nil, nil, nil, nil, nil, domain.DualApprovalPolicy{}, nil, nil, history)
+	uc, err := NewDelinquencyUsecase(loans, loanUsecase, domain.DefaultDelinquencyPolicy, history)
+	if err != nil {
+		t.Fatalf("NewDelinquencyUsecase: %v", err)
+	}
+
+	run, err := uc.RunDelinquencyCheck(now)
+	if err != nil {
+		t.Fatalf("RunDelinquencyCheck: %v", err)
+	}
+	if run.LoansChecked != 5 || run.MarkedDelinquent != 1 || run.MarkedDefaulted != 1 || run.Cured != 1 || len(run.Errors) != 0 {
+		t.Errorf("run %+v, want 5 loans checked, one marked delinquent, one defaulted and one cured", run)
+	}
+
+	want := []struct {
+		id     uint
+		status domain.LoanStatus
+		dpd    int
+		bucket domain.AgingBucket
+	}{
+		{1, domain.LoanStatusActive, 0, domain.AgingCurrent},
+		{2, domain.LoanStatusDelinquent, 10, domain.Aging1To30},
+		{3, domain.LoanStatusDefaulted, 95, domain.AgingOver90},
+		{4, domain.LoanStatusActive, 0, domain.AgingCurrent},
+		{5, domain.LoanStatusDefaulted, 0, domain.AgingCurrent},
+		{6, domain.LoanStatusPaidOff, 0, ""},
+	}
+	for _, w := range want {
+		stored, _ := loans.GetLoanByID(w.id)
+		if stored.Status != w.status || stored.DaysPastDue != w.dpd || stored.AgingBucket != w.bucket {
+			t.Errorf("loan %d is %s at %d days past due in %q, want %s at %d in %q",
+				w.id, stored.Status, stored.DaysPastDue, stored.AgingBucket, w.status, w.dpd, w.bucket)
+		}
+	}
+
+	var changes []uint
+	for _, event := range history.events {
+		if event.Type == domain.LoanEventStatusChanged {
+			changes = append(changes, event.LoanID)
+		}
+	}
+	if !slices.Equal(changes, []uint{2, 3, 4}) {
+		t.Errorf("recorded status changes for loans %v, want 2, 3 and 4", changes)
+	}
+}
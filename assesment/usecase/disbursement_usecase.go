@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type disbursementUsecase struct {
+	loanRepo         domain.LoanRepository
+	disbursementRepo domain.DisbursementRepository
+	loanUsecase      domain.LoanUsecase
+	providers        map[domain.PayoutMethod]domain.PayoutProvider
+}
+
+// NewDisbursementUsecase creates a new instance of DisbursementUsecase that pays out
+// through providers, one per payout method. Status changes go through loanUsecase so they
+// follow the loan state machine.
+func NewDisbursementUsecase(loanRepo domain.LoanRepository, disbursementRepo domain.DisbursementRepository, loanUsecase domain.LoanUsecase, providers ...domain.PayoutProvider) domain.DisbursementUsecase {
+	byMethod := make(map[domain.PayoutMethod]domain.PayoutProvider, len(providers))
+	for _, provider := range providers {
+		byMethod[provider.Method()] = provider
+	}
+
+	return &disbursementUsecase{
+		loanRepo:         loanRepo,
+		disbursementRepo: disbursementRepo,
+		loanUsecase:      loanUsecase,
+		providers:        byMethod,
+	}
+}
+
+// DisburseLoan pays out an approved loan to destination. A loan has at most one
+// disbursement in progress or confirmed; failed ones can be retried or replaced. The loan
+// moves on to active only once the payout is confirmed.
+func (uc *disbursementUsecase) DisburseLoan(loanID uint, method domain.PayoutMethod, destination domain.PayoutDestination, requestedBy primitive.ObjectID) (domain.Disbursement, error) {
+	provider, ok := uc.providers[method]
+	if !ok {
+		return domain.Disbursement{}, domain.ErrUnsupportedPayoutMethod
+	}
+	destination = trimDestination(destination)
+	if !validDestination(method, destination) {
+		return domain.Disbursement{}, domain.ErrInvalidDestination
+	}
+
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.Disbursement{}, err
+	}
+	if loan.Status != domain.LoanStatusApproved {
+		return domain.Disbursement{}, domain.ErrLoanNotApproved
+	}
+
+	existing, err := uc.disbursementRepo.GetDisbursementsByLoan(loanID)
+	if err != nil {
+		return domain.Disbursement{}, err
+	}
+	// A failed disbursement may have been paid out after all, e.g. when the provider could
+	// not be reached. Its replacement is sent under the same reference so the provider
+	// returns that payout instead of making a second one.
+	reference := ""
+	for _, disbursement := range existing {
+		if disbursement.Status != domain.DisbursementFailed {
+			return domain.Disbursement{}, domain.ErrDisbursementInProgress
+		}
+		reference = disbursement.PayoutReference()
+	}
+
+	now := time.Now()
+	id := primitive.NewObjectID()
+	if reference == "" {
+		reference = id.Hex()
+	}
+	disbursement := domain.Disbursement{
+		ID:          id,
+		Reference:   reference,
+		LoanID:      loan.ID,
+		Amount:      loan.Amount,
+		Method:      method,
+		Destination: destination,
+		Status:      domain.DisbursementProcessing,
+		Attempts:    []domain.DisbursementAttempt{},
+		RequestedBy: requestedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	// The disbursement is stored before anything is sent so every payout can be traced.
+	if err := uc.disbursementRepo.CreateDisbursement(disbursement); err != nil {
+		return domain.Disbursement{}, err
+	}
+
+	return uc.send(provider, disbursement)
+}
+
+// RetryDisbursement sends a failed disbursement again, up to MaxDisbursementAttempts times.
+func (uc *disbursementUsecase) RetryDisbursement(loanID uint, id primitive.ObjectID) (domain.Disbursement, error) {
+	disbursement, err := uc.getDisbursement(loanID, id)
+	if err != nil {
+		return domain.Disbursement{}, err
+	}
+	if disbursement.Status != domain.DisbursementFailed {
+		return domain.Disbursement{}, domain.ErrDisbursementNotRetryable
+	}
+	if len(disbursement.Attempts) >= domain.MaxDisbursementAttempts {
+		return domain.Disbursement{}, domain.ErrMaxAttemptsReached
+	}
+
+	provider, ok := uc.providers[disbursement.Method]
+	if !ok {
+		return domain.Disbursement{}, domain.ErrUnsupportedPayoutMethod
+	}
+
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.Disbursement{}, err
+	}
+	if loan.Status != domain.LoanStatusApproved {
+		return domain.Disbursement{}, domain.ErrLoanNotApproved
+	}
+
+	return uc.send(provider, disbursement)
+}
+
+// ConfirmDisbursement settles a disbursement waiting for confirmation. Manual payouts are
+// confirmed by the admin, with reference identifying the payment made. Other payouts are
+// checked with their provider and stay processing until it confirms them. Confirming an
+// already confirmed disbursement finishes activating its loan if that was interrupted.
+func (uc *disbursementUsecase) ConfirmDisbursement(loanID uint, id primitive.ObjectID, reference string) (domain.Disbursement, error) {
+	disbursement, err := uc.getDisbursement(loanID, id)
+	if err != nil {
+		return domain.Disbursement{}, err
+	}
+
+	switch disbursement.Status {
+	case domain.DisbursementConfirmed:
+		return disbursement, uc.activateLoan(disbursement)
+	case domain.DisbursementFailed:
+		return domain.Disbursement{}, domain.ErrDisbursementNotPending
+	}
+
+	if disbursement.Method == domain.PayoutManual {
+		if reference = strings.TrimSpace(reference); reference != "" {
+			disbursement.ProviderReference = reference
+		}
+		return uc.settle(disbursement, domain.PayoutConfirmed)
+	}
+
+	provider, ok := uc.providers[disbursement.Method]
+	if !ok {
+		return domain.Disbursement{}, domain.ErrUnsupportedPayoutMethod
+	}
+	result, err := provider.Status(disbursement.ProviderReference)
+	if err != nil {
+		return domain.Disbursement{}, err
+	}
+	if result.Status == domain.PayoutFailed && len(disbursement.Attempts) > 0 {
+		disbursement.Attempts[len(disbursement.Attempts)-1].Status = domain.PayoutFailed
+		disbursement.Attempts[len(disbursement.Attempts)-1].Error = result.FailureReason
+	}
+	return uc.settle(disbursement, result.Status)
+}
+
+// GetLoanDisbursements retrieves every disbursement of a loan with its attempts.
+func (uc *disbursementUsecase) GetLoanDisbursements(loanID uint) ([]domain.Disbursement, error) {
+	if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+		return nil, err
+	}
+
+	return uc.disbursementRepo.GetDisbursementsByLoan(loanID)
+}
+
+// send makes one attempt to pay out a disbursement and records its outcome. A provider
+// error fails the attempt; every attempt is sent under the disbursement's reference so a
+// retry of a payout that did go out is not paid twice.
+func (uc *disbursementUsecase) send(provider domain.PayoutProvider, disbursement domain.Disbursement) (domain.Disbursement, error) {
+	attempt := domain.DisbursementAttempt{Number: len(disbursement.Attempts) + 1, At: time.Now()}
+
+	result, err := provider.Send(domain.PayoutRequest{
+		Reference:   disbursement.PayoutReference(),
+		LoanID:      disbursement.LoanID,
+		Amount:      disbursement.Amount,
+		Destination: disbursement.Destination,
+	})
+	switch {
+	case err != nil:
+		attempt.Status = domain.PayoutFailed
+		attempt.Error = err.Error()
+	case result.Status == domain.PayoutFailed:
+		attempt.Status = domain.PayoutFailed
+		attempt.ProviderReference = result.ProviderReference
+		attempt.Error = result.FailureReason
+	default:
+		attempt.Status = result.Status
+		attempt.ProviderReference = result.ProviderReference
+		disbursement.ProviderReference = result.ProviderReference
+	}
+	disbursement.Attempts = append(disbursement.Attempts, attempt)
+
+	return uc.settle(disbursement, attempt.Status)
+}
+
+// settle stores the disbursement in the state matching a payout status and activates the
+// loan once the payout is confirmed.
+func (uc *disbursementUsecase) settle(disbursement domain.Disbursement, status domain.PayoutStatus) (domain.Disbursement, error) {
+	now := time.Now()
+	switch status {
+	case domain.PayoutConfirmed:
+		disbursement.Status = domain.DisbursementConfirmed
+		disbursement.ConfirmedAt = &now
+	case domain.PayoutFailed:
+		disbursement.Status = domain.DisbursementFailed
+	default:
+		disbursement.Status = domain.DisbursementProcessing
+	}
+	disbursement.UpdatedAt = now
+
+	if err := uc.disbursementRepo.UpdateDisbursement(disbursement); err != nil {
+		return domain.Disbursement{}, err
+	}
+
+	if disbursement.Status != domain.DisbursementConfirmed {
+		return disbursement, nil
+	}
+	return disbursement, uc.activateLoan(disbursement)
+}
+
+// activateLoan moves the loan of a confirmed disbursement through disbursed to active. The
+// schedule is rebuilt from the day the funds arrived, which is also the last day no
+// interest accrues for. Each step checks where the loan is, so it can be resumed; a loan
+// that is neither approved nor disbursed cannot take the payout and is refused.
+func (uc *disbursementUsecase) activateLoan(disbursement domain.Disbursement) error {
+	loan, err := uc.loanRepo.GetLoanByID(disbursement.LoanID)
+	if err != nil {
+		return err
+	}
+
+	if loan.Status == domain.LoanStatusApproved {
+		disbursedAt := *disbursement.ConfirmedAt
+		schedule, err := generateSchedule(loan, disbursedAt)
+		if err != nil {
+			return err
+		}
+		accruedTo := accrualDay(disbursedAt)
+
+		loan.Schedule = schedule
+		loan.OutstandingBalance = outstandingBalance(loan)
+		loan.InterestAccruedTo = &accruedTo
+		loan.DisbursedAt = &disbursedAt
+		loan.UpdatedAt = time.Now()
+		if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+			return err
+		}
+
+		if err := uc.loanUsecase.TransitionLoan(&loan, domain.LoanStatusDisbursed, disbursement.RequestedBy); err != nil {
+			return err
+		}
+	}
+
+	if loan.Status != domain.LoanStatusDisbursed {
+		// The loan was closed or moved on while the payout was out; the money needs chasing by hand.
+		return &domain.InvalidTransitionError{From: loan.Status, To: domain.LoanStatusDisbursed}
+	}
+	return uc.loanUsecase.TransitionLoan(&loan, domain.LoanStatusActive, disbursement.RequestedBy)
+}
+
+// getDisbursement retrieves a disbursement and checks that it belongs to the loan.
+func (uc *disbursementUsecase) getDisbursement(loanID uint, id primitive.ObjectID) (domain.Disbursement, error) {
+	disbursement, err := uc.disbursementRepo.GetDisbursementByID(id)
+	if err != nil {
+		return domain.Disbursement{}, err
+	}
+	if disbursement.LoanID != loanID {
+		return domain.Disbursement{}, domain.ErrDisbursementNotFound
+	}
+	return disbursement, nil
+}
+
+// validDestination reports whether destination has what method needs to pay out to it.
+func validDestination(method domain.PayoutMethod, destination domain.PayoutDestination) bool {
+	switch method {
+	case domain.PayoutBankTransfer:
+		return destination.AccountNumber != ""
+	case domain.PayoutMobileMoney:
+		return destination.PhoneNumber != ""
+	}
+	return true
+}
+
+// trimDestination removes surrounding whitespace from every field of destination.
+func trimDestination(destination domain.PayoutDestination) domain.PayoutDestination {
+	return domain.PayoutDestination{
+		AccountName:   strings.TrimSpace(destination.AccountName),
+		AccountNumber: strings.TrimSpace(destination.AccountNumber),
+		BankCode:      strings.TrimSpace(destination.BankCode),
+		PhoneNumber:   strings.TrimSpace(destination.PhoneNumber),
+	}
+}
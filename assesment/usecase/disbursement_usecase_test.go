@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryDisbursements keeps disbursements in memory in the order they were created.
+type memoryDisbursements struct {
+	domain.DisbursementRepository
+	disbursements []domain.Disbursement
+}
+
+func (r *memoryDisbursements) CreateDisbursement(disbursement domain.Disbursement) error {
+	r.disbursements = append(r.disbursements, disbursement)
+	return nil
+}
+
+func (r *memoryDisbursements) GetDisbursementByID(id primitive.ObjectID) (domain.Disbursement, error) {
+	for _, disbursement := range r.disbursements {
+		if disbursement.ID == id {
+			return disbursement, nil
+		}
+	}
+	return domain.Disbursement{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryDisbursements) GetDisbursementsByLoan(loanID uint) ([]domain.Disbursement, error) {
+	disbursements := []domain.Disbursement{}
+	for _, disbursement := range r.disbursements {
+		if disbursement.LoanID == loanID {
+			disbursements = append(disbursements, disbursement)
+		}
+	}
+	return disbursements, nil
+}
+
+func (r *memoryDisbursements) UpdateDisbursement(disbursement domain.Disbursement) error {
+	for i := range r.disbursements {
+		if r.disbursements[i].ID == disbursement.ID {
+			r.disbursements[i] = disbursement
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+// unreachableProvider pays out bank transfers straight away, once per reference, but
+// loses its answer for the first lost sends as if the connection had dropped.
+type unreachableProvider struct {
+	lost    int
+	payouts map[string]domain.PayoutResult
+	sent    []string
+}
+
+func (p *unreachableProvider) Method() domain.PayoutMethod {
+	return domain.PayoutBankTransfer
+}
+
+func (p *unreachableProvider) Send(request domain.PayoutRequest) (domain.PayoutResult, error) {
+	p.sent = append(p.sent, request.Reference)
+	result, ok := p.payouts[request.Reference]
+	if !ok {
+		result = domain.PayoutResult{ProviderReference: "bank-" + request.Reference, Status: domain.PayoutConfirmed}
+		p.payouts[request.Reference] = result
+	}
+	if p.lost > 0 {
+		p.lost--
+		return domain.PayoutResult{}, errors.New("connection reset")
+	}
+	return result, nil
+}
+
+func (p *unreachableProvider) Status(providerReference string) (domain.PayoutResult, error) {
+	return domain.PayoutResult{ProviderReference: providerReference, Status: domain.PayoutConfirmed}, nil
+}
+
+var bankAccount = domain.PayoutDestination{AccountName: "Abebe", AccountNumber: "1000123"}
+
+// approvedLoan returns an approved 12 month loan of 1,200.00 USD at 10%.
+func approvedLoan() domain.Loan {
+	return domain.Loan{
+		ID:                 1,
+		Amount:             usd(120000),
+		Term:               12,
+		InterestRate:       10,
+		RepaymentFrequency: domain.FrequencyMonthly,
+		AmortizationMethod: domain.AmortizationAnnuity,
+		Status:             domain.LoanStatusApproved,
+	}
+}
+
+func newTestDisbursementUsecase(provider domain.PayoutProvider, loans ...domain.Loan) (domain.DisbursementUsecase, *memoryLoans, *memoryDisbursements, *memoryLedger) {
+	loanRepo := newMemoryLoans(loans...)
+	disbursements := &memoryDisbursements{}
+	ledger := &memoryLedger{}
+	loanUsecase := NewLoanUsecase(loanRepo, nil, disbursements, nil, nil, NewLedgerUsecase(ledger), nil, nil, nil, domain.DualApprovalPolicy{}, nil, nil, &memoryHistory{})
+	return NewDisbursementUsecase(loanRepo, disbursements, loanUsecase, provider), loanRepo, disbursements, ledger
+}
+
+func TestDisburseLoanActivatesTheLoanOnceConfirmed(t *testing.T) {
+	provider := &unreachableProvider{payouts: map[string]domain.PayoutResult{}}
+	uc, loans, _, ledger := newTestDisbursementUsecase(provider, approvedLoan())
+
+	disbursement, err := uc.DisburseLoan(1, domain.PayoutBankTransfer, bankAccount, primitive.NewObjectID())
+	if err != nil {
+		t.Fatalf("DisburseLoan: %v", err)
+	}
+	if disbursement.Status != domain.DisbursementConfirmed || len(disbursement.Attempts) != 1 {
+		t.Errorf("disbursement is %s after %d attempts, want confirmed after 1", disbursement.Status, len(disbursement.Attempts))
+	}
+	if disbursement.ProviderReference != "bank-"+disbursement.Reference {
+		t.Errorf("provider reference %q, want the provider's reference for %q", disbursement.ProviderReference, disbursement.Reference)
+	}
+
+	loan, _ := loans.GetLoanByID(1)
+	if loan.Status != domain.LoanStatusActive {
+		t.Errorf("loan is %s, want active", loan.Status)
+	}
+	if len(loan.Schedule) != 12 || loan.DisbursedAt == nil || loan.InterestAccruedTo == nil {
+		t.Errorf("loan has %d installments, disbursed at %v, accrued to %v; want a 12 month schedule from the payout",
+			len(loan.Schedule), loan.DisbursedAt, loan.InterestAccruedTo)
+	}
+	if !loan.Schedule[0].DueDate.After(*loan.DisbursedAt) {
+		t.Errorf("first installment due %s, want after the payout on %s", loan.Schedule[0].DueDate, loan.DisbursedAt)
+	}
+	if len(ledger.entries) != 1 {
+		t.Errorf("posted %d ledger entries, want the disbursement", len(ledger.entries))
+	}
+}
+
+func TestDisbursementRetriesAreSentUnderTheSameReference(t *testing.T) {
+	provider := &unreachableProvider{lost: 2, payouts: map[string]domain.PayoutResult{}}
+	uc, loans, disbursements, _ := newTestDisbursementUsecase(provider, approvedLoan())
+	admin := primitive.NewObjectID()
+
+	failed, err := uc.DisburseLoan(1, domain.PayoutBankTransfer, bankAccount, admin)
+	if err != nil {
+		t.Fatalf("DisburseLoan: %v", err)
+	}
+	if failed.Status != domain.DisbursementFailed || failed.Attempts[0].Error == "" {
+		t.Fatalf("disbursement is %s, want failed with the provider error", failed.Status)
+	}
+
+	retried, err := uc.RetryDisbursement(1, failed.ID)
+	if err != nil {
+		t.Fatalf("RetryDisbursement: %v", err)
+	}
+	if retried.Status != domain.DisbursementFailed || len(retried.Attempts) != 2 {
+		t.Fatalf("retried disbursement is %s after %d attempts, want failed after 2", retried.Status, len(retried.Attempts))
+	}
+
+	// The admin gives up on the failed disbursement and pays the loan out anew.
+	replacement, err := uc.DisburseLoan(1, domain.PayoutBankTransfer, bankAccount, admin)
+	if err != nil {
+		t.Fatalf("DisburseLoan again: %v", err)
+	}
+	if replacement.ID == failed.ID || replacement.Reference != failed.Reference {
+		t.Errorf("replacement %s sent under %q, want a new disbursement under %q", replacement.ID.Hex(), replacement.Reference, failed.Reference)
+	}
+	if replacement.Status != domain.DisbursementConfirmed {
+		t.Errorf("replacement is %s, want confirmed", replacement.Status)
+	}
+
+	for i, reference := range provider.sent {
+		if reference != failed.Reference {
+			t.Errorf("send %d used reference %q, want %q", i+1, reference, failed.Reference)
+		}
+	}
+	if len(provider.payouts) != 1 {
+		t.Errorf("provider made %d payouts, want 1", len(provider.payouts))
+	}
+	if len(disbursements.disbursements) != 2 {
+		t.Errorf("stored %d disbursements, want 2", len(disbursements.disbursements))
+	}
+	if loan, _ := loans.GetLoanByID(1); loan.Status != domain.LoanStatusActive {
+		t.Errorf("loan is %s, want active", loan.Status)
+	}
+}
+
+func TestDisburseLoanRefusals(t *testing.T) {
+	pending := approvedLoan()
+	pending.Status = domain.LoanStatusUnderReview
+
+	tests := []struct {
+		name        string
+		loan        domain.Loan
+		method      domain.PayoutMethod
+		destination domain.PayoutDestination
+		want        error
+	}{
+		{"no provider for the method", approvedLoan(), domain.PayoutMobileMoney, domain.PayoutDestination{PhoneNumber: "0911000000"}, domain.ErrUnsupportedPayoutMethod},
+		{"no account number", approvedLoan(), domain.PayoutBankTransfer, domain.PayoutDestination{AccountNumber: "  "}, domain.ErrInvalidDestination},
+		{"loan not approved", pending, domain.PayoutBankTransfer, bankAccount, domain.ErrLoanNotApproved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &unreachableProvider{payouts: map[string]domain.PayoutResult{}}
+			uc, _, disbursements, _ := newTestDisbursementUsecase(provider, tt.loan)
+
+			if _, err := uc.DisburseLoan(1, tt.method, tt.destination, primitive.NewObjectID()); err != tt.want {
+				t.Errorf("DisburseLoan = %v, want %v", err, tt.want)
+			}
+			if len(disbursements.disbursements) != 0 || len(provider.sent) != 0 {
+				t.Errorf("refused disbursement was stored or sent")
+			}
+		})
+	}
+}
+
+func TestRetryDisbursementLimits(t *testing.T) {
+	provider := &unreachableProvider{lost: domain.MaxDisbursementAttempts, payouts: map[string]domain.PayoutResult{}}
+	uc, _, _, _ := newTestDisbursementUsecase(provider, approvedLoan())
+
+	disbursement, err := uc.DisburseLoan(1, domain.PayoutBankTransfer, bankAccount, primitive.NewObjectID())
+	if err != nil {
+		t.Fatalf("DisburseLoan: %v", err)
+	}
+	if _, err := uc.RetryDisbursement(2, disbursement.ID); err != domain.ErrDisbursementNotFound {
+		t.Errorf("retry under another loan = %v, want %v", err, domain.ErrDisbursementNotFound)
+	}
+	for attempt := 2; attempt <= domain.MaxDisbursementAttempts; attempt++ {
+		if _, err := uc.RetryDisbursement(1, disbursement.ID); err != nil {
+			t.Fatalf("retry %d: %v", attempt, err)
+		}
+	}
+	if _, err := uc.RetryDisbursement(1, disbursement.ID); err != domain.ErrMaxAttemptsReached {
+		t.Errorf("retry past the limit = %v, want %v", err, domain.ErrMaxAttemptsReached)
+	}
+
+	confirmed, err := uc.DisburseLoan(1, domain.PayoutBankTransfer, bankAccount, primitive.NewObjectID())
+	if err != nil {
+		t.Fatalf("replacing the failed disbursement: %v", err)
+	}
+	if _, err := uc.RetryDisbursement(1, confirmed.ID); err != domain.ErrDisbursementNotRetryable {
+		t.Errorf("retry of a confirmed disbursement = %v, want %v", err, domain.ErrDisbursementNotRetryable)
+	}
+	if _, err := uc.DisburseLoan(1, domain.PayoutBankTransfer, bankAccount, primitive.NewObjectID()); err != domain.ErrLoanNotApproved {
+		t.Errorf("disbursing an active loan = %v, want %v", err, domain.ErrLoanNotApproved)
+	}
+}
+
+func TestConfirmedDisbursementActivatesOnlyAnOpenLoan(t *testing.T) {
+	tests := []struct {
+		status  domain.LoanStatus
+		want    domain.LoanStatus
+		wantErr bool
+	}{
+		{status: domain.LoanStatusApproved, want: domain.LoanStatusActive},
+		{status: domain.LoanStatusDisbursed, want: domain.LoanStatusActive},
+		{status: domain.LoanStatusCancelled, want: domain.LoanStatusCancelled, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			loan := approvedLoan()
+			loan.Status = tt.status
+			uc, loans, disbursements, _ := newTestDisbursementUsecase(&unreachableProvider{payouts: map[string]domain.PayoutResult{}}, loan)
+			confirmedAt := time.Now()
+			confirmed := domain.Disbursement{ID: primitive.NewObjectID(), LoanID: 1, Amount: loan.Amount, Status: domain.DisbursementConfirmed, ConfirmedAt: &confirmedAt}
+			disbursements.disbursements = append(disbursements.disbursements, confirmed)
+
+			_, err := uc.ConfirmDisbursement(1, confirmed.ID, "")
+			var transitionErr *domain.InvalidTransitionError
+			if tt.wantErr != errors.As(err, &transitionErr) || (!tt.wantErr && err != nil) {
+				t.Fatalf("ConfirmDisbursement = %v, want an invalid transition: %t", err, tt.wantErr)
+			}
+			if stored, _ := loans.GetLoanByID(1); stored.Status != tt.want {
+				t.Errorf("loan is %s, want %s", stored.Status, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type documentUsecase struct {
+	loanRepo     domain.LoanRepository
+	documentRepo domain.LoanDocumentRepository
+	blobs        domain.BlobStore
+	history      domain.LoanHistoryRepository
+}
+
+// NewDocumentUsecase creates a new instance of LoanDocumentUsecase that keeps the content of
+// documents in blobs.
+func NewDocumentUsecase(loanRepo domain.LoanRepository, documentRepo domain.LoanDocumentRepository, blobs domain.BlobStore, history domain.LoanHistoryRepository) domain.LoanDocumentUsecase {
+	return &documentUsecase{
+		loanRepo:     loanRepo,
+		documentRepo: documentRepo,
+		blobs:        blobs,
+		history:      history,
+	}
+}
+
+// UploadDocument attaches a document to an application of userID still under consideration.
+// The content must be a PDF, JPEG or PNG file of at most MaxDocumentSize bytes; its format is
+// detected from the content and its SHA-256 checksum is kept to verify later downloads.
+func (uc *documentUsecase) UploadDocument(loanID uint, upload domain.DocumentUpload, userID primitive.ObjectID) (domain.LoanDocument, error) {
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.LoanDocument{}, err
+	}
+	if loan.UserID != userID {
+		return domain.LoanDocument{}, domain.ErrNotLoanOwner
+	}
+	switch loan.Status {
+	case domain.LoanStatusPending, domain.LoanStatusUnderReview, domain.LoanStatusCounterOffered:
+	default:
+		return domain.LoanDocument{}, domain.ErrDocumentsLocked
+	}
+	if !upload.Type.IsValid() {
+		return domain.LoanDocument{}, domain.ErrInvalidDocumentType
+	}
+	if upload.Size > domain.MaxDocumentSize {
+		return domain.LoanDocument{}, domain.ErrDocumentTooLarge
+	}
+
+	content, err := io.ReadAll(io.LimitReader(upload.Content, domain.MaxDocumentSize+1))
+	if err != nil {
+		return domain.LoanDocument{}, err
+	}
+	if len(content) == 0 {
+		return domain.LoanDocument{}, domain.ErrEmptyDocument
+	}
+	if len(content) > domain.MaxDocumentSize {
+		return domain.LoanDocument{}, domain.ErrDocumentTooLarge
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	if !slices.Contains(domain.AllowedDocumentContentTypes, contentType) {
+		return domain.LoanDocument{}, domain.ErrUnsupportedContentType
+	}
+
+	id := primitive.NewObjectID()
+	document := domain.LoanDocument{
+		ID:          id,
+		LoanID:      loan.ID,
+		Type:        upload.Type,
+		FileName:    documentFileName(upload.FileName, upload.Type),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Checksum:    checksum(content),
+		StorageKey:  fmt.Sprintf("loans/%d/%s", loan.ID, id.Hex()),
+		Status:      domain.DocumentPending,
+		UploadedBy:  userID,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := uc.blobs.Put(document.StorageKey, bytes.NewReader(content)); err != nil {
+		return domain.LoanDocument{}, err
+	}
+	if err := uc.documentRepo.CreateDocument(document); err != nil {
+		// Do not leave content behind that no document points to
+		uc.blobs.Delete(document.StorageKey)
+		return domain.LoanDocument{}, err
+	}
+
+	return document, nil
+}
+
+// GetMyLoanDocuments retrieves the documents of an application of userID, with their review status.
+func (uc *documentUsecase) GetMyLoanDocuments(loanID uint, userID primitive.ObjectID) ([]domain.LoanDocument, error) {
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return nil, err
+	}
+	if loan.UserID != userID {
+		return nil, domain.ErrNotLoanOwner
+	}
+
+	return uc.documentRepo.GetDocumentsByLoan(loan.ID)
+}
+
+// GetLoanDocuments retrieves the documents of a loan for review, oldest first.
+func (uc *documentUsecase) GetLoanDocuments(loanID uint) ([]domain.LoanDocument, error) {
+	if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+		return nil, err
+	}
+
+	return uc.documentRepo.GetDocumentsByLoan(loanID)
+}
+
+// DownloadDocument reads the content of a document of loanID, checking it against the
+// checksum taken at upload.
+func (uc *documentUsecase) DownloadDocument(loanID uint, id primitive.ObjectID) (domain.LoanDocument, []byte, error) {
+	document, err := uc.loanDocument(loanID, id)
+	if err != nil {
+		return domain.LoanDocument{}, nil, err
+	}
+
+	blob, err := uc.blobs.Get(document.StorageKey)
+	if err != nil {
+		return domain.LoanDocument{}, nil, err
+	}
+	defer blob.Close()
+
+	content, err := io.ReadAll(blob)
+	if err != nil {
+		return domain.LoanDocument{}, nil, err
+	}
+	if checksum(content) != document.Checksum {
+		return domain.LoanDocument{}, nil, domain.ErrChecksumMismatch
+	}
+
+	return document, content, nil
+}
+
+// ReviewDocument records an admin's decision to accept or reject a document of loanID. A
+// document can be reviewed again, for example after a second look at a rejected one.
+func (uc *documentUsecase) ReviewDocument(loanID uint, id primitive.ObjectID, review domain.DocumentReview, adminID primitive.ObjectID) (domain.LoanDocument, error) {
+	review.Note = strings.TrimSpace(review.Note)
+	if review.Status != domain.DocumentAccepted && review.Status != domain.DocumentRejected {
+		return domain.LoanDocument{}, domain.ErrInvalidDocumentReview
+	}
+	if review.Status == domain.DocumentRejected && review.Note == "" {
+		return domain.LoanDocument{}, domain.ErrInvalidDocumentReview
+	}
+
+	document, err := uc.loanDocument(loanID, id)
+	if err != nil {
+		return domain.LoanDocument{}, err
+	}
+
+	now := time.Now()
+	document.Status = review.Status
+	document.ReviewNote = review.Note
+	document.ReviewedBy = &adminID
+	document.ReviewedAt = &now
+	if err := uc.documentRepo.UpdateDocument(document); err != nil {
+		return domain.LoanDocument{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{
+		LoanID:  loanID,
+		Type:    domain.LoanEventDocumentReviewed,
+		ActorID: adminID,
+		Detail:  strings.TrimSpace(fmt.Sprintf("%s %s %s %s", document.Type, document.ID.Hex(), document.Status, document.ReviewNote)),
+	}); err != nil {
+		return domain.LoanDocument{}, err
+	}
+
+	return document, nil
+}
+
+// loanDocument loads a document, treating one attached to another loan as not found.
+func (uc *documentUsecase) loanDocument(loanID uint, id primitive.ObjectID) (domain.LoanDocument, error) {
+	document, err := uc.documentRepo.GetDocumentByID(id)
+	if err != nil {
+		return domain.LoanDocument{}, err
+	}
+	if document.LoanID != loanID {
+		return domain.LoanDocument{}, domain.ErrDocumentNotFound
+	}
+	return document, nil
+}
+
+// documentFileName keeps the base name of an uploaded file, naming it after its document
+// type when the client sent none.
+func documentFileName(name string, t domain.DocumentType) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return string(t)
+	}
+	return name
+}
+
+// checksum returns the hex SHA-256 of content.
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryDocuments keeps loan documents in memory in the order they were uploaded.
+type memoryDocuments struct {
+	documents []domain.LoanDocument
+}
+
+func (r *memoryDocuments) CreateDocument(document domain.LoanDocument) error {
+	r.documents = append(r.documents, document)
+	return nil
+}
+
+func (r *memoryDocuments) GetDocumentByID(id primitive.ObjectID) (domain.LoanDocument, error) {
+	for _, document := range r.documents {
+		if document.ID == id {
+			return document, nil
+		}
+	}
+	return domain.LoanDocument{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryDocuments) GetDocumentsByLoan(loanID uint) ([]domain.LoanDocument, error) {
+	documents := []domain.LoanDocument{}
+	for _, document := range r.documents {
+		if document.LoanID == loanID {
+			documents = append(documents, document)
+		}
+	}
+	return documents, nil
+}
+
+func (r *memoryDocuments) UpdateDocument(document domain.LoanDocument) error {
+	for i := range r.documents {
+		if r.documents[i].ID == document.ID {
+			r.documents[i] = document
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+// memoryBlobs keeps blob content in memory by its key.
+type memoryBlobs struct {
+	blobs map[string][]byte
+}
+
+func (s *memoryBlobs) Put(key string, content io.Reader) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *memoryBlobs) Get(key string) (io.ReadCloser, error) {
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryBlobs) Delete(key string) error {
+	delete(s.blobs, key)
+	return nil
+}
+
+var (
+	pdfContent = []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\n%%EOF\n")
+	pngContent = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+)
+
+func upload(t domain.DocumentType, name string, content []byte) domain.DocumentUpload {
+	return domain.DocumentUpload{Type: t, FileName: name, Size: int64(len(content)), Content: bytes.NewReader(content)}
+}
+
+func newTestDocumentUsecase(loans ...domain.Loan) (domain.LoanDocumentUsecase, *memoryDocuments, *memoryBlobs, *memoryHistory) {
+	documents := &memoryDocuments{}
+	blobs := &memoryBlobs{blobs: map[string][]byte{}}
+	history := &memoryHistory{}
+	return NewDocumentUsecase(newMemoryLoans(loans...), documents, blobs, history), documents, blobs, history
+}
+
+func TestUploadDocument(t *testing.T) {
+	borrower := primitive.NewObjectID()
+	application := domain.Loan{ID: 3, UserID: borrower, Status: domain.LoanStatusUnderReview}
+	approved := domain.Loan{ID: 4, UserID: borrower, Status: domain.LoanStatusApproved}
+	tooLarge := upload(domain.DocumentIdentity, "id.pdf", append(bytes.Clone(pdfContent), make([]byte, domain.MaxDocumentSize)...))
+	tooLarge.Size = 100 // the client understates the size
+
+	tests := []struct {
+		name            string
+		loanID          uint
+		userID          primitive.ObjectID
+		upload          domain.DocumentUpload
+		wantErr         error
+		wantContentType string
+		wantFileName    string
+	}{
+		{"payslip as a PDF", 3, borrower, upload(domain.DocumentProofOfIncome, "C:\\Users\\me\\payslip.pdf", pdfContent), nil, "application/pdf", "payslip.pdf"},
+		{"identity card as a PNG without a name", 3, borrower, upload(domain.DocumentIdentity, "", pngContent), nil, "image/png", "identity"},
+		{"someone else's application", 3, primitive.NewObjectID(), upload(domain.DocumentIdentity, "id.pdf", pdfContent), domain.ErrNotLoanOwner, "", ""},
+		{"approved loan", 4, borrower, upload(domain.DocumentIdentity, "id.pdf", pdfContent), domain.ErrDocumentsLocked, "", ""},
+		{"unknown document type", 3, borrower, upload("selfie", "me.png", pngContent), domain.ErrInvalidDocumentType, "", ""},
+		{"announced as too large", 3, borrower, domain.DocumentUpload{Type: domain.DocumentIdentity, Size: domain.MaxDocumentSize + 1, Content: bytes.NewReader(pdfContent)}, domain.ErrDocumentTooLarge, "", ""},
+		{"larger than announced", 3, borrower, tooLarge, domain.ErrDocumentTooLarge, "", ""},
+		{"empty file", 3, borrower, upload(domain.DocumentIdentity, "id.pdf", nil), domain.ErrEmptyDocument, "", ""},
+		{"text file named as a PDF", 3, borrower, upload(domain.DocumentIdentity, "id.pdf", []byte("hello")), domain.ErrUnsupportedContentType, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, documents, blobs, _ := newTestDocumentUsecase(application, approved)
+
+			document, err := uc.UploadDocument(tt.loanID, tt.upload, tt.userID)
+			if err != tt.wantErr {
+				t.Fatalf("UploadDocument = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(documents.documents) != 0 || len(blobs.blobs) != 0 {
+					t.Errorf("refused upload was stored")
+				}
+				return
+			}
+
+			if document.ContentType != tt.wantContentType || document.FileName != tt.wantFileName {
+				t.Errorf("document %q of %s, want %q of %s", document.FileName, document.ContentType, tt.wantFileName, tt.wantContentType)
+			}
+			if document.Status != domain.DocumentPending || document.Checksum == "" || !strings.HasPrefix(document.StorageKey, "loans/3/") {
+				t.Errorf("document %+v is not stored pending review under the loan", document)
+			}
+			if stored, _ := documents.GetDocumentByID(document.ID); stored != document {
+				t.Errorf("stored document %+v, want %+v", stored, document)
+			}
+		})
+	}
+}
+
+func TestDownloadDocumentVerifiesTheChecksum(t *testing.T) {
+	borrower := primitive.NewObjectID()
+	uc, _, blobs, _ := newTestDocumentUsecase(
+		domain.Loan{ID: 3, UserID: borrower, Status: domain.LoanStatusUnderReview},
+		domain.Loan{ID: 4, UserID: borrower, Status: domain.LoanStatusUnderReview},
+	)
+	document, err := uc.UploadDocument(3, upload(domain.DocumentBankStatement, "statement.pdf", pdfContent), borrower)
+	if err != nil {
+		t.Fatalf("UploadDocument: %v", err)
+	}
+
+	if _, content, err := uc.DownloadDocument(3, document.ID); err != nil || !bytes.Equal(content, pdfContent) {
+		t.Fatalf("DownloadDocument = %q, %v, want the uploaded content", content, err)
+	}
+	if _, _, err := uc.DownloadDocument(4, document.ID); err != domain.ErrDocumentNotFound {
+		t.Errorf("download under another loan = %v, want %v", err, domain.ErrDocumentNotFound)
+	}
+
+	blobs.blobs[document.StorageKey] = []byte("%PDF-1.4 altered")
+	if _, _, err := uc.DownloadDocument(3, document.ID); err != domain.ErrChecksumMismatch {
+		t.Errorf("download of altered content = %v, want %v", err, domain.ErrChecksumMismatch)
+	}
+}
+
+func TestReviewDocument(t *testing.T) {
+	borrower := primitive.NewObjectID()
+	admin := primitive.NewObjectID()
+
+	tests := []struct {
+		name    string
+		review  domain.DocumentReview
+		wantErr error
+	}{
+		{"accepted", domain.DocumentReview{Status: domain.DocumentAccepted}, nil},
+		{"rejected with a note", domain.DocumentReview{Status: domain.DocumentRejected, Note: " blurry, upload a new scan "}, nil},
+		{"rejected without a note", domain.DocumentReview{Status: domain.DocumentRejected, Note: "  "}, domain.ErrInvalidDocumentReview},
+		{"back to pending", domain.DocumentReview{Status: domain.DocumentPending}, domain.ErrInvalidDocumentReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, documents, _, history := newTestDocumentUsecase(domain.Loan{ID: 3, UserID: borrower, Status: domain.LoanStatusUnderReview})
+			document, err := uc.UploadDocument(3, upload(domain.DocumentIdentity, "id.png", pngContent), borrower)
+			if err != nil {
+				t.Fatalf("UploadDocument: %v", err)
+			}
+
+			reviewed, err := uc.ReviewDocument(3, document.ID, tt.review, admin)
+			if err != tt.wantErr {
+				t.Fatalf("ReviewDocument = %v, want %v", err, tt.wantErr)
+			}
+
+			stored, _ := documents.GetDocumentByID(document.ID)
+			if err != nil {
+				if stored.Status != domain.DocumentPending || len(history.events) != 0 {
+					t.Errorf("refused review changed the document to %s", stored.Status)
+				}
+				return
+			}
+			if stored.Status != tt.review.Status || stored.ReviewNote != strings.TrimSpace(tt.review.Note) || stored.ReviewedBy == nil || *stored.ReviewedBy != admin {
+				t.Errorf("stored document %+v, want %s by the admin", stored, tt.review.Status)
+			}
+			if reviewed.ReviewedAt == nil {
+				t.Errorf("review time not recorded")
+			}
+			if len(history.events) != 1 || history.events[0].Type != domain.LoanEventDocumentReviewed {
+				t.Errorf("recorded %+v, want a document review event", history.events)
+			}
+		})
+	}
+}
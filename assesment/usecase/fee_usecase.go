@@ -0,0 +1,357 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type feeUsecase struct {
+	loanRepo    domain.LoanRepository
+	feeRepo     domain.FeeRepository
+	loanUsecase domain.LoanUsecase
+	ledger      domain.LedgerUsecase
+	history     domain.LoanHistoryRepository
+}
+
+// NewFeeUsecase creates a new instance of FeeUsecase.
+func NewFeeUsecase(loanRepo domain.LoanRepository, feeRepo domain.FeeRepository, loanUsecase domain.LoanUsecase, ledger domain.LedgerUsecase, history domain.LoanHistoryRepository) domain.FeeUsecase {
+	return &feeUsecase{
+		loanRepo:    loanRepo,
+		feeRepo:     feeRepo,
+		loanUsecase: loanUsecase,
+		ledger:      ledger,
+		history:     history,
+	}
+}
+
+// AccrueLateCharges charges late fees and penalty interest on the overdue installments of
+// every loan being repaid, up to the start of the day now falls on. Running it again on
+// the same day charges nothing new. A loan that fails to update is reported in the run and
+// does not stop the others.
+func (uc *feeUsecase) AccrueLateCharges(now time.Time) (domain.LateChargeRun, error) {
+	run := domain.LateChargeRun{StartedAt: time.Now()}
+
+	loans, err := uc.loanRepo.GetLoansByStatus(domain.LoanStatusActive, domain.LoanStatusDelinquent, domain.LoanStatusDefaulted)
+	if err != nil {
+		return run, err
+	}
+
+	for _, loan := range loans {
+		run.LoansChecked++
+
+		before := balanceState(loan)
+		fees := lateCharges(&loan, now)
+		if len(fees) == 0 {
+			continue
+		}
+
+		// The schedule is stored first so a failure below cannot charge the same days twice.
+		loan.OutstandingBalance = outstandingBalance(loan)
+		if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("loan %d: %v", loan.ID, err))
+			continue
+		}
+
+		charged, err := uc.recordCharges(fees)
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("loan %d: %v", loan.ID, err))
+			// Charges left without a fee record or a posting come off the schedule again, so the
+			// next run charges them afresh.
+			for _, fee := range fees[len(charged):] {
+				unchargeFee(&loan, fee)
+			}
+			loan.OutstandingBalance = outstandingBalance(loan)
+			if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+				run.Errors = append(run.Errors, fmt.Sprintf("loan %d: charges not taken back: %v", loan.ID, err))
+			}
+		}
+
+		for _, fee := range charged {
+			switch fee.Type {
+			case domain.ChargeLateFee:
+				run.LateFeesCharged++
+			case domain.ChargePenaltyInterest:
+				run.PenaltiesCharged++
+			}
+		}
+		if len(charged) == 0 {
+			continue
+		}
+
+		if err := recordEvent(uc.history, domain.LoanEvent{
+			LoanID: loan.ID,
+			Type:   domain.LoanEventFeeCharged,
+			Before: before,
+			After:  balanceState(loan),
+			Detail: chargedFees(charged),
+		}); err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("loan %d: %v", loan.ID, err))
+		}
+	}
+
+	run.FinishedAt = time.Now()
+	return run, nil
+}
+
+// recordCharges stores the fee record of each charge and posts it to the ledger, in turn,
+// and stops at the first that fails. A fee that could not be posted is removed again. It
+// returns the charges that were both recorded and posted.
+func (uc *feeUsecase) recordCharges(fees []domain.LoanFee) ([]domain.LoanFee, error) {
+	for i, fee := range fees {
+		if err := uc.feeRepo.CreateFee(fee); err != nil {
+			return fees[:i], err
+		}
+		if err := uc.ledger.PostFee(fee.LoanID, fee.Amount, fee.ID.Hex()); err != nil {
+			if deleteErr := uc.feeRepo.DeleteFee(fee.ID); deleteErr != nil {
+				return fees[:i], fmt.Errorf("%w; fee %s is recorded but not posted: %v", err, fee.ID.Hex(), deleteErr)
+			}
+			return fees[:i], err
+		}
+	}
+	return fees, nil
+}
+
+// unchargeFee takes a charge made by lateCharges off the schedule of its loan again.
+func unchargeFee(loan *domain.Loan, fee domain.LoanFee) {
+	for i := range loan.Schedule {
+		inst := &loan.Schedule[i]
+		if inst.Number != fee.InstallmentNumber {
+			continue
+		}
+		inst.Penalties = inst.Penalties.Sub(fee.Amount)
+		switch fee.Type {
+		case domain.ChargeLateFee:
+			inst.LateFeeCharged = false
+		case domain.ChargePenaltyInterest:
+			inst.PenaltyAccruedTo = fee.PeriodStart
+		}
+		return
+	}
+}
+
+// GetLoanFees retrieves the late fees and penalty interest charged on a loan.
+func (uc *feeUsecase) GetLoanFees(loanID uint) ([]domain.LoanFee, error) {
+	if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+		return nil, err
+	}
+
+	return uc.feeRepo.GetFeesByLoan(loanID)
+}
+
+// GetLoanBalance splits what is still owed on a loan into principal, interest, fees and penalties.
+func (uc *feeUsecase) GetLoanBalance(loanID uint) (domain.BalanceBreakdown, error) {
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.BalanceBreakdown{}, err
+	}
+
+	zero := loan.Amount.Zero()
+	balance := domain.BalanceBreakdown{LoanID: loan.ID, Principal: zero, Interest: zero, Fees: zero, Penalties: zero}
+	for _, inst := range loan.Schedule {
+		if inst.IsPaid() {
+			continue
+		}
+		balance.Principal = balance.Principal.Add(inst.PrincipalDue())
+		balance.Interest = balance.Interest.Add(inst.InterestDue())
+		balance.Fees = balance.Fees.Add(inst.FeesDue())
+		balance.Penalties = balance.Penalties.Add(inst.PenaltiesDue())
+	}
+	balance.Total = balance.Principal.Add(balance.Interest).Add(balance.Fees).Add(balance.Penalties)
+
+	return balance, nil
+}
+
+// WaiveFee lets an admin waive what is still unpaid of a late fee or penalty interest charge.
+// Payments settle the charges of an installment oldest first, so a charge that was paid
+// cannot be waived in place of a later one. The waiver is reversed in the ledger and
+// recorded on the fee.
+func (uc *feeUsecase) WaiveFee(loanID uint, feeID primitive.ObjectID, reason string, waivedBy primitive.ObjectID) (domain.LoanFee, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return domain.LoanFee{}, domain.ErrWaiveReasonRequired
+	}
+
+	fee, err := uc.feeRepo.GetFeeByID(feeID)
+	if err != nil {
+		return domain.LoanFee{}, err
+	}
+	if fee.LoanID != loanID {
+		return domain.LoanFee{}, domain.ErrFeeNotFound
+	}
+	if fee.IsWaived() {
+		return domain.LoanFee{}, domain.ErrFeeAlreadyWaived
+	}
+
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.LoanFee{}, err
+	}
+	if fee.InstallmentNumber < 1 || fee.InstallmentNumber > len(loan.Schedule) {
+		return domain.LoanFee{}, domain.ErrFeeNotFound
+	}
+
+	fees, err := uc.feeRepo.GetFeesByLoan(loanID)
+	if err != nil {
+		return domain.LoanFee{}, err
+	}
+
+	now := time.Now()
+	before := balanceState(loan)
+	inst := &loan.Schedule[fee.InstallmentNumber-1]
+	waived := unpaidFee(fee, fees, *inst).Min(inst.PenaltiesDue())
+	if !waived.IsPositive() {
+		return domain.LoanFee{}, domain.ErrFeeAlreadyPaid
+	}
+	inst.Penalties = inst.Penalties.Sub(waived)
+	if !inst.AmountDue().IsPositive() {
+		inst.PaidAt = &now
+	}
+	loan.OutstandingBalance = outstandingBalance(loan)
+
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return domain.LoanFee{}, err
+	}
+
+	fee.WaivedAmount = waived
+	fee.WaiveReason = reason
+	fee.WaivedBy = waivedBy
+	fee.WaivedAt = &now
+	if err := uc.feeRepo.UpdateFee(fee); err != nil {
+		return domain.LoanFee{}, err
+	}
+
+	if err := uc.ledger.PostFeeWaiver(loan.ID, waived, fee.ID.Hex()); err != nil {
+		return domain.LoanFee{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{
+		LoanID:  loan.ID,
+		Type:    domain.LoanEventFeeWaived,
+		ActorID: waivedBy,
+		Before:  before,
+		After:   balanceState(loan),
+		Detail:  fmt.Sprintf("%s %s on installment %d waived: %s", fee.Type, waived, fee.InstallmentNumber, reason),
+	}); err != nil {
+		return domain.LoanFee{}, err
+	}
+
+	if !loan.OutstandingBalance.IsPositive() && loan.Status.CanTransitionTo(domain.LoanStatusPaidOff) {
+		if err := uc.loanUsecase.TransitionLoan(&loan, domain.LoanStatusPaidOff, waivedBy); err != nil {
+			return domain.LoanFee{}, err
+		}
+	}
+
+	return fee, nil
+}
+
+// unpaidFee returns what is still owed of fee. The penalties paid on its installment settle
+// the charges on it in the order they were made, oldest first in fees, less what was waived.
+func unpaidFee(fee domain.LoanFee, fees []domain.LoanFee, inst domain.Installment) domain.Money {
+	paid := inst.PenaltiesPaid
+	for _, charged := range fees {
+		if charged.InstallmentNumber != fee.InstallmentNumber {
+			continue
+		}
+		if charged.ID == fee.ID {
+			return fee.Amount.Sub(fee.Amount.Min(paid))
+		}
+		owed := charged.Amount
+		if charged.IsWaived() {
+			owed = owed.Sub(charged.WaivedAmount)
+		}
+		paid = paid.Sub(owed.Min(paid))
+	}
+	return fee.Amount.Zero()
+}
+
+// chargedFees describes the late charges of a run for the history of their loan.
+func chargedFees(fees []domain.LoanFee) string {
+	charged := make([]string, len(fees))
+	for i, fee := range fees {
+		charged[i] = fmt.Sprintf("%s %s on installment %d", fee.Type, fee.Amount, fee.InstallmentNumber)
+	}
+	return strings.Join(charged, "; ")
+}
+
+// lateCharges charges the late fee and the penalty interest due on each overdue installment of
+// a loan once its grace period has passed, updating the schedule in place. Penalty interest
+// runs from the due date to the start of today on the principal and interest still unpaid.
+func lateCharges(loan *domain.Loan, now time.Time) []domain.LoanFee {
+	terms := loan.Penalties
+	today := startOfDay(now)
+
+	var fees []domain.LoanFee
+	charge := func(inst *domain.Installment, chargeType domain.ChargeType, amount domain.Money, from, to *time.Time) {
+		inst.Penalties = inst.Penalties.Add(amount)
+		fees = append(fees, domain.LoanFee{
+			ID:                primitive.NewObjectID(),
+			LoanID:            loan.ID,
+			InstallmentNumber: inst.Number,
+			Type:              chargeType,
+			Amount:            amount,
+			PeriodStart:       from,
+			PeriodEnd:         to,
+			ChargedAt:         now,
+			WaivedAmount:      amount.Zero(),
+		})
+	}
+
+	for i := range loan.Schedule {
+		inst := &loan.Schedule[i]
+		if inst.IsPaid() || overdueDays(inst.DueDate, now) <= terms.GraceDays {
+			continue
+		}
+		overdue := inst.PrincipalDue().Add(inst.InterestDue())
+
+		if !inst.LateFeeCharged {
+			inst.LateFeeCharged = true
+			if fee := lateFee(terms.LateFee, overdue); fee.IsPositive() {
+				charge(inst, domain.ChargeLateFee, fee, nil, nil)
+			}
+		}
+
+		if terms.PenaltyRate <= 0 {
+			continue
+		}
+		from := startOfDay(inst.DueDate)
+		if inst.PenaltyAccruedTo != nil {
+			from = *inst.PenaltyAccruedTo
+		}
+		days := overdueDays(from, today)
+		if days <= 0 {
+			continue
+		}
+		to := today
+		inst.PenaltyAccruedTo = &to
+
+		rate := new(big.Rat).Mul(domain.PercentRate(terms.PenaltyRate), big.NewRat(int64(days), 365))
+		if penalty := overdue.Mul(rate); penalty.IsPositive() {
+			start := from
+			charge(inst, domain.ChargePenaltyInterest, penalty, &start, &to)
+		}
+	}
+
+	return fees
+}
+
+// lateFee returns the late fee a policy charges on an overdue amount.
+func lateFee(policy domain.LateFeePolicy, overdue domain.Money) domain.Money {
+	fee := overdue.Mul(domain.PercentRate(policy.Percentage))
+	if policy.Flat != nil && policy.Flat.Currency == overdue.Currency {
+		fee = fee.Add(*policy.Flat)
+	}
+	if policy.Cap != nil && policy.Cap.Currency == overdue.Currency {
+		fee = fee.Min(*policy.Cap)
+	}
+	return fee
+}
+
+// overdueDays returns how many whole calendar days now is past the day due falls on, or 0
+// if that day has not passed yet.
+func overdueDays(due, now time.Time) int {
+	return max(int(startOfDay(now).Sub(startOfDay(due)).Hours()/24), 0)
+}
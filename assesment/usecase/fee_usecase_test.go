@@ -0,0 +1,347 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLateFee(t *testing.T) {
+	flat, limit, eur := usd(5000), usd(20000), domain.NewMoney(5000, "EUR")
+
+	tests := []struct {
+		name    string
+		policy  domain.LateFeePolicy
+		overdue domain.Money
+		want    domain.Money
+	}{
+		{"percentage", domain.LateFeePolicy{Percentage: 5}, usd(10000), usd(500)},
+		{"flat", domain.LateFeePolicy{Flat: &flat}, usd(10000), usd(5000)},
+		{"flat and percentage", domain.LateFeePolicy{Flat: &flat, Percentage: 5}, usd(100000), usd(10000)},
+		{"capped", domain.LateFeePolicy{Flat: &flat, Percentage: 5, Cap: &limit}, usd(1000000), usd(20000)},
+		{"flat in another currency is ignored", domain.LateFeePolicy{Flat: &eur, Percentage: 5}, usd(10000), usd(500)},
+		{"cap in another currency is ignored", domain.LateFeePolicy{Percentage: 5, Cap: &eur}, usd(1000000), usd(50000)},
+		{"none", domain.LateFeePolicy{}, usd(10000), usd(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lateFee(tt.policy, tt.overdue); got != tt.want {
+				t.Errorf("lateFee = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverdueDays(t *testing.T) {
+	due := time.Date(2024, time.March, 1, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		now  time.Time
+		want int
+	}{
+		{time.Date(2024, time.February, 28, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2024, time.March, 1, 23, 59, 0, 0, time.UTC), 0},
+		{time.Date(2024, time.March, 2, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2024, time.April, 1, 9, 0, 0, 0, time.UTC), 31},
+	}
+
+	for _, tt := range tests {
+		if got := overdueDays(due, tt.now); got != tt.want {
+			t.Errorf("overdueDays(%s) = %d, want %d", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestLateCharges(t *testing.T) {
+	flat := usd(1000)
+	loan := domain.Loan{
+		Amount:    usd(20000),
+		Penalties: domain.PenaltyTerms{GraceDays: 3, LateFee: domain.LateFeePolicy{Flat: &flat}, PenaltyRate: 36.5},
+		Schedule:  []domain.Installment{dueInstallment(1, 10000, 1000, 0, 0), dueInstallment(2, 10000, 500, 0, 0)},
+	}
+	loan.Schedule[0].DueDate = time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	loan.Schedule[1].DueDate = time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	// Each step runs on the loan as the previous one left it.
+	steps := []struct {
+		name      string
+		now       time.Time
+		fees      []domain.ChargeType
+		amounts   []int64
+		penalties int64 // charged on the first installment so far
+	}{
+		{"within the grace period", time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC), nil, nil, 0},
+		{"late fee and penalty interest from the due date", time.Date(2024, time.March, 11, 10, 0, 0, 0, time.UTC), []domain.ChargeType{domain.ChargeLateFee, domain.ChargePenaltyInterest}, []int64{1000, 110}, 1110},
+		{"nothing more the same day", time.Date(2024, time.March, 11, 22, 0, 0, 0, time.UTC), nil, nil, 1110},
+		{"one more day of penalty interest", time.Date(2024, time.March, 12, 1, 0, 0, 0, time.UTC), []domain.ChargeType{domain.ChargePenaltyInterest}, []int64{11}, 1121},
+	}
+
+	for _, step := range steps {
+		fees := lateCharges(&loan, step.now)
+
+		if len(fees) != len(step.fees) {
+			t.Fatalf("%s: got %d charges, want %d", step.name, len(fees), len(step.fees))
+		}
+		for i, fee := range fees {
+			if fee.Type != step.fees[i] || fee.Amount != usd(step.amounts[i]) || fee.InstallmentNumber != 1 {
+				t.Errorf("%s: charge %d is %s %s on installment %d, want %s %s on installment 1",
+					step.name, i, fee.Type, fee.Amount, fee.InstallmentNumber, step.fees[i], usd(step.amounts[i]))
+			}
+		}
+		if got := loan.Schedule[0].Penalties; got != usd(step.penalties) {
+			t.Errorf("%s: installment 1 penalties %s, want %s", step.name, got, usd(step.penalties))
+		}
+		if got := loan.Schedule[1].Penalties; got.IsPositive() {
+			t.Errorf("%s: installment 2 not yet due was charged %s", step.name, got)
+		}
+	}
+}
+
+func TestLateChargesSkipsPaidInstallments(t *testing.T) {
+	paidAt := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	inst := dueInstallment(1, 10000, 1000, 0, 0)
+	inst.DueDate = time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	inst.PrincipalPaid, inst.InterestPaid, inst.PaidAt = usd(10000), usd(1000), &paidAt
+
+	loan := domain.Loan{
+		Amount:    usd(10000),
+		Penalties: domain.PenaltyTerms{LateFee: domain.LateFeePolicy{Percentage: 5}, PenaltyRate: 20},
+		Schedule:  []domain.Installment{inst},
+	}
+	if fees := lateCharges(&loan, time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)); len(fees) != 0 {
+		t.Errorf("got %d charges on a paid installment, want none", len(fees))
+	}
+}
+
+// memoryFees keeps fees in memory, oldest first like the Mongo repository.
+type memoryFees struct {
+	fees []domain.LoanFee
+}
+
+func (r *memoryFees) CreateFee(fee domain.LoanFee) error {
+	r.fees = append(r.fees, fee)
+	return nil
+}
+
+func (r *memoryFees) GetFeeByID(id primitive.ObjectID) (domain.LoanFee, error) {
+	for _, fee := range r.fees {
+		if fee.ID == id {
+			return fee, nil
+		}
+	}
+	return domain.LoanFee{}, domain.ErrFeeNotFound
+}
+
+func (r *memoryFees) GetFeesByLoan(loanID uint) ([]domain.LoanFee, error) {
+	fees := []domain.LoanFee{}
+	for _, fee := range r.fees {
+		if fee.LoanID == loanID {
+			fees = append(fees, fee)
+		}
+	}
+	return fees, nil
+}
+
+func (r *memoryFees) DeleteFee(id primitive.ObjectID) error {
+	for i := range r.fees {
+		if r.fees[i].ID == id {
+			r.fees = append(r.fees[:i], r.fees[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrFeeNotFound
+}
+
+func (r *memoryFees) UpdateFee(fee domain.LoanFee) error {
+	for i := range r.fees {
+		if r.fees[i].ID == fee.ID {
+			r.fees[i] = fee
+			return nil
+		}
+	}
+	return domain.ErrFeeNotFound
+}
+
+func TestUnpaidFee(t *testing.T) {
+	charge := func(amount, waived int64) domain.LoanFee {
+		fee := domain.LoanFee{ID: primitive.NewObjectID(), InstallmentNumber: 1, Amount: usd(amount), WaivedAmount: usd(waived)}
+		if waived > 0 {
+			now := time.Now()
+			fee.WaivedAt = &now
+		}
+		return fee
+	}
+	lateFee, firstPenalty, secondPenalty := charge(1000, 0), charge(110, 0), charge(11, 0)
+	waivedLateFee := charge(1000, 1000)
+	otherInstallment := charge(500, 0)
+	otherInstallment.InstallmentNumber = 2
+
+	tests := []struct {
+		name string
+		fees []domain.LoanFee
+		fee  domain.LoanFee
+		paid int64 // penalties paid on installment 1
+		want int64
+	}{
+		{"nothing paid", []domain.LoanFee{lateFee, firstPenalty}, firstPenalty, 0, 110},
+		{"oldest charge is paid first", []domain.LoanFee{lateFee, firstPenalty}, lateFee, 1000, 0},
+		{"later charge still owed after the oldest is paid", []domain.LoanFee{lateFee, firstPenalty}, firstPenalty, 1000, 110},
+		{"partly paid", []domain.LoanFee{lateFee, firstPenalty, secondPenalty}, firstPenalty, 1050, 60},
+		{"waived charges absorb no payments", []domain.LoanFee{waivedLateFee, firstPenalty}, firstPenalty, 100, 10},
+		{"charges on other installments are ignored", []domain.LoanFee{otherInstallment, lateFee}, lateFee, 400, 600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst := dueInstallment(1, 10000, 0, 0, 1121)
+			inst.PenaltiesPaid = usd(tt.paid)
+			if got := unpaidFee(tt.fee, tt.fees, inst); got != usd(tt.want) {
+				t.Errorf("unpaidFee = %s, want %s", got, usd(tt.want))
+			}
+		})
+	}
+}
+
+func TestWaiveFee(t *testing.T) {
+	adminID := primitive.NewObjectID()
+	lateFee := domain.LoanFee{ID: primitive.NewObjectID(), LoanID: 1, InstallmentNumber: 1, Type: domain.ChargeLateFee, Amount: usd(1000), WaivedAmount: usd(0)}
+	penalty := domain.LoanFee{ID: primitive.NewObjectID(), LoanID: 1, InstallmentNumber: 1, Type: domain.ChargePenaltyInterest, Amount: usd(110), WaivedAmount: usd(0)}
+
+	newUsecase := func() (*feeUsecase, *memoryLoans, *memoryFees) {
+		// The late fee has been paid; the penalty interest charged after it has not.
+		inst := dueInstallment(1, 10000, 1000, 0, 1110)
+		inst.PenaltiesPaid = usd(1000)
+		loan := domain.Loan{ID: 1, Amount: usd(10000), Status: domain.LoanStatusDelinquent, Schedule: []domain.Installment{inst}}
+		loan.OutstandingBalance = outstandingBalance(loan)
+
+		loans := newMemoryLoans(loan)
+		fees := &memoryFees{fees: []domain.LoanFee{lateFee, penalty}}
+		uc := &feeUsecase{loanRepo: loans, feeRepo: fees, ledger: NewLedgerUsecase(&memoryLedger{}), history: &memoryHistory{}}
+		return uc, loans, fees
+	}
+
+	t.Run("paid fee", func(t *testing.T) {
+		uc, loans, _ := newUsecase()
+		if _, err := uc.WaiveFee(1, lateFee.ID, "goodwill", adminID); err != domain.ErrFeeAlreadyPaid {
+			t.Fatalf("WaiveFee = %v, want %v", err, domain.ErrFeeAlreadyPaid)
+		}
+		if got := loans.loans[1].Schedule[0].PenaltiesDue(); got != usd(110) {
+			t.Errorf("penalties due %s, want the unpaid penalty interest of 1.10 USD", got)
+		}
+	})
+
+	t.Run("unpaid fee", func(t *testing.T) {
+		uc, loans, fees := newUsecase()
+		fee, err := uc.WaiveFee(1, penalty.ID, "goodwill", adminID)
+		if err != nil {
+			t.Fatalf("WaiveFee: %v", err)
+		}
+		if fee.WaivedAmount != usd(110) || !fee.IsWaived() || fees.fees[1].WaivedAmount != usd(110) {
+			t.Errorf("waived %s, want 1.10 USD recorded on the fee", fee.WaivedAmount)
+		}
+		if got := loans.loans[1].Schedule[0].PenaltiesDue(); got.IsPositive() {
+			t.Errorf("penalties due %s after the waiver, want none", got)
+		}
+		if _, err := uc.WaiveFee(1, penalty.ID, "again", adminID); err != domain.ErrFeeAlreadyWaived {
+			t.Errorf("second WaiveFee = %v, want %v", err, domain.ErrFeeAlreadyWaived)
+		}
+	})
+}
+
+// failingFees stores the first fees it is given and refuses the rest.
+type failingFees struct {
+	*memoryFees
+	stored int
+}
+
+func (r *failingFees) CreateFee(fee domain.LoanFee) error {
+	if len(r.fees) >= r.stored {
+		return errors.New("fee store unavailable")
+	}
+	return r.memoryFees.CreateFee(fee)
+}
+
+// failingLedger refuses every journal entry.
+type failingLedger struct {
+	domain.LedgerRepository
+}
+
+func (failingLedger) PostEntry(domain.JournalEntry) error {
+	return errors.New("ledger unavailable")
+}
+
+func TestAccrueLateChargesTakesBackUnrecordedCharges(t *testing.T) {
+	now := time.Date(2024, time.March, 11, 10, 0, 0, 0, time.UTC)
+	flat := usd(1000)
+	overdue := func() domain.Loan {
+		inst := dueInstallment(1, 10000, 1000, 0, 0)
+		inst.DueDate = time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+		loan := domain.Loan{
+			ID:        1,
+			Amount:    usd(10000),
+			Status:    domain.LoanStatusDelinquent,
+			Penalties: domain.PenaltyTerms{GraceDays: 3, LateFee: domain.LateFeePolicy{Flat: &flat}, PenaltyRate: 36.5},
+			Schedule:  []domain.Installment{inst},
+		}
+		loan.OutstandingBalance = outstandingBalance(loan)
+		return loan
+	}
+
+	tests := []struct {
+		name      string
+		stored    int  // fees the fee store accepts
+		ledgerUp  bool // whether the ledger accepts postings
+		charged   []domain.ChargeType
+		penalties int64
+	}{
+		{"everything recorded", 2, true, []domain.ChargeType{domain.ChargeLateFee, domain.ChargePenaltyInterest}, 1110},
+		{"penalty interest not stored", 1, true, []domain.ChargeType{domain.ChargeLateFee}, 1000},
+		{"fee store down", 0, true, nil, 0},
+		{"ledger down", 2, false, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loans := newMemoryLoans(overdue())
+			fees := &memoryFees{}
+			var ledgerRepo domain.LedgerRepository = &memoryLedger{}
+			if !tt.ledgerUp {
+				ledgerRepo = failingLedger{}
+			}
+			uc := &feeUsecase{loanRepo: loans, feeRepo: &failingFees{memoryFees: fees, stored: tt.stored}, ledger: NewLedgerUsecase(ledgerRepo), history: &memoryHistory{}}
+
+			run, err := uc.AccrueLateCharges(now)
+			if err != nil {
+				t.Fatalf("AccrueLateCharges: %v", err)
+			}
+			if charged := len(tt.charged); run.LateFeesCharged+run.PenaltiesCharged != charged || (len(run.Errors) == 0) != (charged == 2) {
+				t.Errorf("run %+v, want %d charges", run, charged)
+			}
+			if len(fees.fees) != len(tt.charged) {
+				t.Fatalf("stored %d fees, want %d", len(fees.fees), len(tt.charged))
+			}
+			for i, fee := range fees.fees {
+				if fee.Type != tt.charged[i] {
+					t.Errorf("fee %d is %s, want %s", i, fee.Type, tt.charged[i])
+				}
+			}
+			stored := loans.loans[1]
+			if stored.Schedule[0].Penalties != usd(tt.penalties) || stored.OutstandingBalance != usd(11000+tt.penalties) {
+				t.Errorf("installment carries %s in penalties and the loan owes %s, want %s charged", stored.Schedule[0].Penalties, stored.OutstandingBalance, usd(tt.penalties))
+			}
+
+			// Once the fee store and the ledger are back, the next run charges what was taken back.
+			uc.feeRepo, uc.ledger = fees, NewLedgerUsecase(&memoryLedger{})
+			if _, err := uc.AccrueLateCharges(now); err != nil {
+				t.Fatalf("second AccrueLateCharges: %v", err)
+			}
+			if got := loans.loans[1].Schedule[0].Penalties; got != usd(1110) || len(fees.fees) != 2 {
+				t.Errorf("after the retry the installment carries %s in %d fees, want 11.10 USD in 2", got, len(fees.fees))
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type loanHistoryUsecase struct {
+	loanRepo    domain.LoanRepository
+	historyRepo domain.LoanHistoryRepository
+}
+
+// NewLoanHistoryUsecase creates a new instance of LoanHistoryUsecase.
+func NewLoanHistoryUsecase(loanRepo domain.LoanRepository, historyRepo domain.LoanHistoryRepository) domain.LoanHistoryUsecase {
+	return &loanHistoryUsecase{
+		loanRepo:    loanRepo,
+		historyRepo: historyRepo,
+	}
+}
+
+// GetLoanHistory retrieves the history of a loan, oldest first. Admins see every event, also
+// of deleted loans; a borrower sees the history of their own loan without internal notes,
+// approvals and reviewer assignments.
+func (uc *loanHistoryUsecase) GetLoanHistory(loanID uint, userID primitive.ObjectID, isAdmin bool) ([]domain.LoanEvent, error) {
+	if !isAdmin {
+		loan, err := uc.loanRepo.GetLoanByID(loanID)
+		if err != nil {
+			return nil, err
+		}
+		if loan.UserID != userID {
+			return nil, domain.ErrNotLoanOwner
+		}
+	}
+
+	events, err := uc.historyRepo.GetEventsByLoan(loanID)
+	if err != nil || isAdmin {
+		return events, err
+	}
+
+	visible := []domain.LoanEvent{}
+	for _, event := range events {
+		if !event.Type.Internal() {
+			visible = append(visible, event)
+		}
+	}
+	return visible, nil
+}
+
+// recordEvent appends an event to the history of its loan, stamped with the time now.
+func recordEvent(history domain.LoanHistoryRepository, event domain.LoanEvent) error {
+	event.RecordedAt = time.Now()
+	return history.AppendEvent(event)
+}
+
+// recordStatusChange appends the move of a loan from one state to another to its history.
+// A zero actor stands for the credit rules or a background job.
+func recordStatusChange(history domain.LoanHistoryRepository, loanID uint, from, to domain.LoanStatus, actor primitive.ObjectID, detail string) error {
+	return recordEvent(history, domain.LoanEvent{
+		LoanID:  loanID,
+		Type:    domain.LoanEventStatusChanged,
+		ActorID: actor,
+		Before:  &domain.LoanState{Status: from},
+		After:   &domain.LoanState{Status: to},
+		Detail:  detail,
+	})
+}
+
+// termsState returns the amount, term and rate of a loan as a history state.
+func termsState(loan domain.Loan) *domain.LoanState {
+	amount := loan.Amount
+	return &domain.LoanState{Amount: &amount, Term: loan.Term, InterestRate: loan.InterestRate}
+}
+
+// balanceState returns what a loan still owes as a history state.
+func balanceState(loan domain.Loan) *domain.LoanState {
+	balance := loan.OutstandingBalance
+	return &domain.LoanState{OutstandingBalance: &balance}
+}
+
+// delinquencyState returns how far behind a loan is as a history state.
+func delinquencyState(loan domain.Loan) *domain.LoanState {
+	dpd := loan.DaysPastDue
+	return &domain.LoanState{DaysPastDue: &dpd, AgingBucket: loan.AgingBucket}
+}
+
+// accrualState returns the interest accrued on a loan, and the day it was accrued through,
+// as a history state.
+func accrualState(loan domain.Loan) *domain.LoanState {
+	accrued := loan.AccruedInterest
+	return &domain.LoanState{AccruedInterest: &accrued, InterestAccruedTo: loan.InterestAccruedTo}
+}
+
+// collateralState returns the collateral cover of a loan as a history state.
+func collateralState(loan domain.Loan) *domain.LoanState {
+	value := loan.CollateralValue
+	return &domain.LoanState{CollateralValue: &value, LoanToValue: loan.LoanToValue}
+}
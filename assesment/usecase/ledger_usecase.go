@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ledgerUsecase struct {
+	ledgerRepo domain.LedgerRepository
+}
+
+// NewLedgerUsecase creates a new instance of LedgerUsecase.
+func NewLedgerUsecase(ledgerRepo domain.LedgerRepository) domain.LedgerUsecase {
+	return &ledgerUsecase{
+		ledgerRepo: ledgerRepo,
+	}
+}
+
+// PostDisbursement records the principal paid out to the borrower.
+func (uc *ledgerUsecase) PostDisbursement(loan domain.Loan) error {
+	return uc.post(domain.JournalEntry{
+		Type:        domain.EntryDisbursement,
+		LoanID:      loan.ID,
+		Description: fmt.Sprintf("Disbursement of loan %d", loan.ID),
+		Postings: []domain.Posting{
+			debit(domain.AccountLoansReceivable, loan.Amount),
+			credit(domain.AccountCash, loan.Amount),
+		},
+	})
+}
+
+// PostRepayment records the cash received for a payment and clears the receivables it settled.
+// Interest that was accrued clears interest receivable and the rest is recognised as income
+// when it is received; penalties were booked as fees when charged.
+func (uc *ledgerUsecase) PostRepayment(payment domain.Payment) error {
+	return uc.post(domain.JournalEntry{
+		Type:        domain.EntryRepayment,
+		LoanID:      payment.LoanID,
+		Reference:   payment.ID.Hex(),
+		Description: fmt.Sprintf("Repayment on loan %d", payment.LoanID),
+		PostedAt:    payment.PaidAt,
+		Postings: []domain.Posting{
+			debit(domain.AccountCash, payment.Amount),
+			credit(domain.AccountFeesReceivable, payment.Fees.Add(payment.Penalties)),
+			credit(domain.AccountInterestReceivable, payment.InterestReceivable),
+			credit(domain.AccountInterestIncome, payment.Interest.Sub(payment.InterestReceivable)),
+			credit(domain.AccountLoansReceivable, payment.Principal),
+		},
+	})
+}
+
+// PostInterestAccrual recognises interest earned but not yet received.
+func (uc *ledgerUsecase) PostInterestAccrual(loanID uint, amount domain.Money, reference string) error {
+	return uc.post(domain.JournalEntry{
+		Type:        domain.EntryInterestAccrual,
+		LoanID:      loanID,
+		Reference:   reference,
+		Description: fmt.Sprintf("Interest accrual on loan %d", loanID),
+		Postings: []domain.Posting{
+			debit(domain.AccountInterestReceivable, amount),
+			credit(domain.AccountInterestIncome, amount),
+		},
+	})
+}
+
+// PostFee recognises a fee charged to the borrower.
+func (uc *ledgerUsecase) PostFee(loanID uint, amount domain.Money, reference string) error {
+	return uc.post(domain.JournalEntry{
+		Type:        domain.EntryFee,
+		LoanID:      loanID,
+		Reference:   reference,
+		Description: fmt.Sprintf("Fee charged on loan %d", loanID),
+		Postings: []domain.Posting{
+			debit(domain.AccountFeesReceivable, amount),
+			credit(domain.AccountFeeIncome, amount),
+		},
+	})
+}
+
+// PostFeeWaiver reverses a fee the borrower no longer has to pay.
+func (uc *ledgerUsecase) PostFeeWaiver(loanID uint, amount domain.Money, reference string) error {
+	return uc.post(domain.JournalEntry{
+		Type:        domain.EntryFeeWaiver,
+		LoanID:      loanID,
+		Reference:   reference,
+		Description: fmt.Sprintf("Fee waived on loan %d", loanID),
+		Postings: []domain.Posting{
+			debit(domain.AccountFeeIncome, amount),
+			credit(domain.AccountFeesReceivable, amount),
+		},
+	})
+}
+
+// PostCapitalization turns overdue interest, fees and penalties into principal when a loan is
+// restructured. Interest that was accrued clears interest receivable and the rest is recognised
+// as income, as it would have been had it been paid.
+func (uc *ledgerUsecase) PostCapitalization(loanID uint, interestReceivable, interestIncome, fees domain.Money, reference string) error {
+	return uc.post(domain.JournalEntry{
+		Type:        domain.EntryCapitalization,
+		LoanID:      loanID,
+		Reference:   reference,
+		Description: fmt.Sprintf("Arrears capitalized on loan %d", loanID),
+		Postings: []domain.Posting{
+			debit(domain.AccountLoansReceivable, interestReceivable.Add(interestIncome).Add(fees)),
+			credit(domain.AccountInterestReceivable, interestReceivable),
+			credit(domain.AccountInterestIncome, interestIncome),
+			credit(domain.AccountFeesReceivable, fees),
+		},
+	})
+}
+
+// PostWriteOff moves the receivables of an unrecoverable loan to loan loss expense.
+func (uc *ledgerUsecase) PostWriteOff(loanID uint, principal, interest, fees domain.Money) error {
+	return uc.post(domain.JournalEntry{
+		Type:        domain.EntryWriteOff,
+		LoanID:      loanID,
+		Description: fmt.Sprintf("Write-off of loan %d", loanID),
+		Postings: []domain.Posting{
+			debit(domain.AccountLoanLossExpense, principal.Add(interest).Add(fees)),
+			credit(domain.AccountLoansReceivable, principal),
+			credit(domain.AccountInterestReceivable, interest),
+			credit(domain.AccountFeesReceivable, fees),
+		},
+	})
+}
+
+// PostRecovery records cash received on a written-off loan. Its receivables are already off
+// the books, so the whole amount is recognised as recovery income.
+func (uc *ledgerUsecase) PostRecovery(payment domain.Payment) error {
+	return uc.post(domain.JournalEntry{
+		Type:        domain.EntryRecovery,
+		LoanID:      payment.LoanID,
+		Reference:   payment.ID.Hex(),
+		Description: fmt.Sprintf("Recovery on written-off loan %d", payment.LoanID),
+		PostedAt:    payment.PaidAt,
+		Postings: []domain.Posting{
+			debit(domain.AccountCash, payment.Amount),
+			credit(domain.AccountRecoveryIncome, payment.Amount),
+		},
+	})
+}
+
+// GetLoanEntries retrieves the journal entries posted for a loan.
+func (uc *ledgerUsecase) GetLoanEntries(loanID uint) ([]domain.JournalEntry, error) {
+	return uc.ledgerRepo.GetEntriesByLoan(loanID)
+}
+
+// GetEntries retrieves the journal entries posted within [from, to), e.g. for bank reconciliation.
+func (uc *ledgerUsecase) GetEntries(from, to time.Time) ([]domain.JournalEntry, error) {
+	return uc.ledgerRepo.GetEntries(from, to)
+}
+
+// GetTrialBalance returns the balance of every account in the chart of accounts.
+func (uc *ledgerUsecase) GetTrialBalance() ([]domain.AccountBalance, error) {
+	totals, err := uc.ledgerRepo.GetAccountTotals()
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]domain.AccountBalance, 0, len(totals))
+	for _, account := range domain.ChartOfAccounts {
+		for _, total := range totals {
+			if total.Account != account.Code {
+				continue
+			}
+			debits := domain.NewMoney(total.Debit, total.Currency)
+			credits := domain.NewMoney(total.Credit, total.Currency)
+			balance := credits.Sub(debits)
+			if account.Type.DebitNormal() {
+				balance = debits.Sub(credits)
+			}
+			balances = append(balances, domain.AccountBalance{
+				LedgerAccount: account,
+				Debit:         debits,
+				Credit:        credits,
+				Balance:       balance,
+			})
+		}
+	}
+
+	return balances, nil
+}
+
+// GetOutstandingPrincipal returns the principal outstanding across the whole portfolio, per currency.
+func (uc *ledgerUsecase) GetOutstandingPrincipal() ([]domain.Money, error) {
+	balances, err := uc.GetTrialBalance()
+	if err != nil {
+		return nil, err
+	}
+
+	principal := []domain.Money{}
+	for _, balance := range balances {
+		if balance.Code == domain.AccountLoansReceivable {
+			principal = append(principal, balance.Balance)
+		}
+	}
+	return principal, nil
+}
+
+// post drops empty postings, validates the entry and appends it to the ledger.
+func (uc *ledgerUsecase) post(entry domain.JournalEntry) error {
+	postings := entry.Postings[:0]
+	for _, posting := range entry.Postings {
+		if !posting.Debit.IsZero() || !posting.Credit.IsZero() {
+			postings = append(postings, posting)
+		}
+	}
+	entry.Postings = postings
+
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	entry.ID = primitive.NewObjectID()
+	entry.CreatedAt = time.Now()
+	if entry.PostedAt.IsZero() {
+		entry.PostedAt = entry.CreatedAt
+	}
+
+	return uc.ledgerRepo.PostEntry(entry)
+}
+
+// debit builds a debit posting to account.
+func debit(account string, amount domain.Money) domain.Posting {
+	return domain.Posting{Account: account, Debit: amount, Credit: amount.Zero()}
+}
+
+// credit builds a credit posting to account.
+func credit(account string, amount domain.Money) domain.Posting {
+	return domain.Posting{Account: account, Debit: amount.Zero(), Credit: amount}
+}
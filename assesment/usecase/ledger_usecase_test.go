@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryLedger keeps posted journal entries in memory.
+type memoryLedger struct {
+	domain.LedgerRepository
+	entries []domain.JournalEntry
+}
+
+func (r *memoryLedger) PostEntry(entry domain.JournalEntry) error {
+	for _, posted := range r.entries {
+		if entry.Reference != "" && posted.LoanID == entry.LoanID && posted.Type == entry.Type && posted.Reference == entry.Reference {
+			return domain.ErrEntryAlreadyPosted
+		}
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *memoryLedger) GetAccountTotals() ([]domain.AccountTotal, error) {
+	byAccount := map[string]*domain.AccountTotal{}
+	var totals []*domain.AccountTotal
+	for _, entry := range r.entries {
+		for _, posting := range entry.Postings {
+			total, ok := byAccount[posting.Account]
+			if !ok {
+				total = &domain.AccountTotal{Account: posting.Account, Currency: entry.Currency()}
+				byAccount[posting.Account] = total
+				totals = append(totals, total)
+			}
+			total.Debit += posting.Debit.Amount
+			total.Credit += posting.Credit.Amount
+		}
+	}
+
+	result := make([]domain.AccountTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	return result, nil
+}
+
+func TestLedgerPostingsBalance(t *testing.T) {
+	payment := domain.Payment{
+		ID:                 primitive.NewObjectID(),
+		LoanID:             1,
+		Amount:             usd(12000),
+		Penalties:          usd(300),
+		Fees:               usd(200),
+		Interest:           usd(1500),
+		InterestReceivable: usd(1000),
+		Principal:          usd(10000),
+	}
+
+	tests := []struct {
+		name  string
+		post  func(domain.LedgerUsecase) error
+		lines int
+	}{
+		{"disbursement", func(l domain.LedgerUsecase) error { return l.PostDisbursement(domain.Loan{ID: 1, Amount: usd(100000)}) }, 2},
+		{"repayment", func(l domain.LedgerUsecase) error { return l.PostRepayment(payment) }, 5},
+		{"repayment of principal only drops empty postings", func(l domain.LedgerUsecase) error {
+			return l.PostRepayment(domain.Payment{LoanID: 1, Amount: usd(5000), Penalties: usd(0), Fees: usd(0), Interest: usd(0), InterestReceivable: usd(0), Principal: usd(5000)})
+		}, 2},
+		{"interest accrual", func(l domain.LedgerUsecase) error { return l.PostInterestAccrual(1, usd(27), "2024-03-01") }, 2},
+		{"fee", func(l domain.LedgerUsecase) error { return l.PostFee(1, usd(2500), "late") }, 2},
+		{"fee waiver", func(l domain.LedgerUsecase) error { return l.PostFeeWaiver(1, usd(2500), "late") }, 2},
+		{"capitalization", func(l domain.LedgerUsecase) error {
+			return l.PostCapitalization(1, usd(400), usd(100), usd(2500), "mod")
+		}, 4},
+		{"write-off", func(l domain.LedgerUsecase) error { return l.PostWriteOff(1, usd(80000), usd(900), usd(2500)) }, 4},
+		{"recovery", func(l domain.LedgerUsecase) error { return l.PostRecovery(payment) }, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryLedger{}
+			if err := tt.post(NewLedgerUsecase(repo)); err != nil {
+				t.Fatalf("post: %v", err)
+			}
+			if len(repo.entries) != 1 {
+				t.Fatalf("posted %d entries, want 1", len(repo.entries))
+			}
+
+			entry := repo.entries[0]
+			if len(entry.Postings) != tt.lines {
+				t.Errorf("got %d postings, want %d", len(entry.Postings), tt.lines)
+			}
+			debits, credits := usd(0), usd(0)
+			for _, posting := range entry.Postings {
+				debits = debits.Add(posting.Debit)
+				credits = credits.Add(posting.Credit)
+			}
+			if debits != credits {
+				t.Errorf("debits %s do not equal credits %s", debits, credits)
+			}
+			if entry.PostedAt.IsZero() {
+				t.Error("entry has no posting date")
+			}
+		})
+	}
+}
+
+func TestJournalEntryValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		postings []domain.Posting
+		want     error
+	}{
+		{"balanced", []domain.Posting{debit(domain.AccountCash, usd(100)), credit(domain.AccountFeeIncome, usd(100))}, nil},
+		{"unbalanced", []domain.Posting{debit(domain.AccountCash, usd(100)), credit(domain.AccountFeeIncome, usd(99))}, domain.ErrUnbalancedEntry},
+		{"single posting", []domain.Posting{debit(domain.AccountCash, usd(100))}, domain.ErrUnbalancedEntry},
+		{"unknown account", []domain.Posting{debit("9999", usd(100)), credit(domain.AccountFeeIncome, usd(100))}, domain.ErrUnknownAccount},
+		{"empty posting", []domain.Posting{debit(domain.AccountCash, usd(0)), credit(domain.AccountFeeIncome, usd(0))}, domain.ErrInvalidPosting},
+		{"negative posting", []domain.Posting{debit(domain.AccountCash, usd(-100)), credit(domain.AccountFeeIncome, usd(-100))}, domain.ErrInvalidPosting},
+		{"mixed currencies", []domain.Posting{debit(domain.AccountCash, usd(100)), credit(domain.AccountFeeIncome, domain.NewMoney(100, "EUR"))}, domain.ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (domain.JournalEntry{Postings: tt.postings}).Validate(); err != tt.want {
+				t.Errorf("Validate = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLedgerRefusesUnbalancedEntries(t *testing.T) {
+	repo := &memoryLedger{}
+	uc := NewLedgerUsecase(repo)
+
+	if err := uc.PostFee(1, usd(0), "none"); err != domain.ErrUnbalancedEntry {
+		t.Errorf("PostFee of nothing = %v, want %v", err, domain.ErrUnbalancedEntry)
+	}
+	if len(repo.entries) != 0 {
+		t.Errorf("posted %d entries, want none", len(repo.entries))
+	}
+}
+
+func TestTrialBalance(t *testing.T) {
+	repo := &memoryLedger{}
+	uc := NewLedgerUsecase(repo)
+
+	loan := domain.Loan{ID: 1, Amount: usd(100000)}
+	if err := uc.PostDisbursement(loan); err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.PostRepayment(domain.Payment{LoanID: 1, Amount: usd(12000), Penalties: usd(0), Fees: usd(0), Interest: usd(2000), InterestReceivable: usd(0), Principal: usd(10000)}); err != nil {
+		t.Fatal(err)
+	}
+
+	balances, err := uc.GetTrialBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]domain.Money{
+		domain.AccountLoansReceivable: usd(90000),
+		domain.AccountCash:            usd(-88000),
+		domain.AccountInterestIncome:  usd(2000),
+	}
+	for _, balance := range balances {
+		if balance.Balance != want[balance.Code] {
+			t.Errorf("account %s balance %s, want %s", balance.Code, balance.Balance, want[balance.Code])
+		}
+	}
+
+	principal, err := uc.GetOutstandingPrincipal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(principal) != 1 || principal[0] != usd(90000) {
+		t.Errorf("outstanding principal %v, want [900.00 USD]", principal)
+	}
+}
@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type loanPartyUsecase struct {
+	loanRepo domain.LoanRepository
+	userRepo domain.UserRepository
+	consents domain.ConsentNotifier
+	history  domain.LoanHistoryRepository
+}
+
+// NewLoanPartyUsecase creates a new instance of LoanPartyUsecase that asks parties for their
+// consent through consents.
+func NewLoanPartyUsecase(loanRepo domain.LoanRepository, userRepo domain.UserRepository, consents domain.ConsentNotifier, history domain.LoanHistoryRepository) domain.LoanPartyUsecase {
+	return &loanPartyUsecase{
+		loanRepo: loanRepo,
+		userRepo: userRepo,
+		consents: consents,
+		history:  history,
+	}
+}
+
+// AddLoanParty lets a borrower add a co-borrower or guarantor to their application while it
+// is pending or under review, and sends the party a link to consent.
+func (uc *loanPartyUsecase) AddLoanParty(loanID uint, party domain.LoanParty, userID primitive.ObjectID) (domain.LoanParty, error) {
+	loan, err := uc.openLoan(loanID, userID)
+	if err != nil {
+		return domain.LoanParty{}, err
+	}
+	borrower, err := uc.userRepo.GetUserByID(loan.UserID)
+	if err != nil {
+		return domain.LoanParty{}, domain.ErrUserNotFound
+	}
+
+	party, err = newLoanParty(party, loan, borrower, uc.userRepo, time.Now())
+	if err != nil {
+		return domain.LoanParty{}, err
+	}
+	loan.Parties = append(loan.Parties, party)
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return domain.LoanParty{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{LoanID: loan.ID, Type: domain.LoanEventPartyAdded, ActorID: userID, Detail: partyDetail(party)}); err != nil {
+		return domain.LoanParty{}, err
+	}
+
+	if err := uc.consents.RequestConsent(party, loan); err != nil {
+		return domain.LoanParty{}, domain.ErrConsentRequestNotSent
+	}
+	return party, nil
+}
+
+// RemoveLoanParty lets a borrower remove a co-borrower or guarantor from their application
+// while it is pending or under review, for example one who declined.
+func (uc *loanPartyUsecase) RemoveLoanParty(loanID uint, partyID primitive.ObjectID, userID primitive.ObjectID) error {
+	loan, err := uc.openLoan(loanID, userID)
+	if err != nil {
+		return err
+	}
+
+	for i, party := range loan.Parties {
+		if party.ID == partyID {
+			loan.Parties = append(loan.Parties[:i], loan.Parties[i+1:]...)
+			if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+				return err
+			}
+			return recordEvent(uc.history, domain.LoanEvent{LoanID: loan.ID, Type: domain.LoanEventPartyRemoved, ActorID: userID, Detail: partyDetail(party)})
+		}
+	}
+	return domain.ErrPartyNotFound
+}
+
+// GetLoanParties retrieves the co-borrowers and guarantors of a loan.
+func (uc *loanPartyUsecase) GetLoanParties(loanID uint) ([]domain.LoanParty, error) {
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if loan.Parties == nil {
+		return []domain.LoanParty{}, nil
+	}
+	return loan.Parties, nil
+}
+
+// GetConsentRequest retrieves what the party holding a consent link is asked to agree to.
+func (uc *loanPartyUsecase) GetConsentRequest(token string) (domain.ConsentRequest, error) {
+	loan, i, err := uc.loanByConsentToken(token)
+	if err != nil {
+		return domain.ConsentRequest{}, err
+	}
+
+	return consentRequest(loan, loan.Parties[i]), nil
+}
+
+// RespondToConsent records the answer of the party holding a consent link. Only a pending
+// request on an application still pending or under review can be answered.
+func (uc *loanPartyUsecase) RespondToConsent(token string, consent bool) (domain.ConsentRequest, error) {
+	loan, i, err := uc.loanByConsentToken(token)
+	if err != nil {
+		return domain.ConsentRequest{}, err
+	}
+	party := &loan.Parties[i]
+	if party.ConsentStatus != domain.ConsentPending {
+		return domain.ConsentRequest{}, domain.ErrConsentAlreadyAnswered
+	}
+	if !partiesEditable(loan.Status) {
+		return domain.ConsentRequest{}, domain.ErrPartiesLocked
+	}
+
+	now := time.Now()
+	party.ConsentStatus = domain.ConsentDeclined
+	if consent {
+		party.ConsentStatus = domain.ConsentGiven
+	}
+	party.RespondedAt = &now
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return domain.ConsentRequest{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{
+		LoanID:  loan.ID,
+		Type:    domain.LoanEventConsentRecorded,
+		ActorID: party.UserID,
+		Detail:  partyDetail(*party) + " consent " + string(party.ConsentStatus),
+	}); err != nil {
+		return domain.ConsentRequest{}, err
+	}
+
+	return consentRequest(loan, *party), nil
+}
+
+// GetUserGuarantees retrieves the loans on which a user is a co-borrower or guarantor, whether
+// they were added as a user or by their email.
+func (uc *loanPartyUsecase) GetUserGuarantees(userID primitive.ObjectID) ([]domain.GuaranteedLoan, error) {
+	user, err := uc.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	loans, err := uc.loanRepo.GetLoansByParty(user.ID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	guarantees := []domain.GuaranteedLoan{}
+	for _, loan := range loans {
+		for _, party := range loan.Parties {
+			if party.UserID == user.ID || strings.EqualFold(party.Email, user.Email) {
+				guarantees = append(guarantees, guaranteedLoan(loan, party))
+				break
+			}
+		}
+	}
+	return guarantees, nil
+}
+
+// guaranteedLoan describes a loan to one of its parties.
+func guaranteedLoan(loan domain.Loan, party domain.LoanParty) domain.GuaranteedLoan {
+	return domain.GuaranteedLoan{
+		LoanID:             loan.ID,
+		Role:               party.Role,
+		ConsentStatus:      party.ConsentStatus,
+		Amount:             loan.Amount,
+		Term:               loan.Term,
+		InterestRate:       loan.InterestRate,
+		Status:             loan.Status,
+		OutstandingBalance: loan.OutstandingBalance,
+	}
+}
+
+// openLoan loads an application of userID whose parties can still change.
+func (uc *loanPartyUsecase) openLoan(loanID uint, userID primitive.ObjectID) (domain.Loan, error) {
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	if loan.UserID != userID {
+		return domain.Loan{}, domain.ErrNotLoanOwner
+	}
+	if !partiesEditable(loan.Status) {
+		return domain.Loan{}, domain.ErrPartiesLocked
+	}
+	return loan, nil
+}
+
+// loanByConsentToken loads the loan a consent link was sent for and the index of its party.
+func (uc *loanPartyUsecase) loanByConsentToken(token string) (domain.Loan, int, error) {
+	if token == "" {
+		return domain.Loan{}, 0, domain.ErrInvalidConsentToken
+	}
+	loan, err := uc.loanRepo.GetLoanByConsentToken(token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Loan{}, 0, domain.ErrInvalidConsentToken
+	}
+	if err != nil {
+		return domain.Loan{}, 0, err
+	}
+
+	for i, party := range loan.Parties {
+		if party.ConsentToken == token {
+			return loan, i, nil
+		}
+	}
+	return domain.Loan{}, 0, domain.ErrInvalidConsentToken
+}
+
+// partyDetail names a party in the history of its loan by role and ID, keeping their
+// personal details out of it.
+func partyDetail(party domain.LoanParty) string {
+	return string(party.Role) + " " + party.ID.Hex()
+}
+
+// partiesEditable reports whether the parties of a loan in state s can still change.
+func partiesEditable(s domain.LoanStatus) bool {
+	return s == domain.LoanStatusPending || s == domain.LoanStatusUnderReview
+}
+
+// newLoanParty checks a co-borrower or guarantor to be added to loan and returns it waiting
+// for consent. Existing users are looked up by ID and external persons need a name and a
+// valid email; neither may be the borrower or already a party.
+func newLoanParty(party domain.LoanParty, loan domain.Loan, borrower domain.User, userRepo domain.UserRepository, now time.Time) (domain.LoanParty, error) {
+	if !party.Role.IsValid() {
+		return domain.LoanParty{}, domain.ErrInvalidPartyRole
+	}
+
+	name := strings.TrimSpace(party.Name)
+	email := strings.ToLower(strings.TrimSpace(party.Email))
+	if !party.UserID.IsZero() {
+		user, err := userRepo.GetUserByID(party.UserID)
+		if err != nil {
+			return domain.LoanParty{}, domain.ErrUserNotFound
+		}
+		email = strings.ToLower(user.Email)
+		if name == "" {
+			name = user.Username
+		}
+	} else if name == "" || !domain.IsValidEmail(email) {
+		return domain.LoanParty{}, domain.ErrPartyIdentityRequired
+	}
+
+	if party.UserID == loan.UserID || strings.EqualFold(email, borrower.Email) {
+		return domain.LoanParty{}, domain.ErrPartyIsBorrower
+	}
+	for _, existing := range loan.Parties {
+		if (!party.UserID.IsZero() && existing.UserID == party.UserID) || existing.Email == email {
+			return domain.LoanParty{}, domain.ErrDuplicateParty
+		}
+	}
+
+	token, err := consentToken()
+	if err != nil {
+		return domain.LoanParty{}, err
+	}
+	return domain.LoanParty{
+		ID:                 primitive.NewObjectID(),
+		Role:               party.Role,
+		UserID:             party.UserID,
+		Name:               name,
+		Email:              email,
+		ConsentStatus:      domain.ConsentPending,
+		ConsentToken:       token,
+		ConsentRequestedAt: now,
+	}, nil
+}
+
+// consentToken returns a random token for a consent link.
+func consentToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// consentRequest describes the loan a party is asked to consent to.
+func consentRequest(loan domain.Loan, party domain.LoanParty) domain.ConsentRequest {
+	return domain.ConsentRequest{
+		LoanID:        loan.ID,
+		Role:          party.Role,
+		Name:          party.Name,
+		Amount:        loan.Amount,
+		Term:          loan.Term,
+		InterestRate:  loan.InterestRate,
+		ConsentStatus: party.ConsentStatus,
+	}
+}
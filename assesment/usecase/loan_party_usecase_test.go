@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recordingConsents records the parties asked for their consent.
+type recordingConsents struct {
+	requested []domain.LoanParty
+}
+
+func (n *recordingConsents) RequestConsent(party domain.LoanParty, loan domain.Loan) error {
+	n.requested = append(n.requested, party)
+	return nil
+}
+
+func TestNewLoanParty(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	borrower := domain.User{ID: primitive.NewObjectID(), Email: "borrower@example.com", Username: "borrower"}
+	friend := domain.User{ID: primitive.NewObjectID(), Email: "Friend@Example.com", Username: "friend"}
+	users := newMemoryUsers(borrower, friend)
+	loan := domain.Loan{
+		ID:      1,
+		UserID:  borrower.ID,
+		Parties: []domain.LoanParty{{Role: domain.PartyGuarantor, Email: "taken@example.com"}},
+	}
+
+	tests := []struct {
+		name      string
+		party     domain.LoanParty
+		wantErr   error
+		wantName  string
+		wantEmail string
+	}{
+		{"external person", domain.LoanParty{Role: domain.PartyGuarantor, Name: " Sara ", Email: " Sara@Example.com "}, nil, "Sara", "sara@example.com"},
+		{"existing user", domain.LoanParty{Role: domain.PartyCoBorrower, UserID: friend.ID}, nil, "friend", "friend@example.com"},
+		{"unknown role", domain.LoanParty{Role: "witness", Name: "Sara", Email: "sara@example.com"}, domain.ErrInvalidPartyRole, "", ""},
+		{"no name", domain.LoanParty{Role: domain.PartyGuarantor, Email: "sara@example.com"}, domain.ErrPartyIdentityRequired, "", ""},
+		{"invalid email", domain.LoanParty{Role: domain.PartyGuarantor, Name: "Sara", Email: "sara@"}, domain.ErrPartyIdentityRequired, "", ""},
+		{"unknown user", domain.LoanParty{Role: domain.PartyGuarantor, UserID: primitive.NewObjectID()}, domain.ErrUserNotFound, "", ""},
+		{"the borrower", domain.LoanParty{Role: domain.PartyGuarantor, UserID: borrower.ID}, domain.ErrPartyIsBorrower, "", ""},
+		{"the borrower's email", domain.LoanParty{Role: domain.PartyGuarantor, Name: "Me", Email: "BORROWER@example.com"}, domain.ErrPartyIsBorrower, "", ""},
+		{"already a party", domain.LoanParty{Role: domain.PartyCoBorrower, Name: "Taken", Email: "taken@example.com"}, domain.ErrDuplicateParty, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			party, err := newLoanParty(tt.party, loan, borrower, users, now)
+			if err != tt.wantErr {
+				t.Fatalf("newLoanParty = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if party.Name != tt.wantName || party.Email != tt.wantEmail {
+				t.Errorf("party %q <%s>, want %q <%s>", party.Name, party.Email, tt.wantName, tt.wantEmail)
+			}
+			if party.ID.IsZero() || party.ConsentToken == "" || party.ConsentStatus != domain.ConsentPending || !party.ConsentRequestedAt.Equal(now) {
+				t.Errorf("party %+v is not waiting for consent under a token", party)
+			}
+		})
+	}
+}
+
+func TestLoanPartyConsent(t *testing.T) {
+	borrower := domain.User{ID: primitive.NewObjectID(), Email: "borrower@example.com"}
+	guarantor := domain.User{ID: primitive.NewObjectID(), Email: "sara@example.com"}
+	loans := newMemoryLoans(domain.Loan{
+		ID:                 1,
+		UserID:             borrower.ID,
+		Amount:             usd(500000),
+		Term:               12,
+		InterestRate:       10,
+		Status:             domain.LoanStatusUnderReview,
+		OutstandingBalance: usd(0),
+	})
+	consents := &recordingConsents{}
+	uc := NewLoanPartyUsecase(loans, newMemoryUsers(borrower, guarantor), consents, &memoryHistory{})
+
+	if _, err := uc.AddLoanParty(1, domain.LoanParty{Role: domain.PartyGuarantor, Name: "Sara", Email: "sara@example.com"}, guarantor.ID); err != domain.ErrNotLoanOwner {
+		t.Fatalf("AddLoanParty by someone else = %v, want %v", err, domain.ErrNotLoanOwner)
+	}
+	party, err := uc.AddLoanParty(1, domain.LoanParty{Role: domain.PartyGuarantor, Name: "Sara", Email: "Sara@example.com"}, borrower.ID)
+	if err != nil {
+		t.Fatalf("AddLoanParty: %v", err)
+	}
+	if len(consents.requested) != 1 || consents.requested[0].ConsentToken != party.ConsentToken {
+		t.Fatalf("asked %d parties for consent, want the guarantor", len(consents.requested))
+	}
+
+	guarantees, err := uc.GetUserGuarantees(guarantor.ID)
+	if err != nil {
+		t.Fatalf("GetUserGuarantees: %v", err)
+	}
+	want := domain.GuaranteedLoan{
+		LoanID:             1,
+		Role:               domain.PartyGuarantor,
+		ConsentStatus:      domain.ConsentPending,
+		Amount:             usd(500000),
+		Term:               12,
+		InterestRate:       10,
+		Status:             domain.LoanStatusUnderReview,
+		OutstandingBalance: usd(0),
+	}
+	if len(guarantees) != 1 || guarantees[0] != want {
+		t.Errorf("guarantees %+v, want [%+v]", guarantees, want)
+	}
+
+	if _, err := uc.RespondToConsent("not-a-token", true); err != domain.ErrInvalidConsentToken {
+		t.Errorf("RespondToConsent with an unknown token = %v, want %v", err, domain.ErrInvalidConsentToken)
+	}
+	request, err := uc.RespondToConsent(party.ConsentToken, true)
+	if err != nil {
+		t.Fatalf("RespondToConsent: %v", err)
+	}
+	if request.ConsentStatus != domain.ConsentGiven {
+		t.Errorf("consent is %s, want given", request.ConsentStatus)
+	}
+	if _, err := uc.RespondToConsent(party.ConsentToken, false); err != domain.ErrConsentAlreadyAnswered {
+		t.Errorf("answering twice = %v, want %v", err, domain.ErrConsentAlreadyAnswered)
+	}
+	if loan, _ := loans.GetLoanByID(1); !loan.AllPartiesConsented() {
+		t.Errorf("loan parties %+v have not all consented", loan.Parties)
+	}
+
+	if err := uc.RemoveLoanParty(1, party.ID, borrower.ID); err != nil {
+		t.Fatalf("RemoveLoanParty: %v", err)
+	}
+	if err := uc.RemoveLoanParty(1, party.ID, borrower.ID); err != domain.ErrPartyNotFound {
+		t.Errorf("removing the party twice = %v, want %v", err, domain.ErrPartyNotFound)
+	}
+}
@@ -3,32 +3,249 @@ package usecase
 import (
     "assesment/domain"
     "errors"
+    "strings"
     "time"
+
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
 )
 
 type loanUsecase struct {
-    loanRepo domain.LoanRepository
+    loanRepo         domain.LoanRepository
+    paymentRepo      domain.PaymentRepository
+    disbursementRepo domain.DisbursementRepository
+    productRepo      domain.ProductRepository
+    userRepo         domain.UserRepository
+    ledger           domain.LedgerUsecase
+    decisions        domain.DecisionEngine
+    consents         domain.ConsentNotifier
+    collateral       domain.CollateralRepository
+    approvals        domain.DualApprovalPolicy
+    reasons          domain.RejectionReasonRepository
+    notices          domain.AdverseActionNotifier
+    history          domain.LoanHistoryRepository
 }
 
 // NewLoanUsecase creates a new instance of LoanUsecase.
-func NewLoanUsecase(loanRepo domain.LoanRepository) domain.LoanUsecase {
+func NewLoanUsecase(loanRepo domain.LoanRepository, paymentRepo domain.PaymentRepository, disbursementRepo domain.DisbursementRepository, productRepo domain.ProductRepository, userRepo domain.UserRepository, ledger domain.LedgerUsecase, decisions domain.DecisionEngine, consents domain.ConsentNotifier, collateral domain.CollateralRepository, approvals domain.DualApprovalPolicy, reasons domain.RejectionReasonRepository, notices domain.AdverseActionNotifier, history domain.LoanHistoryRepository) domain.LoanUsecase {
     return &loanUsecase{
-        loanRepo: loanRepo,
+        loanRepo:         loanRepo,
+        paymentRepo:      paymentRepo,
+        disbursementRepo: disbursementRepo,
+        productRepo:      productRepo,
+        userRepo:         userRepo,
+        ledger:           ledger,
+        decisions:        decisions,
+        consents:         consents,
+        collateral:       collateral,
+        approvals:        approvals,
+        reasons:          reasons,
+        notices:          notices,
+        history:          history,
+    }
+}
+
+// ApplyForLoan allows a user to submit a loan application for one of the loan products.
+// The credit rules then approve it, refer it for manual review or decline it.
+func (uc *loanUsecase) ApplyForLoan(loan domain.Loan) (domain.Loan, error) {
+    if loan.ProductID.IsZero() {
+        return domain.Loan{}, domain.ErrProductRequired
+    }
+    if err := uc.checkLoanIDUnused(loan.ID); err != nil {
+        return domain.Loan{}, err
+    }
+
+    product, err := uc.productRepo.GetProductByID(loan.ProductID)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+
+    if loan.RepaymentFrequency == "" {
+        loan.RepaymentFrequency = domain.FrequencyMonthly
+    }
+    if loan.AmortizationMethod == "" {
+        loan.AmortizationMethod = product.AmortizationMethod
+    }
+    // The lender sets the rate; whatever the applicant asked for is replaced by the product's.
+    loan.InterestRate = product.ApplicationRate()
+    if err := applyProductRules(&loan, product); err != nil {
+        return domain.Loan{}, err
+    }
+    if err := validateLoanTerms(loan); err != nil {
+        return domain.Loan{}, err
+    }
+    if loan.MonthlyIncome.Currency != "" {
+        if err := loan.MonthlyIncome.Validate(); err != nil {
+            return domain.Loan{}, err
+        }
+    }
+
+    user, err := uc.userRepo.GetUserByID(loan.UserID)
+    if err != nil {
+        return domain.Loan{}, domain.ErrUserNotFound
+    }
+    existing, err := uc.loanRepo.GetLoansByUser(loan.UserID)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+    now := time.Now()
+    if err := checkEligibility(product.Eligibility, user, existing, now); err != nil {
+        return domain.Loan{}, err
+    }
+
+    parties := loan.Parties
+    loan.Parties = nil
+    for _, party := range parties {
+        party, err := newLoanParty(party, loan, user, uc.userRepo, now)
+        if err != nil {
+            return domain.Loan{}, err
+        }
+        loan.Parties = append(loan.Parties, party)
+    }
+
+    pledged, err := uc.pledgedCollateral(loan)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+    loan.CollateralValue = collateralValue(loan.Amount.Zero(), pledged)
+    loan.LoanToValue = loanToValue(loan.Amount, loan.CollateralValue)
+
+    // Only the lender sets how the loan was reviewed, paid out and closed.
+    loan.Review = nil
+    loan.Rejection = nil
+    loan.WriteOff = nil
+    loan.Closure = nil
+    loan.DisbursedAt = nil
+    loan.InterestAccruedTo = nil
+    loan.DaysPastDue = 0
+    loan.AgingBucket = ""
+    loan.Recovered = loan.Amount.Zero()
+
+    loan.OriginationFee = originationFee(product, loan.Amount)
+    loan.Penalties = product.Penalties
+    loan.Prepayment = product.Prepayment
+    loan.DayCount = product.DayCount
+    loan.AccruedInterest = loan.Amount.Zero()
+    loan.Schedule = nil
+    loan.OutstandingBalance = loan.Amount.Zero()
+    loan.Status = domain.LoanStatusPending
+
+    decision := uc.decisions.Evaluate(domain.CreditApplication{
+        Loan:          loan,
+        User:          user,
+        ExistingLoans: existing,
+        Now:           now,
+    })
+    loan.Decision = &decision
+
+    // An application the rules approve still waits for its co-borrowers and guarantors, and
+    // for two admins when it is above the dual approval threshold.
+    next := decision.Outcome.LoanStatus()
+    if next == domain.LoanStatusApproved && (!loan.AllPartiesConsented() || uc.approvals.Requires(loan.Amount)) {
+        next = domain.LoanStatusUnderReview
+    }
+    loan.Status, err = loan.Status.Transition(next)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+    if loan.Status == domain.LoanStatusRejected {
+        loan.Rejection = &domain.LoanRejection{Reasons: declineReasons(uc.reasons, decision), RejectedAt: now}
+    }
+    if loan.Status == domain.LoanStatusApproved {
+        loan.Schedule, err = generateSchedule(loan, now)
+        if err != nil {
+            return domain.Loan{}, err
+        }
+        loan.OutstandingBalance = outstandingBalance(loan)
+    }
+
+    loan.CreatedAt = now
+    loan.UpdatedAt = now
+
+    if err := uc.loanRepo.ApplyForLoan(loan); err != nil {
+        return domain.Loan{}, err
+    }
+
+    applied := termsState(loan)
+    applied.Status = domain.LoanStatusPending
+    if err := recordEvent(uc.history, domain.LoanEvent{LoanID: loan.ID, Type: domain.LoanEventApplied, ActorID: loan.UserID, After: applied}); err != nil {
+        return domain.Loan{}, err
+    }
+    if loan.Status != domain.LoanStatusPending {
+        detail := "credit rules: " + string(decision.Outcome)
+        if loan.Rejection != nil {
+            detail += ": " + rejectionDetail(loan.Rejection.Reasons, "")
+        }
+        if err := recordStatusChange(uc.history, loan.ID, domain.LoanStatusPending, loan.Status, primitive.NilObjectID, detail); err != nil {
+            return domain.Loan{}, err
+        }
+    }
+
+    // A declined application secures nothing and needs no one's consent, but the borrower
+    // is told why it was declined.
+    if loan.Status.IsTerminal() {
+        return loan, uc.notifyRejection(&loan, user)
+    }
+    for _, collateral := range pledged {
+        if err := pledgeCollateral(uc.collateral, collateral, loan.ID, now); err != nil {
+            return domain.Loan{}, err
+        }
+    }
+    for _, party := range loan.Parties {
+        if err := uc.consents.RequestConsent(party, loan); err != nil {
+            return domain.Loan{}, domain.ErrConsentRequestNotSent
+        }
     }
+    return loan, nil
 }
 
-// ApplyForLoan allows a user to submit a loan application.
-func (uc *loanUsecase) ApplyForLoan(loan domain.Loan) error {
-    // Add business logic here (e.g., validation, setting default values)
-    if loan.Amount <= 0 {
-        return errors.New("invalid loan amount")
+// checkLoanIDUnused refuses the ID of a loan on record, or of a deleted loan whose history is
+// kept under it, so a new loan never takes over the history of another. Two applications
+// racing for the same ID are told apart by the repository, which stores only one of them.
+func (uc *loanUsecase) checkLoanIDUnused(id uint) error {
+    if id == 0 {
+        return domain.ErrLoanIDRequired
     }
 
-    loan.Status = "pending"
-    loan.CreatedAt = time.Now()
-    loan.UpdatedAt = time.Now()
+    _, err := uc.loanRepo.GetLoanByID(id)
+    if err == nil {
+        return domain.ErrLoanIDTaken
+    }
+    if !errors.Is(err, mongo.ErrNoDocuments) {
+        return err
+    }
 
-    return uc.loanRepo.ApplyForLoan(loan)
+    events, err := uc.history.GetEventsByLoan(id)
+    if err != nil {
+        return err
+    }
+    if len(events) > 0 {
+        return domain.ErrLoanIDTaken
+    }
+    return nil
+}
+
+// pledgedCollateral loads the collateral an application pledges and checks that each can
+// secure it.
+func (uc *loanUsecase) pledgedCollateral(loan domain.Loan) ([]domain.Collateral, error) {
+    var pledged []domain.Collateral
+    seen := make(map[primitive.ObjectID]bool, len(loan.CollateralIDs))
+    for _, id := range loan.CollateralIDs {
+        if seen[id] {
+            continue
+        }
+        seen[id] = true
+
+        collateral, err := uc.collateral.GetCollateralByID(id)
+        if err != nil {
+            return nil, err
+        }
+        if err := checkPledge(collateral, loan); err != nil {
+            return nil, err
+        }
+        pledged = append(pledged, collateral)
+    }
+    return pledged, nil
 }
 
 // GetLoanByID retrieves the loan status by ID.
@@ -42,41 +259,581 @@ func (uc *loanUsecase) GetLoanByID(id uint) (domain.Loan, error) {
 }
 
 // GetAllLoans retrieves all loan applications, with optional filtering and sorting.
-func (uc *loanUsecase) GetAllLoans(status, order string) ([]domain.Loan, error) {
-    return uc.loanRepo.GetAllLoans(status, order)
+func (uc *loanUsecase) GetAllLoans(status, order, currency string) ([]domain.Loan, error) {
+    return uc.loanRepo.GetAllLoans(status, order, currency)
+}
+
+// GetUserLoans retrieves every loan of a borrower, oldest first.
+func (uc *loanUsecase) GetUserLoans(userID primitive.ObjectID) ([]domain.Loan, error) {
+    return uc.loanRepo.GetLoansByUser(userID)
 }
 
-// ApproveLoan allows an admin to approve a loan.
-func (uc *loanUsecase) ApproveLoan(id uint) error {
-    // Business logic: Validate the loan approval
+// TransitionLoan moves a loan the caller has loaded to another lifecycle state on behalf of
+// actor, who is zero for background jobs. It is the single entry point for status changes and
+// rejects moves the state machine does not allow, and loans changed since they were loaded.
+func (uc *loanUsecase) TransitionLoan(loan *domain.Loan, to domain.LoanStatus, actor primitive.ObjectID) error {
+    return uc.transition(loan, to, actor, "")
+}
+
+// UpdateLoanStatus lets an admin move a pending application to under_review by hand. Every
+// other state has its own operation, which records what entering it takes: a schedule, a
+// payout, reasons or a closure.
+func (uc *loanUsecase) UpdateLoanStatus(id uint, to domain.LoanStatus, adminID primitive.ObjectID) error {
     loan, err := uc.loanRepo.GetLoanByID(id)
     if err != nil {
         return err
     }
+    if to != domain.LoanStatusUnderReview || loan.Status != domain.LoanStatusPending {
+        return domain.ErrStatusSetByOperation
+    }
+
+    return uc.transition(&loan, to, adminID, "")
+}
+
+// transition applies a state change to an already loaded loan, keeps its status in sync and
+// records the change in the loan's history with the reason given for it, if any.
+func (uc *loanUsecase) transition(loan *domain.Loan, to domain.LoanStatus, actor primitive.ObjectID, reason string) error {
+    next, err := loan.Status.Transition(to)
+    if err != nil {
+        return err
+    }
+    if next == domain.LoanStatusApproved && !loan.AllPartiesConsented() {
+        return domain.ErrPartyConsentRequired
+    }
+    if next == domain.LoanStatusApproved && uc.approvals.Requires(loan.Amount) && len(loan.Approvals()) < 2 {
+        return domain.ErrSecondApprovalRequired
+    }
+    // Withdrawing and cancelling only go through closeLoan, which records who closed the loan and why.
+    if (next == domain.LoanStatusWithdrawn || next == domain.LoanStatusCancelled) && loan.Closure == nil {
+        return domain.ErrClosureReasonRequired
+    }
+
+    from := loan.Status
+    if err := uc.loanRepo.UpdateLoanStatus(loan, next); err != nil {
+        return err
+    }
+    if err := recordStatusChange(uc.history, loan.ID, from, next, actor, reason); err != nil {
+        return err
+    }
 
-    if loan.Status != "pending" {
-        return errors.New("only pending loans can be approved")
+    return uc.afterTransition(loan)
+}
+
+// afterTransition runs the side effects of a loan entering a new state.
+func (uc *loanUsecase) afterTransition(loan *domain.Loan) error {
+    switch loan.Status {
+    case domain.LoanStatusDisbursed:
+        if err := uc.ledger.PostDisbursement(*loan); err != nil {
+            return err
+        }
+        if loan.OriginationFee.IsPositive() {
+            return uc.ledger.PostFee(loan.ID, loan.OriginationFee, "origination")
+        }
+    case domain.LoanStatusPaidOff, domain.LoanStatusRejected, domain.LoanStatusWithdrawn, domain.LoanStatusCancelled:
+        // The loan is repaid or was never paid out, so its collateral is free again.
+        return releaseLiens(uc.collateral, uc.history, loan.ID, time.Now())
+    case domain.LoanStatusWrittenOff:
+        if loan.WriteOff == nil {
+            writeOff := newWriteOff(*loan)
+            loan.WriteOff = &writeOff
+        }
+        loan.AccruedInterest = loan.Amount.Zero()
+        loan.Recovered = loan.Amount.Zero()
+        if err := uc.loanRepo.UpdateLoan(loan); err != nil {
+            return err
+        }
+        return uc.ledger.PostWriteOff(loan.ID, loan.WriteOff.Principal, loan.WriteOff.Interest, loan.WriteOff.Fees)
     }
+    return nil
+}
 
-    return uc.loanRepo.UpdateLoanStatus(id, "approved")
+// newWriteOff records the principal, accrued interest, fees and penalties a loan still owes.
+func newWriteOff(loan domain.Loan) domain.WriteOff {
+    fees := loan.Amount.Zero()
+    for _, inst := range loan.Schedule {
+        fees = fees.Add(inst.FeesDue()).Add(inst.PenaltiesDue())
+    }
+    return domain.WriteOff{
+        Principal:    outstandingPrincipal(loan),
+        Interest:     loan.AccruedInterest,
+        Fees:         fees,
+        WrittenOffAt: time.Now(),
+    }
 }
 
-// RejectLoan allows an admin to reject a loan.
-func (uc *loanUsecase) RejectLoan(id uint) error {
-    // Business logic: Validate the loan rejection
+// WriteOffLoan allows an admin to take a defaulted loan off the books. What it still owed is
+// recorded on the loan and moved to loan loss expense; the borrower's debt remains and
+// later recoveries are recorded with RecordRecovery.
+func (uc *loanUsecase) WriteOffLoan(id uint, reason string, writtenOffBy primitive.ObjectID) (domain.Loan, error) {
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return domain.Loan{}, domain.ErrWriteOffReasonRequired
+    }
+
     loan, err := uc.loanRepo.GetLoanByID(id)
     if err != nil {
-        return err
+        return domain.Loan{}, err
+    }
+
+    writeOff := newWriteOff(loan)
+    writeOff.Reason = reason
+    writeOff.WrittenOffBy = writtenOffBy
+    loan.WriteOff = &writeOff
+
+    if err := uc.transition(&loan, domain.LoanStatusWrittenOff, writtenOffBy, reason); err != nil {
+        return domain.Loan{}, err
+    }
+    return loan, nil
+}
+
+// RecordRecovery records money recovered on a written-off loan. It is allocated across the
+// schedule like a repayment, so the borrower's balance goes down, but it is booked as
+// recovery income and kept apart from repayments in reports.
+func (uc *loanUsecase) RecordRecovery(loanID uint, payment domain.Payment) (domain.Payment, error) {
+    if err := payment.Amount.Validate(); err != nil {
+        return domain.Payment{}, err
+    }
+    if !payment.Amount.IsPositive() {
+        return domain.Payment{}, domain.ErrInvalidPaymentAmount
+    }
+
+    loan, err := uc.loanRepo.GetLoanByID(loanID)
+    if err != nil {
+        return domain.Payment{}, err
+    }
+    if loan.Status != domain.LoanStatusWrittenOff {
+        return domain.Payment{}, domain.ErrLoanNotWrittenOff
+    }
+    if payment.Amount.Currency != loan.Amount.Currency {
+        return domain.Payment{}, domain.ErrCurrencyMismatch
+    }
+    if payment.Amount.Cmp(loan.OutstandingBalance) > 0 {
+        return domain.Payment{}, domain.ErrPaymentExceedsBalance
+    }
+
+    payment.PaidAt, err = paymentDate(payment.PaidAt, loan, time.Now())
+    if err != nil {
+        return domain.Payment{}, err
+    }
+
+    balanceBefore := balanceState(loan)
+    split := allocatePayment(loan.Schedule, payment.Amount, payment.PaidAt)
+    loan.OutstandingBalance = outstandingBalance(loan)
+    loan.Recovered = loan.Recovered.Add(payment.Amount)
+
+    zero := loan.Amount.Zero()
+    payment.ID = primitive.NewObjectID()
+    payment.LoanID = loan.ID
+    payment.UserID = loan.UserID
+    payment.Penalties = split.Penalties
+    payment.Fees = split.Fees
+    payment.Interest = split.Interest
+    payment.InterestReceivable = zero
+    payment.Principal = split.Principal
+    payment.PrepaymentPenalty = zero
+    payment.Prepayment = ""
+    payment.Settlement = false
+    payment.Recovery = true
+    payment.BalanceAfter = loan.OutstandingBalance
+    payment.CreatedAt = time.Now()
+
+    // The loan is saved first: a stale write leaves no payment or posting behind to retry on top of.
+    if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+        return domain.Payment{}, err
+    }
+    if err := uc.paymentRepo.CreatePayment(payment); err != nil {
+        return domain.Payment{}, err
+    }
+    if err := uc.ledger.PostRecovery(payment); err != nil {
+        return domain.Payment{}, err
+    }
+    if err := uc.recordPayment(payment, balanceBefore, balanceState(loan)); err != nil {
+        return domain.Payment{}, err
+    }
+
+    return payment, nil
+}
+
+// recordPayment appends a repayment or recovery, and what the loan owed before and after
+// it, to the loan's history on behalf of whoever posted it.
+func (uc *loanUsecase) recordPayment(payment domain.Payment, before, after *domain.LoanState) error {
+    event := domain.LoanEvent{
+        LoanID:  payment.LoanID,
+        Type:    domain.LoanEventPaymentRecorded,
+        ActorID: payment.RecordedBy,
+        Before:  before,
+        After:   after,
+        Detail:  "payment " + payment.ID.Hex() + " of " + payment.Amount.String(),
+    }
+    if payment.Recovery {
+        event.Detail = "recovery " + payment.ID.Hex() + " of " + payment.Amount.String()
+    }
+    return recordEvent(uc.history, event)
+}
+
+// ApproveLoan allows a reviewer to approve a loan and generates its repayment schedule. The
+// reviewer and their reason are recorded on the loan. A loan above the dual approval threshold
+// needs two different admins: the first approval is recorded and the loan waits for the
+// second. A loan with a counter-offer is approved only when the borrower accepts it; above the
+// threshold a second admin confirms the offer here before the borrower can.
+func (uc *loanUsecase) ApproveLoan(id uint, reviewerID primitive.ObjectID, reason string) (domain.Loan, error) {
+    loan, err := uc.loanRepo.GetLoanByID(id)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+
+    dual := uc.approvals.Requires(loan.Amount)
+    if !dual || len(loan.Approvals()) == 0 {
+        if err := checkReviewer(loan, reviewerID); err != nil {
+            return domain.Loan{}, err
+        }
+    }
+    if dual && loan.ApprovedBy(reviewerID) {
+        return domain.Loan{}, domain.ErrSameApprover
+    }
+
+    now := time.Now()
+    if loan.Status == domain.LoanStatusCounterOffered {
+        // Only an offer above the threshold carries its maker's approval and waits for a second one.
+        if len(loan.Approvals()) != 1 {
+            return domain.Loan{}, domain.ErrCounterOfferPending
+        }
+        return uc.saveApproval(loan, reviewerID, reason, now)
+    }
+    if dual && len(loan.Approvals()) == 0 {
+        if _, err := loan.Status.Transition(domain.LoanStatusApproved); err != nil {
+            return domain.Loan{}, err
+        }
+        return uc.saveApproval(loan, reviewerID, reason, now)
+    }
+
+    schedule, err := generateSchedule(loan, now)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+
+    if dual {
+        recordApproval(&loan, reviewerID, reason, now)
+    }
+    if err := uc.transition(&loan, domain.LoanStatusApproved, reviewerID, strings.TrimSpace(reason)); err != nil {
+        return domain.Loan{}, err
+    }
+
+    loan.Schedule = schedule
+    loan.OutstandingBalance = outstandingBalance(loan)
+    recordDecision(&loan, reviewerID, reason, now)
+    if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+        return domain.Loan{}, err
+    }
+    return loan, nil
+}
+
+// saveApproval records the sign-off of adminID on a loan that keeps waiting for another.
+func (uc *loanUsecase) saveApproval(loan domain.Loan, adminID primitive.ObjectID, reason string, now time.Time) (domain.Loan, error) {
+    recordApproval(&loan, adminID, reason, now)
+    loan.UpdatedAt = now
+    if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+        return domain.Loan{}, err
+    }
+    if err := recordEvent(uc.history, domain.LoanEvent{LoanID: loan.ID, Type: domain.LoanEventApprovalRecorded, ActorID: adminID, Detail: strings.TrimSpace(reason)}); err != nil {
+        return domain.Loan{}, err
+    }
+    return loan, nil
+}
+
+// RejectLoan allows a reviewer to reject a loan for one or more reasons from the catalog,
+// with an optional note. The reasons are recorded on the loan and the borrower is sent an
+// adverse-action notice; a notice that cannot be sent leaves the loan rejected.
+func (uc *loanUsecase) RejectLoan(id uint, reviewerID primitive.ObjectID, rejection domain.RejectionRequest) (domain.Loan, error) {
+    reasons, err := statedReasons(uc.reasons, rejection.ReasonCodes)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+
+    loan, err := uc.loanRepo.GetLoanByID(id)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+    if err := checkReviewer(loan, reviewerID); err != nil {
+        return domain.Loan{}, err
+    }
+
+    note := strings.TrimSpace(rejection.Note)
+    if err := uc.transition(&loan, domain.LoanStatusRejected, reviewerID, rejectionDetail(reasons, note)); err != nil {
+        return domain.Loan{}, err
+    }
+
+    now := time.Now()
+    loan.Rejection = &domain.LoanRejection{Reasons: reasons, Note: note, RejectedBy: reviewerID, RejectedAt: now}
+    recordDecision(&loan, reviewerID, note, now)
+    if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+        return domain.Loan{}, err
+    }
+
+    borrower, err := uc.userRepo.GetUserByID(loan.UserID)
+    if err != nil {
+        return loan, domain.ErrAdverseActionNotSent
+    }
+    return loan, uc.notifyRejection(&loan, borrower)
+}
+
+// notifyRejection sends the borrower of a rejected loan the adverse-action notice stating its
+// reasons and records when it was sent.
+func (uc *loanUsecase) notifyRejection(loan *domain.Loan, borrower domain.User) error {
+    if err := uc.notices.SendNotice(adverseActionNotice(*loan, borrower)); err != nil {
+        return domain.ErrAdverseActionNotSent
+    }
+    sent := time.Now()
+    loan.Rejection.NoticeSentAt = &sent
+    return uc.loanRepo.UpdateLoan(loan)
+}
+
+// WithdrawLoan allows a borrower to withdraw their application while it is pending, under
+// review or counter-offered. The loan is kept as withdrawn with the reason.
+func (uc *loanUsecase) WithdrawLoan(id uint, userID primitive.ObjectID, reason string) (domain.Loan, error) {
+    loan, err := uc.loanRepo.GetLoanByID(id)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+    if loan.UserID != userID {
+        return domain.Loan{}, domain.ErrNotLoanOwner
     }
 
-    if loan.Status != "pending" {
-        return errors.New("only pending loans can be rejected")
+    return uc.closeLoan(loan, domain.LoanStatusWithdrawn, userID, reason)
+}
+
+// CancelLoan allows an admin to cancel an approved loan that has not been paid out. The loan
+// is kept as cancelled with the reason and owes nothing.
+func (uc *loanUsecase) CancelLoan(id uint, adminID primitive.ObjectID, reason string) (domain.Loan, error) {
+    loan, err := uc.loanRepo.GetLoanByID(id)
+    if err != nil {
+        return domain.Loan{}, err
     }
 
-    return uc.loanRepo.UpdateLoanStatus(id, "rejected")
+    return uc.closeLoan(loan, domain.LoanStatusCancelled, adminID, reason)
 }
 
-// DeleteLoan allows an admin to delete a loan by its ID.
-func (uc *loanUsecase) DeleteLoan(id uint) error {
-    return uc.loanRepo.DeleteLoan(id)
+// closeLoan moves a loan that was never paid out to a terminal state and records who closed
+// it and why. A loan with a disbursement processing or confirmed stays open.
+func (uc *loanUsecase) closeLoan(loan domain.Loan, to domain.LoanStatus, closedBy primitive.ObjectID, reason string) (domain.Loan, error) {
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return domain.Loan{}, domain.ErrClosureReasonRequired
+    }
+
+    // A payout already sent or on its way would leave the borrower holding money on a closed loan.
+    disbursements, err := uc.disbursementRepo.GetDisbursementsByLoan(loan.ID)
+    if err != nil {
+        return domain.Loan{}, err
+    }
+    for _, disbursement := range disbursements {
+        if disbursement.Status != domain.DisbursementFailed {
+            return domain.Loan{}, domain.ErrDisbursementInProgress
+        }
+    }
+
+    now := time.Now()
+    loan.Closure = &domain.LoanClosure{Reason: reason, ClosedBy: closedBy, ClosedAt: now}
+    if err := uc.transition(&loan, to, closedBy, reason); err != nil {
+        return domain.Loan{}, err
+    }
+
+    loan.OutstandingBalance = loan.Amount.Zero()
+    loan.UpdatedAt = now
+    if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+        return domain.Loan{}, err
+    }
+
+    return loan, nil
+}
+
+// GetLoanSchedule retrieves the repayment schedule of an approved loan.
+func (uc *loanUsecase) GetLoanSchedule(id uint) ([]domain.Installment, error) {
+    loan, err := uc.loanRepo.GetLoanByID(id)
+    if err != nil {
+        return nil, err
+    }
+
+    if len(loan.Schedule) == 0 {
+        return nil, domain.ErrScheduleNotGenerated
+    }
+
+    return loan.Schedule, nil
+}
+
+// GetPayoffQuote computes what settles a loan in full on date, which defaults to today.
+func (uc *loanUsecase) GetPayoffQuote(id uint, date time.Time) (domain.PayoffQuote, error) {
+    now := time.Now()
+    if date.IsZero() {
+        date = now
+    }
+    day := accrualDay(date)
+    if day.Before(accrualDay(now)) {
+        return domain.PayoffQuote{}, domain.ErrQuoteDateInPast
+    }
+
+    loan, err := uc.loanRepo.GetLoanByID(id)
+    if err != nil {
+        return domain.PayoffQuote{}, err
+    }
+    if !loan.Status.AcceptsPayments() {
+        return domain.PayoffQuote{}, domain.ErrLoanNotRepayable
+    }
+
+    quote := payoffQuote(loan, day)
+    quote.QuotedAt = now
+    return quote, nil
+}
+
+// RecordPayment records a repayment against a loan, allocates it across the schedule
+// and moves the loan to paid_off once nothing is left to pay. A payment of exactly the
+// payoff quote for the day it is made settles the loan. With a prepayment option, whatever
+// exceeds the amount due repays principal and the remaining installments are rebuilt.
+func (uc *loanUsecase) RecordPayment(loanID uint, payment domain.Payment) (domain.Payment, error) {
+    if err := payment.Amount.Validate(); err != nil {
+        return domain.Payment{}, err
+    }
+    if !payment.Amount.IsPositive() {
+        return domain.Payment{}, domain.ErrInvalidPaymentAmount
+    }
+    if payment.Prepayment != "" && !payment.Prepayment.IsValid() {
+        return domain.Payment{}, domain.ErrInvalidPrepaymentOption
+    }
+
+    loan, err := uc.loanRepo.GetLoanByID(loanID)
+    if err != nil {
+        return domain.Payment{}, err
+    }
+
+    if !loan.Status.AcceptsPayments() {
+        return domain.Payment{}, domain.ErrLoanNotRepayable
+    }
+
+    if payment.Amount.Currency != loan.Amount.Currency {
+        return domain.Payment{}, domain.ErrCurrencyMismatch
+    }
+
+    payment.PaidAt, err = paymentDate(payment.PaidAt, loan, time.Now())
+    if err != nil {
+        return domain.Payment{}, err
+    }
+
+    balanceBefore := balanceState(loan)
+    prepaid, penalty := loan.Amount.Zero(), loan.Amount.Zero()
+    var split allocation
+    quote := payoffQuote(loan, accrualDay(payment.PaidAt))
+    due := amountDueBy(loan.Schedule, payment.PaidAt)
+    switch {
+    case payment.Amount.Cmp(quote.Total) == 0:
+        // Interest the loan would have earned after today is not charged on settlement.
+        settleSchedule(&loan, accrualDay(payment.PaidAt))
+        penalty = quote.PrepaymentPenalty
+        split = allocatePayment(loan.Schedule, payment.Amount.Sub(penalty), payment.PaidAt)
+        payment.Settlement = true
+        payment.Prepayment = ""
+    case payment.Amount.Cmp(loan.OutstandingBalance) > 0:
+        return domain.Payment{}, domain.ErrPaymentExceedsBalance
+    case payment.Prepayment != "" && payment.Amount.Cmp(due) > 0:
+        split = allocatePayment(loan.Schedule, due, payment.PaidAt)
+        prepaid, penalty = splitPrepayment(payment.Amount.Sub(due), loan.Prepayment)
+        if err := prepay(&loan, prepaid, payment.Prepayment, payment.PaidAt); err != nil {
+            return domain.Payment{}, err
+        }
+    default:
+        split = allocatePayment(loan.Schedule, payment.Amount, payment.PaidAt)
+        payment.Prepayment = ""
+    }
+    loan.OutstandingBalance = outstandingBalance(loan)
+    loan.DaysPastDue = daysPastDue(loan.Schedule, time.Now())
+    loan.AgingBucket = domain.AgingBucketFor(loan.DaysPastDue)
+
+    payment.ID = primitive.NewObjectID()
+    payment.LoanID = loan.ID
+    payment.UserID = loan.UserID
+    payment.Penalties = split.Penalties
+    payment.Fees = split.Fees.Add(penalty)
+    payment.PrepaymentPenalty = penalty
+    payment.Interest = split.Interest
+    // Interest received first settles what the accrual job has already recognised.
+    payment.InterestReceivable = split.Interest.Min(loan.AccruedInterest)
+    loan.AccruedInterest = loan.AccruedInterest.Sub(payment.InterestReceivable)
+    payment.Principal = split.Principal.Add(prepaid)
+    payment.BalanceAfter = loan.OutstandingBalance
+    payment.CreatedAt = time.Now()
+
+    // The loan is saved first: a stale write leaves no payment or posting behind to retry on top of.
+    if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+        return domain.Payment{}, err
+    }
+    if err := uc.paymentRepo.CreatePayment(payment); err != nil {
+        return domain.Payment{}, err
+    }
+    if penalty.IsPositive() {
+        if err := uc.ledger.PostFee(loan.ID, penalty, payment.ID.Hex()); err != nil {
+            return domain.Payment{}, err
+        }
+    }
+    if err := uc.ledger.PostRepayment(payment); err != nil {
+        return domain.Payment{}, err
+    }
+    if err := uc.recordPayment(payment, balanceBefore, balanceState(loan)); err != nil {
+        return domain.Payment{}, err
+    }
+
+    if !loan.OutstandingBalance.IsPositive() {
+        if err := uc.transition(&loan, domain.LoanStatusPaidOff, payment.RecordedBy, ""); err != nil {
+            return domain.Payment{}, err
+        }
+    } else if loan.Status == domain.LoanStatusDelinquent && loan.DaysPastDue == 0 {
+        // A delinquent borrower who catches up on every overdue installment is current again.
+        if err := uc.transition(&loan, domain.LoanStatusActive, payment.RecordedBy, ""); err != nil {
+            return domain.Payment{}, err
+        }
+    }
+
+    return payment, nil
+}
+
+// paymentDate returns the date a payment is booked on: now, or a day in the past no further
+// back than MaxPaymentBackdate and not before the loan was paid out. The date decides which
+// installments are overdue and how much interest a settlement includes, so it is bounded.
+func paymentDate(paidAt time.Time, loan domain.Loan, now time.Time) (time.Time, error) {
+    if paidAt.IsZero() {
+        return now, nil
+    }
+    if paidAt.After(now) || paidAt.Before(now.Add(-domain.MaxPaymentBackdate)) {
+        return time.Time{}, domain.ErrPaymentDateOutOfRange
+    }
+    if loan.DisbursedAt != nil && paidAt.Before(*loan.DisbursedAt) {
+        return time.Time{}, domain.ErrPaymentDateOutOfRange
+    }
+    return paidAt, nil
+}
+
+// GetLoanPayments retrieves the payment history of a loan.
+func (uc *loanUsecase) GetLoanPayments(loanID uint) ([]domain.Payment, error) {
+    if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+        return nil, err
+    }
+
+    return uc.paymentRepo.GetPaymentsByLoan(loanID)
+}
+
+// GetUserPayments retrieves the payment history of a user across all of their loans.
+func (uc *loanUsecase) GetUserPayments(userID primitive.ObjectID) ([]domain.Payment, error) {
+    return uc.paymentRepo.GetPaymentsByUser(userID)
+}
+
+// DeleteLoan allows an admin to delete a loan by its ID. The loan's history is kept and
+// ends with the deletion.
+func (uc *loanUsecase) DeleteLoan(id uint, deletedBy primitive.ObjectID) error {
+    loan, err := uc.loanRepo.GetLoanByID(id)
+    if err != nil {
+        return err
+    }
+
+    if err := uc.loanRepo.DeleteLoan(id); err != nil {
+        return err
+    }
+    return recordEvent(uc.history, domain.LoanEvent{LoanID: id, Type: domain.LoanEventDeleted, ActorID: deletedBy, Before: &domain.LoanState{Status: loan.Status}})
 }
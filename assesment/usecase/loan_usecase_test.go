@@ -0,0 +1,595 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"maps"
+	"math/big"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestStaleLoanWritesAreRefused(t *testing.T) {
+	loans := newMemoryLoans(domain.Loan{ID: 1, Amount: usd(100000), Status: domain.LoanStatusActive, Version: 3})
+	history := &memoryHistory{}
+	uc := NewLoanUsecase(loans, nil, nil, nil, nil, nil, nil, nil, nil, domain.DualApprovalPolicy{}, nil, nil, history)
+
+	job, _ := loans.GetLoanByID(1)
+	stale, _ := loans.GetLoanByID(1)
+
+	job.AccruedInterest = usd(27)
+	if err := loans.UpdateLoan(&job); err != nil {
+		t.Fatalf("UpdateLoan: %v", err)
+	}
+
+	if err := uc.TransitionLoan(&stale, domain.LoanStatusDelinquent, primitive.NilObjectID); err != domain.ErrLoanModified {
+		t.Fatalf("TransitionLoan on a stale loan = %v, want %v", err, domain.ErrLoanModified)
+	}
+	if err := loans.UpdateLoan(&stale); err != domain.ErrLoanModified {
+		t.Fatalf("UpdateLoan on a stale loan = %v, want %v", err, domain.ErrLoanModified)
+	}
+
+	stored, _ := loans.GetLoanByID(1)
+	if stored.Status != domain.LoanStatusActive || stored.AccruedInterest != usd(27) || stored.Version != 4 {
+		t.Errorf("stored loan is %s with %s accrued at version %d, want active with 0.27 USD at version 4",
+			stored.Status, stored.AccruedInterest, stored.Version)
+	}
+	if len(history.events) != 0 {
+		t.Errorf("recorded %d events for refused writes, want none", len(history.events))
+	}
+
+	fresh, _ := loans.GetLoanByID(1)
+	if err := uc.TransitionLoan(&fresh, domain.LoanStatusDelinquent, primitive.NilObjectID); err != nil {
+		t.Fatalf("TransitionLoan on a fresh loan: %v", err)
+	}
+	if fresh.Status != domain.LoanStatusDelinquent || fresh.Version != 5 {
+		t.Errorf("loan is %s at version %d, want delinquent at version 5", fresh.Status, fresh.Version)
+	}
+}
+
+// applicationFixture is a borrower applying for 1,000.00 USD over 12 months on a product
+// lending 100.00 to 10,000.00 USD at 5% to 20%, under a single credit rule declining
+// applications from accounts that are not activated.
+type applicationFixture struct {
+	borrower   domain.User
+	product    domain.LoanProduct
+	loans      *memoryLoans
+	collateral domain.CollateralRepository
+	consents   *recordingConsents
+	approvals  domain.DualApprovalPolicy
+	notices    *recordingNotices
+	history    *memoryHistory
+}
+
+func newApplicationFixture(active bool) *applicationFixture {
+	return &applicationFixture{
+		borrower: domain.User{ID: primitive.NewObjectIDFromTimestamp(time.Now().AddDate(-1, 0, 0)), Email: "borrower@example.com", IsActive: active},
+		product: domain.LoanProduct{
+			ID:                 primitive.NewObjectID(),
+			AmountLimits:       []domain.AmountLimit{{Min: usd(10000), Max: usd(1000000)}},
+			MinInterestRate:    5,
+			MaxInterestRate:    20,
+			AmortizationMethod: domain.AmortizationAnnuity,
+			Active:             true,
+		},
+		loans:      newMemoryLoans(),
+		collateral: newMemoryCollateral(),
+		consents:   &recordingConsents{},
+		notices:    &recordingNotices{},
+		history:    &memoryHistory{},
+	}
+}
+
+func (f *applicationFixture) application() domain.Loan {
+	return domain.Loan{ID: 21, UserID: f.borrower.ID, ProductID: f.product.ID, Amount: usd(100000), Term: 12, InterestRate: 10}
+}
+
+func (f *applicationFixture) usecase() domain.LoanUsecase {
+	rules, err := NewCreditRules([]domain.RuleConfig{{Name: "activated", Type: domain.RuleActiveAccount, Outcome: domain.DecisionDecline}})
+	if err != nil {
+		panic(err)
+	}
+	return NewLoanUsecase(f.loans, nil, nil, &memoryProducts{product: f.product}, newMemoryUsers(f.borrower), nil, NewDecisionEngine(rules...),
+		f.consents, f.collateral, f.approvals, newMemoryReasons(), f.notices, f.history)
+}
+
+func TestApplyForLoanHoldsLargeLoansForTwoAdmins(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold domain.Money
+		want      domain.LoanStatus
+	}{
+		{"no dual approval", domain.Money{}, domain.LoanStatusApproved},
+		{"below the threshold", usd(500000), domain.LoanStatusApproved},
+		{"at the threshold", usd(100000), domain.LoanStatusApproved},
+		{"above the threshold", usd(50000), domain.LoanStatusUnderReview},
+		{"threshold in another currency without rates", domain.NewMoney(500000, "EUR"), domain.LoanStatusUnderReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newApplicationFixture(true)
+			f.approvals = domain.DualApprovalPolicy{Threshold: tt.threshold}
+			loan, err := f.usecase().ApplyForLoan(f.application())
+			if err != nil {
+				t.Fatalf("ApplyForLoan: %v", err)
+			}
+			if loan.Status != tt.want {
+				t.Errorf("application is %s, want %s", loan.Status, tt.want)
+			}
+			if (len(loan.Schedule) > 0) != (tt.want == domain.LoanStatusApproved) {
+				t.Errorf("%s application has %d installments", loan.Status, len(loan.Schedule))
+			}
+		})
+	}
+}
+
+func TestApproveLoanDualApproval(t *testing.T) {
+	reviewer, checker := primitive.NewObjectID(), primitive.NewObjectID()
+	application := func(amount domain.Money) domain.Loan {
+		return domain.Loan{
+			ID:                 8,
+			Amount:             amount,
+			Term:               12,
+			InterestRate:       10,
+			RepaymentFrequency: domain.FrequencyMonthly,
+			AmortizationMethod: domain.AmortizationAnnuity,
+			Status:             domain.LoanStatusUnderReview,
+			Review:             &domain.LoanReview{ReviewerID: reviewer},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		amount    domain.Money
+		approvers []primitive.ObjectID
+		wantErr   error // of the last approval
+		want      domain.LoanStatus
+		approvals int
+	}{
+		{"below the threshold", usd(300000), []primitive.ObjectID{reviewer}, nil, domain.LoanStatusApproved, 0},
+		{"below the threshold by another admin", usd(300000), []primitive.ObjectID{checker}, domain.ErrNotAssignedReviewer, domain.LoanStatusUnderReview, 0},
+		{"maker only", usd(600000), []primitive.ObjectID{reviewer}, nil, domain.LoanStatusUnderReview, 1},
+		{"maker first by another admin", usd(600000), []primitive.ObjectID{checker}, domain.ErrNotAssignedReviewer, domain.LoanStatusUnderReview, 0},
+		{"maker twice", usd(600000), []primitive.ObjectID{reviewer, reviewer}, domain.ErrSameApprover, domain.LoanStatusUnderReview, 1},
+		{"maker and checker", usd(600000), []primitive.ObjectID{reviewer, checker}, nil, domain.LoanStatusApproved, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loans := newMemoryLoans(application(tt.amount))
+			uc := NewLoanUsecase(loans, nil, nil, nil, nil, nil, nil, nil, nil, domain.DualApprovalPolicy{Threshold: usd(500000)}, nil, nil, &memoryHistory{})
+
+			var err error
+			for _, approver := range tt.approvers {
+				_, err = uc.ApproveLoan(8, approver, "income verified")
+			}
+			if err != tt.wantErr {
+				t.Fatalf("ApproveLoan = %v, want %v", err, tt.wantErr)
+			}
+
+			loan, _ := loans.GetLoanByID(8)
+			if loan.Status != tt.want || len(loan.Approvals()) != tt.approvals {
+				t.Fatalf("loan is %s with %d approvals, want %s with %d", loan.Status, len(loan.Approvals()), tt.want, tt.approvals)
+			}
+			if tt.want != domain.LoanStatusApproved {
+				if len(loan.Schedule) != 0 || loan.Review.Decision != "" {
+					t.Errorf("loan waiting for approval was decided by %s", loan.Review.DecidedBy.Hex())
+				}
+				return
+			}
+			last := tt.approvers[len(tt.approvers)-1]
+			if len(loan.Schedule) != 12 || loan.Review.Decision != domain.LoanStatusApproved || loan.Review.DecidedBy != last {
+				t.Errorf("approved loan has %d installments, decided %s by %s", len(loan.Schedule), loan.Review.Decision, loan.Review.DecidedBy.Hex())
+			}
+		})
+	}
+}
+
+func TestApplyForLoanTellsTheBorrowerWhyItWasDeclined(t *testing.T) {
+	tests := []struct {
+		name       string
+		active     bool
+		noticeDown bool
+		wantErr    error
+		want       domain.LoanStatus
+	}{
+		{"approved application", true, false, nil, domain.LoanStatusApproved},
+		{"declined application", false, false, nil, domain.LoanStatusRejected},
+		{"declined while the notice cannot be sent", false, true, domain.ErrAdverseActionNotSent, domain.LoanStatusRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newApplicationFixture(tt.active)
+			f.notices.down = tt.noticeDown
+			loan, err := f.usecase().ApplyForLoan(f.application())
+			if err != tt.wantErr {
+				t.Fatalf("ApplyForLoan = %v, want %v", err, tt.wantErr)
+			}
+
+			stored, _ := f.loans.GetLoanByID(21)
+			if loan.Status != tt.want || stored.Status != tt.want {
+				t.Fatalf("application is %s and stored %s, want %s", loan.Status, stored.Status, tt.want)
+			}
+			if tt.want != domain.LoanStatusRejected {
+				if stored.Rejection != nil || len(f.notices.sent) != 0 {
+					t.Errorf("%s application has rejection %+v and %d notices", stored.Status, stored.Rejection, len(f.notices.sent))
+				}
+				return
+			}
+
+			want := domain.StatedReason{Code: "unable_to_verify_identity", Description: "Unable to verify identity"}
+			if stored.Rejection == nil || len(stored.Rejection.Reasons) != 1 || stored.Rejection.Reasons[0] != want {
+				t.Fatalf("stored rejection %+v, want the reason %+v", stored.Rejection, want)
+			}
+			if (stored.Rejection.NoticeSentAt != nil) == tt.noticeDown {
+				t.Errorf("notice sent at %v with the notifier down %v", stored.Rejection.NoticeSentAt, tt.noticeDown)
+			}
+			if !tt.noticeDown {
+				if len(f.notices.sent) != 1 || f.notices.sent[0].BorrowerEmail != f.borrower.Email || f.notices.sent[0].Reasons[0] != want {
+					t.Errorf("sent %+v, want one notice to the borrower stating %s", f.notices.sent, want.Code)
+				}
+			}
+			last := f.history.events[len(f.history.events)-1]
+			if last.Detail != "credit rules: decline: unable_to_verify_identity" {
+				t.Errorf("recorded the decline as %q", last.Detail)
+			}
+		})
+	}
+}
+
+func TestDeletedLoanIDsAreNotReused(t *testing.T) {
+	f := newApplicationFixture(true)
+	uc := f.usecase()
+	admin := primitive.NewObjectID()
+
+	if _, err := uc.ApplyForLoan(f.application()); err != nil {
+		t.Fatalf("ApplyForLoan: %v", err)
+	}
+	if _, err := uc.ApplyForLoan(f.application()); err != domain.ErrLoanIDTaken {
+		t.Errorf("applying under the ID of a loan on record = %v, want %v", err, domain.ErrLoanIDTaken)
+	}
+	if err := uc.DeleteLoan(21, admin); err != nil {
+		t.Fatalf("DeleteLoan: %v", err)
+	}
+	if _, err := uc.ApplyForLoan(f.application()); err != domain.ErrLoanIDTaken {
+		t.Errorf("applying under the ID of a deleted loan = %v, want %v", err, domain.ErrLoanIDTaken)
+	}
+
+	history := NewLoanHistoryUsecase(f.loans, f.history)
+	events, err := history.GetLoanHistory(21, admin, true)
+	if err != nil {
+		t.Fatalf("GetLoanHistory: %v", err)
+	}
+	if last := events[len(events)-1]; last.Type != domain.LoanEventDeleted || last.ActorID != admin {
+		t.Errorf("history of the deleted loan ends with %+v, want its deletion", last)
+	}
+	if _, err := history.GetLoanHistory(21, f.borrower.ID, false); err == nil {
+		t.Errorf("the borrower can still read the history of a deleted loan")
+	}
+
+	application := f.application()
+	application.ID = 22
+	if _, err := uc.ApplyForLoan(application); err != nil {
+		t.Fatalf("ApplyForLoan under a new ID: %v", err)
+	}
+	events, err = history.GetLoanHistory(22, f.borrower.ID, false)
+	if err != nil {
+		t.Fatalf("GetLoanHistory: %v", err)
+	}
+	if len(events) == 0 || events[0].Type != domain.LoanEventApplied {
+		t.Errorf("history of the new loan starts with %+v, want its application", events)
+	}
+}
+
+// unseenLoans hides the loans on record from ID checks, as when another application under
+// the same ID is stored between the check and the insert.
+type unseenLoans struct {
+	*memoryLoans
+}
+
+func (r unseenLoans) GetLoanByID(id uint) (domain.Loan, error) {
+	return domain.Loan{}, mongo.ErrNoDocuments
+}
+
+func TestApplyForLoanRefusesUnusableIDs(t *testing.T) {
+	f := newApplicationFixture(true)
+	if _, err := f.usecase().ApplyForLoan(f.application()); err != nil {
+		t.Fatalf("ApplyForLoan: %v", err)
+	}
+	history := &memoryHistory{}
+	uc := NewLoanUsecase(unseenLoans{f.loans}, nil, nil, &memoryProducts{product: f.product}, newMemoryUsers(f.borrower), nil, NewDecisionEngine(),
+		f.consents, f.collateral, f.approvals, newMemoryReasons(), f.notices, history)
+
+	tests := []struct {
+		name string
+		id   uint
+		want error
+	}{
+		{"no ID", 0, domain.ErrLoanIDRequired},
+		{"an ID stored after the check", 21, domain.ErrLoanIDTaken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := f.application()
+			application.ID = tt.id
+			if _, err := uc.ApplyForLoan(application); err != tt.want {
+				t.Errorf("ApplyForLoan = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if len(f.loans.loans) != 1 || len(history.events) != 0 {
+		t.Errorf("refused applications left %d loans and %d events behind, want the first loan only", len(f.loans.loans), len(history.events))
+	}
+}
+
+func TestGetLoanHistoryHidesInternalEvents(t *testing.T) {
+	borrower := primitive.NewObjectID()
+	loans := newMemoryLoans(domain.Loan{ID: 3, UserID: borrower, Status: domain.LoanStatusUnderReview})
+	history := &memoryHistory{}
+	for _, eventType := range []domain.LoanEventType{
+		domain.LoanEventApplied,
+		domain.LoanEventReviewerAssigned,
+		domain.LoanEventNoteAdded,
+		domain.LoanEventInfoRequested,
+		domain.LoanEventApprovalRecorded,
+	} {
+		if err := recordEvent(history, domain.LoanEvent{LoanID: 3, Type: eventType}); err != nil {
+			t.Fatalf("recordEvent: %v", err)
+		}
+	}
+	uc := NewLoanHistoryUsecase(loans, history)
+
+	tests := []struct {
+		name    string
+		userID  primitive.ObjectID
+		isAdmin bool
+		want    int
+		wantErr error
+	}{
+		{"admin", primitive.NewObjectID(), true, 5, nil},
+		{"borrower", borrower, false, 2, nil},
+		{"someone else", primitive.NewObjectID(), false, 0, domain.ErrNotLoanOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := uc.GetLoanHistory(3, tt.userID, tt.isAdmin)
+			if err != tt.wantErr {
+				t.Fatalf("GetLoanHistory = %v, want %v", err, tt.wantErr)
+			}
+			if len(events) != tt.want {
+				t.Errorf("got %d events, want %d", len(events), tt.want)
+			}
+			for _, event := range events {
+				if event.Type.Internal() && !tt.isAdmin {
+					t.Errorf("borrower sees internal event %s", event.Type)
+				}
+			}
+		})
+	}
+}
+
+// racingLoans saves a concurrent write to every loan right after it is read.
+type racingLoans struct {
+	*memoryLoans
+}
+
+func (r racingLoans) GetLoanByID(id uint) (domain.Loan, error) {
+	loan, err := r.memoryLoans.GetLoanByID(id)
+	if err != nil {
+		return loan, err
+	}
+	concurrent := loan
+	if err := r.memoryLoans.UpdateLoan(&concurrent); err != nil {
+		return domain.Loan{}, err
+	}
+	return loan, nil
+}
+
+func TestStalePaymentsLeaveNothingBehind(t *testing.T) {
+	schedule := []domain.Installment{dueInstallment(1, 10000, 1000, 0, 0), dueInstallment(2, 10000, 900, 0, 0)}
+	active := domain.Loan{ID: 1, Amount: usd(20000), Status: domain.LoanStatusActive, Schedule: schedule, OutstandingBalance: usd(21900)}
+	writtenOff := domain.Loan{ID: 2, Amount: usd(20000), Status: domain.LoanStatusWrittenOff, Schedule: schedule, OutstandingBalance: usd(21900)}
+
+	tests := []struct {
+		name   string
+		record func(domain.LoanUsecase) (domain.Payment, error)
+	}{
+		{"repayment", func(uc domain.LoanUsecase) (domain.Payment, error) {
+			return uc.RecordPayment(1, domain.Payment{Amount: usd(5000)})
+		}},
+		{"recovery", func(uc domain.LoanUsecase) (domain.Payment, error) {
+			return uc.RecordRecovery(2, domain.Payment{Amount: usd(5000)})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loans := racingLoans{newMemoryLoans(active, writtenOff)}
+			payments := &memoryPayments{}
+			ledger := &memoryLedger{}
+			history := &memoryHistory{}
+			uc := NewLoanUsecase(loans, payments, nil, nil, nil, NewLedgerUsecase(ledger), nil, nil, nil, domain.DualApprovalPolicy{}, nil, nil, history)
+
+			if _, err := tt.record(uc); err != domain.ErrLoanModified {
+				t.Fatalf("recording on a stale loan = %v, want %v", err, domain.ErrLoanModified)
+			}
+			if len(payments.payments) != 0 || len(ledger.entries) != 0 || len(history.events) != 0 {
+				t.Errorf("stale write left %d payments, %d journal entries and %d events behind, want none",
+					len(payments.payments), len(ledger.entries), len(history.events))
+			}
+		})
+	}
+}
+
+// defaultedLoan returns a defaulted loan whose two installments are overdue, with 19.00 of
+// interest accrued and 8.00 of fees and penalties charged: 227.00 owed in all.
+func defaultedLoan() domain.Loan {
+	first, second := dueInstallment(1, 10000, 1000, 500, 300), dueInstallment(2, 10000, 900, 0, 0)
+	return domain.Loan{
+		ID:                 1,
+		Amount:             usd(20000),
+		Status:             domain.LoanStatusDefaulted,
+		Schedule:           []domain.Installment{first, second},
+		OutstandingBalance: usd(22700),
+		AccruedInterest:    usd(1900),
+	}
+}
+
+// postings returns the amount each account was debited (positive) or credited (negative) by entry.
+func postings(entry domain.JournalEntry) map[string]int64 {
+	byAccount := map[string]int64{}
+	for _, posting := range entry.Postings {
+		byAccount[posting.Account] += posting.Debit.Amount - posting.Credit.Amount
+	}
+	return byAccount
+}
+
+func TestWriteOffAndRecovery(t *testing.T) {
+	loans := newMemoryLoans(defaultedLoan())
+	payments := &memoryPayments{}
+	ledger := &memoryLedger{}
+	uc := NewLoanUsecase(loans, payments, nil, nil, nil, NewLedgerUsecase(ledger), nil, nil, nil, domain.DualApprovalPolicy{}, nil, nil, &memoryHistory{})
+	admin := primitive.NewObjectID()
+
+	if _, err := uc.WriteOffLoan(1, "  ", admin); err != domain.ErrWriteOffReasonRequired {
+		t.Fatalf("writing off without a reason = %v, want %v", err, domain.ErrWriteOffReasonRequired)
+	}
+	loan, err := uc.WriteOffLoan(1, "borrower unreachable", admin)
+	if err != nil {
+		t.Fatalf("WriteOffLoan: %v", err)
+	}
+	writeOff := loan.WriteOff
+	if loan.Status != domain.LoanStatusWrittenOff || writeOff == nil || writeOff.WrittenOffBy != admin {
+		t.Fatalf("loan %s written off %+v, want written off by the admin", loan.Status, writeOff)
+	}
+	if got := [3]int64{writeOff.Principal.Amount, writeOff.Interest.Amount, writeOff.Fees.Amount}; got != [3]int64{20000, 1900, 800} {
+		t.Errorf("wrote off principal, interest, fees %v, want [20000 1900 800]", got)
+	}
+	if loan.AccruedInterest.IsPositive() || loan.OutstandingBalance != usd(22700) {
+		t.Errorf("after the write-off %s accrued and %s owed, want nothing accrued and 227.00 USD owed", loan.AccruedInterest, loan.OutstandingBalance)
+	}
+	if len(ledger.entries) != 1 {
+		t.Fatalf("posted %d entries for the write-off, want 1", len(ledger.entries))
+	}
+	want := map[string]int64{
+		domain.AccountLoanLossExpense:    22700,
+		domain.AccountLoansReceivable:    -20000,
+		domain.AccountInterestReceivable: -1900,
+		domain.AccountFeesReceivable:     -800,
+	}
+	if got := postings(ledger.entries[0]); !maps.Equal(got, want) {
+		t.Errorf("write-off posted %v, want %v", got, want)
+	}
+
+	if _, err := uc.RecordRecovery(1, domain.Payment{Amount: usd(22701)}); err != domain.ErrPaymentExceedsBalance {
+		t.Fatalf("recovering more than was written off = %v, want %v", err, domain.ErrPaymentExceedsBalance)
+	}
+	if len(payments.payments) != 0 || len(ledger.entries) != 1 {
+		t.Fatalf("refused recovery left %d payments and %d entries, want none and the write-off", len(payments.payments), len(ledger.entries))
+	}
+
+	payment, err := uc.RecordRecovery(1, domain.Payment{Amount: usd(11350)})
+	if err != nil {
+		t.Fatalf("RecordRecovery: %v", err)
+	}
+	if !payment.Recovery || payment.BalanceAfter != usd(11350) || len(payments.payments) != 1 {
+		t.Errorf("recovery %+v stored %d times, want a recovery leaving 113.50 USD owed stored once", payment, len(payments.payments))
+	}
+	if len(ledger.entries) != 2 || ledger.entries[1].Reference != payment.ID.Hex() {
+		t.Fatalf("posted %d entries, want the recovery referencing its payment after the write-off", len(ledger.entries))
+	}
+	want = map[string]int64{domain.AccountCash: 11350, domain.AccountRecoveryIncome: -11350}
+	if got := postings(ledger.entries[1]); !maps.Equal(got, want) {
+		t.Errorf("recovery posted %v, want %v", got, want)
+	}
+
+	report, err := NewReportUsecase(loans, fixedRates{"USD": big.NewRat(1, 1)}, "USD").GetRecoveryReport("")
+	if err != nil {
+		t.Fatalf("GetRecoveryReport: %v", err)
+	}
+	if report.TotalWrittenOff != usd(22700) || report.TotalRecovered != usd(11350) || report.RecoveryRate != 50 || len(report.Loans) != 1 {
+		t.Errorf("report wrote off %s and recovered %s at %g%% over %d loans, want 227.00 USD and 113.50 USD at 50%% over 1",
+			report.TotalWrittenOff, report.TotalRecovered, report.RecoveryRate, len(report.Loans))
+	}
+}
+
+func TestCloseLoan(t *testing.T) {
+	borrower, admin := primitive.NewObjectID(), primitive.NewObjectID()
+	withdraw := func(uc domain.LoanUsecase, reason string) (domain.Loan, error) {
+		return uc.WithdrawLoan(1, borrower, reason)
+	}
+	withdrawOther := func(uc domain.LoanUsecase, reason string) (domain.Loan, error) {
+		return uc.WithdrawLoan(1, admin, reason)
+	}
+	cancel := func(uc domain.LoanUsecase, reason string) (domain.Loan, error) {
+		return uc.CancelLoan(1, admin, reason)
+	}
+
+	tests := []struct {
+		name         string
+		status       domain.LoanStatus
+		disbursement domain.DisbursementStatus
+		close        func(domain.LoanUsecase, string) (domain.Loan, error)
+		reason       string
+		want         domain.LoanStatus
+		wantErr      error
+	}{
+		{name: "borrower withdraws a pending application", status: domain.LoanStatusPending, close: withdraw, reason: "found a better rate", want: domain.LoanStatusWithdrawn},
+		{name: "withdrawing needs a reason", status: domain.LoanStatusPending, close: withdraw, reason: " ", wantErr: domain.ErrClosureReasonRequired},
+		{name: "only the borrower withdraws", status: domain.LoanStatusPending, close: withdrawOther, reason: "not mine", wantErr: domain.ErrNotLoanOwner},
+		{name: "an approved loan is no longer withdrawn", status: domain.LoanStatusApproved, close: withdraw, reason: "changed my mind",
+			wantErr: &domain.InvalidTransitionError{From: domain.LoanStatusApproved, To: domain.LoanStatusWithdrawn}},
+		{name: "admin cancels an approved loan", status: domain.LoanStatusApproved, close: cancel, reason: "borrower deceased", want: domain.LoanStatusCancelled},
+		{name: "a failed disbursement does not hold the loan open", status: domain.LoanStatusApproved, disbursement: domain.DisbursementFailed,
+			close: cancel, reason: "account closed", want: domain.LoanStatusCancelled},
+		{name: "a processing disbursement holds the loan open", status: domain.LoanStatusApproved, disbursement: domain.DisbursementProcessing,
+			close: cancel, reason: "account closed", wantErr: domain.ErrDisbursementInProgress},
+		{name: "a confirmed disbursement holds the loan open", status: domain.LoanStatusApproved, disbursement: domain.DisbursementConfirmed,
+			close: cancel, reason: "account closed", wantErr: domain.ErrDisbursementInProgress},
+		{name: "an active loan is not cancelled", status: domain.LoanStatusActive, close: cancel, reason: "fraud",
+			wantErr: &domain.InvalidTransitionError{From: domain.LoanStatusActive, To: domain.LoanStatusCancelled}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loans := newMemoryLoans(domain.Loan{ID: 1, UserID: borrower, Amount: usd(50000), OutstandingBalance: usd(50000), Status: tt.status})
+			disbursements := &memoryDisbursements{}
+			if tt.disbursement != "" {
+				disbursements.disbursements = append(disbursements.disbursements, domain.Disbursement{ID: primitive.NewObjectID(), LoanID: 1, Status: tt.disbursement})
+			}
+			history := &memoryHistory{}
+			uc := NewLoanUsecase(loans, nil, disbursements, nil, nil, nil, nil, nil, newMemoryCollateral(), domain.DualApprovalPolicy{}, nil, nil, history)
+
+			closed, err := tt.close(uc, tt.reason)
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				if stored, _ := loans.GetLoanByID(1); stored.Status != tt.status || len(history.events) != 0 {
+					t.Errorf("refused close left the loan %s with %d events, want %s with none", stored.Status, len(history.events), tt.status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("closing: %v", err)
+			}
+
+			stored, _ := loans.GetLoanByID(1)
+			if stored.Status != tt.want || stored.OutstandingBalance.IsPositive() {
+				t.Errorf("loan is %s owing %s, want %s owing nothing", stored.Status, stored.OutstandingBalance, tt.want)
+			}
+			if c := stored.Closure; c == nil || c.Reason != tt.reason || c.ClosedAt.IsZero() || closed.Closure == nil {
+				t.Fatalf("closure %+v, want the reason %q and when", c, tt.reason)
+			}
+			if len(history.events) != 1 || history.events[0].Detail != tt.reason {
+				t.Errorf("recorded %+v, want one status change with the reason", history.events)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"cmp"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryLoans keeps loans in memory by their ID, like the Mongo repository.
+type memoryLoans struct {
+	domain.LoanRepository
+	loans map[uint]domain.Loan
+}
+
+func newMemoryLoans(loans ...domain.Loan) *memoryLoans {
+	r := &memoryLoans{loans: map[uint]domain.Loan{}}
+	for _, loan := range loans {
+		r.loans[loan.ID] = loan
+	}
+	return r
+}
+
+func (r *memoryLoans) ApplyForLoan(loan domain.Loan) error {
+	if _, ok := r.loans[loan.ID]; ok {
+		return domain.ErrLoanIDTaken
+	}
+	r.loans[loan.ID] = loan
+	return nil
+}
+
+func (r *memoryLoans) GetLoanByID(id uint) (domain.Loan, error) {
+	loan, ok := r.loans[id]
+	if !ok {
+		return domain.Loan{}, mongo.ErrNoDocuments
+	}
+	return loan, nil
+}
+
+func (r *memoryLoans) GetLoansByUser(userID primitive.ObjectID) ([]domain.Loan, error) {
+	loans := []domain.Loan{}
+	for _, loan := range r.loans {
+		if loan.UserID == userID {
+			loans = append(loans, loan)
+		}
+	}
+	return loans, nil
+}
+
+func (r *memoryLoans) GetAllLoans(status, order, currency string) ([]domain.Loan, error) {
+	loans := []domain.Loan{}
+	for _, loan := range r.loans {
+		if (status == "" || status == "all" || string(loan.Status) == status) &&
+			(currency == "" || loan.Amount.Currency == strings.ToUpper(currency)) {
+			loans = append(loans, loan)
+		}
+	}
+	slices.SortFunc(loans, byCreation)
+	if order == "desc" {
+		slices.Reverse(loans)
+	}
+	return loans, nil
+}
+
+func (r *memoryLoans) GetLoansByStatus(statuses ...domain.LoanStatus) ([]domain.Loan, error) {
+	loans := []domain.Loan{}
+	for _, loan := range r.loans {
+		for _, status := range statuses {
+			if loan.Status == status {
+				loans = append(loans, loan)
+			}
+		}
+	}
+	slices.SortFunc(loans, byCreation)
+	return loans, nil
+}
+
+func (r *memoryLoans) GetLastAssignedLoan() (domain.Loan, error) {
+	var last domain.Loan
+	for _, loan := range r.loans {
+		if loan.Review == nil || loan.Review.AssignedAt == nil {
+			continue
+		}
+		if last.Review == nil || loan.Review.AssignedAt.After(*last.Review.AssignedAt) {
+			last = loan
+		}
+	}
+	if last.Review == nil {
+		return domain.Loan{}, mongo.ErrNoDocuments
+	}
+	return last, nil
+}
+
+func (r *memoryLoans) GetLoansByParty(userID primitive.ObjectID, email string) ([]domain.Loan, error) {
+	loans := []domain.Loan{}
+	for _, loan := range r.loans {
+		for _, party := range loan.Parties {
+			if party.UserID == userID || party.Email == email {
+				loans = append(loans, loan)
+				break
+			}
+		}
+	}
+	return loans, nil
+}
+
+func (r *memoryLoans) GetLoanByConsentToken(token string) (domain.Loan, error) {
+	for _, loan := range r.loans {
+		for _, party := range loan.Parties {
+			if party.ConsentToken == token {
+				return loan, nil
+			}
+		}
+	}
+	return domain.Loan{}, mongo.ErrNoDocuments
+}
+
+func (r *memoryLoans) UpdateLoanStatus(loan *domain.Loan, status domain.LoanStatus) error {
+	stored, err := r.current(*loan)
+	if err != nil {
+		return err
+	}
+	stored.Status = status
+	stored.Version++
+	r.loans[loan.ID] = stored
+	loan.Status, loan.Version = status, stored.Version
+	return nil
+}
+
+func (r *memoryLoans) UpdateLoan(loan *domain.Loan) error {
+	if _, err := r.current(*loan); err != nil {
+		return err
+	}
+	loan.Version++
+	r.loans[loan.ID] = *loan
+	return nil
+}
+
+// byCreation orders loans oldest first, like the Mongo repository.
+func byCreation(a, b domain.Loan) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// current returns the stored loan if it still has the version loan was read with.
+func (r *memoryLoans) current(loan domain.Loan) (domain.Loan, error) {
+	stored, ok := r.loans[loan.ID]
+	if !ok {
+		return domain.Loan{}, mongo.ErrNoDocuments
+	}
+	if stored.Version != loan.Version {
+		return domain.Loan{}, domain.ErrLoanModified
+	}
+	return stored, nil
+}
+
+func (r *memoryLoans) DeleteLoan(id uint) error {
+	if _, ok := r.loans[id]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	delete(r.loans, id)
+	return nil
+}
+
+// memoryHistory keeps loan events in memory in the order they were appended.
+type memoryHistory struct {
+	events []domain.LoanEvent
+}
+
+func (r *memoryHistory) AppendEvent(event domain.LoanEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *memoryHistory) GetEventsByLoan(loanID uint) ([]domain.LoanEvent, error) {
+	events := []domain.LoanEvent{}
+	for _, event := range r.events {
+		if event.LoanID == loanID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// memoryUsers keeps users in memory by their ID.
+type memoryUsers struct {
+	domain.UserRepository
+	users map[primitive.ObjectID]domain.User
+}
+
+func newMemoryUsers(users ...domain.User) *memoryUsers {
+	r := &memoryUsers{users: map[primitive.ObjectID]domain.User{}}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *memoryUsers) GetUserByID(id primitive.ObjectID) (domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return domain.User{}, mongo.ErrNoDocuments
+	}
+	return user, nil
+}
+
+func (r *memoryUsers) GetUsersByRole(role string) ([]domain.User, error) {
+	users := []domain.User{}
+	for _, user := range r.users {
+		if user.Role == role {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// memoryPayments keeps payments in memory in the order they were recorded.
+type memoryPayments struct {
+	domain.PaymentRepository
+	payments []domain.Payment
+}
+
+func (r *memoryPayments) CreatePayment(payment domain.Payment) error {
+	r.payments = append(r.payments, payment)
+	return nil
+}
+
+func (r *memoryPayments) GetPaymentsByLoan(loanID uint) ([]domain.Payment, error) {
+	payments := []domain.Payment{}
+	for _, payment := range r.payments {
+		if payment.LoanID == loanID {
+			payments = append(payments, payment)
+		}
+	}
+	return payments, nil
+}
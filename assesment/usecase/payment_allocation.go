@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"time"
+)
+
+// allocation is how much of a payment went to each component of the schedule.
+type allocation struct {
+	Penalties domain.Money
+	Fees      domain.Money
+	Interest  domain.Money
+	Principal domain.Money
+	Unapplied domain.Money
+}
+
+// allocatePayment applies amount to the schedule in installment order, settling each
+// installment's penalties, then fees, then interest, then principal before moving to the next one.
+// The schedule is updated in place; whatever cannot be applied is returned as Unapplied.
+func allocatePayment(schedule []domain.Installment, amount domain.Money, paidAt time.Time) allocation {
+	zero := amount.Zero()
+	result := allocation{Penalties: zero, Fees: zero, Interest: zero, Principal: zero}
+	remaining := amount
+
+	for i := range schedule {
+		if !remaining.IsPositive() {
+			break
+		}
+		inst := &schedule[i]
+		if inst.IsPaid() {
+			continue
+		}
+
+		penalties := remaining.Min(inst.PenaltiesDue())
+		inst.PenaltiesPaid = inst.PenaltiesPaid.Add(penalties)
+		remaining = remaining.Sub(penalties)
+
+		fees := remaining.Min(inst.FeesDue())
+		inst.FeesPaid = inst.FeesPaid.Add(fees)
+		remaining = remaining.Sub(fees)
+
+		interest := remaining.Min(inst.InterestDue())
+		inst.InterestPaid = inst.InterestPaid.Add(interest)
+		remaining = remaining.Sub(interest)
+
+		principal := remaining.Min(inst.PrincipalDue())
+		inst.PrincipalPaid = inst.PrincipalPaid.Add(principal)
+		remaining = remaining.Sub(principal)
+
+		result.Penalties = result.Penalties.Add(penalties)
+		result.Fees = result.Fees.Add(fees)
+		result.Interest = result.Interest.Add(interest)
+		result.Principal = result.Principal.Add(principal)
+
+		if !inst.AmountDue().IsPositive() {
+			settled := paidAt
+			inst.PaidAt = &settled
+		}
+	}
+
+	result.Unapplied = remaining
+	return result
+}
+
+// outstandingBalance returns the penalties, fees, interest and principal still owed on a schedule.
+func outstandingBalance(loan domain.Loan) domain.Money {
+	balance := loan.Amount.Zero()
+	for _, inst := range loan.Schedule {
+		if !inst.IsPaid() {
+			balance = balance.Add(inst.AmountDue())
+		}
+	}
+	return balance
+}
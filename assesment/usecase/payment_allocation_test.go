@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+	"time"
+)
+
+// dueInstallment returns an unpaid installment owing the given amounts in cents.
+func dueInstallment(number int, principal, interest, fees, penalties int64) domain.Installment {
+	return domain.Installment{
+		Number:        number,
+		DueDate:       time.Date(2024, time.Month(number), 15, 0, 0, 0, 0, time.UTC),
+		Principal:     usd(principal),
+		Interest:      usd(interest),
+		Fees:          usd(fees),
+		Penalties:     usd(penalties),
+		Total:         usd(principal + interest + fees),
+		PrincipalPaid: usd(0),
+		InterestPaid:  usd(0),
+		FeesPaid:      usd(0),
+		PenaltiesPaid: usd(0),
+	}
+}
+
+func TestAllocatePayment(t *testing.T) {
+	paidAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	settled := paidAt
+
+	paid := dueInstallment(1, 10000, 1000, 0, 0)
+	paid.PrincipalPaid, paid.InterestPaid, paid.PaidAt = usd(10000), usd(1000), &settled
+
+	tests := []struct {
+		name     string
+		schedule []domain.Installment
+		amount   int64
+		want     [5]int64 // penalties, fees, interest, principal, unapplied
+		paidOff  []bool
+	}{
+		{
+			name:     "penalties, then fees, then interest, then principal",
+			schedule: []domain.Installment{dueInstallment(1, 10000, 1000, 500, 200), dueInstallment(2, 10000, 900, 0, 0)},
+			amount:   1500,
+			want:     [5]int64{200, 500, 800, 0, 0},
+			paidOff:  []bool{false, false},
+		},
+		{
+			name:     "settles the oldest installment before the next",
+			schedule: []domain.Installment{dueInstallment(1, 10000, 1000, 500, 200), dueInstallment(2, 10000, 900, 0, 0)},
+			amount:   12000,
+			want:     [5]int64{200, 500, 1300, 10000, 0},
+			paidOff:  []bool{true, false},
+		},
+		{
+			name:     "skips paid installments",
+			schedule: []domain.Installment{paid, dueInstallment(2, 10000, 900, 0, 0)},
+			amount:   5000,
+			want:     [5]int64{0, 0, 900, 4100, 0},
+			paidOff:  []bool{true, false},
+		},
+		{
+			name:     "returns what is left once everything is paid",
+			schedule: []domain.Installment{dueInstallment(1, 10000, 1000, 0, 0)},
+			amount:   12500,
+			want:     [5]int64{0, 0, 1000, 10000, 1500},
+			paidOff:  []bool{true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := allocatePayment(tt.schedule, usd(tt.amount), paidAt)
+
+			got := [5]int64{result.Penalties.Amount, result.Fees.Amount, result.Interest.Amount, result.Principal.Amount, result.Unapplied.Amount}
+			if got != tt.want {
+				t.Errorf("got penalties, fees, interest, principal, unapplied %v, want %v", got, tt.want)
+			}
+			for i, inst := range tt.schedule {
+				if inst.IsPaid() != tt.paidOff[i] {
+					t.Errorf("installment %d paid = %v, want %v", inst.Number, inst.IsPaid(), tt.paidOff[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOutstandingBalance(t *testing.T) {
+	paid := dueInstallment(1, 10000, 1000, 0, 0)
+	paid.PrincipalPaid, paid.InterestPaid = usd(10000), usd(1000)
+	now := time.Now()
+	paid.PaidAt = &now
+
+	partly := dueInstallment(2, 10000, 900, 500, 300)
+	partly.PenaltiesPaid, partly.InterestPaid = usd(300), usd(400)
+
+	loan := domain.Loan{Amount: usd(30000), Schedule: []domain.Installment{paid, partly, dueInstallment(3, 10000, 800, 0, 0)}}
+	if got, want := outstandingBalance(loan), usd(10000+500+500+10000+800); got != want {
+		t.Errorf("outstandingBalance = %s, want %s", got, want)
+	}
+}
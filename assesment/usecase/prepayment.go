@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"math/big"
+	"time"
+)
+
+// payoffQuote returns what settles a loan in full on day, a midnight UTC.
+func payoffQuote(loan domain.Loan, day time.Time) domain.PayoffQuote {
+	zero := loan.Amount.Zero()
+	quote := domain.PayoffQuote{LoanID: loan.ID, Principal: zero, Interest: zero, Fees: zero, Penalties: zero}
+
+	notYetDue := zero
+	owed := interestOwedAt(loan, day)
+	for i, inst := range loan.Schedule {
+		if inst.IsPaid() {
+			continue
+		}
+		quote.Principal = quote.Principal.Add(inst.PrincipalDue())
+		quote.Interest = quote.Interest.Add(owed[i])
+		quote.Fees = quote.Fees.Add(inst.FeesDue())
+		quote.Penalties = quote.Penalties.Add(inst.PenaltiesDue())
+		if accrualDay(inst.DueDate).After(day) {
+			notYetDue = notYetDue.Add(inst.PrincipalDue())
+		}
+	}
+
+	quote.PrepaymentPenalty = notYetDue.Mul(domain.PercentRate(loan.Prepayment.PenaltyPercentage))
+	quote.Total = quote.Principal.Add(quote.Interest).Add(quote.Fees).Add(quote.Penalties).Add(quote.PrepaymentPenalty)
+	quote.ValidUntil = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	return quote
+}
+
+// interestOwedAt returns, for each installment of a loan, the interest still owed on it if the
+// loan were settled on day: all of it once due, the share earned so far for the current
+// period, and nothing for later periods.
+func interestOwedAt(loan domain.Loan, day time.Time) []domain.Money {
+	zero := loan.Amount.Zero()
+	owed := make([]domain.Money, len(loan.Schedule))
+	for i := range owed {
+		owed[i] = zero
+	}
+
+	current := -1
+	var periodStart time.Time
+	for i, inst := range loan.Schedule {
+		if current >= 0 {
+			break
+		}
+		switch {
+		case inst.IsPaid():
+			periodStart = inst.DueDate
+		case !accrualDay(inst.DueDate).After(day):
+			owed[i] = inst.InterestDue()
+			periodStart = inst.DueDate
+		default:
+			current = i
+		}
+	}
+	if current < 0 {
+		return owed
+	}
+
+	inst := loan.Schedule[current]
+	if periodStart.IsZero() {
+		periodStart = loan.RepaymentFrequency.DueDate(inst.DueDate, -1)
+	}
+	dayCount := loan.DayCount
+	if dayCount == "" {
+		dayCount = domain.DayCountActual365
+	}
+	elapsed := dayCount.YearFraction(periodStart, day)
+	period := dayCount.YearFraction(periodStart, inst.DueDate)
+	if elapsed.Sign() <= 0 || period.Sign() <= 0 {
+		return owed
+	}
+
+	earned := inst.Interest.Mul(new(big.Rat).Quo(elapsed, period))
+	if earned.Cmp(inst.InterestPaid) > 0 {
+		owed[current] = earned.Sub(inst.InterestPaid)
+	}
+	return owed
+}
+
+// settleSchedule reduces the interest of every unpaid installment of a loan to what is owed
+// when it is settled on day, so that paying the payoff quote clears the schedule.
+func settleSchedule(loan *domain.Loan, day time.Time) {
+	owed := interestOwedAt(*loan, day)
+	for i := range loan.Schedule {
+		inst := &loan.Schedule[i]
+		if inst.IsPaid() {
+			continue
+		}
+		inst.Interest = inst.InterestPaid.Add(owed[i])
+		inst.Total = inst.Principal.Add(inst.Interest).Add(inst.Fees)
+	}
+}
+
+// amountDueBy returns everything owed on the installments of a schedule due by at.
+func amountDueBy(schedule []domain.Installment, at time.Time) domain.Money {
+	var due domain.Money
+	for _, inst := range schedule {
+		if !inst.IsPaid() && !inst.DueDate.After(at) {
+			due = due.Add(inst.AmountDue())
+		}
+	}
+	return due
+}
+
+// splitPrepayment splits an amount paid ahead of schedule into the principal it repays and
+// the prepayment penalty charged on that principal.
+func splitPrepayment(amount domain.Money, terms domain.PrepaymentTerms) (domain.Money, domain.Money) {
+	rate := domain.PercentRate(terms.PenaltyPercentage)
+	principal := amount.Mul(new(big.Rat).Inv(new(big.Rat).Add(big.NewRat(1, 1), rate)))
+	return principal, amount.Sub(principal)
+}
+
+// prepay repays principal on the installments of a loan not yet due at now and rebuilds
+// them: reduce_installment keeps their number and lowers the installments, reduce_term keeps
+// the installment amount and drops installments from the end. Whatever was already paid or
+// charged on them moves to the first new installment, so interest paid in advance for the
+// current period counts against that period's new interest instead of being charged again.
+func prepay(loan *domain.Loan, principal domain.Money, option domain.PrepaymentOption, now time.Time) error {
+	zero := loan.Amount.Zero()
+	remainingPrincipal, installment := zero, zero
+	carried := domain.Installment{PrincipalPaid: zero, InterestPaid: zero, Fees: zero, FeesPaid: zero, Penalties: zero, PenaltiesPaid: zero}
+
+	var kept []domain.Installment
+	var periodStart time.Time
+	remaining := 0
+	for _, inst := range loan.Schedule {
+		if inst.IsPaid() || !inst.DueDate.After(now) {
+			kept = append(kept, inst)
+			periodStart = inst.DueDate
+			continue
+		}
+		if remaining == 0 {
+			installment = inst.Principal.Add(inst.Interest)
+			if periodStart.IsZero() {
+				periodStart = loan.RepaymentFrequency.DueDate(inst.DueDate, -1)
+			}
+		}
+		remaining++
+		remainingPrincipal = remainingPrincipal.Add(inst.PrincipalDue())
+		carried.PrincipalPaid = carried.PrincipalPaid.Add(inst.PrincipalPaid)
+		carried.InterestPaid = carried.InterestPaid.Add(inst.InterestPaid)
+		carried.Fees = carried.Fees.Add(inst.Fees)
+		carried.FeesPaid = carried.FeesPaid.Add(inst.FeesPaid)
+		carried.Penalties = carried.Penalties.Add(inst.Penalties)
+		carried.PenaltiesPaid = carried.PenaltiesPaid.Add(inst.PenaltiesPaid)
+	}
+	if principal.Cmp(remainingPrincipal) >= 0 {
+		return domain.ErrPrepaymentTooLarge
+	}
+
+	next := *loan
+	next.Amount = remainingPrincipal.Sub(principal)
+	next.Term = remaining
+	next.OriginationFee = zero
+	schedule, err := generateSchedule(next, periodStart)
+	if err != nil {
+		return err
+	}
+	if option == domain.PrepayReduceTerm {
+		// Fewer installments mean larger ones; keep the shortest term that does not raise them.
+		for term := 1; term < remaining; term++ {
+			next.Term = term
+			shorter, err := generateSchedule(next, periodStart)
+			if err != nil {
+				return err
+			}
+			if shorter[0].Principal.Add(shorter[0].Interest).Cmp(installment) <= 0 {
+				schedule = shorter
+				break
+			}
+		}
+	}
+
+	// The first new installment covers the current period, so it takes over what was paid
+	// on the old ones; interest paid beyond its new interest stays paid rather than owed back.
+	first := &schedule[0]
+	first.Principal = first.Principal.Add(carried.PrincipalPaid)
+	first.PrincipalPaid = carried.PrincipalPaid
+	if carried.InterestPaid.Cmp(first.Interest) > 0 {
+		first.Interest = carried.InterestPaid
+	}
+	first.InterestPaid = carried.InterestPaid
+	first.Fees = first.Fees.Add(carried.Fees)
+	first.FeesPaid = carried.FeesPaid
+	first.Penalties = first.Penalties.Add(carried.Penalties)
+	first.PenaltiesPaid = carried.PenaltiesPaid
+	first.Total = first.Principal.Add(first.Interest).Add(first.Fees)
+	for i := range schedule {
+		schedule[i].Number = len(kept) + i + 1
+	}
+
+	loan.Schedule = append(kept, schedule...)
+	loan.Term = len(loan.Schedule)
+	return nil
+}
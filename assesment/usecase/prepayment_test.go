@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+	"time"
+)
+
+// quotedLoan returns a monthly loan whose first installment is paid and whose next two, due
+// on February 15 and March 15 2024, are not.
+func quotedLoan() domain.Loan {
+	paidAt := utcDay(2024, time.January, 15)
+	first := dueInstallment(1, 10000, 3300, 0, 0)
+	first.DueDate = paidAt
+	first.PrincipalPaid, first.InterestPaid, first.PaidAt = usd(10000), usd(3300), &paidAt
+
+	second := dueInstallment(2, 10000, 3100, 0, 0)
+	second.DueDate = utcDay(2024, time.February, 15)
+	third := dueInstallment(3, 10000, 2900, 0, 0)
+	third.DueDate = utcDay(2024, time.March, 15)
+
+	return domain.Loan{
+		ID:                 3,
+		Amount:             usd(30000),
+		RepaymentFrequency: domain.FrequencyMonthly,
+		DayCount:           domain.DayCountActual365,
+		Prepayment:         domain.PrepaymentTerms{PenaltyPercentage: 2},
+		Schedule:           []domain.Installment{first, second, third},
+	}
+}
+
+func TestPayoffQuote(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*domain.Loan)
+		day    time.Time
+		want   [6]int64 // principal, interest, fees, penalties, prepayment penalty, total
+	}{
+		{
+			name: "interest earned so far in the current period",
+			day:  utcDay(2024, time.February, 10),
+			want: [6]int64{20000, 2600, 0, 0, 400, 23000},
+		},
+		{
+			name:   "interest already paid in advance is not owed again",
+			change: func(l *domain.Loan) { l.Schedule[1].InterestPaid = usd(1000) },
+			day:    utcDay(2024, time.February, 10),
+			want:   [6]int64{20000, 1600, 0, 0, 400, 22000},
+		},
+		{
+			name: "an overdue installment is owed in full with its penalties",
+			change: func(l *domain.Loan) {
+				l.Schedule[1].Penalties = usd(300)
+				l.Schedule[1].Fees = usd(150)
+			},
+			day:  utcDay(2024, time.February, 20),
+			want: [6]int64{20000, 3600, 150, 300, 200, 24250},
+		},
+		{
+			name: "on the due date the installment is owed in full",
+			day:  utcDay(2024, time.February, 15),
+			want: [6]int64{20000, 3100, 0, 0, 200, 23300},
+		},
+		{
+			name: "the first period starts one period before the first due date",
+			change: func(l *domain.Loan) {
+				l.Schedule = l.Schedule[1:]
+				l.Prepayment.PenaltyPercentage = 0
+			},
+			day:  utcDay(2024, time.January, 15),
+			want: [6]int64{20000, 0, 0, 0, 0, 20000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := quotedLoan()
+			if tt.change != nil {
+				tt.change(&loan)
+			}
+
+			quote := payoffQuote(loan, tt.day)
+			got := [6]int64{quote.Principal.Amount, quote.Interest.Amount, quote.Fees.Amount, quote.Penalties.Amount, quote.PrepaymentPenalty.Amount, quote.Total.Amount}
+			if got != tt.want {
+				t.Errorf("got principal, interest, fees, penalties, prepayment penalty, total %v, want %v", got, tt.want)
+			}
+			if want := tt.day.AddDate(0, 0, 1).Add(-time.Nanosecond); !quote.ValidUntil.Equal(want) {
+				t.Errorf("quote valid until %s, want %s", quote.ValidUntil, want)
+			}
+		})
+	}
+}
+
+func TestSettleSchedule(t *testing.T) {
+	loan := quotedLoan()
+	day := utcDay(2024, time.February, 10)
+	quote := payoffQuote(loan, day)
+
+	settleSchedule(&loan, day)
+	if got, want := outstandingBalance(loan), quote.Total.Sub(quote.PrepaymentPenalty); got != want {
+		t.Fatalf("balance after settling %s, want the quote without its prepayment penalty %s", got, want)
+	}
+
+	result := allocatePayment(loan.Schedule, quote.Total.Sub(quote.PrepaymentPenalty), day)
+	if result.Unapplied.IsPositive() {
+		t.Errorf("%s of the quote was left unapplied", result.Unapplied)
+	}
+	for _, inst := range loan.Schedule {
+		if !inst.IsPaid() {
+			t.Errorf("installment %d still owes %s after paying the quote", inst.Number, inst.AmountDue())
+		}
+	}
+}
+
+func TestSplitPrepayment(t *testing.T) {
+	tests := []struct {
+		amount     int64
+		percentage float64
+		principal  int64
+		penalty    int64
+	}{
+		{10200, 2, 10000, 200},
+		{10000, 0, 10000, 0},
+		{10000, 3, 9709, 291},
+	}
+
+	for _, tt := range tests {
+		principal, penalty := splitPrepayment(usd(tt.amount), domain.PrepaymentTerms{PenaltyPercentage: tt.percentage})
+		if principal != usd(tt.principal) || penalty != usd(tt.penalty) {
+			t.Errorf("splitPrepayment(%d at %g%%) = %s, %s; want %s, %s", tt.amount, tt.percentage, principal, penalty, usd(tt.principal), usd(tt.penalty))
+		}
+	}
+}
+
+func TestPrepayAfterPartialPayment(t *testing.T) {
+	tests := []struct {
+		name         string
+		interestPaid int64
+		want         [4]int64 // interest, interest due, fees due and principal paid of the first new installment
+		wantDue      int64    // everything still owed on the schedule
+	}{
+		{
+			name:         "interest paid in advance counts against the new interest",
+			interestPaid: 50,
+			want:         [4]int64{100, 50, 150, 2000},
+			wantDue:      10250,
+		},
+		{
+			name:         "interest paid beyond the new interest is not owed back",
+			interestPaid: 200,
+			want:         [4]int64{200, 0, 150, 2000},
+			wantDue:      10200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := quotedLoan()
+			loan.InterestRate, loan.Term, loan.AmortizationMethod = 12, 3, domain.AmortizationAnnuity
+			loan.Prepayment.PenaltyPercentage = 0
+			loan.Schedule[1].PrincipalPaid = usd(2000)
+			loan.Schedule[1].InterestPaid = usd(tt.interestPaid)
+			loan.Schedule[1].Fees = usd(150)
+
+			if err := prepay(&loan, usd(8000), domain.PrepayReduceInstallment, utcDay(2024, time.February, 10)); err != nil {
+				t.Fatalf("prepay: %v", err)
+			}
+			if len(loan.Schedule) != 3 {
+				t.Fatalf("got %d installments, want the paid one and two new ones", len(loan.Schedule))
+			}
+
+			first := loan.Schedule[1]
+			got := [4]int64{first.Interest.Amount, first.InterestDue().Amount, first.FeesDue().Amount, first.PrincipalPaid.Amount}
+			if got != tt.want {
+				t.Errorf("got interest, interest due, fees due, principal paid %v, want %v", got, tt.want)
+			}
+			if !first.DueDate.Equal(utcDay(2024, time.February, 15)) {
+				t.Errorf("first new installment due %s, want 2024-02-15", first.DueDate)
+			}
+
+			var due domain.Money
+			for _, inst := range loan.Schedule {
+				due = due.Add(inst.AmountDue())
+			}
+			if due != usd(tt.wantDue) {
+				t.Errorf("schedule owes %s, want %s", due, usd(tt.wantDue))
+			}
+			// On the next due date the quote leaves out only the last period's interest, not yet earned.
+			want := due.Sub(loan.Schedule[2].InterestDue())
+			if quote := payoffQuote(loan, utcDay(2024, time.February, 15)); quote.Total != want {
+				t.Errorf("payoff quote on the next due date %s, want %s", quote.Total, want)
+			}
+		})
+	}
+}
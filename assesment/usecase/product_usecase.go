@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type productUsecase struct {
+	productRepo domain.ProductRepository
+}
+
+// NewProductUsecase creates a new instance of ProductUsecase.
+func NewProductUsecase(productRepo domain.ProductRepository) domain.ProductUsecase {
+	return &productUsecase{
+		productRepo: productRepo,
+	}
+}
+
+// CreateProduct validates and stores a new loan product.
+func (uc *productUsecase) CreateProduct(product domain.LoanProduct) (domain.LoanProduct, error) {
+	if err := validateProduct(&product); err != nil {
+		return domain.LoanProduct{}, err
+	}
+
+	product.ID = primitive.NewObjectID()
+	product.CreatedAt = time.Now()
+	product.UpdatedAt = product.CreatedAt
+
+	if err := uc.productRepo.CreateProduct(product); err != nil {
+		return domain.LoanProduct{}, err
+	}
+	return product, nil
+}
+
+// GetProduct retrieves a loan product by its ID.
+func (uc *productUsecase) GetProduct(id primitive.ObjectID) (domain.LoanProduct, error) {
+	return uc.productRepo.GetProductByID(id)
+}
+
+// GetProducts retrieves the loan products, optionally only those open for applications.
+func (uc *productUsecase) GetProducts(activeOnly bool) ([]domain.LoanProduct, error) {
+	return uc.productRepo.GetProducts(activeOnly)
+}
+
+// UpdateProduct validates and replaces an existing loan product. Loans already
+// applied for keep the terms they were granted.
+func (uc *productUsecase) UpdateProduct(product domain.LoanProduct) (domain.LoanProduct, error) {
+	existing, err := uc.productRepo.GetProductByID(product.ID)
+	if err != nil {
+		return domain.LoanProduct{}, err
+	}
+
+	if err := validateProduct(&product); err != nil {
+		return domain.LoanProduct{}, err
+	}
+
+	product.CreatedAt = existing.CreatedAt
+	product.UpdatedAt = time.Now()
+
+	if err := uc.productRepo.UpdateProduct(product); err != nil {
+		return domain.LoanProduct{}, err
+	}
+	return product, nil
+}
+
+// DeleteProduct removes a loan product.
+func (uc *productUsecase) DeleteProduct(id primitive.ObjectID) error {
+	return uc.productRepo.DeleteProduct(id)
+}
+
+// validateProduct checks a product definition and fills in its defaults.
+func validateProduct(product *domain.LoanProduct) error {
+	product.Name = strings.TrimSpace(product.Name)
+	if product.Name == "" || len(product.AmountLimits) == 0 {
+		return domain.ErrInvalidProduct
+	}
+
+	seen := map[string]bool{}
+	for _, limit := range product.AmountLimits {
+		if err := limit.Min.Validate(); err != nil {
+			return err
+		}
+		if limit.Min.Currency != limit.Max.Currency || seen[limit.Min.Currency] ||
+			!limit.Min.IsPositive() || limit.Min.Cmp(limit.Max) > 0 {
+			return domain.ErrInvalidAmountLimit
+		}
+		seen[limit.Min.Currency] = true
+	}
+
+	for _, term := range product.AllowedTerms {
+		if term < 1 || term > domain.MaxLoanTerm {
+			return domain.ErrInvalidLoanTerm
+		}
+	}
+
+	if product.InterestRate == 0 {
+		product.InterestRate = product.MinInterestRate
+	}
+	if product.MinInterestRate < 0 || product.MinInterestRate > product.InterestRate || product.InterestRate > product.MaxInterestRate {
+		return domain.ErrInvalidRateRange
+	}
+
+	for _, frequency := range product.RepaymentFrequencies {
+		if frequency.PeriodsPerYear() == 0 {
+			return domain.ErrInvalidFrequency
+		}
+	}
+
+	if product.AmortizationMethod == "" {
+		product.AmortizationMethod = domain.AmortizationAnnuity
+	}
+	if !product.AmortizationMethod.IsValid() {
+		return domain.ErrInvalidAmortization
+	}
+
+	if product.DayCount == "" {
+		product.DayCount = domain.DayCountActual365
+	}
+	if !product.DayCount.IsValid() {
+		return domain.ErrInvalidDayCount
+	}
+
+	if err := product.Penalties.Validate(); err != nil {
+		return err
+	}
+	if err := product.Prepayment.Validate(); err != nil {
+		return err
+	}
+
+	for _, fee := range product.Fees {
+		if fee.Percentage < 0 {
+			return domain.ErrInvalidProduct
+		}
+		if fee.Flat != nil {
+			if err := fee.Flat.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// originationFee returns the fees a product charges on a loan of amount.
+func originationFee(product domain.LoanProduct, amount domain.Money) domain.Money {
+	fee := amount.Zero()
+	for _, productFee := range product.Fees {
+		fee = fee.Add(amount.Mul(domain.PercentRate(productFee.Percentage)))
+		if productFee.Flat != nil && productFee.Flat.Currency == amount.Currency {
+			fee = fee.Add(*productFee.Flat)
+		}
+	}
+	return fee
+}
+
+// applyProductRules validates the terms of a loan, applied for or counter-offered, against its product.
+func applyProductRules(loan *domain.Loan, product domain.LoanProduct) error {
+	if !product.Active {
+		return domain.ErrProductInactive
+	}
+
+	if err := loan.Amount.Validate(); err != nil {
+		return err
+	}
+	limit, ok := product.LimitFor(loan.Amount.Currency)
+	if !ok {
+		return domain.ErrCurrencyNotOffered
+	}
+	if loan.Amount.Cmp(limit.Min) < 0 || loan.Amount.Cmp(limit.Max) > 0 {
+		return domain.ErrAmountOutOfRange
+	}
+
+	if !product.AllowsTerm(loan.Term) {
+		return domain.ErrTermNotOffered
+	}
+
+	if loan.InterestRate < product.MinInterestRate || loan.InterestRate > product.MaxInterestRate {
+		return domain.ErrRateOutOfRange
+	}
+
+	if !product.AllowsFrequency(loan.RepaymentFrequency) {
+		return domain.ErrFrequencyNotOffered
+	}
+
+	return nil
+}
+
+// checkEligibility checks a borrower against the eligibility requirements of a product.
+func checkEligibility(eligibility domain.ProductEligibility, user domain.User, loans []domain.Loan, now time.Time) error {
+	if eligibility.RequireActiveAccount && !user.IsActive {
+		return domain.ErrInactiveAccount
+	}
+
+	if eligibility.MinAccountAgeDays > 0 && accountAgeDays(user, now) < eligibility.MinAccountAgeDays {
+		return domain.ErrAccountTooNew
+	}
+
+	if eligibility.MaxActiveLoans > 0 && countActiveLoans(loans) >= eligibility.MaxActiveLoans {
+		return domain.ErrTooManyActiveLoans
+	}
+
+	return nil
+}
+
+// accountAgeDays returns the number of whole days since the user registered, taken from their ObjectID.
+func accountAgeDays(user domain.User, now time.Time) int {
+	return int(now.Sub(user.ID.Timestamp()).Hours() / 24)
+}
+
+// countActiveLoans counts the loans that have not reached a terminal state.
+func countActiveLoans(loans []domain.Loan) int {
+	active := 0
+	for _, loan := range loans {
+		if !loan.Status.IsTerminal() {
+			active++
+		}
+	}
+	return active
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// salaryAdvance lends 100.00 to 10,000.00 USD over 3 or 6 monthly installments at 12% to 18%.
+func salaryAdvance() domain.LoanProduct {
+	return domain.LoanProduct{
+		Name:                 " Salary Advance ",
+		AmountLimits:         []domain.AmountLimit{{Min: usd(10000), Max: usd(1000000)}},
+		AllowedTerms:         []int{3, 6},
+		MinInterestRate:      12,
+		MaxInterestRate:      18,
+		RepaymentFrequencies: []domain.RepaymentFrequency{domain.FrequencyMonthly},
+		Active:               true,
+	}
+}
+
+func TestValidateProduct(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*domain.LoanProduct)
+		want   error
+	}{
+		{"valid", func(p *domain.LoanProduct) {}, nil},
+		{"rate inside the range", func(p *domain.LoanProduct) { p.InterestRate = 15 }, nil},
+		{"blank name", func(p *domain.LoanProduct) { p.Name = "  " }, domain.ErrInvalidProduct},
+		{"no amount limit", func(p *domain.LoanProduct) { p.AmountLimits = nil }, domain.ErrInvalidProduct},
+		{"minimum above maximum", func(p *domain.LoanProduct) { p.AmountLimits[0].Min = usd(2000000) }, domain.ErrInvalidAmountLimit},
+		{"limits in two currencies", func(p *domain.LoanProduct) {
+			p.AmountLimits[0].Max = domain.NewMoney(1000000, "EUR")
+		}, domain.ErrInvalidAmountLimit},
+		{"two limits in one currency", func(p *domain.LoanProduct) {
+			p.AmountLimits = append(p.AmountLimits, domain.AmountLimit{Min: usd(100), Max: usd(200)})
+		}, domain.ErrInvalidAmountLimit},
+		{"term too long", func(p *domain.LoanProduct) { p.AllowedTerms = []int{domain.MaxLoanTerm + 1} }, domain.ErrInvalidLoanTerm},
+		{"rate range inverted", func(p *domain.LoanProduct) { p.MinInterestRate = 20 }, domain.ErrInvalidRateRange},
+		{"rate above the range", func(p *domain.LoanProduct) { p.InterestRate = 19 }, domain.ErrInvalidRateRange},
+		{"rate below the range", func(p *domain.LoanProduct) { p.InterestRate = 10 }, domain.ErrInvalidRateRange},
+		{"unknown frequency", func(p *domain.LoanProduct) { p.RepaymentFrequencies = []domain.RepaymentFrequency{"daily"} }, domain.ErrInvalidFrequency},
+		{"unknown amortization", func(p *domain.LoanProduct) { p.AmortizationMethod = "balloon" }, domain.ErrInvalidAmortization},
+		{"negative fee", func(p *domain.LoanProduct) { p.Fees = []domain.ProductFee{{Name: "origination", Percentage: -1}} }, domain.ErrInvalidProduct},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := salaryAdvance()
+			tt.change(&product)
+			rate := product.InterestRate
+
+			if err := validateProduct(&product); err != tt.want {
+				t.Fatalf("validateProduct = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+			if product.Name != "Salary Advance" || product.AmortizationMethod != domain.AmortizationAnnuity || product.DayCount != domain.DayCountActual365 {
+				t.Errorf("defaults not filled in: %q, %s, %s", product.Name, product.AmortizationMethod, product.DayCount)
+			}
+			if want := max(rate, product.MinInterestRate); product.InterestRate != want {
+				t.Errorf("product charges %g%%, want %g%%", product.InterestRate, want)
+			}
+		})
+	}
+}
+
+func TestApplyProductRules(t *testing.T) {
+	inactive := salaryAdvance()
+	inactive.Active = false
+	terms := func(amount domain.Money, term int, rate float64, frequency domain.RepaymentFrequency) domain.Loan {
+		return domain.Loan{Amount: amount, Term: term, InterestRate: rate, RepaymentFrequency: frequency}
+	}
+
+	tests := []struct {
+		name    string
+		product domain.LoanProduct
+		loan    domain.Loan
+		want    error
+	}{
+		{"within the product", salaryAdvance(), terms(usd(50000), 6, 15, domain.FrequencyMonthly), nil},
+		{"at the limits", salaryAdvance(), terms(usd(1000000), 3, 18, domain.FrequencyMonthly), nil},
+		{"product closed", inactive, terms(usd(50000), 6, 15, domain.FrequencyMonthly), domain.ErrProductInactive},
+		{"currency not offered", salaryAdvance(), terms(domain.NewMoney(50000, "EUR"), 6, 15, domain.FrequencyMonthly), domain.ErrCurrencyNotOffered},
+		{"amount below the limit", salaryAdvance(), terms(usd(9999), 6, 15, domain.FrequencyMonthly), domain.ErrAmountOutOfRange},
+		{"amount above the limit", salaryAdvance(), terms(usd(1000001), 6, 15, domain.FrequencyMonthly), domain.ErrAmountOutOfRange},
+		{"term not offered", salaryAdvance(), terms(usd(50000), 12, 15, domain.FrequencyMonthly), domain.ErrTermNotOffered},
+		{"rate below the range", salaryAdvance(), terms(usd(50000), 6, 0, domain.FrequencyMonthly), domain.ErrRateOutOfRange},
+		{"rate above the range", salaryAdvance(), terms(usd(50000), 6, 18.5, domain.FrequencyMonthly), domain.ErrRateOutOfRange},
+		{"frequency not offered", salaryAdvance(), terms(usd(50000), 6, 15, domain.FrequencyWeekly), domain.ErrFrequencyNotOffered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := tt.loan
+			if err := applyProductRules(&loan, tt.product); err != tt.want {
+				t.Errorf("applyProductRules = %v, want %v", err, tt.want)
+			}
+			if loan.InterestRate != tt.loan.InterestRate {
+				t.Errorf("rate changed from %g%% to %g%%", tt.loan.InterestRate, loan.InterestRate)
+			}
+		})
+	}
+}
+
+func TestApplyForLoanChargesTheProductRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      float64 // set on the product
+		requested float64
+		want      float64
+	}{
+		{"product rate", 12, 5, 12},
+		{"rate above the range asked for", 12, 25, 12},
+		{"no rate asked for", 12, 0, 12},
+		{"product without a rate charges its minimum", 0, 20, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newApplicationFixture(true)
+			f.product.InterestRate = tt.rate
+			application := f.application()
+			application.InterestRate = tt.requested
+
+			loan, err := f.usecase().ApplyForLoan(application)
+			if err != nil {
+				t.Fatalf("ApplyForLoan: %v", err)
+			}
+			if stored, _ := f.loans.GetLoanByID(loan.ID); loan.InterestRate != tt.want || stored.InterestRate != tt.want {
+				t.Errorf("loan charges %g%% (stored %g%%), want %g%%", loan.InterestRate, stored.InterestRate, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckEligibility(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	user := domain.User{ID: primitive.NewObjectIDFromTimestamp(now.AddDate(0, 0, -10)), IsActive: true}
+	inactive := user
+	inactive.IsActive = false
+	open := []domain.Loan{{Status: domain.LoanStatusActive}, {Status: domain.LoanStatusPaidOff}, {Status: domain.LoanStatusRejected}}
+
+	tests := []struct {
+		name        string
+		eligibility domain.ProductEligibility
+		user        domain.User
+		loans       []domain.Loan
+		want        error
+	}{
+		{"no requirements", domain.ProductEligibility{}, inactive, open, nil},
+		{"activated account", domain.ProductEligibility{RequireActiveAccount: true}, user, nil, nil},
+		{"account not activated", domain.ProductEligibility{RequireActiveAccount: true}, inactive, nil, domain.ErrInactiveAccount},
+		{"account old enough", domain.ProductEligibility{MinAccountAgeDays: 10}, user, nil, nil},
+		{"account too new", domain.ProductEligibility{MinAccountAgeDays: 11}, user, nil, domain.ErrAccountTooNew},
+		{"closed loans do not count", domain.ProductEligibility{MaxActiveLoans: 2}, user, open, nil},
+		{"too many active loans", domain.ProductEligibility{MaxActiveLoans: 1}, user, open, domain.ErrTooManyActiveLoans},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkEligibility(tt.eligibility, tt.user, tt.loans, now); err != tt.want {
+				t.Errorf("checkEligibility = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
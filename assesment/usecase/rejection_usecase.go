@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reasonCodePattern is the form of rejection reason codes, e.g. "insufficient_income".
+var reasonCodePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+type rejectionUsecase struct {
+	reasonRepo domain.RejectionReasonRepository
+	loanRepo   domain.LoanRepository
+	userRepo   domain.UserRepository
+	notices    domain.AdverseActionNotifier
+}
+
+// NewRejectionUsecase creates a new instance of RejectionUsecase that renders notices through notices.
+func NewRejectionUsecase(reasonRepo domain.RejectionReasonRepository, loanRepo domain.LoanRepository, userRepo domain.UserRepository, notices domain.AdverseActionNotifier) domain.RejectionUsecase {
+	return &rejectionUsecase{
+		reasonRepo: reasonRepo,
+		loanRepo:   loanRepo,
+		userRepo:   userRepo,
+		notices:    notices,
+	}
+}
+
+// CreateReason adds a reason to the rejection catalog. New reasons are active.
+func (uc *rejectionUsecase) CreateReason(reason domain.RejectionReason) (domain.RejectionReason, error) {
+	reason.Code = strings.TrimSpace(reason.Code)
+	reason.Description = strings.TrimSpace(reason.Description)
+	if !reasonCodePattern.MatchString(reason.Code) || reason.Description == "" {
+		return domain.RejectionReason{}, domain.ErrInvalidRejectionReason
+	}
+
+	now := time.Now()
+	reason.Active = true
+	reason.CreatedAt = now
+	reason.UpdatedAt = now
+	if err := uc.reasonRepo.CreateReason(reason); err != nil {
+		return domain.RejectionReason{}, err
+	}
+	return reason, nil
+}
+
+// GetReasons retrieves the rejection catalog, or only the reasons that can still be given.
+func (uc *rejectionUsecase) GetReasons(activeOnly bool) ([]domain.RejectionReason, error) {
+	return uc.reasonRepo.GetReasons(activeOnly)
+}
+
+// UpdateReason rewords a rejection reason or retires it. Loans already rejected keep the
+// wording they were given.
+func (uc *rejectionUsecase) UpdateReason(code string, update domain.RejectionReason) (domain.RejectionReason, error) {
+	reason, err := uc.reasonRepo.GetReasonByCode(code)
+	if err != nil {
+		return domain.RejectionReason{}, err
+	}
+
+	reason.Description = strings.TrimSpace(update.Description)
+	if reason.Description == "" {
+		return domain.RejectionReason{}, domain.ErrInvalidRejectionReason
+	}
+	reason.Active = update.Active
+	reason.UpdatedAt = time.Now()
+	if err := uc.reasonRepo.UpdateReason(reason); err != nil {
+		return domain.RejectionReason{}, err
+	}
+	return reason, nil
+}
+
+// SeedDefaultReasons adds the default rejection reasons missing from the catalog, leaving
+// those already there, reworded or retired, as they are.
+func (uc *rejectionUsecase) SeedDefaultReasons() error {
+	for _, reason := range domain.DefaultRejectionReasons {
+		if _, err := uc.CreateReason(reason); err != nil && !errors.Is(err, domain.ErrDuplicateRejectionReason) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAdverseActionNotice renders the adverse-action notice of a rejected application of userID.
+func (uc *rejectionUsecase) GetAdverseActionNotice(loanID uint, userID primitive.ObjectID) (string, error) {
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return "", err
+	}
+	if loan.UserID != userID {
+		return "", domain.ErrNotLoanOwner
+	}
+	if loan.Rejection == nil {
+		return "", domain.ErrLoanNotRejected
+	}
+	user, err := uc.userRepo.GetUserByID(loan.UserID)
+	if err != nil {
+		return "", domain.ErrUserNotFound
+	}
+
+	return uc.notices.RenderNotice(adverseActionNotice(loan, user))
+}
+
+// statedReasons looks up the reason codes given for a rejection in the catalog. Codes must
+// be active; repeated codes are given once.
+func statedReasons(repo domain.RejectionReasonRepository, codes []string) ([]domain.StatedReason, error) {
+	var reasons []domain.StatedReason
+	seen := map[string]bool{}
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		reason, err := repo.GetReasonByCode(code)
+		if errors.Is(err, domain.ErrRejectionReasonNotFound) || (err == nil && !reason.Active) {
+			return nil, domain.ErrUnknownRejectionReason
+		}
+		if err != nil {
+			return nil, err
+		}
+		reasons = append(reasons, domain.StatedReason{Code: reason.Code, Description: reason.Description})
+	}
+
+	if len(reasons) == 0 {
+		return nil, domain.ErrRejectionReasonRequired
+	}
+	return reasons, nil
+}
+
+// declineReasons states the reasons the credit rules declined an application for: the
+// catalog wording of the reason code of each declining rule, or the rule's own explanation
+// when its code is not in the catalog or retired.
+func declineReasons(repo domain.RejectionReasonRepository, decision domain.CreditDecision) []domain.StatedReason {
+	var reasons []domain.StatedReason
+	seen := map[string]bool{}
+	for _, result := range decision.Results {
+		if result.Outcome != domain.DecisionDecline {
+			continue
+		}
+		code := result.ReasonCode
+		if code == "" {
+			code = result.Rule
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		reason := domain.StatedReason{Code: code, Description: result.Reason}
+		if catalog, err := repo.GetReasonByCode(code); err == nil && catalog.Active {
+			reason.Description = catalog.Description
+		}
+		reasons = append(reasons, reason)
+	}
+	return reasons
+}
+
+// adverseActionNotice returns the notice telling the borrower of a rejected loan why.
+func adverseActionNotice(loan domain.Loan, borrower domain.User) domain.AdverseActionNotice {
+	name := borrower.Username
+	if name == "" {
+		name = borrower.Email
+	}
+	return domain.AdverseActionNotice{
+		LoanID:        loan.ID,
+		BorrowerName:  name,
+		BorrowerEmail: borrower.Email,
+		Amount:        loan.Amount,
+		AppliedAt:     loan.CreatedAt,
+		RejectedAt:    loan.Rejection.RejectedAt,
+		Reasons:       loan.Rejection.Reasons,
+		Note:          loan.Rejection.Note,
+	}
+}
+
+// rejectionDetail describes a rejection for the history of its loan: the reason codes and the
+// reviewer's note.
+func rejectionDetail(reasons []domain.StatedReason, note string) string {
+	codes := make([]string, len(reasons))
+	for i, reason := range reasons {
+		codes[i] = reason.Code
+	}
+	detail := strings.Join(codes, ", ")
+	if note != "" {
+		detail += ": " + note
+	}
+	return detail
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryReasons keeps the rejection reason catalog in memory by code.
+type memoryReasons struct {
+	domain.RejectionReasonRepository
+	reasons map[string]domain.RejectionReason
+}
+
+// newMemoryReasons returns the default catalog, with insufficient_collateral retired.
+func newMemoryReasons() *memoryReasons {
+	r := &memoryReasons{reasons: map[string]domain.RejectionReason{}}
+	for _, reason := range domain.DefaultRejectionReasons {
+		reason.Active = reason.Code != "insufficient_collateral"
+		r.reasons[reason.Code] = reason
+	}
+	return r
+}
+
+func (r *memoryReasons) GetReasonByCode(code string) (domain.RejectionReason, error) {
+	reason, ok := r.reasons[code]
+	if !ok {
+		return domain.RejectionReason{}, domain.ErrRejectionReasonNotFound
+	}
+	return reason, nil
+}
+
+// recordingNotices records the adverse-action notices sent, or fails to send any.
+type recordingNotices struct {
+	domain.AdverseActionNotifier
+	sent []domain.AdverseActionNotice
+	down bool
+}
+
+func (n *recordingNotices) SendNotice(notice domain.AdverseActionNotice) error {
+	if n.down {
+		return errors.New("mail server unavailable")
+	}
+	n.sent = append(n.sent, notice)
+	return nil
+}
+
+func (n *recordingNotices) RenderNotice(notice domain.AdverseActionNotice) (string, error) {
+	codes := make([]string, len(notice.Reasons))
+	for i, reason := range notice.Reasons {
+		codes[i] = reason.Code
+	}
+	return notice.BorrowerEmail + ": " + strings.Join(codes, ", "), nil
+}
+
+func TestStatedReasons(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes []string
+		want  []string
+		err   error
+	}{
+		{"catalog reasons", []string{"insufficient_income", " excessive_obligations "}, []string{"insufficient_income", "excessive_obligations"}, nil},
+		{"repeated code", []string{"insufficient_income", "insufficient_income"}, []string{"insufficient_income"}, nil},
+		{"no code", []string{" "}, nil, domain.ErrRejectionReasonRequired},
+		{"unknown code", []string{"bad_vibes"}, nil, domain.ErrUnknownRejectionReason},
+		{"retired code", []string{"insufficient_collateral"}, nil, domain.ErrUnknownRejectionReason},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons, err := statedReasons(newMemoryReasons(), tt.codes)
+			if err != tt.err {
+				t.Fatalf("statedReasons = %v, want %v", err, tt.err)
+			}
+			if len(reasons) != len(tt.want) {
+				t.Fatalf("stated %+v, want %v", reasons, tt.want)
+			}
+			for i, reason := range reasons {
+				if reason.Code != tt.want[i] || reason.Description == "" {
+					t.Errorf("reason %d is %+v, want %s with its catalog wording", i, reason, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeclineReasons(t *testing.T) {
+	decision := domain.CreditDecision{
+		Outcome: domain.DecisionDecline,
+		Results: []domain.RuleResult{
+			{Rule: "activated", Outcome: domain.DecisionApprove},
+			{Rule: "dti", Outcome: domain.DecisionDecline, Reason: "debt-to-income ratio of 52.0% exceeds 40%", ReasonCode: "excessive_obligations"},
+			{Rule: "loans", Outcome: domain.DecisionDecline, Reason: "borrower already has 2 active loans, at most 2 allowed", ReasonCode: "excessive_obligations"},
+			{Rule: "age", Outcome: domain.DecisionRefer, Reason: "account is 3 days old, 30 required", ReasonCode: "insufficient_credit_history"},
+			{Rule: "secured", Outcome: domain.DecisionDecline, Reason: "no collateral pledged", ReasonCode: "insufficient_collateral"},
+			{Rule: "custom", Outcome: domain.DecisionDecline, Reason: "amount above what the branch lends"},
+		},
+	}
+
+	want := []domain.StatedReason{
+		{Code: "excessive_obligations", Description: "Excessive obligations in relation to income"},
+		{Code: "insufficient_collateral", Description: "no collateral pledged"},
+		{Code: "custom", Description: "amount above what the branch lends"},
+	}
+	reasons := declineReasons(newMemoryReasons(), decision)
+	if len(reasons) != len(want) {
+		t.Fatalf("stated %+v, want %+v", reasons, want)
+	}
+	for i := range want {
+		if reasons[i] != want[i] {
+			t.Errorf("reason %d is %+v, want %+v", i, reasons[i], want[i])
+		}
+	}
+}
+
+func TestRejectLoan(t *testing.T) {
+	borrower := domain.User{ID: primitive.NewObjectID(), Email: "borrower@example.com", Username: "borrower"}
+	reviewer := primitive.NewObjectID()
+	application := domain.Loan{
+		ID:     6,
+		UserID: borrower.ID,
+		Amount: usd(100000),
+		Status: domain.LoanStatusUnderReview,
+		Review: &domain.LoanReview{ReviewerID: reviewer},
+	}
+
+	tests := []struct {
+		name       string
+		request    domain.RejectionRequest
+		reviewerID primitive.ObjectID
+		noticeDown bool
+		wantErr    error
+		want       domain.LoanStatus
+	}{
+		{"stated reasons", domain.RejectionRequest{ReasonCodes: []string{"insufficient_income"}, Note: " payslips show less "}, reviewer, false, nil, domain.LoanStatusRejected},
+		{"notice not sent", domain.RejectionRequest{ReasonCodes: []string{"insufficient_income"}}, reviewer, true, domain.ErrAdverseActionNotSent, domain.LoanStatusRejected},
+		{"no reason", domain.RejectionRequest{Note: "no"}, reviewer, false, domain.ErrRejectionReasonRequired, domain.LoanStatusUnderReview},
+		{"retired reason", domain.RejectionRequest{ReasonCodes: []string{"insufficient_collateral"}}, reviewer, false, domain.ErrUnknownRejectionReason, domain.LoanStatusUnderReview},
+		{"another reviewer", domain.RejectionRequest{ReasonCodes: []string{"insufficient_income"}}, primitive.NewObjectID(), false, domain.ErrNotAssignedReviewer, domain.LoanStatusUnderReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loans := newMemoryLoans(application)
+			users := newMemoryUsers(borrower)
+			reasons := newMemoryReasons()
+			notices := &recordingNotices{down: tt.noticeDown}
+			uc := NewLoanUsecase(loans, nil, nil, nil, users, nil, nil, nil, newMemoryCollateral(), domain.DualApprovalPolicy{}, reasons, notices, &memoryHistory{})
+
+			if _, err := uc.RejectLoan(6, tt.reviewerID, tt.request); err != tt.wantErr {
+				t.Fatalf("RejectLoan = %v, want %v", err, tt.wantErr)
+			}
+			stored, _ := loans.GetLoanByID(6)
+			if stored.Status != tt.want {
+				t.Fatalf("loan is %s, want %s", stored.Status, tt.want)
+			}
+			if tt.want != domain.LoanStatusRejected {
+				if stored.Rejection != nil || len(notices.sent) != 0 {
+					t.Errorf("refused rejection was stored or sent")
+				}
+				return
+			}
+
+			rejection := stored.Rejection
+			if rejection == nil || len(rejection.Reasons) != 1 || rejection.Reasons[0].Description != "Income insufficient for the amount of credit requested" ||
+				rejection.Note != strings.TrimSpace(tt.request.Note) || rejection.RejectedBy != reviewer {
+				t.Fatalf("stored rejection %+v, want the stated reason and note by the reviewer", rejection)
+			}
+			if sent := len(notices.sent) == 1; sent == tt.noticeDown || (rejection.NoticeSentAt != nil) != sent {
+				t.Errorf("notice sent at %v (%d sent) with the notifier down %v", rejection.NoticeSentAt, len(notices.sent), tt.noticeDown)
+			}
+
+			rejections := NewRejectionUsecase(reasons, loans, users, notices)
+			if _, err := rejections.GetAdverseActionNotice(6, primitive.NewObjectID()); err != domain.ErrNotLoanOwner {
+				t.Errorf("notice for someone else = %v, want %v", err, domain.ErrNotLoanOwner)
+			}
+			if notice, err := rejections.GetAdverseActionNotice(6, borrower.ID); err != nil || notice != "borrower@example.com: insufficient_income" {
+				t.Errorf("GetAdverseActionNotice = %q, %v, want the borrower's notice", notice, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"errors"
+	"math/big"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type reportUsecase struct {
+	loanRepo          domain.LoanRepository
+	rates             domain.ExchangeRateProvider
+	reportingCurrency string
+}
+
+// NewReportUsecase creates a new instance of ReportUsecase. Reports are converted into
+// reportingCurrency unless the caller asks for another currency.
+func NewReportUsecase(loanRepo domain.LoanRepository, rates domain.ExchangeRateProvider, reportingCurrency string) domain.ReportUsecase {
+	return &reportUsecase{
+		loanRepo:          loanRepo,
+		rates:             rates,
+		reportingCurrency: strings.ToUpper(reportingCurrency),
+	}
+}
+
+// GetPortfolioReport summarises the loans on the book per currency and converts
+// the totals into the reporting currency.
+func (uc *reportUsecase) GetPortfolioReport(reportingCurrency string) (domain.PortfolioReport, error) {
+	reportingCurrency = strings.ToUpper(reportingCurrency)
+	if reportingCurrency == "" {
+		reportingCurrency = uc.reportingCurrency
+	}
+	if !domain.IsValidCurrency(reportingCurrency) {
+		return domain.PortfolioReport{}, domain.ErrUnknownCurrency
+	}
+
+	loans, err := uc.loanRepo.GetAllLoans("", "asc", "")
+	if err != nil {
+		return domain.PortfolioReport{}, err
+	}
+
+	exposures := map[string]*domain.CurrencyExposure{}
+	for _, loan := range loans {
+		if !loan.Status.IsOnBook() {
+			continue
+		}
+		currency := loan.Amount.Currency
+		exposure, ok := exposures[currency]
+		if !ok {
+			exposure = &domain.CurrencyExposure{
+				Currency:             currency,
+				Principal:            loan.Amount.Zero(),
+				OutstandingPrincipal: loan.Amount.Zero(),
+			}
+			exposures[currency] = exposure
+		}
+		exposure.LoanCount++
+		exposure.Principal = exposure.Principal.Add(loan.Amount)
+		exposure.OutstandingPrincipal = exposure.OutstandingPrincipal.Add(outstandingPrincipal(loan))
+	}
+
+	report := domain.PortfolioReport{
+		ReportingCurrency: reportingCurrency,
+		GeneratedAt:       time.Now(),
+		Currencies:        []domain.CurrencyExposure{},
+		TotalPrincipal:    domain.NewMoney(0, reportingCurrency),
+		TotalOutstanding:  domain.NewMoney(0, reportingCurrency),
+	}
+	for _, exposure := range exposures {
+		report.Currencies = append(report.Currencies, *exposure)
+		rate, err := uc.rates.Rate(exposure.Currency, reportingCurrency)
+		if errors.Is(err, domain.ErrRateUnavailable) {
+			report.UnconvertedCurrencies = addCurrency(report.UnconvertedCurrencies, exposure.Currency)
+			continue
+		}
+		if err != nil {
+			return domain.PortfolioReport{}, err
+		}
+
+		converted := &report.Currencies[len(report.Currencies)-1]
+		principal, err := domain.ConvertMoney(exposure.Principal, reportingCurrency, uc.rates)
+		if err != nil {
+			return domain.PortfolioReport{}, err
+		}
+		outstanding, err := domain.ConvertMoney(exposure.OutstandingPrincipal, reportingCurrency, uc.rates)
+		if err != nil {
+			return domain.PortfolioReport{}, err
+		}
+		converted.Rate = rate.FloatString(6)
+		converted.ConvertedPrincipal, converted.ConvertedOutstanding = &principal, &outstanding
+
+		report.TotalPrincipal = report.TotalPrincipal.Add(principal)
+		report.TotalOutstanding = report.TotalOutstanding.Add(outstanding)
+	}
+	sort.Slice(report.Currencies, func(i, j int) bool {
+		return report.Currencies[i].Currency < report.Currencies[j].Currency
+	})
+
+	return report, nil
+}
+
+// GetAgingReport spreads the outstanding principal of the loans on the book over the
+// aging buckets, converted into the reporting currency. Days past due are computed at
+// the time of the report rather than taken from the last delinquency check.
+func (uc *reportUsecase) GetAgingReport(reportingCurrency string) (domain.AgingReport, error) {
+	reportingCurrency = strings.ToUpper(reportingCurrency)
+	if reportingCurrency == "" {
+		reportingCurrency = uc.reportingCurrency
+	}
+	if !domain.IsValidCurrency(reportingCurrency) {
+		return domain.AgingReport{}, domain.ErrUnknownCurrency
+	}
+
+	loans, err := uc.loanRepo.GetLoansByStatus(domain.LoanStatusDisbursed, domain.LoanStatusActive,
+		domain.LoanStatusDelinquent, domain.LoanStatusDefaulted)
+	if err != nil {
+		return domain.AgingReport{}, err
+	}
+
+	report := domain.AgingReport{
+		ReportingCurrency: reportingCurrency,
+		GeneratedAt:       time.Now(),
+		Buckets:           make([]domain.AgingBucketSummary, len(domain.AgingBuckets)),
+		TotalOutstanding:  domain.NewMoney(0, reportingCurrency),
+	}
+	index := map[domain.AgingBucket]int{}
+	for i, bucket := range domain.AgingBuckets {
+		report.Buckets[i] = domain.AgingBucketSummary{Bucket: bucket, OutstandingPrincipal: report.TotalOutstanding}
+		index[bucket] = i
+	}
+
+	for _, loan := range loans {
+		summary := &report.Buckets[index[domain.AgingBucketFor(daysPastDue(loan.Schedule, report.GeneratedAt))]]
+		summary.LoanCount++
+
+		outstanding, err := domain.ConvertMoney(outstandingPrincipal(loan), reportingCurrency, uc.rates)
+		if errors.Is(err, domain.ErrRateUnavailable) {
+			summary.Unconverted = addByCurrency(summary.Unconverted, outstandingPrincipal(loan))
+			report.UnconvertedCurrencies = addCurrency(report.UnconvertedCurrencies, loan.Amount.Currency)
+			continue
+		}
+		if err != nil {
+			return domain.AgingReport{}, err
+		}
+		summary.OutstandingPrincipal = summary.OutstandingPrincipal.Add(outstanding)
+		report.TotalOutstanding = report.TotalOutstanding.Add(outstanding)
+	}
+
+	return report, nil
+}
+
+// GetRecoveryReport lists every written-off loan with what was written off and what has been
+// recovered on it since, converted into the reporting currency, newest write-off first.
+func (uc *reportUsecase) GetRecoveryReport(reportingCurrency string) (domain.RecoveryReport, error) {
+	reportingCurrency = strings.ToUpper(reportingCurrency)
+	if reportingCurrency == "" {
+		reportingCurrency = uc.reportingCurrency
+	}
+	if !domain.IsValidCurrency(reportingCurrency) {
+		return domain.RecoveryReport{}, domain.ErrUnknownCurrency
+	}
+
+	loans, err := uc.loanRepo.GetLoansByStatus(domain.LoanStatusWrittenOff)
+	if err != nil {
+		return domain.RecoveryReport{}, err
+	}
+
+	report := domain.RecoveryReport{
+		ReportingCurrency: reportingCurrency,
+		GeneratedAt:       time.Now(),
+		Loans:             []domain.LoanRecovery{},
+		TotalWrittenOff:   domain.NewMoney(0, reportingCurrency),
+		TotalRecovered:    domain.NewMoney(0, reportingCurrency),
+	}
+	for _, loan := range loans {
+		if loan.WriteOff == nil {
+			continue
+		}
+		recovery := domain.LoanRecovery{
+			LoanID:       loan.ID,
+			WrittenOff:   loan.WriteOff.Total(),
+			Recovered:    loan.Amount.Zero().Add(loan.Recovered),
+			WrittenOffAt: loan.WriteOff.WrittenOffAt,
+		}
+		report.Loans = append(report.Loans, recovery)
+
+		writtenOff, err := domain.ConvertMoney(recovery.WrittenOff, reportingCurrency, uc.rates)
+		if errors.Is(err, domain.ErrRateUnavailable) {
+			report.UnconvertedCurrencies = addCurrency(report.UnconvertedCurrencies, loan.Amount.Currency)
+			continue
+		}
+		if err != nil {
+			return domain.RecoveryReport{}, err
+		}
+		recovered, err := domain.ConvertMoney(recovery.Recovered, reportingCurrency, uc.rates)
+		if err != nil {
+			return domain.RecoveryReport{}, err
+		}
+		converted := &report.Loans[len(report.Loans)-1]
+		converted.ConvertedWrittenOff, converted.ConvertedRecovered = &writtenOff, &recovered
+
+		report.TotalWrittenOff = report.TotalWrittenOff.Add(writtenOff)
+		report.TotalRecovered = report.TotalRecovered.Add(recovered)
+	}
+	sort.Slice(report.Loans, func(i, j int) bool {
+		return report.Loans[i].WrittenOffAt.After(report.Loans[j].WrittenOffAt)
+	})
+
+	if report.TotalWrittenOff.IsPositive() {
+		rate, _ := new(big.Rat).Quo(report.TotalRecovered.Rat(), report.TotalWrittenOff.Rat()).Float64()
+		report.RecoveryRate = rate * 100
+	}
+
+	return report, nil
+}
+
+// addCurrency adds currency to the sorted list of currencies a report could not convert.
+func addCurrency(currencies []string, currency string) []string {
+	i, found := slices.BinarySearch(currencies, currency)
+	if found {
+		return currencies
+	}
+	return slices.Insert(currencies, i, currency)
+}
+
+// addByCurrency adds m to the amount kept in its currency, keeping the amounts sorted by currency.
+func addByCurrency(amounts []domain.Money, m domain.Money) []domain.Money {
+	i, found := slices.BinarySearchFunc(amounts, m.Currency, func(a domain.Money, currency string) int {
+		return strings.Compare(a.Currency, currency)
+	})
+	if found {
+		amounts[i] = amounts[i].Add(m)
+		return amounts
+	}
+	return slices.Insert(amounts, i, m)
+}
+
+// outstandingPrincipal returns the principal of a loan that has not been repaid yet.
+func outstandingPrincipal(loan domain.Loan) domain.Money {
+	principal := loan.Amount.Zero()
+	for _, inst := range loan.Schedule {
+		principal = principal.Add(inst.PrincipalDue())
+	}
+	return principal
+}
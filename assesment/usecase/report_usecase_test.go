@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fixedRates quotes currencies in units per US dollar.
+type fixedRates map[string]*big.Rat
+
+func (r fixedRates) Rate(from, to string) (*big.Rat, error) {
+	fromRate, ok := r[from]
+	if !ok {
+		return nil, domain.ErrRateUnavailable
+	}
+	toRate, ok := r[to]
+	if !ok {
+		return nil, domain.ErrRateUnavailable
+	}
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
+
+// bookedLoan is a loan whose whole principal is due in a single installment on due.
+func bookedLoan(id uint, status domain.LoanStatus, principal domain.Money, due time.Time) domain.Loan {
+	return domain.Loan{
+		ID:        id,
+		Amount:    principal,
+		Status:    status,
+		Schedule:  []domain.Installment{{Number: 1, DueDate: due, Principal: principal, Total: principal}},
+		CreatedAt: time.Date(2024, time.January, int(id), 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// writtenOffLoan is a loan of which principal was written off and recovered recovered since.
+func writtenOffLoan(id uint, principal, recovered domain.Money, writtenOffAt time.Time) domain.Loan {
+	loan := bookedLoan(id, domain.LoanStatusWrittenOff, principal, writtenOffAt)
+	loan.WriteOff = &domain.WriteOff{Principal: principal, Interest: principal.Zero(), Fees: principal.Zero(), WrittenOffAt: writtenOffAt}
+	loan.Recovered = recovered
+	return loan
+}
+
+// newTestReportUsecase reports on dollar, euro and birr loans, with rates for dollars and euros only.
+func newTestReportUsecase() domain.ReportUsecase {
+	now := time.Now()
+	etb := func(cents int64) domain.Money { return domain.NewMoney(cents, "ETB") }
+	eur := func(cents int64) domain.Money { return domain.NewMoney(cents, "EUR") }
+	loans := newMemoryLoans(
+		bookedLoan(1, domain.LoanStatusActive, usd(100000), now.AddDate(0, 1, 0)),
+		bookedLoan(2, domain.LoanStatusActive, eur(50000), now.AddDate(0, 1, 0)),
+		bookedLoan(3, domain.LoanStatusDelinquent, etb(500000), now.AddDate(0, 0, -45)),
+		bookedLoan(4, domain.LoanStatusPaidOff, usd(70000), now.AddDate(0, -1, 0)),
+		bookedLoan(5, domain.LoanStatusDefaulted, usd(30000), now.AddDate(0, 0, -100)),
+		writtenOffLoan(6, usd(80000), usd(20000), now.AddDate(0, -2, 0)),
+		writtenOffLoan(7, etb(900000), etb(0), now.AddDate(0, -1, 0)),
+	)
+	rates := fixedRates{"USD": big.NewRat(1, 1), "EUR": big.NewRat(1, 2)}
+	return NewReportUsecase(loans, rates, "usd")
+}
+
+func TestGetPortfolioReport(t *testing.T) {
+	uc := newTestReportUsecase()
+
+	tests := []struct {
+		currency       string
+		wantPrincipal  domain.Money
+		wantCurrencies []string
+		err            error
+	}{
+		{"", usd(230000), []string{"ETB", "EUR", "USD"}, nil},
+		{"eur", domain.NewMoney(115000, "EUR"), []string{"ETB", "EUR", "USD"}, nil},
+		{"XXX", domain.Money{}, nil, domain.ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			report, err := uc.GetPortfolioReport(tt.currency)
+			if err != tt.err {
+				t.Fatalf("GetPortfolioReport = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if report.TotalPrincipal != tt.wantPrincipal || report.TotalOutstanding != tt.wantPrincipal {
+				t.Errorf("totals %s lent and %s outstanding, want %s", report.TotalPrincipal, report.TotalOutstanding, tt.wantPrincipal)
+			}
+			var currencies []string
+			for _, exposure := range report.Currencies {
+				currencies = append(currencies, exposure.Currency)
+				converted := exposure.ConvertedPrincipal != nil && exposure.ConvertedOutstanding != nil && exposure.Rate != ""
+				if converted == (exposure.Currency == "ETB") {
+					t.Errorf("%s exposure %+v converted %v", exposure.Currency, exposure, converted)
+				}
+			}
+			if !slices.Equal(currencies, tt.wantCurrencies) || !slices.Equal(report.UnconvertedCurrencies, []string{"ETB"}) {
+				t.Errorf("currencies %v with %v unconverted, want %v with ETB unconverted", currencies, report.UnconvertedCurrencies, tt.wantCurrencies)
+			}
+		})
+	}
+}
+
+func TestGetAgingReport(t *testing.T) {
+	report, err := newTestReportUsecase().GetAgingReport("")
+	if err != nil {
+		t.Fatalf("GetAgingReport: %v", err)
+	}
+
+	want := map[domain.AgingBucket]struct {
+		loans       int
+		outstanding domain.Money
+		unconverted []domain.Money
+	}{
+		domain.AgingCurrent: {2, usd(200000), nil},
+		domain.Aging31To60:  {1, usd(0), []domain.Money{domain.NewMoney(500000, "ETB")}},
+		domain.AgingOver90:  {1, usd(30000), nil},
+	}
+	for _, summary := range report.Buckets {
+		w := want[summary.Bucket]
+		if w.outstanding.Currency == "" {
+			w.outstanding = usd(0)
+		}
+		if summary.LoanCount != w.loans || summary.OutstandingPrincipal != w.outstanding || !slices.Equal(summary.Unconverted, w.unconverted) {
+			t.Errorf("bucket %s has %d loans with %s outstanding and %v unconverted, want %d with %s and %v",
+				summary.Bucket, summary.LoanCount, summary.OutstandingPrincipal, summary.Unconverted, w.loans, w.outstanding, w.unconverted)
+		}
+	}
+	if report.TotalOutstanding != usd(230000) || !slices.Equal(report.UnconvertedCurrencies, []string{"ETB"}) {
+		t.Errorf("total %s outstanding with %v unconverted, want 2300.00 USD with ETB unconverted", report.TotalOutstanding, report.UnconvertedCurrencies)
+	}
+}
+
+func TestGetRecoveryReport(t *testing.T) {
+	report, err := newTestReportUsecase().GetRecoveryReport("")
+	if err != nil {
+		t.Fatalf("GetRecoveryReport: %v", err)
+	}
+
+	if len(report.Loans) != 2 || report.Loans[0].LoanID != 7 || report.Loans[1].LoanID != 6 {
+		t.Fatalf("listed %+v, want loans 7 and 6, newest write-off first", report.Loans)
+	}
+	if report.Loans[0].ConvertedWrittenOff != nil || report.Loans[1].ConvertedWrittenOff == nil || *report.Loans[1].ConvertedRecovered != usd(20000) {
+		t.Errorf("converted %+v, want only the dollar loan converted", report.Loans)
+	}
+	if report.TotalWrittenOff != usd(80000) || report.TotalRecovered != usd(20000) || report.RecoveryRate != 25 {
+		t.Errorf("wrote off %s and recovered %s at %g%%, want 800.00 USD and 200.00 USD at 25%%",
+			report.TotalWrittenOff, report.TotalRecovered, report.RecoveryRate)
+	}
+	if !slices.Equal(report.UnconvertedCurrencies, []string{"ETB"}) {
+		t.Errorf("unconverted %v, want ETB", report.UnconvertedCurrencies)
+	}
+}
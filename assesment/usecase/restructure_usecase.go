@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"math/big"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type restructureUsecase struct {
+	loanRepo         domain.LoanRepository
+	modificationRepo domain.ModificationRepository
+	loanUsecase      domain.LoanUsecase
+	ledger           domain.LedgerUsecase
+	history          domain.LoanHistoryRepository
+}
+
+// NewRestructureUsecase creates a new instance of RestructureUsecase.
+func NewRestructureUsecase(loanRepo domain.LoanRepository, modificationRepo domain.ModificationRepository, loanUsecase domain.LoanUsecase, ledger domain.LedgerUsecase, history domain.LoanHistoryRepository) domain.RestructureUsecase {
+	return &restructureUsecase{
+		loanRepo:         loanRepo,
+		modificationRepo: modificationRepo,
+		loanUsecase:      loanUsecase,
+		ledger:           ledger,
+		history:          history,
+	}
+}
+
+// RestructureLoan regenerates the remaining schedule of an active, delinquent or defaulted
+// loan on new terms and records the schedule before and after as a LoanModification. A
+// delinquent loan that no longer has anything overdue is returned to active; a defaulted
+// one is returned to active, or to delinquent while something is still overdue.
+func (uc *restructureUsecase) RestructureLoan(loanID uint, terms domain.RestructureTerms, modifiedBy primitive.ObjectID) (domain.LoanModification, error) {
+	terms.Reason = strings.TrimSpace(terms.Reason)
+	if err := terms.Validate(); err != nil {
+		return domain.LoanModification{}, err
+	}
+
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.LoanModification{}, err
+	}
+	if loan.Status != domain.LoanStatusActive && loan.Status != domain.LoanStatusDelinquent && loan.Status != domain.LoanStatusDefaulted {
+		return domain.LoanModification{}, domain.ErrLoanNotRestructurable
+	}
+
+	now := time.Now()
+	before := restructuredState(loan)
+	modification := domain.LoanModification{
+		ID:               primitive.NewObjectID(),
+		LoanID:           loan.ID,
+		Terms:            terms,
+		PreviousTerm:     loan.Term,
+		PreviousRate:     loan.InterestRate,
+		PreviousSchedule: loan.Schedule,
+		ModifiedBy:       modifiedBy,
+		CreatedAt:        now,
+	}
+
+	if terms.InterestRate != nil {
+		loan.InterestRate = *terms.InterestRate
+	}
+	result, err := rescheduleLoan(loan, terms, now)
+	if err != nil {
+		return domain.LoanModification{}, err
+	}
+
+	// Capitalized interest first settles what the accrual job has already recognised.
+	interestReceivable := result.Interest.Min(loan.AccruedInterest)
+	loan.AccruedInterest = loan.AccruedInterest.Sub(interestReceivable)
+	loan.Schedule = result.Schedule
+	loan.Term = len(result.Schedule)
+	loan.OutstandingBalance = outstandingBalance(loan)
+	loan.DaysPastDue = daysPastDue(loan.Schedule, now)
+	loan.AgingBucket = domain.AgingBucketFor(loan.DaysPastDue)
+	loan.UpdatedAt = now
+
+	modification.NewTerm = loan.Term
+	modification.NewRate = loan.InterestRate
+	modification.CapitalizedAmount = result.Interest.Add(result.Fees)
+	modification.NewSchedule = loan.Schedule
+
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return domain.LoanModification{}, err
+	}
+	if err := uc.modificationRepo.CreateModification(modification); err != nil {
+		return domain.LoanModification{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{
+		LoanID:  loan.ID,
+		Type:    domain.LoanEventTermsChanged,
+		ActorID: modifiedBy,
+		Before:  before,
+		After:   restructuredState(loan),
+		Detail:  terms.Reason,
+	}); err != nil {
+		return domain.LoanModification{}, err
+	}
+
+	if modification.CapitalizedAmount.IsPositive() {
+		if err := uc.ledger.PostCapitalization(loan.ID, interestReceivable, result.Interest.Sub(interestReceivable), result.Fees, modification.ID.Hex()); err != nil {
+			return domain.LoanModification{}, err
+		}
+	}
+
+	if next := restructuredStatus(loan.Status, loan.DaysPastDue); next != loan.Status {
+		if err := uc.loanUsecase.TransitionLoan(&loan, next, modifiedBy); err != nil {
+			return domain.LoanModification{}, err
+		}
+	}
+
+	return modification, nil
+}
+
+// GetLoanModifications retrieves the restructurings of a loan, oldest first.
+func (uc *restructureUsecase) GetLoanModifications(loanID uint) ([]domain.LoanModification, error) {
+	if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+		return nil, err
+	}
+
+	return uc.modificationRepo.GetModificationsByLoan(loanID)
+}
+
+// restructuredStatus returns the state of a restructured loan that is daysPastDue behind on
+// its new schedule. Only a restructuring brings a defaulted loan back.
+func restructuredStatus(status domain.LoanStatus, daysPastDue int) domain.LoanStatus {
+	switch {
+	case status == domain.LoanStatusActive:
+		return status
+	case daysPastDue == 0:
+		return domain.LoanStatusActive
+	}
+	return domain.LoanStatusDelinquent
+}
+
+// restructuredState returns the term, rate and balance of a loan, what a restructuring changes.
+func restructuredState(loan domain.Loan) *domain.LoanState {
+	balance := loan.OutstandingBalance
+	return &domain.LoanState{Term: loan.Term, InterestRate: loan.InterestRate, OutstandingBalance: &balance}
+}
+
+// rescheduled is a restructured schedule and the overdue amounts it capitalized.
+type rescheduled struct {
+	Schedule []domain.Installment
+	Interest domain.Money // overdue interest added to the principal
+	Fees     domain.Money // overdue fees and penalties added to the principal
+}
+
+// rescheduleLoan rebuilds the schedule of a loan at its interest rate on terms. Settled
+// installments are kept. Overdue ones are kept as they are unless their arrears are
+// capitalized, in which case they are closed at what was paid and the rest joins the
+// principal. Installments not yet due are replaced by a new schedule over the same number
+// of installments plus the extension, which starts after the payment holiday; fees still
+// due move to its first installment along with the interest of the holiday periods.
+func rescheduleLoan(loan domain.Loan, terms domain.RestructureTerms, now time.Time) (rescheduled, error) {
+	zero := loan.Amount.Zero()
+	result := rescheduled{Interest: zero, Fees: zero}
+	principal, fees, penalties := zero, zero, zero
+
+	var kept []domain.Installment
+	var periodStart time.Time
+	remaining := 0
+	for _, inst := range loan.Schedule {
+		switch {
+		case inst.IsPaid():
+			kept = append(kept, inst)
+			periodStart = inst.DueDate
+		case !inst.DueDate.After(now):
+			periodStart = inst.DueDate
+			if !terms.CapitalizeArrears {
+				kept = append(kept, inst)
+				continue
+			}
+			principal = principal.Add(inst.PrincipalDue())
+			result.Interest = result.Interest.Add(inst.InterestDue())
+			result.Fees = result.Fees.Add(inst.FeesDue()).Add(inst.PenaltiesDue())
+			kept = append(kept, closeInstallment(inst, now))
+		default:
+			remaining++
+			principal = principal.Add(inst.PrincipalDue())
+			fees = fees.Add(inst.FeesDue())
+			penalties = penalties.Add(inst.PenaltiesDue())
+			if inst.PrincipalPaid.Add(inst.InterestPaid).Add(inst.FeesPaid).Add(inst.PenaltiesPaid).IsPositive() {
+				kept = append(kept, closeInstallment(inst, now))
+			}
+		}
+	}
+
+	if remaining+terms.ExtendTerm < 1 {
+		return rescheduled{}, domain.ErrTermExtensionRequired
+	}
+	base := principal.Add(result.Interest).Add(result.Fees)
+	if !base.IsPositive() {
+		return rescheduled{}, domain.ErrNothingToRestructure
+	}
+	if periodStart.IsZero() {
+		// Nothing has fallen due yet: the current period started one period before the first due date.
+		periodStart = loan.RepaymentFrequency.DueDate(loan.Schedule[0].DueDate, -1)
+	}
+
+	next := loan
+	next.Amount = base
+	next.Term = remaining + terms.ExtendTerm
+	next.OriginationFee = zero
+	schedule, err := generateSchedule(next, loan.RepaymentFrequency.DueDate(periodStart, terms.PaymentHoliday))
+	if err != nil {
+		return rescheduled{}, err
+	}
+
+	periodicRate := new(big.Rat).Quo(domain.PercentRate(loan.InterestRate), big.NewRat(int64(loan.RepaymentFrequency.PeriodsPerYear()), 1))
+	holidayInterest := base.Mul(new(big.Rat).Mul(periodicRate, big.NewRat(int64(terms.PaymentHoliday), 1)))
+
+	first := &schedule[0]
+	first.Interest = first.Interest.Add(holidayInterest)
+	first.Fees = first.Fees.Add(fees)
+	first.Penalties = first.Penalties.Add(penalties)
+	first.Total = first.Total.Add(holidayInterest).Add(fees)
+	for i := range schedule {
+		schedule[i].Number = len(kept) + i + 1
+	}
+
+	result.Schedule = append(kept, schedule...)
+	return result, nil
+}
+
+// closeInstallment settles an installment at what has been paid on it, once the rest has
+// moved to a new schedule.
+func closeInstallment(inst domain.Installment, now time.Time) domain.Installment {
+	inst.Principal = inst.PrincipalPaid
+	inst.Interest = inst.InterestPaid
+	inst.Fees = inst.FeesPaid
+	inst.Penalties = inst.PenaltiesPaid
+	inst.Total = inst.Principal.Add(inst.Interest).Add(inst.Fees)
+	inst.PaidAt = &now
+	return inst
+}
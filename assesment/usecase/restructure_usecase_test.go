@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryModifications keeps loan modifications in memory in the order they were made.
+type memoryModifications struct {
+	domain.ModificationRepository
+	modifications []domain.LoanModification
+}
+
+func (r *memoryModifications) CreateModification(modification domain.LoanModification) error {
+	r.modifications = append(r.modifications, modification)
+	return nil
+}
+
+// restructurableLoan returns a loan of 400.00 USD at 12% in four monthly installments of
+// 100.00 USD principal and 1.00 USD interest, due on the 15th from January 2024.
+func restructurableLoan(status domain.LoanStatus) domain.Loan {
+	loan := domain.Loan{
+		ID:                 5,
+		Amount:             usd(40000),
+		Term:               4,
+		InterestRate:       12,
+		RepaymentFrequency: domain.FrequencyMonthly,
+		AmortizationMethod: domain.AmortizationAnnuity,
+		AccruedInterest:    usd(0),
+		Status:             status,
+	}
+	for number := 1; number <= 4; number++ {
+		loan.Schedule = append(loan.Schedule, dueInstallment(number, 10000, 100, 0, 0))
+	}
+	return loan
+}
+
+func TestRescheduleLoan(t *testing.T) {
+	now := time.Date(2024, time.March, 20, 9, 0, 0, 0, time.UTC)
+	settled := restructurableLoan(domain.LoanStatusActive)
+	for i := range settled.Schedule {
+		settled.Schedule[i].PrincipalPaid, settled.Schedule[i].InterestPaid = usd(10000), usd(100)
+		settled.Schedule[i].PaidAt = &now
+	}
+	overdue := restructurableLoan(domain.LoanStatusDefaulted)
+	overdue.Schedule = overdue.Schedule[:3]
+
+	tests := []struct {
+		name         string
+		loan         domain.Loan
+		terms        domain.RestructureTerms
+		installments int
+		capitalized  domain.Money
+		firstNewDue  time.Time
+		newPrincipal domain.Money
+		keptOverdue  bool
+		wantErr      error
+	}{
+		{"extension keeps the arrears due", restructurableLoan(domain.LoanStatusDelinquent),
+			domain.RestructureTerms{ExtendTerm: 2}, 6, usd(0), utcDay(2024, time.April, 15), usd(10000), true, nil},
+		{"capitalized arrears join the principal", restructurableLoan(domain.LoanStatusDefaulted),
+			domain.RestructureTerms{ExtendTerm: 2, CapitalizeArrears: true}, 6, usd(300), utcDay(2024, time.April, 15), usd(40300), false, nil},
+		{"payment holiday moves the new schedule", restructurableLoan(domain.LoanStatusActive),
+			domain.RestructureTerms{PaymentHoliday: 1}, 4, usd(0), utcDay(2024, time.May, 15), usd(10000), true, nil},
+		{"capitalizing everything needs an extension", overdue,
+			domain.RestructureTerms{CapitalizeArrears: true}, 0, usd(0), time.Time{}, usd(0), false, domain.ErrTermExtensionRequired},
+		{"nothing left to restructure", settled,
+			domain.RestructureTerms{ExtendTerm: 1}, 0, usd(0), time.Time{}, usd(0), false, domain.ErrNothingToRestructure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := rescheduleLoan(tt.loan, tt.terms, now)
+			if err != tt.wantErr {
+				t.Fatalf("rescheduleLoan = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(result.Schedule) != tt.installments {
+				t.Fatalf("got %d installments, want %d", len(result.Schedule), tt.installments)
+			}
+			if capitalized := result.Interest.Add(result.Fees); capitalized != tt.capitalized {
+				t.Errorf("capitalized %s, want %s", capitalized, tt.capitalized)
+			}
+			for i, inst := range result.Schedule {
+				if inst.Number != i+1 {
+					t.Errorf("installment %d is numbered %d", i+1, inst.Number)
+				}
+			}
+
+			overdue := result.Schedule[:3]
+			for _, inst := range overdue {
+				if inst.IsPaid() == tt.keptOverdue {
+					t.Errorf("overdue installment %d paid %v, want kept due %v", inst.Number, inst.IsPaid(), tt.keptOverdue)
+				}
+			}
+
+			newSchedule := result.Schedule[3:]
+			if !newSchedule[0].DueDate.Equal(tt.firstNewDue) {
+				t.Errorf("new schedule starts %s, want %s", newSchedule[0].DueDate, tt.firstNewDue)
+			}
+			principal := usd(0)
+			for _, inst := range newSchedule {
+				principal = principal.Add(inst.Principal)
+			}
+			if principal != tt.newPrincipal {
+				t.Errorf("new schedule repays %s of principal, want %s", principal, tt.newPrincipal)
+			}
+		})
+	}
+}
+
+func TestRestructureLoanReturnsTheLoanToServicing(t *testing.T) {
+	now := time.Now()
+	dueDays := []int{-45, -15, 15, 45}
+
+	tests := []struct {
+		name       string
+		status     domain.LoanStatus
+		capitalize bool
+		want       domain.LoanStatus
+		wantErr    error
+	}{
+		{"defaulted loan caught up", domain.LoanStatusDefaulted, true, domain.LoanStatusActive, nil},
+		{"defaulted loan still behind", domain.LoanStatusDefaulted, false, domain.LoanStatusDelinquent, nil},
+		{"delinquent loan caught up", domain.LoanStatusDelinquent, true, domain.LoanStatusActive, nil},
+		{"delinquent loan still behind", domain.LoanStatusDelinquent, false, domain.LoanStatusDelinquent, nil},
+		{"active loan", domain.LoanStatusActive, false, domain.LoanStatusActive, nil},
+		{"paid off loan", domain.LoanStatusPaidOff, true, domain.LoanStatusPaidOff, domain.ErrLoanNotRestructurable},
+		{"written off loan", domain.LoanStatusWrittenOff, true, domain.LoanStatusWrittenOff, domain.ErrLoanNotRestructurable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := restructurableLoan(tt.status)
+			for i, days := range dueDays {
+				loan.Schedule[i].DueDate = accrualDay(now).AddDate(0, 0, days)
+			}
+			loans := newMemoryLoans(loan)
+			modifications := &memoryModifications{}
+			history := &memoryHistory{}
+			ledger := NewLedgerUsecase(&memoryLedger{})
+			loanUsecase := NewLoanUsecase(loans, nil, nil, nil, nil, ledger, nil, nil, nil, domain.DualApprovalPolicy{}, nil, nil, history)
+			uc := NewRestructureUsecase(loans, modifications, loanUsecase, ledger, history)
+
+			terms := domain.RestructureTerms{Reason: "lost their job", ExtendTerm: 2, CapitalizeArrears: tt.capitalize}
+			modification, err := uc.RestructureLoan(loan.ID, terms, primitive.NewObjectID())
+			if err != tt.wantErr {
+				t.Fatalf("RestructureLoan = %v, want %v", err, tt.wantErr)
+			}
+
+			stored, _ := loans.GetLoanByID(loan.ID)
+			if stored.Status != tt.want {
+				t.Errorf("loan is %s, want %s", stored.Status, tt.want)
+			}
+			if err != nil {
+				if len(modifications.modifications) != 0 {
+					t.Errorf("recorded a modification of a loan that was not restructured")
+				}
+				return
+			}
+
+			if modification.PreviousTerm != 4 || modification.NewTerm != len(stored.Schedule) {
+				t.Errorf("term went from %d to %d, want from 4 to %d", modification.PreviousTerm, modification.NewTerm, len(stored.Schedule))
+			}
+			if (stored.DaysPastDue == 0) != tt.capitalize {
+				t.Errorf("loan is %d days past due after restructuring", stored.DaysPastDue)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type reviewUsecase struct {
+	loanRepo    domain.LoanRepository
+	userRepo    domain.UserRepository
+	noteRepo    domain.ReviewNoteRepository
+	loanUsecase domain.LoanUsecase
+	history     domain.LoanHistoryRepository
+	sla         time.Duration
+}
+
+// NewReviewUsecase creates a new instance of ReviewUsecase that flags applications waiting
+// longer than sla. Status changes go through loanUsecase so they follow the loan state machine.
+func NewReviewUsecase(loanRepo domain.LoanRepository, userRepo domain.UserRepository, noteRepo domain.ReviewNoteRepository, loanUsecase domain.LoanUsecase, history domain.LoanHistoryRepository, sla time.Duration) domain.ReviewUsecase {
+	if sla <= 0 {
+		sla = domain.DefaultReviewSLA
+	}
+	return &reviewUsecase{
+		loanRepo:    loanRepo,
+		userRepo:    userRepo,
+		noteRepo:    noteRepo,
+		loanUsecase: loanUsecase,
+		history:     history,
+		sla:         sla,
+	}
+}
+
+// GetReviewQueue lists the applications pending or under review, longest waiting first, with
+// their age and whether they are past the review SLA.
+func (uc *reviewUsecase) GetReviewQueue(filter domain.ReviewQueueFilter, now time.Time) ([]domain.ReviewQueueItem, error) {
+	loans, err := uc.loanRepo.GetLoansByStatus(domain.LoanStatusPending, domain.LoanStatusUnderReview)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := []domain.ReviewQueueItem{}
+	for _, loan := range loans {
+		reviewer := assignedReviewer(loan)
+		if filter.Unassigned && !reviewer.IsZero() {
+			continue
+		}
+		if !filter.ReviewerID.IsZero() && reviewer != filter.ReviewerID {
+			continue
+		}
+
+		age := now.Sub(loan.CreatedAt)
+		queue = append(queue, domain.ReviewQueueItem{
+			Loan:             loan,
+			AgeHours:         math.Round(age.Hours()*10) / 10,
+			OverSLA:          age > uc.sla,
+			AwaitingBorrower: loan.OpenInfoRequests() > 0,
+			AwaitingApproval: len(loan.Approvals()) == 1,
+		})
+	}
+	return queue, nil
+}
+
+// AssignReviewer lets an admin assign an application in the queue to a reviewer, replacing
+// any previous assignment. A pending application moves to under review.
+func (uc *reviewUsecase) AssignReviewer(loanID uint, reviewerID primitive.ObjectID, assignedBy primitive.ObjectID) (domain.Loan, error) {
+	loan, err := uc.queuedLoan(loanID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	reviewer, err := uc.userRepo.GetUserByID(reviewerID)
+	if err != nil || reviewer.Role != domain.ReviewerRole {
+		return domain.Loan{}, domain.ErrInvalidReviewer
+	}
+
+	return uc.assign(loan, reviewerID, assignedBy, time.Now())
+}
+
+// ClaimLoan lets a reviewer take an unassigned application from the queue.
+func (uc *reviewUsecase) ClaimLoan(loanID uint, reviewerID primitive.ObjectID) (domain.Loan, error) {
+	loan, err := uc.queuedLoan(loanID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	if reviewer := assignedReviewer(loan); !reviewer.IsZero() && reviewer != reviewerID {
+		return domain.Loan{}, domain.ErrNotAssignedReviewer
+	}
+
+	return uc.assign(loan, reviewerID, reviewerID, time.Now())
+}
+
+// AssignUnassigned hands the unassigned applications of the queue to the reviewers in turn,
+// oldest application first, continuing after the reviewer who received the last assignment.
+// It returns how many applications were assigned; one that fails to be assigned is reported
+// in the error and does not stop the others.
+func (uc *reviewUsecase) AssignUnassigned(now time.Time) (int, error) {
+	queue, err := uc.GetReviewQueue(domain.ReviewQueueFilter{Unassigned: true}, now)
+	if err != nil || len(queue) == 0 {
+		return 0, err
+	}
+
+	reviewers, err := uc.userRepo.GetUsersByRole(domain.ReviewerRole)
+	if err != nil {
+		return 0, err
+	}
+	if len(reviewers) == 0 {
+		return 0, domain.ErrNoReviewers
+	}
+	slices.SortFunc(reviewers, func(a, b domain.User) int { return bytes.Compare(a.ID[:], b.ID[:]) })
+
+	next := 0
+	last, err := uc.loanRepo.GetLastAssignedLoan()
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, err
+	}
+	if err == nil {
+		if i := slices.IndexFunc(reviewers, func(u domain.User) bool { return u.ID == assignedReviewer(last) }); i >= 0 {
+			next = i + 1
+		}
+	}
+
+	assigned := 0
+	var errs []error
+	for _, item := range queue {
+		reviewer := reviewers[next%len(reviewers)]
+		if _, err := uc.assign(item.Loan, reviewer.ID, primitive.NilObjectID, now); err != nil {
+			errs = append(errs, fmt.Errorf("loan %d: %w", item.Loan.ID, err))
+			continue
+		}
+		next++
+		assigned++
+	}
+
+	return assigned, errors.Join(errs...)
+}
+
+// AddReviewNote records an internal note on a loan and in its history.
+func (uc *reviewUsecase) AddReviewNote(loanID uint, text string, authorID primitive.ObjectID) (domain.ReviewNote, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return domain.ReviewNote{}, domain.ErrReviewNoteRequired
+	}
+	if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+		return domain.ReviewNote{}, err
+	}
+
+	note := domain.ReviewNote{
+		ID:        primitive.NewObjectID(),
+		LoanID:    loanID,
+		AuthorID:  authorID,
+		Text:      text,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.noteRepo.CreateReviewNote(note); err != nil {
+		return domain.ReviewNote{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{LoanID: loanID, Type: domain.LoanEventNoteAdded, ActorID: authorID, Detail: text}); err != nil {
+		return domain.ReviewNote{}, err
+	}
+	return note, nil
+}
+
+// GetReviewNotes retrieves the internal notes of a loan, oldest first.
+func (uc *reviewUsecase) GetReviewNotes(loanID uint) ([]domain.ReviewNote, error) {
+	if _, err := uc.loanRepo.GetLoanByID(loanID); err != nil {
+		return nil, err
+	}
+
+	return uc.noteRepo.GetReviewNotesByLoan(loanID)
+}
+
+// RequestInformation asks the borrower of an application in the queue for more information.
+// Only the assigned reviewer, or any reviewer while the application is unassigned, can ask.
+func (uc *reviewUsecase) RequestInformation(loanID uint, message string, reviewerID primitive.ObjectID) (domain.InfoRequest, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return domain.InfoRequest{}, domain.ErrInfoRequestMessage
+	}
+	loan, err := uc.queuedLoan(loanID)
+	if err != nil {
+		return domain.InfoRequest{}, err
+	}
+	if err := checkReviewer(loan, reviewerID); err != nil {
+		return domain.InfoRequest{}, err
+	}
+
+	request := domain.InfoRequest{
+		ID:          primitive.NewObjectID(),
+		Message:     message,
+		RequestedBy: reviewerID,
+		RequestedAt: time.Now(),
+	}
+	if loan.Review == nil {
+		loan.Review = &domain.LoanReview{}
+	}
+	loan.Review.InfoRequests = append(loan.Review.InfoRequests, request)
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return domain.InfoRequest{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{LoanID: loan.ID, Type: domain.LoanEventInfoRequested, ActorID: reviewerID, Detail: message}); err != nil {
+		return domain.InfoRequest{}, err
+	}
+	return request, nil
+}
+
+// RespondToInformationRequest records the borrower's answer to an information request on
+// their application. Each request is answered once.
+func (uc *reviewUsecase) RespondToInformationRequest(loanID uint, requestID primitive.ObjectID, response string, userID primitive.ObjectID) (domain.InfoRequest, error) {
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return domain.InfoRequest{}, domain.ErrInfoResponseRequired
+	}
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.InfoRequest{}, err
+	}
+	if loan.UserID != userID {
+		return domain.InfoRequest{}, domain.ErrNotLoanOwner
+	}
+	if loan.Review == nil {
+		return domain.InfoRequest{}, domain.ErrInfoRequestNotFound
+	}
+
+	for i := range loan.Review.InfoRequests {
+		request := &loan.Review.InfoRequests[i]
+		if request.ID != requestID {
+			continue
+		}
+		if request.RespondedAt != nil {
+			return domain.InfoRequest{}, domain.ErrInfoRequestAnswered
+		}
+
+		now := time.Now()
+		request.Response = response
+		request.RespondedAt = &now
+		if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+			return domain.InfoRequest{}, err
+		}
+		if err := recordEvent(uc.history, domain.LoanEvent{LoanID: loan.ID, Type: domain.LoanEventInfoProvided, ActorID: userID, Detail: response}); err != nil {
+			return domain.InfoRequest{}, err
+		}
+		return *request, nil
+	}
+	return domain.InfoRequest{}, domain.ErrInfoRequestNotFound
+}
+
+// queuedLoan loads an application that is in the underwriting queue.
+func (uc *reviewUsecase) queuedLoan(loanID uint) (domain.Loan, error) {
+	loan, err := uc.loanRepo.GetLoanByID(loanID)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+	if loan.Status != domain.LoanStatusPending && loan.Status != domain.LoanStatusUnderReview {
+		return domain.Loan{}, domain.ErrLoanNotInReviewQueue
+	}
+	return loan, nil
+}
+
+// assign gives an application to reviewerID, moving it from pending to under review.
+func (uc *reviewUsecase) assign(loan domain.Loan, reviewerID, assignedBy primitive.ObjectID, now time.Time) (domain.Loan, error) {
+	if loan.Status == domain.LoanStatusPending {
+		if err := uc.loanUsecase.TransitionLoan(&loan, domain.LoanStatusUnderReview, assignedBy); err != nil {
+			return domain.Loan{}, err
+		}
+	}
+
+	before := &domain.LoanState{ReviewerID: assignedReviewer(loan)}
+	if loan.Review == nil {
+		loan.Review = &domain.LoanReview{}
+	}
+	loan.Review.ReviewerID = reviewerID
+	loan.Review.AssignedBy = assignedBy
+	loan.Review.AssignedAt = &now
+	if err := uc.loanRepo.UpdateLoan(&loan); err != nil {
+		return domain.Loan{}, err
+	}
+	if err := recordEvent(uc.history, domain.LoanEvent{
+		LoanID:  loan.ID,
+		Type:    domain.LoanEventReviewerAssigned,
+		ActorID: assignedBy,
+		Before:  before,
+		After:   &domain.LoanState{ReviewerID: reviewerID},
+	}); err != nil {
+		return domain.Loan{}, err
+	}
+	return loan, nil
+}
+
+// assignedReviewer returns the reviewer a loan is assigned to, or the zero ID.
+func assignedReviewer(loan domain.Loan) primitive.ObjectID {
+	if loan.Review == nil {
+		return primitive.NilObjectID
+	}
+	return loan.Review.ReviewerID
+}
+
+// checkReviewer checks that reviewerID may act on a loan: it is assigned to them or to nobody.
+func checkReviewer(loan domain.Loan, reviewerID primitive.ObjectID) error {
+	if reviewer := assignedReviewer(loan); !reviewer.IsZero() && reviewer != reviewerID {
+		return domain.ErrNotAssignedReviewer
+	}
+	return nil
+}
+
+// recordApproval records the sign-off of adminID on the current terms of a loan.
+func recordApproval(loan *domain.Loan, adminID primitive.ObjectID, reason string, now time.Time) {
+	if loan.Review == nil {
+		loan.Review = &domain.LoanReview{}
+	}
+	loan.Review.Approvals = append(loan.Review.Approvals, domain.Approval{
+		AdminID:    adminID,
+		Reason:     strings.TrimSpace(reason),
+		ApprovedAt: now,
+	})
+}
+
+// recordDecision records on a loan the reviewer who approved or rejected it and why.
+func recordDecision(loan *domain.Loan, reviewerID primitive.ObjectID, reason string, now time.Time) {
+	if loan.Review == nil {
+		loan.Review = &domain.LoanReview{}
+	}
+	loan.Review.Decision = loan.Status
+	loan.Review.DecisionReason = strings.TrimSpace(reason)
+	loan.Review.DecidedBy = reviewerID
+	loan.Review.DecidedAt = &now
+}
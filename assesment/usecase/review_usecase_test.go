@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryReviewNotes keeps review notes in memory in the order they were written.
+type memoryReviewNotes struct {
+	notes []domain.ReviewNote
+}
+
+func (r *memoryReviewNotes) CreateReviewNote(note domain.ReviewNote) error {
+	r.notes = append(r.notes, note)
+	return nil
+}
+
+func (r *memoryReviewNotes) GetReviewNotesByLoan(loanID uint) ([]domain.ReviewNote, error) {
+	notes := []domain.ReviewNote{}
+	for _, note := range r.notes {
+		if note.LoanID == loanID {
+			notes = append(notes, note)
+		}
+	}
+	return notes, nil
+}
+
+// reviewFixture is an underwriting queue with two admin reviewers and a borrower.
+type reviewFixture struct {
+	borrower, first, second domain.User
+	loans                   *memoryLoans
+	notes                   *memoryReviewNotes
+	history                 *memoryHistory
+	uc                      domain.ReviewUsecase
+}
+
+func newReviewFixture(loans ...domain.Loan) reviewFixture {
+	f := reviewFixture{
+		borrower: domain.User{ID: primitive.NewObjectID(), Role: "user"},
+		first:    domain.User{ID: primitive.NewObjectID(), Role: domain.ReviewerRole},
+		second:   domain.User{ID: primitive.NewObjectID(), Role: domain.ReviewerRole},
+		loans:    newMemoryLoans(loans...),
+		notes:    &memoryReviewNotes{},
+		history:  &memoryHistory{},
+	}
+	loanUsecase := NewLoanUsecase(f.loans, nil, nil, nil, nil, nil, nil, nil, nil, domain.DualApprovalPolicy{}, nil, nil, f.history)
+	f.uc = NewReviewUsecase(f.loans, newMemoryUsers(f.borrower, f.first, f.second), f.notes, loanUsecase, f.history, 24*time.Hour)
+	return f
+}
+
+func queuedApplication(id uint, status domain.LoanStatus, createdAt time.Time) domain.Loan {
+	return domain.Loan{ID: id, Amount: usd(100000), Term: 12, InterestRate: 10, Status: status, CreatedAt: createdAt}
+}
+
+func TestGetReviewQueue(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	reviewer := primitive.NewObjectID()
+
+	waiting := queuedApplication(1, domain.LoanStatusPending, now.Add(-30*time.Hour))
+	asked := queuedApplication(2, domain.LoanStatusUnderReview, now.Add(-90*time.Minute))
+	asked.Review = &domain.LoanReview{ReviewerID: reviewer, InfoRequests: []domain.InfoRequest{{Message: "latest payslip"}}}
+	signed := queuedApplication(3, domain.LoanStatusUnderReview, now.Add(-5*time.Hour))
+	signed.Review = &domain.LoanReview{ReviewerID: primitive.NewObjectID(), Approvals: []domain.Approval{{AdminID: primitive.NewObjectID()}}}
+	decided := queuedApplication(4, domain.LoanStatusApproved, now.Add(-72*time.Hour))
+	f := newReviewFixture(waiting, asked, signed, decided)
+
+	tests := []struct {
+		name   string
+		filter domain.ReviewQueueFilter
+		want   []uint
+	}{
+		{"whole queue, longest waiting first", domain.ReviewQueueFilter{}, []uint{1, 3, 2}},
+		{"one reviewer", domain.ReviewQueueFilter{ReviewerID: reviewer}, []uint{2}},
+		{"unassigned", domain.ReviewQueueFilter{Unassigned: true}, []uint{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue, err := f.uc.GetReviewQueue(tt.filter, now)
+			if err != nil {
+				t.Fatalf("GetReviewQueue: %v", err)
+			}
+			got := []uint{}
+			for _, item := range queue {
+				got = append(got, item.Loan.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("queue %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("queue %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	queue, _ := f.uc.GetReviewQueue(domain.ReviewQueueFilter{}, now)
+	want := map[uint]domain.ReviewQueueItem{
+		1: {AgeHours: 30, OverSLA: true},
+		2: {AgeHours: 1.5, AwaitingBorrower: true},
+		3: {AgeHours: 5, AwaitingApproval: true},
+	}
+	for _, item := range queue {
+		w := want[item.Loan.ID]
+		if item.AgeHours != w.AgeHours || item.OverSLA != w.OverSLA || item.AwaitingBorrower != w.AwaitingBorrower || item.AwaitingApproval != w.AwaitingApproval {
+			t.Errorf("loan %d waited %gh (over SLA %v, awaiting borrower %v, awaiting approval %v), want %gh (%v, %v, %v)",
+				item.Loan.ID, item.AgeHours, item.OverSLA, item.AwaitingBorrower, item.AwaitingApproval,
+				w.AgeHours, w.OverSLA, w.AwaitingBorrower, w.AwaitingApproval)
+		}
+	}
+}
+
+func TestAssignReviewerAndClaimLoan(t *testing.T) {
+	f := newReviewFixture(
+		queuedApplication(1, domain.LoanStatusPending, time.Now()),
+		queuedApplication(2, domain.LoanStatusApproved, time.Now()),
+	)
+	admin := primitive.NewObjectID()
+
+	if _, err := f.uc.AssignReviewer(1, f.borrower.ID, admin); err != domain.ErrInvalidReviewer {
+		t.Errorf("assigning a borrower = %v, want %v", err, domain.ErrInvalidReviewer)
+	}
+	if _, err := f.uc.AssignReviewer(1, primitive.NewObjectID(), admin); err != domain.ErrInvalidReviewer {
+		t.Errorf("assigning an unknown user = %v, want %v", err, domain.ErrInvalidReviewer)
+	}
+	if _, err := f.uc.AssignReviewer(2, f.first.ID, admin); err != domain.ErrLoanNotInReviewQueue {
+		t.Errorf("assigning an approved loan = %v, want %v", err, domain.ErrLoanNotInReviewQueue)
+	}
+
+	loan, err := f.uc.AssignReviewer(1, f.first.ID, admin)
+	if err != nil {
+		t.Fatalf("AssignReviewer: %v", err)
+	}
+	if loan.Status != domain.LoanStatusUnderReview || assignedReviewer(loan) != f.first.ID || loan.Review.AssignedBy != admin {
+		t.Errorf("loan is %s assigned to %s by %s, want under review assigned to the first reviewer by the admin",
+			loan.Status, assignedReviewer(loan).Hex(), loan.Review.AssignedBy.Hex())
+	}
+	last := f.history.events[len(f.history.events)-1]
+	if last.Type != domain.LoanEventReviewerAssigned || last.After == nil || last.After.ReviewerID != f.first.ID {
+		t.Errorf("last event %+v, want the assignment to the first reviewer", last)
+	}
+
+	if _, err := f.uc.ClaimLoan(1, f.second.ID); err != domain.ErrNotAssignedReviewer {
+		t.Errorf("claiming another reviewer's application = %v, want %v", err, domain.ErrNotAssignedReviewer)
+	}
+	if _, err := f.uc.AssignReviewer(1, f.second.ID, admin); err != nil {
+		t.Fatalf("reassigning: %v", err)
+	}
+	if stored, _ := f.loans.GetLoanByID(1); assignedReviewer(stored) != f.second.ID {
+		t.Errorf("loan assigned to %s, want the second reviewer", assignedReviewer(stored).Hex())
+	}
+}
+
+func TestAssignUnassignedTakesTurns(t *testing.T) {
+	start := time.Date(2024, time.March, 10, 9, 0, 0, 0, time.UTC)
+	f := newReviewFixture(queuedApplication(1, domain.LoanStatusPending, start.Add(-time.Hour)))
+
+	if assigned, err := f.uc.AssignUnassigned(start); err != nil || assigned != 1 {
+		t.Fatalf("first round assigned %d: %v", assigned, err)
+	}
+
+	f.loans.loans[2] = queuedApplication(2, domain.LoanStatusPending, start.Add(time.Minute))
+	f.loans.loans[3] = queuedApplication(3, domain.LoanStatusUnderReview, start.Add(2*time.Minute))
+	if assigned, err := f.uc.AssignUnassigned(start.Add(time.Hour)); err != nil || assigned != 2 {
+		t.Fatalf("second round assigned %d: %v", assigned, err)
+	}
+
+	want := map[uint]primitive.ObjectID{1: f.first.ID, 2: f.second.ID, 3: f.first.ID}
+	for id, reviewer := range want {
+		loan, _ := f.loans.GetLoanByID(id)
+		if assignedReviewer(loan) != reviewer || loan.Status != domain.LoanStatusUnderReview || !loan.Review.AssignedBy.IsZero() {
+			t.Errorf("loan %d is %s assigned to %s, want under review assigned round-robin to %s",
+				id, loan.Status, assignedReviewer(loan).Hex(), reviewer.Hex())
+		}
+	}
+
+	if assigned, err := f.uc.AssignUnassigned(start.Add(2 * time.Hour)); err != nil || assigned != 0 {
+		t.Errorf("nothing left to assign, assigned %d: %v", assigned, err)
+	}
+}
+
+func TestAssignUnassignedWithoutReviewers(t *testing.T) {
+	loans := newMemoryLoans(queuedApplication(1, domain.LoanStatusPending, time.Now()))
+	history := &memoryHistory{}
+	loanUsecase := NewLoanUsecase(loans, nil, nil, nil, nil, nil, nil, nil, nil, domain.DualApprovalPolicy{}, nil, nil, history)
+	uc := NewReviewUsecase(loans, newMemoryUsers(), &memoryReviewNotes{}, loanUsecase, history, 0)
+
+	if _, err := uc.AssignUnassigned(time.Now()); err != domain.ErrNoReviewers {
+		t.Errorf("AssignUnassigned = %v, want %v", err, domain.ErrNoReviewers)
+	}
+}
+
+func TestInformationRequests(t *testing.T) {
+	f := newReviewFixture()
+	loan := queuedApplication(1, domain.LoanStatusUnderReview, time.Now())
+	loan.UserID = f.borrower.ID
+	loan.Review = &domain.LoanReview{ReviewerID: f.first.ID}
+	f.loans.loans[1] = loan
+
+	if _, err := f.uc.RequestInformation(1, "  ", f.first.ID); err != domain.ErrInfoRequestMessage {
+		t.Errorf("request without a message = %v, want %v", err, domain.ErrInfoRequestMessage)
+	}
+	if _, err := f.uc.RequestInformation(1, "latest payslip", f.second.ID); err != domain.ErrNotAssignedReviewer {
+		t.Errorf("request by another reviewer = %v, want %v", err, domain.ErrNotAssignedReviewer)
+	}
+	request, err := f.uc.RequestInformation(1, " latest payslip ", f.first.ID)
+	if err != nil {
+		t.Fatalf("RequestInformation: %v", err)
+	}
+	if stored, _ := f.loans.GetLoanByID(1); stored.OpenInfoRequests() != 1 || request.Message != "latest payslip" {
+		t.Fatalf("loan has %d open requests for %q, want the payslip request", stored.OpenInfoRequests(), request.Message)
+	}
+
+	tests := []struct {
+		name      string
+		requestID primitive.ObjectID
+		response  string
+		userID    primitive.ObjectID
+		want      error
+	}{
+		{"no response", request.ID, " ", f.borrower.ID, domain.ErrInfoResponseRequired},
+		{"someone else", request.ID, "attached", f.second.ID, domain.ErrNotLoanOwner},
+		{"unknown request", primitive.NewObjectID(), "attached", f.borrower.ID, domain.ErrInfoRequestNotFound},
+		{"the borrower", request.ID, "attached", f.borrower.ID, nil},
+		{"answered again", request.ID, "attached again", f.borrower.ID, domain.ErrInfoRequestAnswered},
+	}
+	for _, tt := range tests {
+		if _, err := f.uc.RespondToInformationRequest(1, tt.requestID, tt.response, tt.userID); err != tt.want {
+			t.Errorf("%s: RespondToInformationRequest = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	stored, _ := f.loans.GetLoanByID(1)
+	if stored.OpenInfoRequests() != 0 || stored.Review.InfoRequests[0].Response != "attached" {
+		t.Errorf("request %+v, want answered by the borrower", stored.Review.InfoRequests[0])
+	}
+	types := []domain.LoanEventType{}
+	for _, event := range f.history.events {
+		types = append(types, event.Type)
+	}
+	if len(types) != 2 || types[0] != domain.LoanEventInfoRequested || types[1] != domain.LoanEventInfoProvided {
+		t.Errorf("recorded %v, want the request and its answer", types)
+	}
+}
+
+func TestAddReviewNote(t *testing.T) {
+	f := newReviewFixture(queuedApplication(1, domain.LoanStatusUnderReview, time.Now()))
+
+	if _, err := f.uc.AddReviewNote(1, " ", f.first.ID); err != domain.ErrReviewNoteRequired {
+		t.Errorf("empty note = %v, want %v", err, domain.ErrReviewNoteRequired)
+	}
+	if _, err := f.uc.AddReviewNote(1, " income checked with employer ", f.first.ID); err != nil {
+		t.Fatalf("AddReviewNote: %v", err)
+	}
+
+	notes, err := f.uc.GetReviewNotes(1)
+	if err != nil {
+		t.Fatalf("GetReviewNotes: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Text != "income checked with employer" || notes[0].AuthorID != f.first.ID {
+		t.Errorf("notes %+v, want the reviewer's note", notes)
+	}
+	if len(f.history.events) != 1 || f.history.events[0].Type != domain.LoanEventNoteAdded {
+		t.Errorf("recorded %+v, want the note", f.history.events)
+	}
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"math/big"
+	"time"
+)
+
+// generateSchedule builds the amortization schedule of a loan whose first
+// installment falls one period after start.
+func generateSchedule(loan domain.Loan, start time.Time) ([]domain.Installment, error) {
+	if err := validateLoanTerms(loan); err != nil {
+		return nil, err
+	}
+
+	periodicRate := new(big.Rat).Quo(
+		domain.PercentRate(loan.InterestRate),
+		big.NewRat(int64(loan.RepaymentFrequency.PeriodsPerYear()), 1),
+	)
+
+	var schedule []domain.Installment
+	switch loan.AmortizationMethod {
+	case domain.AmortizationFlat:
+		schedule = flatSchedule(loan, start, periodicRate)
+	default:
+		schedule = annuitySchedule(loan, start, periodicRate)
+	}
+
+	// Origination fees are collected with the first installment.
+	if loan.OriginationFee.IsPositive() {
+		schedule[0].Fees = loan.OriginationFee
+		schedule[0].Total = schedule[0].Total.Add(loan.OriginationFee)
+	}
+	return schedule, nil
+}
+
+// annuitySchedule spreads the loan over equal installments, charging interest on the remaining balance.
+func annuitySchedule(loan domain.Loan, start time.Time, rate *big.Rat) []domain.Installment {
+	n := loan.Term
+	payment := loan.Amount.Mul(annuityFactor(rate, n))
+
+	schedule := make([]domain.Installment, 0, n)
+	balance := loan.Amount
+	for i := 1; i <= n; i++ {
+		interest := balance.Mul(rate)
+		principal := payment.Sub(interest)
+		// The last installment settles whatever rounding left on the balance.
+		if i == n || principal.Cmp(balance) > 0 {
+			principal = balance
+		}
+		balance = balance.Sub(principal)
+
+		schedule = append(schedule, newInstallment(loan, start, i, principal, interest, balance))
+	}
+	return schedule
+}
+
+// flatSchedule charges interest on the original principal for the whole term and splits
+// principal and interest evenly across installments.
+func flatSchedule(loan domain.Loan, start time.Time, rate *big.Rat) []domain.Installment {
+	n := loan.Term
+	totalInterest := loan.Amount.Mul(new(big.Rat).Mul(rate, big.NewRat(int64(n), 1)))
+	principalPart := loan.Amount.Div(int64(n))
+	interestPart := totalInterest.Div(int64(n))
+
+	schedule := make([]domain.Installment, 0, n)
+	balance := loan.Amount
+	interestLeft := totalInterest
+	for i := 1; i <= n; i++ {
+		principal, interest := principalPart.Min(balance), interestPart.Min(interestLeft)
+		// The last installment settles whatever rounding left on the balance.
+		if i == n {
+			principal, interest = balance, interestLeft
+		}
+		balance = balance.Sub(principal)
+		interestLeft = interestLeft.Sub(interest)
+
+		schedule = append(schedule, newInstallment(loan, start, i, principal, interest, balance))
+	}
+	return schedule
+}
+
+// newInstallment builds the n-th installment of a schedule.
+func newInstallment(loan domain.Loan, start time.Time, n int, principal, interest, balance domain.Money) domain.Installment {
+	zero := loan.Amount.Zero()
+	return domain.Installment{
+		Number:           n,
+		DueDate:          loan.RepaymentFrequency.DueDate(start, n),
+		Principal:        principal,
+		Interest:         interest,
+		Fees:             zero,
+		Total:            principal.Add(interest),
+		RemainingBalance: balance,
+		PrincipalPaid:    zero,
+		InterestPaid:     zero,
+		FeesPaid:         zero,
+		Penalties:        zero,
+		PenaltiesPaid:    zero,
+	}
+}
+
+// annuityFactor returns the share of the principal repaid by each of n equal installments:
+// rate / (1 - (1 + rate)^-n), or 1/n for an interest-free loan.
+func annuityFactor(rate *big.Rat, n int) *big.Rat {
+	if rate.Sign() == 0 {
+		return big.NewRat(1, int64(n))
+	}
+
+	growth := new(big.Rat).Add(big.NewRat(1, 1), rate)
+	compounded := big.NewRat(1, 1)
+	for i := 0; i < n; i++ {
+		compounded.Mul(compounded, growth)
+	}
+
+	// rate / (1 - 1/compounded) == rate * compounded / (compounded - 1)
+	denominator := new(big.Rat).Sub(compounded, big.NewRat(1, 1))
+	return new(big.Rat).Quo(new(big.Rat).Mul(rate, compounded), denominator)
+}
+
+// validateLoanTerms checks the fields a schedule is generated from.
+func validateLoanTerms(loan domain.Loan) error {
+	if err := loan.Amount.Validate(); err != nil {
+		return err
+	}
+	if !loan.Amount.IsPositive() {
+		return domain.ErrInvalidLoanAmount
+	}
+	if loan.Term < 1 || loan.Term > domain.MaxLoanTerm {
+		return domain.ErrInvalidLoanTerm
+	}
+	if loan.InterestRate < 0 {
+		return domain.ErrInvalidInterestRate
+	}
+	if loan.RepaymentFrequency.PeriodsPerYear() == 0 {
+		return domain.ErrInvalidFrequency
+	}
+	if !loan.AmortizationMethod.IsValid() {
+		return domain.ErrInvalidAmortization
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"assesment/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func usd(cents int64) domain.Money {
+	return domain.NewMoney(cents, "USD")
+}
+
+func TestGenerateSchedule(t *testing.T) {
+	start := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		loan     domain.Loan
+		first    [3]int64 // principal, interest, remaining balance in cents
+		last     [3]int64
+		interest int64
+	}{
+		{
+			name:     "annuity",
+			loan:     domain.Loan{Amount: usd(1000000), Term: 12, InterestRate: 12, RepaymentFrequency: domain.FrequencyMonthly, AmortizationMethod: domain.AmortizationAnnuity},
+			first:    [3]int64{78849, 10000, 921151},
+			last:     [3]int64{87967, 880, 0},
+			interest: 66186,
+		},
+		{
+			name:     "annuity last installment takes the rounding remainder",
+			loan:     domain.Loan{Amount: usd(100000), Term: 3, InterestRate: 10, RepaymentFrequency: domain.FrequencyMonthly, AmortizationMethod: domain.AmortizationAnnuity},
+			first:    [3]int64{33057, 833, 66943},
+			last:     [3]int64{33611, 280, 0},
+			interest: 1671,
+		},
+		{
+			name:     "interest-free",
+			loan:     domain.Loan{Amount: usd(100000), Term: 3, InterestRate: 0, RepaymentFrequency: domain.FrequencyMonthly, AmortizationMethod: domain.AmortizationAnnuity},
+			first:    [3]int64{33333, 0, 66667},
+			last:     [3]int64{33334, 0, 0},
+			interest: 0,
+		},
+		{
+			name:     "flat",
+			loan:     domain.Loan{Amount: usd(100000), Term: 3, InterestRate: 12, RepaymentFrequency: domain.FrequencyMonthly, AmortizationMethod: domain.AmortizationFlat},
+			first:    [3]int64{33333, 1000, 66667},
+			last:     [3]int64{33334, 1000, 0},
+			interest: 3000,
+		},
+		{
+			name:     "flat installments rounded up never repay more than the balance",
+			loan:     domain.Loan{Amount: usd(150), Term: 100, InterestRate: 1, RepaymentFrequency: domain.FrequencyMonthly, AmortizationMethod: domain.AmortizationFlat},
+			first:    [3]int64{2, 0, 148},
+			last:     [3]int64{0, 12, 0},
+			interest: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := generateSchedule(tt.loan, start)
+			if err != nil {
+				t.Fatalf("generateSchedule: %v", err)
+			}
+			if len(schedule) != tt.loan.Term {
+				t.Fatalf("got %d installments, want %d", len(schedule), tt.loan.Term)
+			}
+
+			check := func(inst domain.Installment, want [3]int64) {
+				t.Helper()
+				got := [3]int64{inst.Principal.Amount, inst.Interest.Amount, inst.RemainingBalance.Amount}
+				if got != want {
+					t.Errorf("installment %d: got principal, interest, balance %v, want %v", inst.Number, got, want)
+				}
+				if inst.Total != inst.Principal.Add(inst.Interest) {
+					t.Errorf("installment %d: total %s is not principal plus interest", inst.Number, inst.Total)
+				}
+			}
+			check(schedule[0], tt.first)
+			check(schedule[len(schedule)-1], tt.last)
+
+			principal, interest := usd(0), usd(0)
+			for _, inst := range schedule {
+				if inst.Principal.IsNegative() || inst.Interest.IsNegative() || inst.RemainingBalance.IsNegative() {
+					t.Errorf("installment %d: negative principal, interest or balance", inst.Number)
+				}
+				principal = principal.Add(inst.Principal)
+				interest = interest.Add(inst.Interest)
+			}
+			if principal != tt.loan.Amount {
+				t.Errorf("principal repaid %s, want %s", principal, tt.loan.Amount)
+			}
+			if interest.Amount != tt.interest {
+				t.Errorf("total interest %d, want %d", interest.Amount, tt.interest)
+			}
+		})
+	}
+}
+
+func TestGenerateScheduleOriginationFee(t *testing.T) {
+	loan := domain.Loan{Amount: usd(100000), Term: 3, InterestRate: 0, RepaymentFrequency: domain.FrequencyMonthly, AmortizationMethod: domain.AmortizationAnnuity, OriginationFee: usd(1500)}
+
+	schedule, err := generateSchedule(loan, time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("generateSchedule: %v", err)
+	}
+	if schedule[0].Fees != usd(1500) || schedule[0].Total != usd(34833) {
+		t.Errorf("first installment fees %s, total %s; want 15.00 USD, 348.33 USD", schedule[0].Fees, schedule[0].Total)
+	}
+	if schedule[1].Fees.IsPositive() {
+		t.Errorf("second installment fees %s, want none", schedule[1].Fees)
+	}
+}
+
+func TestGenerateScheduleInvalidTerms(t *testing.T) {
+	valid := domain.Loan{Amount: usd(100000), Term: 12, InterestRate: 10, RepaymentFrequency: domain.FrequencyMonthly, AmortizationMethod: domain.AmortizationAnnuity}
+
+	tests := []struct {
+		name   string
+		change func(*domain.Loan)
+		want   error
+	}{
+		{"zero amount", func(l *domain.Loan) { l.Amount = usd(0) }, domain.ErrInvalidLoanAmount},
+		{"unknown currency", func(l *domain.Loan) { l.Amount = domain.NewMoney(100000, "XXX") }, domain.ErrUnknownCurrency},
+		{"no installments", func(l *domain.Loan) { l.Term = 0 }, domain.ErrInvalidLoanTerm},
+		{"term over the cap", func(l *domain.Loan) { l.Term = domain.MaxLoanTerm + 1 }, domain.ErrInvalidLoanTerm},
+		{"negative rate", func(l *domain.Loan) { l.InterestRate = -1 }, domain.ErrInvalidInterestRate},
+		{"unknown frequency", func(l *domain.Loan) { l.RepaymentFrequency = "daily" }, domain.ErrInvalidFrequency},
+		{"unknown amortization", func(l *domain.Loan) { l.AmortizationMethod = "balloon" }, domain.ErrInvalidAmortization},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := valid
+			tt.change(&loan)
+			if _, err := generateSchedule(loan, time.Now()); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDueDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency domain.RepaymentFrequency
+		start     time.Time
+		n         int
+		want      time.Time
+	}{
+		{"weekly", domain.FrequencyWeekly, time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), 2, time.Date(2024, time.February, 14, 0, 0, 0, 0, time.UTC)},
+		{"biweekly", domain.FrequencyBiweekly, time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), 1, time.Date(2024, time.February, 14, 0, 0, 0, 0, time.UTC)},
+		{"monthly", domain.FrequencyMonthly, time.Date(2024, time.January, 15, 9, 30, 0, 0, time.UTC), 1, time.Date(2024, time.February, 15, 9, 30, 0, 0, time.UTC)},
+		{"clamped to February in a leap year", domain.FrequencyMonthly, time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), 1, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"clamped to February", domain.FrequencyMonthly, time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC), 1, time.Date(2023, time.February, 28, 0, 0, 0, 0, time.UTC)},
+		{"keeps the start day after a short month", domain.FrequencyMonthly, time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), 2, time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		{"clamped to a 30-day month", domain.FrequencyMonthly, time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), 3, time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC)},
+		{"across a year end", domain.FrequencyMonthly, time.Date(2024, time.November, 30, 0, 0, 0, 0, time.UTC), 3, time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.frequency.DueDate(tt.start, tt.n); !got.Equal(tt.want) {
+				t.Errorf("DueDate(%s, %d) = %s, want %s", tt.start.Format(time.DateOnly), tt.n, got, tt.want)
+			}
+		})
+	}
+}